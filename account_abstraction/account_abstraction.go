@@ -12,6 +12,7 @@ import (
 	"github.com/anyproto/any-ns-node/alchemysdk"
 	"github.com/anyproto/any-ns-node/config"
 	"github.com/anyproto/any-ns-node/contracts"
+	"github.com/anyproto/any-ns-node/signer"
 	"github.com/anyproto/any-sync/accountservice"
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
@@ -19,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.uber.org/zap"
 
 	asdk "github.com/anyproto/alchemy-aa-sdk/alchemysdk"
@@ -39,10 +41,26 @@ type anynsAA struct {
 	confContracts config.Contracts
 	contracts     contracts.ContractsService
 	alchemy       alchemysdk.AlchemyAAService
+	signer        signer.Signer
 }
 
 type OperationInfo struct {
 	OperationState nsp.OperationState
+
+	// block that operation was included into (if known)
+	BlockNumber uint64
+	BlockHash   string
+}
+
+// alchemy SDK does not decode the receipt itself
+// so we read block info from the raw eth_getUserOperationReceipt response
+type userOperationReceiptBlock struct {
+	Result struct {
+		Receipt struct {
+			BlockNumber string `json:"blockNumber"`
+			BlockHash   string `json:"blockHash"`
+		} `json:"receipt"`
+	} `json:"result"`
 }
 
 type AccountAbstractionService interface {
@@ -78,6 +96,7 @@ func (aa *anynsAA) Init(a *app.App) (err error) {
 	aa.confContracts = a.MustComponent(config.CName).(*config.Config).GetContracts()
 	aa.contracts = a.MustComponent(contracts.CName).(contracts.ContractsService)
 	aa.alchemy = a.MustComponent(alchemysdk.CName).(alchemysdk.AlchemyAAService)
+	aa.signer = a.MustComponent(signer.CName).(signer.Signer)
 
 	return nil
 }
@@ -191,7 +210,6 @@ func (aa *anynsAA) AdminMintAccessTokens(ctx context.Context, userScwAddress com
 	policyID := aa.aaConfig.GasPolicyId
 
 	adminAddress := common.HexToAddress(aa.confContracts.AddrAdmin)
-	adminPK := aa.confContracts.AdminPk
 
 	var chainID int64 = int64(aa.aaConfig.ChainID)
 
@@ -275,7 +293,7 @@ func (aa *anynsAA) AdminMintAccessTokens(ctx context.Context, userScwAddress com
 	log.Info("jsonDataPre is ready", zap.String("jsonDataPre", string(jsonDATAPre)))
 
 	// 5 - send it
-	response, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATAPre)
+	response, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATAPre)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -296,14 +314,14 @@ func (aa *anynsAA) AdminMintAccessTokens(ctx context.Context, userScwAddress com
 			zap.Int("Error code", responseStruct.Error.Code),
 			zap.String("Error message", responseStruct.Error.Message),
 		)
-		return "", errors.New(responseStruct.Error.Message)
+		return "", bundlerError(response, responseStruct.Error.Message)
 	}
 
 	log.Info("alchemy_requestGasAndPaymasterAndData got response", zap.Any("responseStruct", responseStruct))
 
 	// 6 - now create new transaction
 	appendEntryPoint := true
-	jsonDATA, err := aa.alchemy.CreateRequestAndSign(callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, adminPK, factoryAddr, appendEntryPoint)
+	jsonDATA, err := aa.alchemy.CreateRequestAndSign(ctx, callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, aa.signer, factoryAddr, appendEntryPoint)
 	if err != nil {
 		log.Error("failed to create request", zap.Error(err))
 		return "", err
@@ -312,7 +330,7 @@ func (aa *anynsAA) AdminMintAccessTokens(ctx context.Context, userScwAddress com
 	log.Info("created eth_sendUserOperation request", zap.String("jsonDATA", string(jsonDATA)))
 
 	// send it
-	response, err = aa.alchemy.SendRequest(alchemyApiKey, jsonDATA)
+	response, err = aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATA)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -403,7 +421,7 @@ func (aa *anynsAA) getDataNameRegister(ctx context.Context, fullName string, own
 	log.Info("got nonce", zap.String("scw", scw.String()), zap.Int64("nonce", nonce.Int64()))
 
 	// 2 - create user operation
-	callData, err := aa.getCallDataForNameRegister(fullName, ownerAnyAddress, ownerEthAddress, spaceID, isReverseRecordUpdate, registerPeriodMonths)
+	callData, err := aa.getCallDataForNameRegister(ctx, fullName, ownerAnyAddress, ownerEthAddress, spaceID, isReverseRecordUpdate, registerPeriodMonths)
 	if err != nil {
 		log.Error("failed to get original call data", zap.Error(err))
 		return nil, nil, err
@@ -424,7 +442,7 @@ func (aa *anynsAA) getDataNameRegister(ctx context.Context, fullName string, own
 	log.Info("jsonDataPre is ready", zap.String("jsonDataPre", string(jsonDATAPre)))
 
 	// 3 - send it
-	response, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATAPre)
+	response, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATAPre)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return nil, nil, err
@@ -444,7 +462,7 @@ func (aa *anynsAA) getDataNameRegister(ctx context.Context, fullName string, own
 			zap.Int("Error code", responseStruct.Error.Code),
 			zap.String("Error message", responseStruct.Error.Message),
 		)
-		return nil, nil, errors.New(responseStruct.Error.Message)
+		return nil, nil, bundlerError(response, responseStruct.Error.Message)
 	}
 
 	log.Info("alchemy_requestGasAndPaymasterAndData got response", zap.Any("responseStruct", responseStruct))
@@ -466,7 +484,7 @@ func (aa *anynsAA) getDataNameRegister(ctx context.Context, fullName string, own
 	return jsonData, contextData, nil
 }
 
-func (aa *anynsAA) getCallDataForNameRegister(fullName string, ownerAnyAddress string, ownerEthAddress string, spaceID string, isReverseRecordUpdate bool, registerPeriodMonths uint32) ([]byte, error) {
+func (aa *anynsAA) getCallDataForNameRegister(ctx context.Context, fullName string, ownerAnyAddress string, ownerEthAddress string, spaceID string, isReverseRecordUpdate bool, registerPeriodMonths uint32) ([]byte, error) {
 	registrarControllerPrivate := common.HexToAddress(aa.confContracts.AddrRegistrarPrivateController)
 
 	resolverAddress := common.HexToAddress(aa.confContracts.AddrResolver)
@@ -491,7 +509,7 @@ func (aa *anynsAA) getCallDataForNameRegister(fullName string, ownerAnyAddress s
 		return nil, err
 	}
 
-	commitment, err := aa.contracts.MakeCommitment(&contracts.MakeCommitmentParams{
+	commitment, err := aa.contracts.MakeCommitment(ctx, &contracts.MakeCommitmentParams{
 		NameFirstPart:         nameFirstPart,
 		RegistrantAccount:     registrantAccount,
 		Secret:                secret32,
@@ -609,7 +627,7 @@ func (aa *anynsAA) SendUserOperation(ctx context.Context, contextData []byte, si
 	}
 
 	// 2 - send it
-	response, err := aa.alchemy.SendRequest(aa.aaConfig.AlchemyApiKey, data)
+	response, err := aa.alchemy.SendRequest(ctx, aa.aaConfig.AlchemyApiKey, data)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -653,7 +671,7 @@ func (aa *anynsAA) GetOperation(ctx context.Context, operationID string) (*Opera
 	log.Info("created eth_getUserOperationReceipt request", zap.String("jsonDATA", string(jsonDATA)))
 
 	// send it
-	res, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATA)
+	res, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATA)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return nil, err
@@ -683,16 +701,55 @@ func (aa *anynsAA) GetOperation(ctx context.Context, operationID string) (*Opera
 		return &out, nil
 	}
 
-	// return results
-	if uoRes.Result.Success {
-		out.OperationState = nsp.OperationState_Completed
-	} else {
+	if !uoRes.Result.Success {
 		out.OperationState = nsp.OperationState_Error
+		return &out, nil
+	}
+
+	// 2 - operation is mined, but it is not final until block has enough confirmations
+	out.OperationState, err = aa.getStateByReceiptBlock(ctx, res, &out)
+	if err != nil {
+		log.Error("failed to check operation block", zap.String("operation", operationID), zap.Error(err))
+		return nil, err
 	}
 
 	return &out, nil
 }
 
+func (aa *anynsAA) getStateByReceiptBlock(ctx context.Context, response []byte, out *OperationInfo) (nsp.OperationState, error) {
+	var receipt userOperationReceiptBlock
+	err := json.Unmarshal(response, &receipt)
+	if err == nil && receipt.Result.Receipt.BlockHash != "" {
+		out.BlockNumber, err = hexutil.DecodeUint64(receipt.Result.Receipt.BlockNumber)
+		out.BlockHash = receipt.Result.Receipt.BlockHash
+	}
+
+	// old behaviour - final as soon as it is mined
+	if aa.confContracts.ConfirmationBlocks == 0 {
+		return nsp.OperationState_Completed, nil
+	}
+
+	if err != nil || out.BlockHash == "" {
+		log.Warn("can not get block from operation receipt", zap.Error(err))
+		return nsp.OperationState_PendingOrNotFound, nil
+	}
+
+	confirmed, err := aa.contracts.IsBlockConfirmed(ctx, out.BlockNumber, common.HexToHash(out.BlockHash))
+	if errors.Is(err, contracts.ErrBlockReorged) {
+		// operation will be included into another block
+		log.Warn("block with operation was reorged out", zap.Uint64("block", out.BlockNumber))
+		return nsp.OperationState_PendingOrNotFound, nil
+	}
+	if err != nil {
+		return nsp.OperationState_Error, err
+	}
+	if !confirmed {
+		return nsp.OperationState_PendingOrNotFound, nil
+	}
+
+	return nsp.OperationState_Completed, nil
+}
+
 /*
 func (aa *anynsAA) getUserOperationByHash(ctx context.Context, operationID string) (*OperationInfo, error) {
 	alchemyApiKey := aa.aaConfig.AlchemyApiKey
@@ -715,7 +772,7 @@ func (aa *anynsAA) getUserOperationByHash(ctx context.Context, operationID strin
 	log.Info("created eth_getUserOperationReceipt request", zap.String("jsonDATA", string(jsonDATA)))
 
 	// send it
-	res, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATA)
+	res, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATA)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return nil, err
@@ -750,7 +807,6 @@ func (aa *anynsAA) AdminNameRegister(ctx context.Context, in *nsp.NameRegisterRe
 	policyID := aa.aaConfig.GasPolicyId
 
 	adminAddress := common.HexToAddress(aa.confContracts.AddrAdmin)
-	adminPK := aa.confContracts.AdminPk
 
 	var chainID int64 = int64(aa.aaConfig.ChainID)
 
@@ -800,7 +856,7 @@ func (aa *anynsAA) AdminNameRegister(ctx context.Context, in *nsp.NameRegisterRe
 	spaceID := ""
 	isReverseRecordUpdate := true
 
-	callData, err := aa.getCallDataForNameRegister(in.FullName, in.OwnerAnyAddress, nameOwnerEthAddress, spaceID, isReverseRecordUpdate, in.RegisterPeriodMonths)
+	callData, err := aa.getCallDataForNameRegister(ctx, in.FullName, in.OwnerAnyAddress, nameOwnerEthAddress, spaceID, isReverseRecordUpdate, in.RegisterPeriodMonths)
 	if err != nil {
 		log.Error("failed to get original call data", zap.Error(err))
 		return "", err
@@ -835,7 +891,7 @@ func (aa *anynsAA) AdminNameRegister(ctx context.Context, in *nsp.NameRegisterRe
 	log.Debug("jsonDataPre is ready", zap.String("jsonDataPre", string(jsonDATAPre)))
 
 	// 5 - send it
-	response, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATAPre)
+	response, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATAPre)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -856,14 +912,14 @@ func (aa *anynsAA) AdminNameRegister(ctx context.Context, in *nsp.NameRegisterRe
 			zap.Int("Error code", responseStruct.Error.Code),
 			zap.String("Error message", responseStruct.Error.Message),
 		)
-		return "", errors.New(responseStruct.Error.Message)
+		return "", bundlerError(response, responseStruct.Error.Message)
 	}
 
 	log.Info("alchemy_requestGasAndPaymasterAndData got response", zap.Any("responseStruct", responseStruct))
 
 	// 6 - now create new transaction
 	appendEntryPoint := true
-	jsonDATA, err := aa.alchemy.CreateRequestAndSign(callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, adminPK, factoryAddr, appendEntryPoint)
+	jsonDATA, err := aa.alchemy.CreateRequestAndSign(ctx, callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, aa.signer, factoryAddr, appendEntryPoint)
 	if err != nil {
 		log.Error("failed to create request", zap.Error(err))
 		return "", err
@@ -872,7 +928,7 @@ func (aa *anynsAA) AdminNameRegister(ctx context.Context, in *nsp.NameRegisterRe
 	log.Info("created eth_sendUserOperation request", zap.String("jsonDATA", string(jsonDATA)))
 
 	// send it
-	response, err = aa.alchemy.SendRequest(alchemyApiKey, jsonDATA)
+	response, err = aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATA)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -903,7 +959,6 @@ func (aa *anynsAA) AdminNameRenew(ctx context.Context, in *nsp.NameRenewRequest)
 	policyID := aa.aaConfig.GasPolicyId
 
 	adminAddress := common.HexToAddress(aa.confContracts.AddrAdmin)
-	adminPK := aa.confContracts.AdminPk
 
 	var chainID int64 = int64(aa.aaConfig.ChainID)
 
@@ -982,7 +1037,7 @@ func (aa *anynsAA) AdminNameRenew(ctx context.Context, in *nsp.NameRenewRequest)
 	log.Debug("jsonDataPre is ready", zap.String("jsonDataPre", string(jsonDATAPre)))
 
 	// 5 - send it
-	response, err := aa.alchemy.SendRequest(alchemyApiKey, jsonDATAPre)
+	response, err := aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATAPre)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
@@ -1003,14 +1058,14 @@ func (aa *anynsAA) AdminNameRenew(ctx context.Context, in *nsp.NameRenewRequest)
 			zap.Int("Error code", responseStruct.Error.Code),
 			zap.String("Error message", responseStruct.Error.Message),
 		)
-		return "", errors.New(responseStruct.Error.Message)
+		return "", bundlerError(response, responseStruct.Error.Message)
 	}
 
 	log.Info("alchemy_requestGasAndPaymasterAndData got response", zap.Any("responseStruct", responseStruct))
 
 	// 6 - now create new transaction
 	appendEntryPoint := true
-	jsonDATA, err := aa.alchemy.CreateRequestAndSign(callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, adminPK, factoryAddr, appendEntryPoint)
+	jsonDATA, err := aa.alchemy.CreateRequestAndSign(ctx, callData, responseStruct, chainID, entryPointAddr, adminAddress, adminScw, uint64(nonce.Int64()), id+1, aa.signer, factoryAddr, appendEntryPoint)
 	if err != nil {
 		log.Error("failed to create request", zap.Error(err))
 		return "", err
@@ -1019,7 +1074,7 @@ func (aa *anynsAA) AdminNameRenew(ctx context.Context, in *nsp.NameRenewRequest)
 	log.Info("created eth_sendUserOperation request", zap.String("jsonDATA", string(jsonDATA)))
 
 	// send it
-	response, err = aa.alchemy.SendRequest(alchemyApiKey, jsonDATA)
+	response, err = aa.alchemy.SendRequest(ctx, alchemyApiKey, jsonDATA)
 	if err != nil {
 		log.Error("failed to send request", zap.Error(err))
 		return "", err
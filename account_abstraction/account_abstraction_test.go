@@ -11,15 +11,19 @@ import (
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/net/rpc/rpctest"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 	"github.com/zeebo/assert"
 	"go.uber.org/mock/gomock"
 
 	"github.com/anyproto/any-ns-node/alchemysdk"
 	mock_alchemysdk "github.com/anyproto/any-ns-node/alchemysdk/mock"
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
 	"github.com/anyproto/any-ns-node/config"
 	"github.com/anyproto/any-ns-node/contracts"
 	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
+	"github.com/anyproto/any-ns-node/signer"
 
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
 
@@ -50,7 +54,7 @@ func newFixture(t *testing.T) *fixture {
 
 	fx.config.Contracts = config.Contracts{
 		AddrAdmin:     "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
-		GethUrl:       "xxx",
+		GethUrl:       config.Urls{"xxx"},
 		TokenDecimals: 6,
 	}
 	fx.config.Account.PeerId = "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS"
@@ -59,15 +63,18 @@ func newFixture(t *testing.T) *fixture {
 
 	fx.config.Aa.NameTokensPerName = 10
 
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
 	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
 	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
 	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-	fx.contracts.EXPECT().GenerateAuthOptsForAdmin().MaxTimes(2)
-	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GenerateAuthOptsForAdmin(gomock.Any()).MaxTimes(2)
+	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().ConnectToPrivateController().AnyTimes()
 	fx.contracts.EXPECT().TxByHash(gomock.Any(), gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().MakeCommitment(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().MakeCommitment(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().WaitForTxToStartMining(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().IsContractDeployed(gomock.Any(), gomock.Any()).AnyTimes()
 
@@ -75,12 +82,13 @@ func newFixture(t *testing.T) *fixture {
 	fx.alchemy.EXPECT().Name().Return(alchemysdk.CName).AnyTimes()
 	fx.alchemy.EXPECT().Init(gomock.Any()).AnyTimes()
 	//fx.alchemy.EXPECT().CreateRequestGasAndPaymasterData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
-	//fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).AnyTimes()
+	//fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 
 	fx.a.Register(fx.ts).
 		Register(fx.config).
 		Register(fx.contracts).
 		Register(fx.alchemy).
+		Register(signer.NewWithKey(key)).
 		Register(fx.anynsAA)
 
 	require.NoError(t, fx.a.Start(ctx))
@@ -266,7 +274,7 @@ func TestAAS_MintAccessTokens(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -280,7 +288,7 @@ func TestAAS_MintAccessTokens(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -335,7 +343,7 @@ func TestAAS_MintAccessTokens(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -349,7 +357,7 @@ func TestAAS_MintAccessTokens(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -396,7 +404,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 			return []byte{}, errors.New("fail")
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -439,7 +447,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 			return []byte{}, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			return nil, errors.New("fail")
 		}).AnyTimes()
 
@@ -475,7 +483,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 		}
 
 		// return wrong JSON
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			byteArr := []byte("123A")
 
 			return byteArr, nil
@@ -508,7 +516,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 			OwnerAnyAddress: "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -553,7 +561,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 			OwnerAnyAddress: "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -594,7 +602,7 @@ func TestAAS_GetDataNameRegister(t *testing.T) {
 			OwnerAnyAddress: "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -637,7 +645,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 			return []byte{}, errors.New("fail")
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -681,7 +689,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 			return []byte{}, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			return nil, errors.New("fail")
 		}).AnyTimes()
 
@@ -719,7 +727,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 		}
 
 		// return wrong JSON
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			byteArr := []byte("123A")
 
 			return byteArr, nil
@@ -753,7 +761,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 			SpaceId:         "bafybeibs62gqtignuckfqlcr7lhhihgzh2vorxtmc5afm6uxh4zdcmuwuu",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -799,7 +807,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 			SpaceId:         "bafybeibs62gqtignuckfqlcr7lhhihgzh2vorxtmc5afm6uxh4zdcmuwuu",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -841,7 +849,7 @@ func TestAAS_GetDataNameRegisterForSpace(t *testing.T) {
 			SpaceId:         "bafybeibs62gqtignuckfqlcr7lhhihgzh2vorxtmc5afm6uxh4zdcmuwuu",
 		}
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -980,7 +988,7 @@ func TestAAS_GetCallDataForNameRegister(t *testing.T) {
 		spaceID := "bafybeibs62gqtignuckfqlcr7lhhihgzh2vorxtmc5afm6uxh4zdcmuwuu"
 		isReverseRecordUpdate := true
 
-		_, err := fx.getCallDataForNameRegister(fullName, ownerAnyAddress, ownerEthAddress, spaceID, isReverseRecordUpdate, 100500)
+		_, err := fx.getCallDataForNameRegister(ctx, fullName, ownerAnyAddress, ownerEthAddress, spaceID, isReverseRecordUpdate, 100500)
 		assert.NoError(t, err)
 
 		// the result has some randomness in it (secret)
@@ -998,7 +1006,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -1012,7 +1020,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -1055,7 +1063,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -1069,7 +1077,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -1118,11 +1126,11 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			return nil, errors.New("i cannot")
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -1169,7 +1177,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -1183,7 +1191,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -1221,7 +1229,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return "0x31b09cc37a91866b493ee9a31980e90b94b09195a85599f5e6d6a246c9e20186", nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			// convert asdk.JSONRPCResponseGasAndPaymaster to []byte array
 			response := asdk.JSONRPCResponseGasAndPaymaster{}
 
@@ -1235,7 +1243,7 @@ func TestAAS_SendUserOperation(t *testing.T) {
 			return byteArr, nil
 		}).AnyTimes()
 
-		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().CreateRequestAndSign(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, data interface{}, in interface{}, s interface{}, scw interface{}, nonce interface{}, gasPrice interface{}, x interface{}, y interface{}, z interface{}, xx interface{}, yy interface{}) (out []byte, err error) {
 			var req asdk.JSONRPCRequest
 
 			// convert to JSON
@@ -1281,7 +1289,7 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			response := asdk.JSONRPCResponseUserOpHash{}
 
 			// convert to JSON
@@ -1322,7 +1330,7 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			response := asdk.JSONRPCResponseUserOpHash{}
 			response.Error.Code = 123
 			response.Error.Message = "bad error"
@@ -1367,7 +1375,7 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			response := asdk.JSONRPCResponseUserOpHash{}
 
 			// convert to JSON
@@ -1411,7 +1419,7 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			response := asdk.JSONRPCResponseUserOpHash{}
 			response.Error.Code = 0
 			response.Error.Message = ""
@@ -1458,7 +1466,7 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (out []byte, err error) {
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, apiKey interface{}, in interface{}) (out []byte, err error) {
 			response := asdk.JSONRPCResponseUserOpHash{}
 			response.Error.Code = 0
 			response.Error.Message = ""
@@ -1501,3 +1509,103 @@ func TestAAS_GetOperationInfo(t *testing.T) {
 		assert.Equal(t, op.OperationState, nsp.OperationState_Completed)
 	})
 }
+
+func TestAAS_GetOperationWithConfirmations(t *testing.T) {
+	// raw eth_getUserOperationReceipt response
+	const response = `{"jsonrpc":"2.0","id":0,"result":{"userOpHash":"123","success":true,` +
+		`"receipt":{"blockNumber":"0x64","blockHash":"0x0000000000000000000000000000000000000000000000000000000000000001"}}}`
+
+	setup := func(fx *fixture) {
+		fx.anynsAA.confContracts.ConfirmationBlocks = 5
+
+		fx.alchemy.EXPECT().CreateRequestGetUserOperationReceipt(gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+		fx.alchemy.EXPECT().SendRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte(response), nil).AnyTimes()
+		fx.alchemy.EXPECT().DecodeResponseGetUserOperationReceipt(gomock.Any()).DoAndReturn(func(one interface{}) (ret *asdk.JSONRPCResponseGetOp, err error) {
+			var out asdk.JSONRPCResponseGetOp
+			out.Result.UserOpHash = "123"
+			out.Result.Success = true
+			return &out, nil
+		}).AnyTimes()
+	}
+
+	t.Run("should return PENDING if block is not confirmed yet", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+		setup(fx)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), uint64(100), common.HexToHash("0x01")).Return(false, nil)
+
+		op, err := fx.GetOperation(ctx, "123")
+		assert.NoError(t, err)
+		assert.Equal(t, op.OperationState, nsp.OperationState_PendingOrNotFound)
+		assert.Equal(t, op.BlockNumber, uint64(100))
+	})
+
+	t.Run("should return PENDING if block was reorged out", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+		setup(fx)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, contracts.ErrBlockReorged)
+
+		op, err := fx.GetOperation(ctx, "123")
+		assert.NoError(t, err)
+		assert.Equal(t, op.OperationState, nsp.OperationState_PendingOrNotFound)
+	})
+
+	t.Run("should return error if can not check block", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+		setup(fx)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errors.New("bad error"))
+
+		_, err := fx.GetOperation(ctx, "123")
+		assert.Error(t, err)
+	})
+
+	t.Run("success if block is confirmed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+		setup(fx)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+
+		op, err := fx.GetOperation(ctx, "123")
+		assert.NoError(t, err)
+		assert.Equal(t, op.OperationState, nsp.OperationState_Completed)
+		assert.Equal(t, op.BlockHash, "0x0000000000000000000000000000000000000000000000000000000000000001")
+	})
+}
+
+func TestAAS_BundlerError(t *testing.T) {
+	parsed, err := ac.AnytypeRegistrarControllerPrivateMetaData.GetAbi()
+	require.NoError(t, err)
+	e := parsed.Errors["NameNotAvailable"]
+	args, err := e.Inputs.Pack("hello")
+	require.NoError(t, err)
+	revertData := hexutil.Encode(append(e.ID[:4:4], args...))
+
+	t.Run("revert data as a string", func(t *testing.T) {
+		resp := `{"jsonrpc":"2.0","id":1,"error":{"code":-32521,"message":"execution reverted","data":"` + revertData + `"}}`
+
+		err := bundlerError([]byte(resp), "execution reverted")
+		assert.True(t, errors.Is(err, contracts.ErrNameNotAvailable))
+	})
+
+	t.Run("revert data in the object", func(t *testing.T) {
+		resp := `{"jsonrpc":"2.0","id":1,"error":{"code":-32521,"message":"execution reverted","data":{"revertData":"` + revertData + `"}}}`
+
+		err := bundlerError([]byte(resp), "execution reverted")
+		assert.True(t, errors.Is(err, contracts.ErrNameNotAvailable))
+		assert.Equal(t, contracts.RevertName(err), "NameNotAvailable")
+	})
+
+	t.Run("no revert data", func(t *testing.T) {
+		resp := `{"jsonrpc":"2.0","id":1,"error":{"code":-32500,"message":"AA25 invalid account nonce"}}`
+
+		err := bundlerError([]byte(resp), "AA25 invalid account nonce")
+		assert.Equal(t, err.Error(), "AA25 invalid account nonce")
+		assert.False(t, errors.Is(err, contracts.ErrReverted))
+	})
+}
@@ -1,12 +1,17 @@
 package accountabstraction
 
 import (
+	"encoding/json"
+	"errors"
 	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/contracts"
 )
 
 func getCallDataForMint(smartAccountAddress common.Address, fullTokensToMint *big.Int, tokenDecimals uint8) ([]byte, error) {
@@ -111,3 +116,37 @@ func getCallDataForRegister(
 
 	return inputData, nil
 }
+
+// bundler simulates user operation before accepting it
+// if it was reverted -> revert data is in "error.data" (as a hex string or as {"revertData": "0x..."})
+type bundlerErrorResponse struct {
+	Error struct {
+		Data json.RawMessage `json:"data"`
+	} `json:"error"`
+}
+
+// returns contracts.RevertError if the bundler error has revert data
+// otherwise returns the error with the message
+func bundlerError(response []byte, message string) error {
+	var resp bundlerErrorResponse
+	if err := json.Unmarshal(response, &resp); err != nil || len(resp.Error.Data) == 0 {
+		return errors.New(message)
+	}
+
+	var hexData string
+	if err := json.Unmarshal(resp.Error.Data, &hexData); err != nil {
+		var withRevertData struct {
+			RevertData string `json:"revertData"`
+		}
+		if err := json.Unmarshal(resp.Error.Data, &withRevertData); err != nil {
+			return errors.New(message)
+		}
+		hexData = withRevertData.RevertData
+	}
+
+	data, err := hexutil.Decode(hexData)
+	if err != nil || len(data) == 0 {
+		return errors.New(message)
+	}
+	return contracts.DecodeRevertData(data)
+}
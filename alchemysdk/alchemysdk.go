@@ -1,18 +1,40 @@
 package alchemysdk
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
 	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/signer"
 
 	asdk "github.com/anyproto/alchemy-aa-sdk/alchemysdk"
 )
 
 const CName = "any-ns.alchemysdk"
 
+// TODO: sepolia only! (same as in alchemy-aa-sdk)
+const alchemyUrl = "https://eth-sepolia.g.alchemy.com/v2/"
+
+const defaultBundlerTimeoutSec = 30
+
 var log = logger.NewNamed(CName)
 
 type alchemysdk struct {
+	url string
+	// applied to each request to the bundler
+	// (in addition to the caller's deadline)
+	timeout time.Duration
 }
 
 // A simple wrapper around github.com/anyproto/alchemy-aa-sdk/alchemysdk
@@ -20,10 +42,12 @@ type alchemysdk struct {
 type AlchemyAAService interface {
 	// if factoryAddr is non-null -> will set init code
 	CreateRequestGasAndPaymasterData(callData []byte, sender common.Address, senderScw common.Address, nonce uint64, policyID string, entryPointAddr common.Address, factoryAddr common.Address, id int) (asdk.JSONRPCRequestGasAndPaymaster, error)
-	CreateRequestAndSign(callData []byte, rgap asdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr common.Address, sender common.Address, senderScw common.Address, nonce uint64, id int, myPK string, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error)
+	// UserOperation is signed by the signer (admin)
+	CreateRequestAndSign(ctx context.Context, callData []byte, rgap asdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr common.Address, sender common.Address, senderScw common.Address, nonce uint64, id int, signer signer.Signer, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error)
 
 	// can be used to send any type of request to Alchemy
-	SendRequest(apiKey string, jsonDATA []byte) ([]byte, error)
+	// request is aborted if ctx is cancelled (e.g. client disconnected or node is stopping)
+	SendRequest(ctx context.Context, apiKey string, jsonDATA []byte) ([]byte, error)
 	DecodeResponseSendRequest(response []byte) (opHash string, err error)
 
 	CreateRequestGetUserOperationReceipt(operationHash string, id int) ([]byte, error)
@@ -45,7 +69,13 @@ func New() app.Component {
 }
 
 func (aa *alchemysdk) Init(a *app.App) (err error) {
+	aa.url = alchemyUrl
 
+	timeoutSec := a.MustComponent(config.CName).(*config.Config).GetAA().BundlerTimeoutSec
+	if timeoutSec == 0 {
+		timeoutSec = defaultBundlerTimeoutSec
+	}
+	aa.timeout = time.Duration(timeoutSec) * time.Second
 	return nil
 }
 
@@ -59,8 +89,51 @@ func (aa *alchemysdk) CreateRequestGasAndPaymasterData(callData []byte, sender c
 }
 
 // creates a JSONRPCRequest with "eth_sendUserOperation" formatted data
-func (aa *alchemysdk) CreateRequestAndSign(callData []byte, rgap asdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr common.Address, sender common.Address, senderScw common.Address, nonce uint64, id int, myPK string, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error) {
-	return asdk.CreateRequestAndSign(callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint)
+// same as asdk.CreateRequestAndSign, but the private key is not passed (it is kept by the signer)
+func (aa *alchemysdk) CreateRequestAndSign(ctx context.Context, callData []byte, rgap asdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr common.Address, sender common.Address, senderScw common.Address, nonce uint64, id int, signer signer.Signer, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error) {
+	// 1 - create UserOperation
+	uo := asdk.UserOperation{
+		Sender:               senderScw.String(),
+		Nonce:                fmt.Sprintf("0x%x", nonce),
+		InitCode:             "0x",
+		CallData:             "0x" + hex.EncodeToString(callData),
+		CallGasLimit:         rgap.Result.CallGasLimit,
+		VerificationGasLimit: rgap.Result.VerificationGasLimit,
+		PreVerificationGas:   rgap.Result.PreVerificationGas,
+		MaxFeePerGas:         rgap.Result.MaxFeePerGas,
+		MaxPriorityFeePerGas: rgap.Result.MaxPriorityFeePerGas,
+		PaymasterAndData:     rgap.Result.PaymasterAndData,
+	}
+
+	// SCW will be deployed by this operation
+	if (factoryAddr != common.Address{}) {
+		code, err := accountInitCode(sender, factoryAddr)
+		if err != nil {
+			log.Error("failed to get init code", zap.Error(err))
+			return nil, err
+		}
+		uo.InitCode = "0x" + hex.EncodeToString(code)
+	}
+
+	// 2 - sign
+	sig, err := signer.SignMessage(ctx, userOperationHash(uo, chainID, entryPointAddr))
+	if err != nil {
+		log.Error("failed to sign UserOperation", zap.Error(err))
+		return nil, err
+	}
+	uo.Signature = "0x" + hex.EncodeToString(sig)
+
+	// 3 - create request
+	req := sendUserOperationRequest{
+		ID:      id,
+		JSONRPC: "2.0",
+		Method:  "eth_sendUserOperation",
+		Params:  []interface{}{uo},
+	}
+	if appendEntryPoint {
+		req.Params = append(req.Params, entryPointAddr.String())
+	}
+	return json.Marshal(req)
 }
 
 // creates a JSONRPCRequest with "eth_getUserOperationReceipt" formatted data
@@ -68,8 +141,34 @@ func (aa *alchemysdk) CreateRequestGetUserOperationReceipt(operationHash string,
 	return asdk.CreateRequestGetUserOperationReceipt(operationHash, id)
 }
 
-func (aa *alchemysdk) SendRequest(apiKey string, jsonDATA []byte) ([]byte, error) {
-	return asdk.SendRequest(apiKey, jsonDATA)
+// same as asdk.SendRequest, but with context
+func (aa *alchemysdk) SendRequest(ctx context.Context, apiKey string, jsonDATA []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, aa.timeout)
+	defer cancel()
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, aa.url+apiKey, bytes.NewReader(jsonDATA))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Add("accept", "application/json")
+	r.Header.Add("content-type", "application/json")
+
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		log.Error("failed to send data", zap.Error(err))
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		log.Error("failed to read response", zap.Error(err))
+		return nil, err
+	}
+
+	log.Debug("sent Alchemy request", zap.String("response", string(body)))
+	return body, nil
 }
 
 func (aa *alchemysdk) DecodeResponseSendRequest(response []byte) (opHash string, err error) {
@@ -4,14 +4,22 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	asdk "github.com/anyproto/alchemy-aa-sdk/alchemysdk"
 	"github.com/anyproto/any-sync/app"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 	"github.com/zeebo/assert"
 	"go.uber.org/mock/gomock"
+
+	"github.com/anyproto/any-ns-node/signer"
 )
 
 var ctx = context.Background()
@@ -192,15 +200,16 @@ func TestAA_CreateRequest(t *testing.T) {
 		nonce := uint64(8)
 		id := 32
 
+		key, err := crypto.HexToECDSA("ac4bab11ad6b7ec2c84e5e293710828234ab63b62d377a23681228be588fab57")
+		assert.NoError(t, err)
 		// do not append it only for test, otherwise JSON Unmarshal won't work
-		myPK := "ac4bab11ad6b7ec2c84e5e293710828234ab63b62d377a23681228be588fab57"
 		appendEntryPoint := false
 
 		var chainID int64 = 11155111
 		entryPointAddress := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
 
 		factoryAddr := common.Address{}
-		outBytes, err := fx.CreateRequestAndSign(callData, rgap, chainID, entryPointAddress, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint)
+		outBytes, err := fx.CreateRequestAndSign(ctx, callData, rgap, chainID, entryPointAddress, sender, senderScw, nonce, id, signer.NewWithKey(key), factoryAddr, appendEntryPoint)
 		assert.NoError(t, err)
 
 		// convert byte array to JSON
@@ -239,15 +248,16 @@ func TestAA_CreateRequest(t *testing.T) {
 		nonce := uint64(8)
 		id := 32
 
+		key, err := crypto.HexToECDSA("ac4bab11ad6b7ec2c84e5e293710828234ab63b62d377a23681228be588fab57")
+		assert.NoError(t, err)
 		// do not append it only for test, otherwise JSON Unmarshal won't work
-		myPK := "ac4bab11ad6b7ec2c84e5e293710828234ab63b62d377a23681228be588fab57"
 		appendEntryPoint := false
 
 		var chainID int64 = 11155111
 		entryPointAddress := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
 
 		factoryAddr := common.HexToAddress("0x61d1eeE7FBF652482DEa98A1Df591C626bA09a60")
-		outBytes, err := fx.CreateRequestAndSign(callData, rgap, chainID, entryPointAddress, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint)
+		outBytes, err := fx.CreateRequestAndSign(ctx, callData, rgap, chainID, entryPointAddress, sender, senderScw, nonce, id, signer.NewWithKey(key), factoryAddr, appendEntryPoint)
 		assert.NoError(t, err)
 
 		// convert byte array to JSON
@@ -290,3 +300,49 @@ func TestAA_DecodeSendUserOperationResponse(t *testing.T) {
 		assert.Equal(t, hash, "0xa417d6e564c27e7803097f7c712490896d093e27c6f9f44b0192252d82522792")
 	})
 }
+
+func TestAA_SendRequest(t *testing.T) {
+	newServer := func(t *testing.T, delay time.Duration) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, r.URL.Path, "/key")
+			body, _ := io.ReadAll(r.Body)
+
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	t.Run("success", func(t *testing.T) {
+		srv := newServer(t, 0)
+		aa := &alchemysdk{url: srv.URL + "/", timeout: time.Second}
+
+		out, err := aa.SendRequest(ctx, "key", []byte(`{"id":1}`))
+		assert.NoError(t, err)
+		assert.Equal(t, string(out), `{"id":1}`)
+	})
+
+	t.Run("fail if timeout is exceeded", func(t *testing.T) {
+		srv := newServer(t, time.Minute)
+		aa := &alchemysdk{url: srv.URL + "/", timeout: 50 * time.Millisecond}
+
+		_, err := aa.SendRequest(ctx, "key", []byte(`{"id":1}`))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("fail if caller has cancelled the request", func(t *testing.T) {
+		srv := newServer(t, time.Minute)
+		aa := &alchemysdk{url: srv.URL + "/", timeout: time.Minute}
+
+		cctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := aa.SendRequest(cctx, "key", []byte(`{"id":1}`))
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -5,13 +5,16 @@
 //
 //	mockgen -source=alchemysdk/alchemysdk.go
 //
+
 // Package mock_alchemysdk is a generated GoMock package.
 package mock_alchemysdk
 
 import (
+	context "context"
 	reflect "reflect"
 
 	alchemysdk "github.com/anyproto/alchemy-aa-sdk/alchemysdk"
+	signer "github.com/anyproto/any-ns-node/signer"
 	app "github.com/anyproto/any-sync/app"
 	common "github.com/ethereum/go-ethereum/common"
 	gomock "go.uber.org/mock/gomock"
@@ -41,18 +44,18 @@ func (m *MockAlchemyAAService) EXPECT() *MockAlchemyAAServiceMockRecorder {
 }
 
 // CreateRequestAndSign mocks base method.
-func (m *MockAlchemyAAService) CreateRequestAndSign(callData []byte, rgap alchemysdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr, sender, senderScw common.Address, nonce uint64, id int, myPK string, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error) {
+func (m *MockAlchemyAAService) CreateRequestAndSign(ctx context.Context, callData []byte, rgap alchemysdk.JSONRPCResponseGasAndPaymaster, chainID int64, entryPointAddr, sender, senderScw common.Address, nonce uint64, id int, arg9 signer.Signer, factoryAddr common.Address, appendEntryPoint bool) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRequestAndSign", callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint)
+	ret := m.ctrl.Call(m, "CreateRequestAndSign", ctx, callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, arg9, factoryAddr, appendEntryPoint)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateRequestAndSign indicates an expected call of CreateRequestAndSign.
-func (mr *MockAlchemyAAServiceMockRecorder) CreateRequestAndSign(callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint any) *gomock.Call {
+func (mr *MockAlchemyAAServiceMockRecorder) CreateRequestAndSign(ctx, callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, arg9, factoryAddr, appendEntryPoint any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRequestAndSign", reflect.TypeOf((*MockAlchemyAAService)(nil).CreateRequestAndSign), callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, myPK, factoryAddr, appendEntryPoint)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRequestAndSign", reflect.TypeOf((*MockAlchemyAAService)(nil).CreateRequestAndSign), ctx, callData, rgap, chainID, entryPointAddr, sender, senderScw, nonce, id, arg9, factoryAddr, appendEntryPoint)
 }
 
 // CreateRequestGasAndPaymasterData mocks base method.
@@ -175,16 +178,16 @@ func (mr *MockAlchemyAAServiceMockRecorder) Name() *gomock.Call {
 }
 
 // SendRequest mocks base method.
-func (m *MockAlchemyAAService) SendRequest(apiKey string, jsonDATA []byte) ([]byte, error) {
+func (m *MockAlchemyAAService) SendRequest(ctx context.Context, apiKey string, jsonDATA []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendRequest", apiKey, jsonDATA)
+	ret := m.ctrl.Call(m, "SendRequest", ctx, apiKey, jsonDATA)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendRequest indicates an expected call of SendRequest.
-func (mr *MockAlchemyAAServiceMockRecorder) SendRequest(apiKey, jsonDATA any) *gomock.Call {
+func (mr *MockAlchemyAAServiceMockRecorder) SendRequest(ctx, apiKey, jsonDATA any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRequest", reflect.TypeOf((*MockAlchemyAAService)(nil).SendRequest), apiKey, jsonDATA)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRequest", reflect.TypeOf((*MockAlchemyAAService)(nil).SendRequest), ctx, apiKey, jsonDATA)
 }
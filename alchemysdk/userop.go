@@ -0,0 +1,76 @@
+package alchemysdk
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	asdk "github.com/anyproto/alchemy-aa-sdk/alchemysdk"
+)
+
+// same as in alchemy-aa-sdk, it is not exported there
+const factoryAbi = `[{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"internalType":"contract SimpleAccount","name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"}]`
+
+type sendUserOperationRequest struct {
+	ID      int           `json:"id"`
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// hash of the UserOperation (EntryPoint v0.6) that is signed by the owner of the SCW
+// keccak256(abi.encode(keccak256(pack(uo)), entryPoint, chainID))
+func userOperationHash(uo asdk.UserOperation, chainID int64, entryPointAddr common.Address) []byte {
+	packed := make([]byte, 0, 10*32)
+	packed = append(packed, common.LeftPadBytes(common.HexToAddress(uo.Sender).Bytes(), 32)...)
+	packed = append(packed, uint256(uo.Nonce)...)
+	packed = append(packed, crypto.Keccak256(hexToBytes(uo.InitCode))...)
+	packed = append(packed, crypto.Keccak256(hexToBytes(uo.CallData))...)
+	packed = append(packed, uint256(uo.CallGasLimit)...)
+	packed = append(packed, uint256(uo.VerificationGasLimit)...)
+	packed = append(packed, uint256(uo.PreVerificationGas)...)
+	packed = append(packed, uint256(uo.MaxFeePerGas)...)
+	packed = append(packed, uint256(uo.MaxPriorityFeePerGas)...)
+	packed = append(packed, crypto.Keccak256(hexToBytes(uo.PaymasterAndData))...)
+
+	return crypto.Keccak256(
+		crypto.Keccak256(packed),
+		common.LeftPadBytes(entryPointAddr.Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(chainID).Bytes(), 32),
+	)
+}
+
+// factory address + createAccount(owner, 0)
+func accountInitCode(owner common.Address, factoryAddr common.Address) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(factoryAbi))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parsed.Pack("createAccount", owner, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	return append(factoryAddr.Bytes(), data...), nil
+}
+
+func hexToBytes(s string) []byte {
+	out, _ := hexutil.Decode(s)
+	if out == nil {
+		// "0x" or invalid
+		return []byte{}
+	}
+	return out
+}
+
+func uint256(s string) []byte {
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		v = new(big.Int)
+	}
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
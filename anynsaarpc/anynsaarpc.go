@@ -10,6 +10,7 @@ import (
 	"github.com/anyproto/any-ns-node/cache"
 	"github.com/anyproto/any-ns-node/config"
 	dbservice "github.com/anyproto/any-ns-node/db"
+	"github.com/anyproto/any-ns-node/queue"
 	"github.com/anyproto/any-ns-node/verification"
 	"github.com/anyproto/any-sync/accountservice"
 	"github.com/anyproto/any-sync/app"
@@ -44,6 +45,7 @@ type anynsAARpc struct {
 
 	contracts contracts.ContractsService
 	aa        accountabstraction.AccountAbstractionService
+	queue     queue.QueueService
 	cache     cache.CacheService
 }
 
@@ -56,6 +58,7 @@ func (arpc *anynsAARpc) Init(a *app.App) (err error) {
 	arpc.confAccount = a.MustComponent(config.CName).(*config.Config).GetAccount()
 
 	arpc.aa = a.MustComponent(accountabstraction.CName).(accountabstraction.AccountAbstractionService)
+	arpc.queue = a.MustComponent(queue.CName).(queue.QueueService)
 	arpc.cache = a.MustComponent(cache.CName).(cache.CacheService)
 
 	return nsp.DRPCRegisterAnynsAccountAbstraction(a.MustComponent(server.CName).(server.DRPCServer), arpc)
@@ -129,8 +132,8 @@ func (arpc *anynsAARpc) GetOperation(ctx context.Context, in *nsp.GetOperationSt
 		return nil, errors.New("failed to get cache")
 	}
 
-	// 1 - get operation status from the AA service
-	status, err := arpc.aa.GetOperation(ctx, in.OperationId)
+	// 1 - get operation status from the AA service or from the queue
+	status, err := arpc.getOperationStatus(ctx, in.OperationId)
 	if err != nil {
 		log.Error("failed to get operation info", zap.Error(err))
 		return nil, errors.New("failed to get operation from cache")
@@ -141,7 +144,18 @@ func (arpc *anynsAARpc) GetOperation(ctx context.Context, in *nsp.GetOperationSt
 
 	// 2 - update cache (only once operation is completed)
 	if operationFound && status.OperationState == nsp.OperationState_Completed {
+		// 2.0 - remember the block operation was included into
+		if status.BlockHash != "" && op.BlockHash != status.BlockHash {
+			err = arpc.db.SetOperationBlock(ctx, in.OperationId, status.BlockNumber, status.BlockHash)
+			if err != nil {
+				// not critical
+				log.Warn("failed to save operation block", zap.Error(err))
+			}
+		}
+
 		// 2.1 - is info already is in the cache?
+		// (do not trust in-memory lookups done before the operation was completed)
+		arpc.cache.InvalidateName(op.FullName)
 		cacheRes, err := arpc.cache.IsNameAvailable(ctx, &nsp.NameAvailableRequest{
 			FullName: op.FullName,
 		})
@@ -165,6 +179,25 @@ func (arpc *anynsAARpc) GetOperation(ctx context.Context, in *nsp.GetOperationSt
 	return &out, nil
 }
 
+// operation is sent either by the AA service or by the queue (see backend in config)
+func (arpc *anynsAARpc) getOperationStatus(ctx context.Context, operationID string) (*accountabstraction.OperationInfo, error) {
+	index, isQueueItem := queue.ParseOperationID(operationID)
+	if !isQueueItem {
+		return arpc.aa.GetOperation(ctx, operationID)
+	}
+
+	// block is saved to the item when it is completed
+	item, err := arpc.queue.GetItem(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return &accountabstraction.OperationInfo{
+		OperationState: queue.StatusToState(item.Status),
+		BlockNumber:    uint64(item.BlockNumber),
+		BlockHash:      item.BlockHash,
+	}, nil
+}
+
 func (arpc *anynsAARpc) isAdmin(peerId string) bool {
 	// 1 - check if peer is a payment node!
 	if slices.Contains(arpc.nodeConf.NodeTypes(peerId), nodeconf.NodeTypePaymentProcessingNode) {
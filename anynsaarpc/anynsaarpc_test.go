@@ -32,6 +32,8 @@ import (
 	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
 	db_service "github.com/anyproto/any-ns-node/db"
 	mock_db_service "github.com/anyproto/any-ns-node/db/mock"
+	"github.com/anyproto/any-ns-node/queue"
+	mock_queue "github.com/anyproto/any-ns-node/queue/mock"
 	"github.com/anyproto/any-ns-node/verification"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
 )
@@ -47,6 +49,7 @@ type fixture struct {
 	contracts *mock_contracts.MockContractsService
 	aa        *mock_accountabstraction.MockAccountAbstractionService
 	cache     *mock_cache.MockCacheService
+	queue     *mock_queue.MockQueueService
 	db        *mock_db_service.MockDbService
 
 	*anynsAARpc
@@ -75,12 +78,15 @@ func newFixture(t *testing.T, adminSignKey string) *fixture {
 	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
 	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().GetBalanceOf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
+	fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().IsContractDeployed(gomock.Any(), gomock.Any()).AnyTimes()
 
 	fx.cache = mock_cache.NewMockCacheService(fx.ctrl)
 	fx.cache.EXPECT().Name().Return(cache.CName).AnyTimes()
 	fx.cache.EXPECT().Init(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Run(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Close(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().InvalidateName(gomock.Any()).AnyTimes()
 
 	fx.db = mock_db_service.NewMockDbService(fx.ctrl)
 	fx.db.EXPECT().Name().Return(db_service.CName).AnyTimes()
@@ -88,7 +94,7 @@ func newFixture(t *testing.T, adminSignKey string) *fixture {
 
 	fx.config.Contracts = config.Contracts{
 		AddrAdmin: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
-		GethUrl:   "xxx",
+		GethUrl:   config.Urls{"xxx"},
 	}
 
 	fx.config.Mongo = config.Mongo{
@@ -112,12 +118,19 @@ func newFixture(t *testing.T, adminSignKey string) *fixture {
 	fx.aa.EXPECT().Name().Return(accountabstraction.CName).AnyTimes()
 	fx.aa.EXPECT().Init(gomock.Any()).AnyTimes()
 
+	fx.queue = mock_queue.NewMockQueueService(fx.ctrl)
+	fx.queue.EXPECT().Name().Return(queue.CName).AnyTimes()
+	fx.queue.EXPECT().Init(gomock.Any()).AnyTimes()
+	fx.queue.EXPECT().Run(gomock.Any()).AnyTimes()
+	fx.queue.EXPECT().Close(gomock.Any()).AnyTimes()
+
 	fx.a.Register(fx.ts).
 		// this generates new random account every Init
 		// Register(&accounttest.AccountTestService{}).
 		Register(fx.config).
 		Register(fx.contracts).
 		Register(fx.aa).
+		Register(fx.queue).
 		Register(fx.cache).
 		Register(fx.nodeConf).
 		Register(fx.db).
@@ -343,6 +356,62 @@ func TestAnynsRpc_GetOperation(t *testing.T) {
 		require.Equal(t, resp.OperationState, nsp.OperationState_Pending)
 	})
 
+	t.Run("success if operation was sent by the queue", func(t *testing.T) {
+		fx := newFixture(t, "")
+		defer fx.finish(t)
+
+		fx.queue.EXPECT().GetItem(gomock.Any(), int64(12)).Return(&queue.QueueItem{Index: 12, Status: queue.OperationStatus_CommitSent}, nil)
+
+		fx.db.EXPECT().GetOperation(gomock.Any(), "queue-12").Return(db_service.AAUserOperation{}, mongo.ErrNoDocuments)
+
+		gosr := nsp.GetOperationStatusRequest{
+			OperationId: queue.OperationID(12),
+		}
+		resp, err := fx.GetOperation(context.Background(), &gosr)
+		require.NoError(t, err)
+		require.Equal(t, resp.OperationId, "queue-12")
+		require.Equal(t, resp.OperationState, nsp.OperationState_Pending)
+	})
+
+	t.Run("save block of the completed queue operation", func(t *testing.T) {
+		fx := newFixture(t, "")
+		defer fx.finish(t)
+
+		fx.queue.EXPECT().GetItem(gomock.Any(), int64(12)).Return(&queue.QueueItem{
+			Index:       12,
+			Status:      queue.OperationStatus_Completed,
+			BlockNumber: 100,
+			BlockHash:   "0x01",
+		}, nil)
+
+		fx.db.EXPECT().GetOperation(gomock.Any(), "queue-12").Return(db_service.AAUserOperation{OperationID: "queue-12", FullName: "hello.any"}, nil)
+		fx.db.EXPECT().SetOperationBlock(gomock.Any(), "queue-12", uint64(100), "0x01").Return(nil)
+
+		fx.cache.EXPECT().IsNameAvailable(gomock.Any(), gomock.Any()).Return(&nsp.NameAvailableResponse{}, nil)
+
+		gosr := nsp.GetOperationStatusRequest{
+			OperationId: queue.OperationID(12),
+		}
+		resp, err := fx.GetOperation(context.Background(), &gosr)
+		require.NoError(t, err)
+		require.Equal(t, resp.OperationState, nsp.OperationState_Completed)
+	})
+
+	t.Run("fail if queue item is not found", func(t *testing.T) {
+		fx := newFixture(t, "")
+		defer fx.finish(t)
+
+		fx.queue.EXPECT().GetItem(gomock.Any(), int64(12)).Return(nil, queue.ErrItemNotFound)
+
+		fx.db.EXPECT().GetOperation(gomock.Any(), gomock.Any()).Return(db_service.AAUserOperation{}, mongo.ErrNoDocuments)
+
+		gosr := nsp.GetOperationStatusRequest{
+			OperationId: queue.OperationID(12),
+		}
+		_, err := fx.GetOperation(context.Background(), &gosr)
+		require.Error(t, err)
+	})
+
 	t.Run("opertaion completed - already in cache", func(t *testing.T) {
 		fx := newFixture(t, "")
 		defer fx.finish(t)
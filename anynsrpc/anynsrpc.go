@@ -3,7 +3,9 @@ package anynsrpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"slices"
+	"time"
 
 	"github.com/anyproto/any-ns-node/cache"
 	"github.com/anyproto/any-ns-node/config"
@@ -17,11 +19,13 @@ import (
 	"github.com/anyproto/any-sync/net/rpc/server"
 	"github.com/anyproto/any-sync/nodeconf"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.uber.org/zap"
 
 	accountabstraction "github.com/anyproto/any-ns-node/account_abstraction"
 	contracts "github.com/anyproto/any-ns-node/contracts"
 	dbservice "github.com/anyproto/any-ns-node/db"
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
 )
 
@@ -38,6 +42,7 @@ type anynsRpc struct {
 	conf          *config.Config
 	confContracts config.Contracts
 	confAccount   accountservice.Config
+	confBackend   config.Backend
 	nodeConf      nodeconf.NodeConf
 	contracts     contracts.ContractsService
 	queue         queue.QueueService
@@ -59,7 +64,19 @@ func (arpc *anynsRpc) Init(a *app.App) (err error) {
 	arpc.aa = a.MustComponent(accountabstraction.CName).(accountabstraction.AccountAbstractionService)
 	arpc.db = a.MustComponent(dbservice.CName).(dbservice.DbService)
 
-	return nsp.DRPCRegisterAnyns(a.MustComponent(server.CName).(server.DRPCServer), arpc)
+	arpc.confBackend, err = checkBackend(a.MustComponent(config.CName).(*config.Config).GetBackend())
+	if err != nil {
+		return err
+	}
+
+	srv := a.MustComponent(server.CName).(server.DRPCServer)
+	err = nsp.DRPCRegisterAnyns(srv, arpc)
+	if err != nil {
+		return err
+	}
+
+	// methods that are not in the nameserviceproto yet
+	return nsextproto.DRPCRegisterAnynsExt(srv, arpc)
 }
 
 func (arpc *anynsRpc) Name() (name string) {
@@ -77,11 +94,12 @@ func (arpc *anynsRpc) IsNameAvailable(ctx context.Context, in *nsp.NameAvailable
 
 	in.FullName = fullName
 
-	// 1 - if ReadFromCache is false -> always first read from smart contracts
+	// 1 - if ReadFromCache is false -> first read from smart contracts
+	// (unless it was read just now, see EnsureFresh)
 	// if not, then always just read quickly from cache
 	if !arpc.readFromCache {
 		log.Debug("EXCPLICIT: read data from smart contracts -> cache", zap.String("FullName", in.FullName))
-		err := arpc.cache.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+		err := arpc.cache.EnsureFresh(ctx, &nsp.NameAvailableRequest{
 			FullName: in.FullName,
 		})
 
@@ -91,7 +109,7 @@ func (arpc *anynsRpc) IsNameAvailable(ctx context.Context, in *nsp.NameAvailable
 		}
 	}
 
-	// 2 - check in cache (Mongo)
+	// 2 - check in cache (memory -> Mongo)
 	return arpc.cache.IsNameAvailable(ctx, in)
 }
 
@@ -114,6 +132,67 @@ func (arpc *anynsRpc) GetNameByAnyId(ctx context.Context, in *nsp.NameByAnyIdReq
 	return arpc.cache.GetNameByAnyId(ctx, in)
 }
 
+func (arpc *anynsRpc) GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (*nsextproto.NamesByOwnerResponse, error) {
+	// 1 - check parameters
+	if in.OwnerEthAddress != "" && !common.IsHexAddress(in.OwnerEthAddress) {
+		log.Error("invalid ETH address", zap.String("ETH address", in.OwnerEthAddress))
+		return nil, errors.New("invalid ETH address")
+	}
+	if in.OwnerScwEthAddress != "" && !common.IsHexAddress(in.OwnerScwEthAddress) {
+		log.Error("invalid SCW address", zap.String("SCW address", in.OwnerScwEthAddress))
+		return nil, errors.New("invalid SCW address")
+	}
+
+	// 2 - this method always reads from cache!
+	// there is no way to list names of the owner using smart contracts
+	return arpc.cache.GetNamesByOwner(ctx, in)
+}
+
+func (arpc *anynsRpc) GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (*nsextproto.NameBySpaceIdResponse, error) {
+	// 1 - if ReadFromCache is false -> verify the result using smart contracts
+	// if not, then always just read quickly from cache
+	if !arpc.readFromCache {
+		log.Debug("EXCPLICIT: resolve space ID using smart contracts", zap.String("SpaceId", in.SpaceId))
+		return arpc.getNameBySpaceIdDirectly(ctx, in)
+	}
+
+	// check in cache (Mongo)
+	return arpc.cache.GetNameBySpaceId(ctx, in)
+}
+
+// there is no way to find names by space ID in smart contracts
+// so candidate from the cache is re-read from smart contracts until it is confirmed
+func (arpc *anynsRpc) getNameBySpaceIdDirectly(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (*nsextproto.NameBySpaceIdResponse, error) {
+	const maxCandidates = 5
+
+	for i := 0; i < maxCandidates; i++ {
+		// 1 - get candidate from cache
+		candidate, err := arpc.cache.GetNameBySpaceId(ctx, in)
+		if err != nil || !candidate.Found {
+			return candidate, err
+		}
+
+		// 2 - read data from smart contracts -> cache
+		// (candidate is removed from the cache if it is not registered anymore)
+		err = arpc.cache.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+			FullName: candidate.Name,
+		})
+		if err != nil {
+			log.Error("failed to update in cache", zap.Error(err))
+			return nil, errors.New("failed to update in cache")
+		}
+
+		// 3 - if space ID was not changed -> same name will be returned again
+		res, err := arpc.cache.GetNameBySpaceId(ctx, in)
+		if err != nil || !res.Found || res.Name == candidate.Name {
+			return res, err
+		}
+	}
+
+	log.Error("too many outdated names for space", zap.String("SpaceId", in.SpaceId))
+	return nil, errors.New("failed to get name by space ID")
+}
+
 func (arpc *anynsRpc) getNameByAddressDirectly(ctx context.Context, in *nsp.NameByAddressRequest) (*nsp.NameByAddressResponse, error) {
 	// 1 - check parameters
 	if !common.IsHexAddress(in.OwnerScwEthAddress) {
@@ -124,7 +203,7 @@ func (arpc *anynsRpc) getNameByAddressDirectly(ctx context.Context, in *nsp.Name
 	// convert in.OwnerScwEthAddress to common.Address
 	var addr = common.HexToAddress(in.OwnerScwEthAddress)
 
-	name, err := arpc.contracts.GetNameByAddress(addr)
+	name, err := arpc.contracts.GetNameByAddress(ctx, addr)
 	if err != nil {
 		log.Error("failed to get name by address", zap.Error(err))
 		return nil, errors.New("failed to get name by address")
@@ -187,17 +266,8 @@ func (arpc *anynsRpc) AdminNameRegisterSigned(ctx context.Context, in *nsp.NameR
 		return nil, err
 	}
 
-	// old version: process it manually in the queue
-	/*
-		// 4 - add to queue
-		operationId, err := arpc.queue.AddNewRequest(ctx, &nrr)
-		resp.OperationId = fmt.Sprint(operationId)
-		resp.OperationState = nsp.OperationState_Pending
-		return &resp, err
-	*/
-
-	// 4 - new version - use AA to process it
-	opID, err := arpc.aa.AdminNameRegister(ctx, &nrr)
+	// 4 - send it with the AA or with the queue
+	opID, err := arpc.nameRegister(ctx, &nrr)
 	if err != nil {
 		log.Error("failed to process AdminNameRegister", zap.Error(err))
 		return nil, err
@@ -252,17 +322,8 @@ func (arpc *anynsRpc) AdminNameRenewSigned(ctx context.Context, in *nsp.NameRene
 
 	// TODO: validate renew parameters without waiting for TX to fail in the smart contract
 
-	// old version: process it manually in the queue
-	/*
-		// 4 - add to queue
-		operationId, err := arpc.queue.AddNewRequest(ctx, &nrr)
-		resp.OperationId = fmt.Sprint(operationId)
-		resp.OperationState = nsp.OperationState_Pending
-		return &resp, err
-	*/
-
-	// 4 - new version - use AA to process it
-	opID, err := arpc.aa.AdminNameRenew(ctx, &nrr)
+	// 4 - send it with the AA or with the queue
+	opID, err := arpc.nameRenew(ctx, &nrr)
 	if err != nil {
 		log.Error("failed to process AdminNameRegister", zap.Error(err))
 		return nil, err
@@ -290,15 +351,79 @@ func (arpc *anynsRpc) AdminNameRenewSigned(ctx context.Context, in *nsp.NameRene
 	return &out, err
 }
 
+// "aa" is used if backend is not set
+func checkBackend(conf config.Backend) (config.Backend, error) {
+	if conf.NameRegister == "" {
+		conf.NameRegister = config.BackendAA
+	}
+	if conf.NameRenew == "" {
+		conf.NameRenew = config.BackendAA
+	}
+
+	if conf.NameRegister != config.BackendAA && conf.NameRegister != config.BackendQueue {
+		return conf, fmt.Errorf("unknown backend.nameRegister: %q", conf.NameRegister)
+	}
+	if conf.NameRenew != config.BackendAA && conf.NameRenew != config.BackendQueue {
+		return conf, fmt.Errorf("unknown backend.nameRenew: %q", conf.NameRenew)
+	}
+	return conf, nil
+}
+
+// returns ID of the user operation (AA) or index of the item (queue)
+func (arpc *anynsRpc) nameRegister(ctx context.Context, nrr *nsp.NameRegisterRequest) (opID string, err error) {
+	if arpc.confBackend.NameRegister == config.BackendQueue {
+		index, err := arpc.queue.AddNewRequest(ctx, nrr)
+		if err != nil {
+			return "", err
+		}
+		return queue.OperationID(index), nil
+	}
+
+	return arpc.aa.AdminNameRegister(ctx, nrr)
+}
+
+func (arpc *anynsRpc) nameRenew(ctx context.Context, nrr *nsp.NameRenewRequest) (opID string, err error) {
+	if arpc.confBackend.NameRenew == config.BackendQueue {
+		index, err := arpc.queue.AddRenewRequest(ctx, nrr)
+		if err != nil {
+			return "", err
+		}
+		return queue.OperationID(index), nil
+	}
+
+	return arpc.aa.AdminNameRenew(ctx, nrr)
+}
+
 // Batch methods
 func (arpc *anynsRpc) BatchIsNameAvailable(ctx context.Context, in *nsp.BatchNameAvailableRequest) (out *nsp.BatchNameAvailableResponse, err error) {
-	// for each string in in.FullNames call IsNameAvailable and collect results into out.NameAvailableResponse[]
 	out = &nsp.BatchNameAvailableResponse{
 		Results: make([]*nsp.NameAvailableResponse, len(in.FullNames)),
 	}
 
-	for i, fullName := range in.FullNames {
-		resp, err := arpc.IsNameAvailable(ctx, &nsp.NameAvailableRequest{
+	// 1 - normalize all names (including .any suffix)
+	useEnsip15 := arpc.conf.Ensip15Validation
+	fullNames := make([]string, len(in.FullNames))
+	for i, name := range in.FullNames {
+		fullNames[i], err = contracts.NormalizeAnyName(name, useEnsip15)
+		if err != nil {
+			log.Error("failed to normalize name", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	// 2 - if ReadFromCache is false -> read all names from smart contracts at once
+	if !arpc.readFromCache {
+		log.Debug("EXCPLICIT: read data from smart contracts -> cache", zap.Int("names", len(fullNames)))
+		err = arpc.cache.EnsureFreshBatch(ctx, fullNames)
+		if err != nil {
+			log.Error("failed to update in cache", zap.Error(err))
+			return nil, errors.New("failed to update in cache")
+		}
+	}
+
+	// 3 - check each name in cache (memory -> Mongo)
+	for i, fullName := range fullNames {
+		resp, err := arpc.cache.IsNameAvailable(ctx, &nsp.NameAvailableRequest{
 			FullName: fullName,
 		})
 
@@ -356,3 +481,290 @@ func (arpc *anynsRpc) BatchGetNameByAnyId(ctx context.Context, in *nsp.BatchName
 
 	return out, nil
 }
+
+func (arpc *anynsRpc) GetNameAtBlock(ctx context.Context, in *nsextproto.NameAtBlockRequest) (*nsextproto.NameAtBlockResponse, error) {
+	// 1 - check parameters
+	fullName, err := contracts.NormalizeAnyName(in.FullName, arpc.conf.Ensip15Validation)
+	if err != nil {
+		log.Error("failed to normalize name", zap.Error(err))
+		return nil, err
+	}
+
+	var blockHash common.Hash
+	if in.BlockHash != "" {
+		if in.BlockNumber == 0 {
+			return nil, errors.New("block number should be set with block hash")
+		}
+		b, err := hexutil.Decode(in.BlockHash)
+		if err != nil || len(b) != common.HashLength {
+			log.Error("invalid block hash", zap.String("block hash", in.BlockHash))
+			return nil, errors.New("invalid block hash")
+		}
+		blockHash = common.BytesToHash(b)
+	}
+
+	// 2 - this method always reads from smart contracts!
+	ndi, err := arpc.cache.ReadNameAtBlock(ctx, fullName, in.BlockNumber, blockHash)
+	if err != nil {
+		log.Error("failed to read name at block", zap.Error(err))
+		return nil, errors.New("failed to read name at block")
+	}
+
+	return &nsextproto.NameAtBlockResponse{
+		Available:          ndi.OwnerEthAddress == "" && ndi.OwnerScwEthAddress == "",
+		OwnerScwEthAddress: ndi.OwnerScwEthAddress,
+		OwnerEthAddress:    ndi.OwnerEthAddress,
+		OwnerAnyAddress:    ndi.OwnerAnyAddress,
+		SpaceId:            ndi.SpaceId,
+		NameExpires:        ndi.NameExpires,
+		BlockNumber:        uint64(ndi.BlockNumber),
+		BlockHash:          ndi.BlockHash,
+	}, nil
+}
+
+func (arpc *anynsRpc) BatchGetNameBySpaceId(ctx context.Context, in *nsextproto.BatchNameBySpaceIdRequest) (*nsextproto.BatchNameBySpaceIdResponse, error) {
+	// for each in.SpaceIds call GetNameBySpaceId and collect results into out.Results[]
+	out := &nsextproto.BatchNameBySpaceIdResponse{
+		Results: make([]*nsextproto.NameBySpaceIdResponse, len(in.SpaceIds)),
+	}
+
+	for i, spaceId := range in.SpaceIds {
+		resp, err := arpc.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{
+			SpaceId: spaceId,
+		})
+
+		// do not ignore error here, stop the cycle!
+		if err != nil {
+			log.Error("failed to call GetNameBySpaceId", zap.Error(err))
+			return nil, err
+		}
+		out.Results[i] = resp
+	}
+
+	return out, nil
+}
+
+const defaultDeadQueueItemsLimit = 100
+
+func (arpc *anynsRpc) checkAdmin(ctx context.Context) error {
+	peerId, err := peer.CtxPeerId(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !arpc.isAdmin(peerId) {
+		log.Error("not an Admin!!!", zap.String("peerId", peerId))
+		return errors.New("not an Admin!!!")
+	}
+	return nil
+}
+
+func (arpc *anynsRpc) AdminQueueGetDeadItems(ctx context.Context, in *nsextproto.DeadQueueItemsRequest) (*nsextproto.DeadQueueItemsResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(in.Limit)
+	if limit == 0 {
+		limit = defaultDeadQueueItemsLimit
+	}
+
+	items, err := arpc.queue.GetDeadItems(ctx, limit)
+	if err != nil {
+		log.Error("failed to get dead items", zap.Error(err))
+		return nil, errors.New("failed to get dead items")
+	}
+
+	out := &nsextproto.DeadQueueItemsResponse{
+		Items: make([]*nsextproto.DeadQueueItem, len(items)),
+	}
+	for i, item := range items {
+		out.Items[i] = deadQueueItemToProto(&item)
+	}
+	return out, nil
+}
+
+func (arpc *anynsRpc) AdminQueueRequeueDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (*nsextproto.DeadQueueItemResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = arpc.queue.RequeueDeadItem(ctx, in.Index)
+	if err != nil {
+		log.Error("failed to requeue dead item", zap.Error(err), zap.Int64("index", in.Index))
+		return nil, err
+	}
+	return &nsextproto.DeadQueueItemResponse{Index: in.Index}, nil
+}
+
+func (arpc *anynsRpc) AdminQueueAbandonDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (*nsextproto.DeadQueueItemResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = arpc.queue.AbandonDeadItem(ctx, in.Index)
+	if err != nil {
+		log.Error("failed to abandon dead item", zap.Error(err), zap.Int64("index", in.Index))
+		return nil, err
+	}
+	return &nsextproto.DeadQueueItemResponse{Index: in.Index}, nil
+}
+
+func deadQueueItemToProto(item *queue.DeadItem) *nsextproto.DeadQueueItem {
+	out := &nsextproto.DeadQueueItem{
+		Index:        item.Index,
+		ItemType:     item.ItemType.String(),
+		FullName:     item.FullName,
+		FailedState:  item.FailedStatus.String(),
+		State:        item.Status.String(),
+		ErrorCode:    item.ErrorCode,
+		ErrorMessage: item.ErrorMessage,
+		Attempts:     queueItemAttemptsToProto(item.Attempts),
+		DateCreated:  item.DateCreated,
+		DateDied:     item.DateDied,
+	}
+	return out
+}
+
+func queueItemAttemptsToProto(attempts []queue.QueueItemAttempt) []*nsextproto.QueueItemAttempt {
+	out := make([]*nsextproto.QueueItemAttempt, len(attempts))
+	for i, a := range attempts {
+		out[i] = &nsextproto.QueueItemAttempt{
+			State:        a.Status.String(),
+			ErrorCode:    a.ErrorCode,
+			ErrorMessage: a.ErrorMessage,
+			Permanent:    a.Permanent,
+			Date:         a.Date,
+		}
+	}
+	return out
+}
+
+const defaultQueueItemsLimit = 100
+
+func (arpc *anynsRpc) AdminQueueGetItems(ctx context.Context, in *nsextproto.QueueItemsRequest) (*nsextproto.QueueItemsResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1 - check parameters
+	filter := queue.ListItemsFilter{
+		MinAge: time.Duration(in.MinAgeSec) * time.Second,
+		Limit:  int64(in.Limit),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultQueueItemsLimit
+	}
+	for _, name := range in.Statuses {
+		status, ok := queue.ParseStatus(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown state: %q", name)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	// 2 - read items
+	items, err := arpc.queue.ListItems(ctx, filter)
+	if err != nil {
+		log.Error("failed to list queue items", zap.Error(err))
+		return nil, errors.New("failed to list queue items")
+	}
+
+	out := &nsextproto.QueueItemsResponse{
+		Items: make([]*nsextproto.QueueItem, len(items)),
+	}
+	for i, item := range items {
+		out.Items[i] = queueItemToProto(&item)
+	}
+	return out, nil
+}
+
+func (arpc *anynsRpc) AdminQueueGetItem(ctx context.Context, in *nsextproto.QueueItemRequest) (*nsextproto.QueueItemResponse, error) {
+	return arpc.queueItemAction(ctx, "get", in, arpc.queue.GetItem)
+}
+
+func (arpc *anynsRpc) AdminQueueCancelItem(ctx context.Context, in *nsextproto.QueueItemRequest) (*nsextproto.QueueItemResponse, error) {
+	return arpc.queueItemAction(ctx, "cancel", in, arpc.queue.CancelItem)
+}
+
+func (arpc *anynsRpc) AdminQueueAdvanceItem(ctx context.Context, in *nsextproto.QueueItemRequest) (*nsextproto.QueueItemResponse, error) {
+	return arpc.queueItemAction(ctx, "advance", in, arpc.queue.AdvanceItem)
+}
+
+func (arpc *anynsRpc) AdminQueueRequeueItem(ctx context.Context, in *nsextproto.QueueItemRequest) (*nsextproto.QueueItemResponse, error) {
+	return arpc.queueItemAction(ctx, "requeue", in, arpc.queue.RequeueItem)
+}
+
+func (arpc *anynsRpc) queueItemAction(ctx context.Context, action string, in *nsextproto.QueueItemRequest, fn func(ctx context.Context, index int64) (*queue.QueueItem, error)) (*nsextproto.QueueItemResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := fn(ctx, in.Index)
+	if err != nil {
+		log.Error("failed to "+action+" queue item", zap.Error(err), zap.Int64("index", in.Index))
+		return nil, err
+	}
+	return &nsextproto.QueueItemResponse{Item: queueItemToProto(item)}, nil
+}
+
+func (arpc *anynsRpc) AdminQueueGetCounts(ctx context.Context, in *nsextproto.QueueCountsRequest) (*nsextproto.QueueCountsResponse, error) {
+	err := arpc.checkAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := arpc.queue.CountItemsByStatus(ctx)
+	if err != nil {
+		log.Error("failed to count queue items", zap.Error(err))
+		return nil, errors.New("failed to count queue items")
+	}
+
+	out := &nsextproto.QueueCountsResponse{
+		Counts: make(map[string]int64, len(counts)),
+	}
+	for status, count := range counts {
+		out.Counts[status.String()] = count
+	}
+	return out, nil
+}
+
+func queueItemToProto(item *queue.QueueItem) *nsextproto.QueueItem {
+	out := &nsextproto.QueueItem{
+		Index:                item.Index,
+		ItemType:             item.ItemType.String(),
+		State:                item.Status.String(),
+		FullName:             item.FullName,
+		OwnerAnyAddress:      item.OwnerAnyAddress,
+		OwnerEthAddress:      item.OwnerEthAddress,
+		SpaceId:              item.SpaceId,
+		RegisterPeriodMonths: item.RegisterPeriodMonths,
+		TxCommitHash:         item.TxCommitHash,
+		TxCommitNonce:        item.TxCommitNonce,
+		TxRegisterHash:       item.TxRegisterHash,
+		TxRegisterNonce:      item.TxRegisterNonce,
+		TxRenewHash:          item.TxRenewHash,
+		TxRenewNonce:         item.TxRenewNonce,
+		TxCurrentNonce:       item.TxCurrentNonce,
+		TxCurrentRetry:       uint32(item.TxCurrentRetry),
+		BlockNumber:          item.BlockNumber,
+		BlockHash:            item.BlockHash,
+		ErrorCode:            item.ErrorCode,
+		ErrorMessage:         item.ErrorMessage,
+		StateRetry:           uint32(item.StateRetry),
+		Attempts:             queueItemAttemptsToProto(item.Attempts),
+		DateCreated:          item.DateCreated,
+		DateModified:         item.DateModified,
+		LeaseOwner:           item.LeaseOwner,
+		LeaseExpires:         item.LeaseExpires,
+	}
+	if item.Status == queue.OperationStatus_Cancelled {
+		out.CancelledState = item.CancelledStatus.String()
+	}
+	return out
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/anyproto/any-sync/net/rpc/rpctest"
 	"github.com/anyproto/any-sync/nodeconf"
 	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 	"github.com/zeebo/assert"
 	"go.uber.org/mock/gomock"
@@ -25,6 +26,7 @@ import (
 	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
 	db_service "github.com/anyproto/any-ns-node/db"
 	mock_db "github.com/anyproto/any-ns-node/db/mock"
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
 	"github.com/anyproto/any-ns-node/queue"
 	mock_queue "github.com/anyproto/any-ns-node/queue/mock"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
@@ -74,6 +76,8 @@ func newFixture(t *testing.T, readFromCache bool) *fixture {
 	fx.cache = mock_cache.NewMockCacheService(fx.ctrl)
 	fx.cache.EXPECT().Name().Return(cache.CName).AnyTimes()
 	fx.cache.EXPECT().Init(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Run(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Close(gomock.Any()).AnyTimes()
 
 	fx.queue = mock_queue.NewMockQueueService(fx.ctrl)
 	fx.queue.EXPECT().Name().Return(queue.CName).AnyTimes()
@@ -150,7 +154,7 @@ func TestAnynsRpc_IsNameAvailable(t *testing.T) {
 		fx := newFixture(t, readFromCache)
 		defer fx.finish(t)
 
-		fx.cache.EXPECT().UpdateInCache(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, nar *nsp.NameAvailableRequest) (err error) {
+		fx.cache.EXPECT().EnsureFresh(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, nar *nsp.NameAvailableRequest) (err error) {
 			return nil
 		})
 
@@ -177,7 +181,7 @@ func TestAnynsRpc_IsNameAvailable(t *testing.T) {
 		fx := newFixture(t, readFromCache)
 		defer fx.finish(t)
 
-		fx.cache.EXPECT().UpdateInCache(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, nar *nsp.NameAvailableRequest) (err error) {
+		fx.cache.EXPECT().EnsureFresh(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, nar *nsp.NameAvailableRequest) (err error) {
 			// see here >
 			return errors.New("failed to update in cache")
 		})
@@ -195,6 +199,7 @@ func TestAnynsRpc_IsNameAvailable(t *testing.T) {
 		defer fx.finish(t)
 
 		fx.cache.EXPECT().UpdateInCache(gomock.Any(), gomock.Any()).MaxTimes(0)
+		fx.cache.EXPECT().EnsureFresh(gomock.Any(), gomock.Any()).MaxTimes(0)
 
 		fx.cache.EXPECT().IsNameAvailable(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, in interface{}) (*nsp.NameAvailableResponse, error) {
 			return &nsp.NameAvailableResponse{
@@ -220,6 +225,43 @@ func TestAnynsRpc_IsNameAvailable(t *testing.T) {
 	})
 }
 
+func TestAnynsRpc_BatchIsNameAvailable(t *testing.T) {
+	t.Run("read all names from smart contracts at once", func(t *testing.T) {
+		fx := newFixture(t, false)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().EnsureFresh(gomock.Any(), gomock.Any()).MaxTimes(0)
+		fx.cache.EXPECT().EnsureFreshBatch(gomock.Any(), []string{"hello.any", "world.any"}).Return(nil)
+		fx.cache.EXPECT().IsNameAvailable(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, in *nsp.NameAvailableRequest) (*nsp.NameAvailableResponse, error) {
+			return &nsp.NameAvailableResponse{
+				Available: in.FullName == "world.any",
+			}, nil
+		}).Times(2)
+
+		resp, err := fx.BatchIsNameAvailable(context.Background(), &nsp.BatchNameAvailableRequest{
+			FullNames: []string{"Hello.any", "world.any"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, len(resp.Results), 2)
+		assert.False(t, resp.Results[0].Available)
+		assert.True(t, resp.Results[1].Available)
+	})
+
+	t.Run("fail if reading from smart contracts failed", func(t *testing.T) {
+		fx := newFixture(t, false)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().EnsureFreshBatch(gomock.Any(), gomock.Any()).Return(errors.New("failed"))
+		fx.cache.EXPECT().IsNameAvailable(gomock.Any(), gomock.Any()).MaxTimes(0)
+
+		_, err := fx.BatchIsNameAvailable(context.Background(), &nsp.BatchNameAvailableRequest{
+			FullNames: []string{"hello.any", "world.any"},
+		})
+		require.Error(t, err)
+	})
+}
+
 func TestAnynsRpc_GetNameByAddress(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		fx := newFixture(t, true)
@@ -272,8 +314,8 @@ func TestAnynsRpc_GetNameByAddress(t *testing.T) {
 		fx := newFixture(t, readFromCache)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-		fx.contracts.EXPECT().GetNameByAddress(gomock.Any()).DoAndReturn(func(owner interface{}) (string, error) {
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, owner interface{}) (string, error) {
 			return "hello.any", nil
 		})
 
@@ -295,8 +337,8 @@ func TestAnynsRpc_GetNameByAddress(t *testing.T) {
 		fx := newFixture(t, readFromCache)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-		fx.contracts.EXPECT().GetNameByAddress(gomock.Any()).DoAndReturn(func(owner interface{}) (string, error) {
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, owner interface{}) (string, error) {
 			return "", errors.New("failed to get name by address")
 		})
 
@@ -313,7 +355,7 @@ func TestAnynsRpc_GetNameByAddress(t *testing.T) {
 		defer fx.finish(t)
 
 		/*
-			fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
+			fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
 			fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).DoAndReturn(func(client interface{}, owner interface{}) (string, error) {
 				return "", nil
 			})
@@ -396,6 +438,159 @@ func TestAnynsRpc_GetNameByAnyId(t *testing.T) {
 	})
 }
 
+func TestAnynsRpc_GetNamesByOwner(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().GetNamesByOwner(gomock.Any(), gomock.Any()).Return(&nsextproto.NamesByOwnerResponse{
+			Names: []*nsextproto.OwnedName{
+				{FullName: "hello.any", NameExpires: 123, IsPrimary: true},
+			},
+		}, nil)
+
+		pctx := context.Background()
+		resp, err := fx.GetNamesByOwner(pctx, &nsextproto.NamesByOwnerRequest{
+			AnyAddress: "A5jC4SXWYEhdFswASPoMYAqWjZb9szm5EGXvS9CMyCE9JCD4",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, len(resp.Names), 1)
+		assert.Equal(t, resp.Names[0].FullName, "hello.any")
+	})
+
+	t.Run("fail if address is invalid", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		pctx := context.Background()
+		_, err := fx.GetNamesByOwner(pctx, &nsextproto.NamesByOwnerRequest{
+			OwnerEthAddress: "0x123",
+		})
+		require.Error(t, err)
+
+		_, err = fx.GetNamesByOwner(pctx, &nsextproto.NamesByOwnerRequest{
+			OwnerScwEthAddress: "hello",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestAnynsRpc_GetNameBySpaceId(t *testing.T) {
+	t.Run("read from cache", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "hello.any"}, nil)
+
+		resp, err := fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{SpaceId: "space"})
+		require.NoError(t, err)
+		assert.True(t, resp.Found)
+		assert.Equal(t, resp.Name, "hello.any")
+	})
+
+	t.Run("verify using smart contracts if not reading from cache", func(t *testing.T) {
+		fx := newFixture(t, false)
+		defer fx.finish(t)
+
+		// 1 - first candidate was moved to another space
+		gomock.InOrder(
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "old.any"}, nil),
+			fx.cache.EXPECT().UpdateInCache(gomock.Any(), &nsp.NameAvailableRequest{FullName: "old.any"}).Return(nil),
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "new.any"}, nil),
+
+			// 2 - second one is still there
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "new.any"}, nil),
+			fx.cache.EXPECT().UpdateInCache(gomock.Any(), &nsp.NameAvailableRequest{FullName: "new.any"}).Return(nil),
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "new.any"}, nil),
+		)
+
+		resp, err := fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{SpaceId: "space"})
+		require.NoError(t, err)
+		assert.True(t, resp.Found)
+		assert.Equal(t, resp.Name, "new.any")
+	})
+
+	t.Run("not found if candidate is not registered anymore", func(t *testing.T) {
+		fx := newFixture(t, false)
+		defer fx.finish(t)
+
+		gomock.InOrder(
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "old.any"}, nil),
+			// removed from the cache
+			fx.cache.EXPECT().UpdateInCache(gomock.Any(), &nsp.NameAvailableRequest{FullName: "old.any"}).Return(nil),
+			fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), gomock.Any()).Return(&nsextproto.NameBySpaceIdResponse{Found: false}, nil),
+		)
+
+		resp, err := fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{SpaceId: "space"})
+		require.NoError(t, err)
+		assert.False(t, resp.Found)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), &nsextproto.NameBySpaceIdRequest{SpaceId: "space1"}).Return(&nsextproto.NameBySpaceIdResponse{Found: true, Name: "hello.any"}, nil)
+		fx.cache.EXPECT().GetNameBySpaceId(gomock.Any(), &nsextproto.NameBySpaceIdRequest{SpaceId: "space2"}).Return(&nsextproto.NameBySpaceIdResponse{Found: false}, nil)
+
+		resp, err := fx.BatchGetNameBySpaceId(ctx, &nsextproto.BatchNameBySpaceIdRequest{SpaceIds: []string{"space1", "space2"}})
+		require.NoError(t, err)
+		assert.Equal(t, len(resp.Results), 2)
+		assert.Equal(t, resp.Results[0].Name, "hello.any")
+		assert.False(t, resp.Results[1].Found)
+	})
+}
+
+func TestAnynsRpc_GetNameAtBlock(t *testing.T) {
+	t.Run("read at the block even if reading from cache", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		hash := common.HexToHash("0x01")
+		fx.cache.EXPECT().ReadNameAtBlock(gomock.Any(), "hello.any", uint64(100), hash).Return(&cache.NameDataItem{
+			FullName:           "hello.any",
+			OwnerScwEthAddress: "0x10d5b0e279e5e4c1d1df5f57dfb7e84813920a51",
+			OwnerEthAddress:    "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5",
+			BlockNumber:        100,
+			BlockHash:          hash.Hex(),
+		}, nil)
+
+		resp, err := fx.GetNameAtBlock(ctx, &nsextproto.NameAtBlockRequest{FullName: "hello.any", BlockNumber: 100, BlockHash: hash.Hex()})
+		require.NoError(t, err)
+		assert.False(t, resp.Available)
+		assert.Equal(t, resp.OwnerScwEthAddress, "0x10d5b0e279e5e4c1d1df5f57dfb7e84813920a51")
+		assert.Equal(t, resp.BlockNumber, uint64(100))
+		assert.Equal(t, resp.BlockHash, hash.Hex())
+	})
+
+	t.Run("not registered at the block", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.cache.EXPECT().ReadNameAtBlock(gomock.Any(), "hello.any", uint64(0), common.Hash{}).Return(&cache.NameDataItem{
+			FullName:    "hello.any",
+			BlockNumber: 120,
+		}, nil)
+
+		resp, err := fx.GetNameAtBlock(ctx, &nsextproto.NameAtBlockRequest{FullName: "hello.any"})
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+		assert.Equal(t, resp.BlockNumber, uint64(120))
+	})
+
+	t.Run("fail if block hash is invalid", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		_, err := fx.GetNameAtBlock(ctx, &nsextproto.NameAtBlockRequest{FullName: "hello.any", BlockNumber: 100, BlockHash: "0x01"})
+		require.Error(t, err)
+
+		_, err = fx.GetNameAtBlock(ctx, &nsextproto.NameAtBlockRequest{FullName: "hello.any", BlockHash: common.HexToHash("0x01").Hex()})
+		require.Error(t, err)
+	})
+}
+
 func TestAnynsRpc_AdminNameRegisterSigned(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		fx := newFixture(t, true)
@@ -441,4 +636,199 @@ func TestAnynsRpc_AdminNameRegisterSigned(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.Equal(t, "operation-id", resp.OperationId)
 	})
+
+	t.Run("success if sent by the queue", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.confBackend.NameRegister = config.BackendQueue
+
+		fx.queue.EXPECT().AddNewRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *nsp.NameRegisterRequest) (int64, error) {
+			assert.Equal(t, req.FullName, "hello.any")
+			return 5, nil
+		})
+		fx.db.EXPECT().SaveOperation(gomock.Any(), "queue-5", gomock.Any()).Return(nil)
+
+		nrrs := signedNameRegisterRequest(t, &nsp.NameRegisterRequest{
+			OwnerAnyAddress: "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
+			FullName:        "hello.any",
+			OwnerEthAddress: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+		})
+
+		pctx := peer.CtxWithPeerId(context.Background(), "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS")
+		resp, err := fx.AdminNameRegisterSigned(pctx, nrrs)
+		require.NoError(t, err)
+		assert.Equal(t, resp.OperationId, "queue-5")
+		assert.Equal(t, resp.OperationState, nsp.OperationState_Pending)
+	})
+
+	t.Run("fail if queue is full", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.confBackend.NameRegister = config.BackendQueue
+
+		fx.queue.EXPECT().AddNewRequest(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("queue is full"))
+
+		nrrs := signedNameRegisterRequest(t, &nsp.NameRegisterRequest{
+			OwnerAnyAddress: "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
+			FullName:        "hello.any",
+			OwnerEthAddress: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+		})
+
+		pctx := peer.CtxWithPeerId(context.Background(), "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS")
+		_, err := fx.AdminNameRegisterSigned(pctx, nrrs)
+		require.Error(t, err)
+	})
+}
+
+func signedNameRegisterRequest(t *testing.T, req *nsp.NameRegisterRequest) *nsp.NameRegisterRequestSigned {
+	signKey, err := crypto.DecodeKeyFromString(
+		"3MFdA66xRw9PbCWlfa620980P4QccXehFlABnyJ/tfwHbtBVHt+KWuXOfyWSF63Ngi70m+gcWtPAcW5fxCwgVg==",
+		crypto.UnmarshalEd25519PrivateKey,
+		nil)
+	require.NoError(t, err)
+
+	var nrrs nsp.NameRegisterRequestSigned
+	nrrs.Payload, err = req.MarshalVT()
+	require.NoError(t, err)
+	nrrs.Signature, err = signKey.Sign(nrrs.Payload)
+	require.NoError(t, err)
+	return &nrrs
+}
+
+func TestAnynsRpc_CheckBackend(t *testing.T) {
+	conf, err := checkBackend(config.Backend{})
+	require.NoError(t, err)
+	assert.Equal(t, conf, config.Backend{NameRegister: config.BackendAA, NameRenew: config.BackendAA})
+
+	conf, err = checkBackend(config.Backend{NameRegister: config.BackendQueue})
+	require.NoError(t, err)
+	assert.Equal(t, conf.NameRegister, config.BackendQueue)
+
+	_, err = checkBackend(config.Backend{NameRegister: "bundler"})
+	assert.Error(t, err)
+
+	_, err = checkBackend(config.Backend{NameRenew: "bundler"})
+	assert.Error(t, err)
+}
+
+func TestAnynsRpc_AdminNameRenewSigned(t *testing.T) {
+	signedRenew := func(t *testing.T, req *nsp.NameRenewRequest) *nsp.NameRenewRequestSigned {
+		signKey, err := crypto.DecodeKeyFromString(
+			"3MFdA66xRw9PbCWlfa620980P4QccXehFlABnyJ/tfwHbtBVHt+KWuXOfyWSF63Ngi70m+gcWtPAcW5fxCwgVg==",
+			crypto.UnmarshalEd25519PrivateKey,
+			nil)
+		require.NoError(t, err)
+
+		var nrrs nsp.NameRenewRequestSigned
+		nrrs.Payload, err = req.MarshalVT()
+		require.NoError(t, err)
+		nrrs.Signature, err = signKey.Sign(nrrs.Payload)
+		require.NoError(t, err)
+		return &nrrs
+	}
+
+	req := &nsp.NameRenewRequest{
+		FullName:          "hello.any",
+		OwnerAnyAddress:   "A5k2d9sFZw84yisTxRnz2bPRd1YPfVfhxqymZ6yESprFTG65",
+		OwnerEthAddress:   "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+		RenewPeriodMonths: 12,
+	}
+	pctx := peer.CtxWithPeerId(context.Background(), "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS")
+
+	t.Run("success", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.aa.EXPECT().AdminNameRenew(gomock.Any(), gomock.Any()).Return("operation-id", nil)
+		fx.db.EXPECT().SaveOperation(gomock.Any(), "operation-id", gomock.Any()).Return(nil)
+
+		resp, err := fx.AdminNameRenewSigned(pctx, signedRenew(t, req))
+		require.NoError(t, err)
+		assert.Equal(t, resp.OperationId, "operation-id")
+	})
+
+	t.Run("success if sent by the queue", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.confBackend.NameRenew = config.BackendQueue
+
+		fx.queue.EXPECT().AddRenewRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, in *nsp.NameRenewRequest) (int64, error) {
+			assert.Equal(t, in.FullName, "hello.any")
+			assert.Equal(t, in.RenewPeriodMonths, uint32(12))
+			return 7, nil
+		})
+		fx.db.EXPECT().SaveOperation(gomock.Any(), "queue-7", gomock.Any()).Return(nil)
+
+		resp, err := fx.AdminNameRenewSigned(pctx, signedRenew(t, req))
+		require.NoError(t, err)
+		assert.Equal(t, resp.OperationId, "queue-7")
+		assert.Equal(t, resp.OperationState, nsp.OperationState_Pending)
+	})
+}
+
+func TestAnynsRpc_AdminQueueDeadItems(t *testing.T) {
+	adminCtx := peer.CtxWithPeerId(context.Background(), "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS")
+	userCtx := peer.CtxWithPeerId(context.Background(), "12D3KooWPANzVZgHqAL57CchRH4q8NGjoWDpUShVovBE3bhhXczy")
+
+	t.Run("get dead items", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.queue.EXPECT().GetDeadItems(gomock.Any(), int64(100)).Return([]queue.DeadItem{
+			{
+				Index:        3,
+				ItemType:     queue.ItemType_NameRegister,
+				FullName:     "hello.any",
+				FailedStatus: queue.OperationStatus_CommitDone,
+				Status:       queue.OperationStatus_RegisterError,
+				ErrorCode:    "NameNotAvailable",
+				ErrorMessage: "name is not available",
+				Attempts: []queue.QueueItemAttempt{
+					{Status: queue.OperationStatus_CommitDone, ErrorCode: "NameNotAvailable", ErrorMessage: "name is not available", Permanent: true, Date: 10},
+				},
+				DateDied: 10,
+			},
+		}, nil)
+
+		resp, err := fx.AdminQueueGetDeadItems(adminCtx, &nsextproto.DeadQueueItemsRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, resp.Items[0].Index, int64(3))
+		assert.Equal(t, resp.Items[0].ItemType, "nameRegister")
+		assert.Equal(t, resp.Items[0].FailedState, "commitDone")
+		assert.Equal(t, resp.Items[0].State, "registerError")
+		assert.Equal(t, resp.Items[0].ErrorCode, "NameNotAvailable")
+		require.Len(t, resp.Items[0].Attempts, 1)
+		assert.True(t, resp.Items[0].Attempts[0].Permanent)
+	})
+
+	t.Run("requeue and abandon", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		fx.queue.EXPECT().RequeueDeadItem(gomock.Any(), int64(3)).Return(nil)
+		fx.queue.EXPECT().AbandonDeadItem(gomock.Any(), int64(4)).Return(queue.ErrItemNotFound)
+
+		resp, err := fx.AdminQueueRequeueDeadItem(adminCtx, &nsextproto.DeadQueueItemRequest{Index: 3})
+		require.NoError(t, err)
+		assert.Equal(t, resp.Index, int64(3))
+
+		_, err = fx.AdminQueueAbandonDeadItem(adminCtx, &nsextproto.DeadQueueItemRequest{Index: 4})
+		assert.True(t, errors.Is(err, queue.ErrItemNotFound))
+	})
+
+	t.Run("fail if not an admin", func(t *testing.T) {
+		fx := newFixture(t, true)
+		defer fx.finish(t)
+
+		_, err := fx.AdminQueueGetDeadItems(userCtx, &nsextproto.DeadQueueItemsRequest{})
+		assert.Error(t, err)
+		_, err = fx.AdminQueueRequeueDeadItem(userCtx, &nsextproto.DeadQueueItemRequest{Index: 3})
+		assert.Error(t, err)
+		_, err = fx.AdminQueueAbandonDeadItem(userCtx, &nsextproto.DeadQueueItemRequest{Index: 3})
+		assert.Error(t, err)
+	})
 }
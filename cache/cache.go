@@ -3,21 +3,45 @@ package cache
 import (
 	"context"
 	"errors"
+	"math/big"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/anyproto/any-ns-node/config"
 	"github.com/anyproto/any-ns-node/contracts"
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 const CName = "any-ns.cache"
 
+// all data is first written here during the full rebuild
+const rebuildCollectionName = "cache-rebuild"
+
+// reverse records are stored separately, one per address
+const reverseCollectionName = "reverse"
+
+// max number of names that are read from the chain at once
+// (each name is 5 calls, so it fits into one multicall)
+const namesPerRead = 100
+
+// non-final entries are read from Mongo in pages of this size
+const recentEntriesPerPage = 100
+
+// max number of non-final entries that are checked in one VerifyRecentEntries call
+const maxRecentEntries = 1000
+
 var log = logger.NewNamed(CName)
 
 type NameDataItem struct {
@@ -28,48 +52,139 @@ type NameDataItem struct {
 	OwnerAnyAddress    string `bson:"owner_any_address"`
 	SpaceId            string `bson:"space_id"`
 	NameExpires        int64  `bson:"name_expires"`
+
+	// block that data was read at
+	// if this block is reorged out -> data is re-read from the canonical chain
+	BlockNumber int64  `bson:"block_number"`
+	BlockHash   string `bson:"block_hash"`
+	// true when block has at least ConfirmationBlocks on top of it
+	Final bool `bson:"final"`
+
+	// unixtime when expiration date was read from the chain last time
+	ExpiryCheckedAt int64 `bson:"expiry_checked_at"`
+	// unixtime when all data was read from the chain last time
+	// old entries are re-read in the background (see RefreshStaleEntries)
+	LastRefreshed int64 `bson:"last_refreshed"`
 }
 
-// TODO: index it
 type findNameDataByName struct {
 	FullName string `bson:"name"`
 }
 
-// TODO: index it
 type findNameDataByAddress struct {
 	OwnerScwEthAddress string `bson:"owner_scw_eth_address"`
 }
 
+type findNameDataByEthAddress struct {
+	OwnerEthAddress string `bson:"owner_eth_address"`
+}
+
 type findNameDataByAnyAddress struct {
 	OwnerAnyAddress string `bson:"owner_any_address"`
 }
 
-func New() app.Component {
+type findNameDataBySpaceId struct {
+	SpaceId string `bson:"space_id"`
+}
+
+type findNameDataByBlock struct {
+	FullName  string `bson:"name"`
+	BlockHash string `bson:"block_hash"`
+}
+
+type findNameDataByFinal struct {
+	Final bool `bson:"final"`
+}
+
+func New() app.ComponentRunnable {
 	return &cacheService{}
 }
 
 type CacheService interface {
 	// call it before you want to check in smart contracts
-	// it will look up data in Mongo
+	// it will look up data in memory, then in Mongo
+	// expired names are reported as available only after the grace period (see GetNameState)
 	IsNameAvailable(ctx context.Context, in *nsp.NameAvailableRequest) (out *nsp.NameAvailableResponse, err error)
+	// reverse lookups return the primary name (on-chain reverse record) if it is still owned by the address
+	// otherwise the name that expires last is returned
 	GetNameByAddress(ctx context.Context, in *nsp.NameByAddressRequest) (out *nsp.NameByAddressResponse, err error)
 	GetNameByAnyId(ctx context.Context, in *nsp.NameByAnyIdRequest) (out *nsp.NameByAddressResponse, err error)
+	// returns one page of names owned by AnyID, owner EOA or SCW
+	GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (out *nsextproto.NamesByOwnerResponse, err error)
+	// if several names are attached to the space -> the one that expires last is returned
+	GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (out *nsextproto.NameBySpaceIdResponse, err error)
 
 	// call it when you need to read REAL data: smart contracts -> cache
-	// will return "not found" if can not find name
 	// will return no error if name is found and data was updated
+	// will return no error if name is not registered (outdated entry is removed from the cache then)
 	// will return error if something went wrong
 	UpdateInCache(ctx context.Context, in *nsp.NameAvailableRequest) (err error)
-
-	app.Component
+	// same as UpdateInCache, but does nothing if name was read from smart contracts
+	// less than MemoryPositiveTtlSec (or MemoryNegativeTtlSec if it is not registered) ago
+	EnsureFresh(ctx context.Context, in *nsp.NameAvailableRequest) (err error)
+	// same as EnsureFresh for many names, but all of them are read in one round trip
+	EnsureFreshBatch(ctx context.Context, fullNames []string) (err error)
+	// removes all in-memory lookups that depend on this name
+	// call it when this node completes an operation with the name
+	InvalidateName(fullName string)
+
+	// reads name data from smart contracts as it was at the block (nothing is written to the cache)
+	// blockNumber 0 means the latest block, blockHash is optional
+	// owner fields are empty if name was not registered at that block
+	ReadNameAtBlock(ctx context.Context, fullName string, blockNumber uint64, blockHash common.Hash) (*NameDataItem, error)
+
+	// will read data for all names from smart contracts into a temporary collection
+	// and then atomically replace the whole cache with it
+	// entries that are written to the cache during the rebuild are lost,
+	// so names that were changed since it has started should be read again (see indexer.Reindex)
+	RebuildCache(ctx context.Context, names []string) (err error)
+
+	// call it periodically to check all entries that are not final yet
+	// entries that were read at a block that is reorged out are read again from the canonical chain
+	// (or removed if name is not registered anymore)
+	// at most maxRecentEntries are checked in one call
+	VerifyRecentEntries(ctx context.Context) (err error)
+
+	// will re-read expiration date of all entries with grace period over
+	// is called periodically in the background
+	VerifyExpiredEntries(ctx context.Context) (err error)
+
+	// will re-read entries that were not refreshed for RefreshTtlSec
+	// (or RefreshNearExpiryTtlSec if name expires soon)
+	// is called periodically in the background
+	RefreshStaleEntries(ctx context.Context) (refreshed int, err error)
+
+	// will read reverse record of the address from the resolver -> cache
+	// is called for the owner every time name is updated in the cache
+	UpdateReverseRecord(ctx context.Context, address string) (err error)
+	// same, but for NameChanged events (they only have the node of "<address>.addr.reverse")
+	// does nothing if address is not known yet
+	UpdateReverseRecordByNode(ctx context.Context, node [32]byte) (err error)
+
+	app.ComponentRunnable
 }
 
 type cacheService struct {
-	confMongo config.Mongo
-	itemColl  *mongo.Collection
+	confMongo   config.Mongo
+	itemColl    *mongo.Collection
+	reverseColl *mongo.Collection
 
 	confContracts config.Contracts
+	confCache     config.Cache
 	contracts     contracts.ContractsService
+
+	mu             sync.Mutex
+	gracePeriodSec *int64
+
+	// in-memory tier in front of Mongo
+	memory *memoryTier
+	// concurrent lookups of the same key are done only once
+	group   singleflight.Group
+	metrics *cacheMetrics
+
+	cancel      context.CancelFunc
+	done        chan bool
+	refreshDone chan bool
 }
 
 func (cs *cacheService) Name() (name string) {
@@ -79,7 +194,17 @@ func (cs *cacheService) Name() (name string) {
 func (cs *cacheService) Init(a *app.App) (err error) {
 	cs.confMongo = a.MustComponent(config.CName).(*config.Config).Mongo
 	cs.confContracts = a.MustComponent(config.CName).(*config.Config).GetContracts()
+	cs.confCache = a.MustComponent(config.CName).(*config.Config).GetCache()
 	cs.contracts = a.MustComponent(contracts.CName).(contracts.ContractsService)
+	cs.setRefreshDefaults()
+	cs.setMemoryDefaults()
+	cs.metrics = newCacheMetrics(a)
+
+	memorySize := int(cs.confCache.MemorySize)
+	if cs.confCache.MemoryDisabled {
+		memorySize = 0
+	}
+	cs.memory = newMemoryTier(memorySize)
 
 	// connect to mongo
 	uri := cs.confMongo.Connect
@@ -96,15 +221,38 @@ func (cs *cacheService) Init(a *app.App) (err error) {
 	if cs.itemColl == nil {
 		return errors.New("failed to connect to MongoDB")
 	}
+	cs.reverseColl = client.Database(dbName).Collection(reverseCollectionName)
 
 	log.Info("mongo for cache connected!")
 
+	cs.done = make(chan bool)
+	cs.refreshDone = make(chan bool)
+	return nil
+}
+
+func (cs *cacheService) Run(ctx context.Context) (err error) {
+	// do not use ctx here, it is used only during app start
+	var workerCtx context.Context
+	workerCtx, cs.cancel = context.WithCancel(context.Background())
+	go cs.expiryWorker(workerCtx)
+	go cs.refreshWorker(workerCtx)
+
 	return nil
 }
 
-// TODO: check if it is even called, this is not a app.ComponentRunnable instance
-// so maybe it won't be called
 func (cs *cacheService) Close(ctx context.Context) (err error) {
+	if cs.cancel != nil {
+		cs.cancel()
+
+		// wait for the workers to stop
+		for _, done := range []chan bool{cs.done, cs.refreshDone} {
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}
+	}
+
 	if cs.itemColl != nil {
 		err = cs.itemColl.Database().Client().Disconnect(ctx)
 		cs.itemColl = nil
@@ -113,9 +261,31 @@ func (cs *cacheService) Close(ctx context.Context) (err error) {
 }
 
 func (cs *cacheService) IsNameAvailable(ctx context.Context, in *nsp.NameAvailableRequest) (out *nsp.NameAvailableResponse, err error) {
+	v, err := cs.tieredLookup(ctx, memoryKeyAvailable+in.FullName, func(ctx context.Context) (interface{}, error) {
+		return cs.isNameAvailable(ctx, in.FullName)
+	}, func(v interface{}) (string, bool) {
+		return in.FullName, !v.(*nsp.NameAvailableResponse).Available
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// response is shared with other callers, so return a copy
+	res := v.(*nsp.NameAvailableResponse)
+	return &nsp.NameAvailableResponse{
+		Available:          res.Available,
+		OwnerEthAddress:    res.OwnerEthAddress,
+		OwnerScwEthAddress: res.OwnerScwEthAddress,
+		OwnerAnyAddress:    res.OwnerAnyAddress,
+		SpaceId:            res.SpaceId,
+		NameExpires:        res.NameExpires,
+	}, nil
+}
+
+func (cs *cacheService) isNameAvailable(ctx context.Context, fullName string) (out *nsp.NameAvailableResponse, err error) {
 	// 1 - lookup in the cache
 	item := &NameDataItem{}
-	err = cs.itemColl.FindOne(ctx, findNameDataByName{FullName: in.FullName}).Decode(&item)
+	err = cs.itemColl.FindOne(ctx, findNameDataByName{FullName: fullName}).Decode(&item)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -126,61 +296,91 @@ func (cs *cacheService) IsNameAvailable(ctx context.Context, in *nsp.NameAvailab
 		return nil, err
 	}
 
-	log.Debug("found item in cache", zap.String("FullName", in.FullName))
+	log.Debug("found item in cache", zap.String("FullName", fullName))
 
-	// 2 - if found in the cache -> return false
-	return &nsp.NameAvailableResponse{
-		Available:          false,
-		OwnerEthAddress:    item.OwnerEthAddress,
-		OwnerScwEthAddress: item.OwnerScwEthAddress,
-		OwnerAnyAddress:    item.OwnerAnyAddress,
-		SpaceId:            item.SpaceId,
-		NameExpires:        item.NameExpires,
-	}, nil
+	// 2 - if found in the cache -> check if it is expired
+	now := time.Now().Unix()
+	state := GetNameState(item, now, 0)
+
+	if state != NameState_Taken {
+		gracePeriodSec, err := cs.getGracePeriod(ctx)
+		if err != nil {
+			// can not say if it is free, so report it as taken
+			log.Error("failed to get grace period", zap.Error(err))
+			return nameStateToResponse(NameState_Taken, item), nil
+		}
+		state = GetNameState(item, now, gracePeriodSec)
+	}
+
+	return nameStateToResponse(state, item), nil
 }
 
 func (cs *cacheService) GetNameByAddress(ctx context.Context, in *nsp.NameByAddressRequest) (out *nsp.NameByAddressResponse, err error) {
 	// 1 - lookup in the cache
-	item := &NameDataItem{}
-
 	// WARNING: convert to lower!
 	inEthAddr := strings.ToLower(in.OwnerScwEthAddress)
-	err = cs.itemColl.FindOne(ctx, findNameDataByAddress{OwnerScwEthAddress: inEthAddr}).Decode(&item)
+	return cs.lookupName(ctx, memoryKeyAddress+inEthAddr, func(ctx context.Context) (*NameDataItem, error) {
+		return cs.getPrimaryNameByAddress(ctx, inEthAddr)
+	})
+}
 
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
+func (cs *cacheService) GetNameByAnyId(ctx context.Context, in *nsp.NameByAnyIdRequest) (out *nsp.NameByAddressResponse, err error) {
+	// 1 - lookup in the cache
+	// WARNING: DO NOT convert to lower!
+	return cs.lookupName(ctx, memoryKeyAnyId+in.AnyAddress, func(ctx context.Context) (*NameDataItem, error) {
+		return cs.getPrimaryNameByAnyId(ctx, in.AnyAddress)
+	})
+}
+
+// reverse lookup through memory -> Mongo
+func (cs *cacheService) lookupName(ctx context.Context, key string, read func(ctx context.Context) (*NameDataItem, error)) (*nsp.NameByAddressResponse, error) {
+	v, err := cs.tieredLookup(ctx, key, func(ctx context.Context) (interface{}, error) {
+		item, err := read(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
 			return &nsp.NameByAddressResponse{Found: false}, nil
 		}
 
-		log.Error("failed to get item from DB", zap.Error(err))
+		// 2 - if found in the cache -> return
+		return &nsp.NameByAddressResponse{
+			Found: true,
+			Name:  item.FullName,
+		}, nil
+	}, func(v interface{}) (string, bool) {
+		res := v.(*nsp.NameByAddressResponse)
+		return res.Name, res.Found
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// 2 - if found in the cache -> return
+	// response is shared with other callers, so return a copy
+	res := v.(*nsp.NameByAddressResponse)
 	return &nsp.NameByAddressResponse{
-		Found: true,
-		Name:  item.FullName,
+		Found: res.Found,
+		Name:  res.Name,
 	}, nil
 }
 
-func (cs *cacheService) GetNameByAnyId(ctx context.Context, in *nsp.NameByAnyIdRequest) (out *nsp.NameByAddressResponse, err error) {
-	// 1 - lookup in the cache
-	item := &NameDataItem{}
+func (cs *cacheService) GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (out *nsextproto.NameBySpaceIdResponse, err error) {
+	if in.SpaceId == "" {
+		return &nsextproto.NameBySpaceIdResponse{Found: false}, nil
+	}
 
+	// 1 - lookup in the cache
 	// WARNING: DO NOT convert to lower!
-	err = cs.itemColl.FindOne(ctx, findNameDataByAnyAddress{OwnerAnyAddress: in.AnyAddress}).Decode(&item)
-
+	item, err := cs.getFallbackName(ctx, findNameDataBySpaceId{SpaceId: in.SpaceId})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return &nsp.NameByAddressResponse{Found: false}, nil
-		}
-
-		log.Error("failed to get item from DB", zap.Error(err))
 		return nil, err
 	}
+	if item == nil {
+		return &nsextproto.NameBySpaceIdResponse{Found: false}, nil
+	}
 
 	// 2 - if found in the cache -> return
-	return &nsp.NameByAddressResponse{
+	return &nsextproto.NameBySpaceIdResponse{
 		Found: true,
 		Name:  item.FullName,
 	}, nil
@@ -189,6 +389,10 @@ func (cs *cacheService) GetNameByAnyId(ctx context.Context, in *nsp.NameByAnyIdR
 // call it when data changes in smart contracts
 // it will write to Mongo
 func (cs *cacheService) setNameData(ctx context.Context, in *NameDataItem) (err error) {
+	return setNameDataTo(ctx, cs.itemColl, in)
+}
+
+func setNameDataTo(ctx context.Context, coll *mongo.Collection, in *NameDataItem) (err error) {
 	filter := findNameDataByName{FullName: in.FullName}
 	opts := options.Replace().SetUpsert(true)
 
@@ -196,7 +400,7 @@ func (cs *cacheService) setNameData(ctx context.Context, in *NameDataItem) (err
 	in.OwnerScwEthAddress = strings.ToLower(in.OwnerScwEthAddress)
 	in.OwnerEthAddress = strings.ToLower(in.OwnerEthAddress)
 
-	_, err = cs.itemColl.ReplaceOne(ctx, filter, in, opts)
+	_, err = coll.ReplaceOne(ctx, filter, in, opts)
 	if err != nil {
 		log.Error("failed to update name data", zap.Error(err))
 		return err
@@ -206,71 +410,442 @@ func (cs *cacheService) setNameData(ctx context.Context, in *NameDataItem) (err
 }
 
 func (cs *cacheService) UpdateInCache(ctx context.Context, in *nsp.NameAvailableRequest) (err error) {
-	log.Debug("reading data from smart contracts -> cache", zap.String("FullName", in.FullName))
+	// concurrent reads of the same name are done only once
+	_, err = cs.doShared(ctx, memoryKeyChain+in.FullName, func(ctx context.Context) (interface{}, error) {
+		ndi, err := cs.updateInCache(ctx, in.FullName)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+
+		// remember that it was just read (see EnsureFresh)
+		cs.memory.set(memoryKeyChain+in.FullName, err, in.FullName, cs.memoryTtl(ndi != nil))
+		return nil, err
+	})
+	return err
+}
+
+func (cs *cacheService) EnsureFresh(ctx context.Context, in *nsp.NameAvailableRequest) (err error) {
+	if v, ok := cs.memory.get(memoryKeyChain + in.FullName); ok {
+		cs.metrics.lookup(tierMemory, true)
+
+		// same result as the last read
+		if v != nil {
+			return v.(error)
+		}
+		return nil
+	}
+	cs.metrics.lookup(tierMemory, false)
+
+	return cs.UpdateInCache(ctx, in)
+}
+
+func (cs *cacheService) EnsureFreshBatch(ctx context.Context, fullNames []string) (err error) {
+	// 1 - skip names that were just read
+	stale := make([]string, 0, len(fullNames))
+	seen := make(map[string]bool, len(fullNames))
+	for _, fullName := range fullNames {
+		if seen[fullName] {
+			continue
+		}
+		seen[fullName] = true
+
+		if v, ok := cs.memory.get(memoryKeyChain + fullName); ok && v == nil {
+			cs.metrics.lookup(tierMemory, true)
+			continue
+		}
+		cs.metrics.lookup(tierMemory, false)
+		stale = append(stale, fullName)
+	}
+
+	// 2 - read all other names at once
+	for from := 0; from < len(stale); from += namesPerRead {
+		chunk := stale[from:min(from+namesPerRead, len(stale))]
+
+		items, err := cs.readNamesData(ctx, chunk)
+		if err != nil {
+			return err
+		}
+
+		for i, fullName := range chunk {
+			ndi, err := cs.storeNameData(ctx, fullName, items[i])
+			if err != nil {
+				return err
+			}
+			cs.memory.set(memoryKeyChain+fullName, nil, fullName, cs.memoryTtl(ndi != nil))
+		}
+	}
+
+	return nil
+}
+
+// returns the data that was written to the cache
+// or nil if name is not registered
+func (cs *cacheService) updateInCache(ctx context.Context, fullName string) (*NameDataItem, error) {
+	log.Debug("reading data from smart contracts -> cache", zap.String("FullName", fullName))
+
+	ndi, err := cs.readNameData(ctx, fullName)
+	if err != nil {
+		return nil, err
+	}
+	return cs.storeNameData(ctx, fullName, ndi)
+}
+
+// writes the data that was just read from the chain
+// nil means that name is not registered (anymore)
+func (cs *cacheService) storeNameData(ctx context.Context, fullName string, ndi *NameDataItem) (*NameDataItem, error) {
+	if ndi == nil {
+		// remove outdated entry if any
+		defer cs.invalidateMemory(fullName, "", "")
+		_, err := cs.itemColl.DeleteOne(ctx, findNameDataByName{FullName: fullName})
+		if err != nil {
+			log.Error("failed to remove name data", zap.Error(err))
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	err := cs.setNameData(ctx, ndi)
+	if err != nil {
+		log.Error("failed to update name data after reading from smart contracts", zap.Error(err))
+		return nil, err
+	}
+
+	// owner could change -> primary name could change too
+	cs.updateReverseRecordForOwner(ctx, ndi)
+	cs.invalidateMemory(fullName, ndi.OwnerScwEthAddress, ndi.OwnerAnyAddress)
+
+	// success
+	return ndi, nil
+}
 
-	// 1 - convert to name hash
-	nh, err := contracts.NameHash(in.FullName)
+// will return nil if name is not registered yet
+func (cs *cacheService) readNameData(ctx context.Context, fullName string) (*NameDataItem, error) {
+	items, err := cs.readNamesData(ctx, []string{fullName})
 	if err != nil {
-		log.Error("can not convert FullName to namehash", zap.Error(err))
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// reads all names at once (see contracts.GetNamesInfo)
+// results are in the same order, nil for names that are not registered yet
+func (cs *cacheService) readNamesData(ctx context.Context, fullNames []string) ([]*NameDataItem, error) {
+	// entries are final right away, so there is no need to pin the block
+	if cs.confContracts.ConfirmationBlocks == 0 {
+		return cs.readNamesDataAt(ctx, fullNames, 0, common.Hash{})
+	}
+
+	// remember the block we are reading at
+	blockNumber, blockHash, err := cs.getCurrentBlock(ctx)
+	if err != nil {
+		log.Error("can not get current block", zap.Error(err))
+		return nil, err
+	}
+	return cs.readNamesDataAt(ctx, fullNames, blockNumber, blockHash)
+}
+
+// all data is read from the same block (pinned by hash)
+// so it can not mix values from before and after the name was changed
+// blockNumber 0 means the latest block (then block of the entries is unknown)
+func (cs *cacheService) readNamesDataAt(ctx context.Context, fullNames []string, blockNumber uint64, blockHash common.Hash) ([]*NameDataItem, error) {
+	// 1 - call contracts
+	at := contracts.BlockRef{Hash: blockHash}
+	if blockNumber > 0 {
+		at.Number = new(big.Int).SetUint64(blockNumber)
+	}
+	infos, err := cs.contracts.GetNamesInfo(ctx, fullNames, at)
+	if err != nil {
+		log.Error("failed to read names from smart contracts", zap.Int("names", len(fullNames)), zap.Error(err))
+		return nil, err
+	}
+
+	// 2 - fill in the items
+	now := time.Now().Unix()
+	items := make([]*NameDataItem, len(infos))
+	for i, info := range infos {
+		if !info.Registered {
+			log.Debug("name is not registered yet", zap.String("FullName", info.FullName))
+			continue
+		}
+
+		ndi := &NameDataItem{
+			FullName:        info.FullName,
+			OwnerAnyAddress: info.OwnerAnyAddress,
+			SpaceId:         info.SpaceId,
+			BlockNumber:     int64(blockNumber),
+			Final:           (cs.confContracts.ConfirmationBlocks == 0),
+			ExpiryCheckedAt: now,
+			LastRefreshed:   now,
+		}
+		if blockHash != (common.Hash{}) {
+			ndi.BlockHash = blockHash.Hex()
+		}
+		if info.NameExpires != nil {
+			ndi.NameExpires = info.NameExpires.Int64()
+		}
+
+		if info.IsScw {
+			ndi.OwnerScwEthAddress = strings.ToLower(info.Owner)
+			ndi.OwnerEthAddress = strings.ToLower(info.ScwOwner.Hex())
+		} else {
+			ndi.OwnerScwEthAddress = ""
+			ndi.OwnerEthAddress = strings.ToLower(info.Owner)
+		}
+		items[i] = ndi
+	}
+
+	return items, nil
+}
+
+func (cs *cacheService) ReadNameAtBlock(ctx context.Context, fullName string, blockNumber uint64, blockHash common.Hash) (*NameDataItem, error) {
+	var err error
+	switch {
+	case blockNumber == 0:
+		blockNumber, blockHash, err = cs.getCurrentBlock(ctx)
+	case blockHash == (common.Hash{}):
+		blockHash, err = cs.contracts.GetBlockHash(ctx, blockNumber)
+	}
+	if err != nil {
+		log.Error("can not get block", zap.Uint64("block", blockNumber), zap.Error(err))
+		return nil, err
+	}
+
+	items, err := cs.readNamesDataAt(ctx, []string{fullName}, blockNumber, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if items[0] == nil {
+		// not registered at that block, but still tell which block it was
+		return &NameDataItem{FullName: fullName, BlockNumber: int64(blockNumber), BlockHash: blockHash.Hex()}, nil
+	}
+	return items[0], nil
+}
+
+func (cs *cacheService) RebuildCache(ctx context.Context, names []string) (err error) {
+	db := cs.itemColl.Database()
+	tmpColl := db.Collection(rebuildCollectionName)
+
+	// 1 - start from scratch (previous rebuild could fail in the middle)
+	err = tmpColl.Drop(ctx)
+	if err != nil {
+		log.Error("failed to drop temporary collection", zap.Error(err))
+		return err
+	}
+
+	// create it explicitly, otherwise it won't exist if there are no names at all
+	err = db.CreateCollection(ctx, rebuildCollectionName)
+	if err != nil {
+		log.Error("failed to create temporary collection", zap.Error(err))
 		return err
 	}
 
-	// 2 - call contract's method
-	log.Info("getting owner for name", zap.String("FullName", in.GetFullName()))
-	addr, err := cs.contracts.GetOwnerForNamehash(ctx, nh)
+	// indexes are not moved with renameCollection, so copy them
+	err = copyIndexes(ctx, cs.itemColl, tmpColl)
 	if err != nil {
-		if err.Error() == "not found" {
-			log.Info("name is not registered yet...")
+		log.Error("failed to copy indexes", zap.Error(err))
+		return err
+	}
+
+	// 2 - read all names from smart contracts
+	// reverse records are updated in place (lookups check that the name is still owned)
+	owners := make(map[string]bool)
+	for from := 0; from < len(names); from += namesPerRead {
+		chunk := names[from:min(from+namesPerRead, len(names))]
+
+		items, err := cs.readNamesData(ctx, chunk)
+		if err != nil {
+			log.Error("failed to read name data", zap.Strings("FullNames", chunk), zap.Error(err))
 			return err
 		}
 
-		log.Error("can not get owner", zap.Error(err))
+		for _, ndi := range items {
+			if ndi == nil {
+				// name was registered once, but now it is not (expired and burned, etc)
+				continue
+			}
+
+			err = setNameDataTo(ctx, tmpColl, ndi)
+			if err != nil {
+				return err
+			}
+
+			if !owners[ndi.OwnerScwEthAddress] {
+				owners[ndi.OwnerScwEthAddress] = true
+				cs.updateReverseRecordForOwner(ctx, ndi)
+			}
+		}
+
+		log.Info("rebuilding cache...", zap.Int("processed", from+len(chunk)), zap.Int("total", len(names)))
+	}
+
+	// 3 - atomically replace the cache
+	// renameCollection is an admin command and should be called with full names
+	cmd := bson.D{
+		{Key: "renameCollection", Value: db.Name() + "." + rebuildCollectionName},
+		{Key: "to", Value: db.Name() + "." + cs.itemColl.Name()},
+		{Key: "dropTarget", Value: true},
+	}
+
+	err = db.Client().Database("admin").RunCommand(ctx, cmd).Err()
+	if err != nil {
+		log.Error("failed to replace cache collection", zap.Error(err))
 		return err
 	}
 
-	// the owner can be NameWrapper
-	log.Info("received owner address", zap.String("Owner addr", addr.Hex()))
-	if (addr == common.Address{}) {
-		log.Info("name is not registered yet...")
-		return nil
+	// everything that was read before could be outdated
+	cs.memory.clear()
+
+	log.Info("cache rebuilt", zap.Int("names", len(names)))
+	return nil
+}
+
+func copyIndexes(ctx context.Context, from *mongo.Collection, to *mongo.Collection) error {
+	cursor, err := from.Indexes().List(ctx)
+	if err != nil {
+		return err
 	}
 
-	// 3 - if name is already registered, then get additional info
-	log.Info("name is already registered...Getting additional info")
-	ea, aa, si, exp, err := cs.contracts.GetAdditionalNameInfo(ctx, addr, in.GetFullName())
+	var specs []struct {
+		Name   string `bson:"name"`
+		Key    bson.D `bson:"key"`
+		Unique bool   `bson:"unique"`
+	}
+	err = cursor.All(ctx, &specs)
 	if err != nil {
-		log.Error("failed to get additional info", zap.Error(err))
 		return err
 	}
 
-	// 4 - update cache
-	var ndi NameDataItem
-	ndi.FullName = in.FullName
-	ndi.OwnerAnyAddress = aa
-	ndi.SpaceId = si
-	ndi.NameExpires = exp.Int64()
+	for _, spec := range specs {
+		// created automatically
+		if spec.Name == "_id_" {
+			continue
+		}
+
+		_, err = to.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    spec.Key,
+			Options: options.Index().SetName(spec.Name).SetUnique(spec.Unique),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cs *cacheService) getCurrentBlock(ctx context.Context) (uint64, common.Hash, error) {
+	blockNumber, err := cs.contracts.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+
+	blockHash, err := cs.contracts.GetBlockHash(ctx, blockNumber)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+
+	return blockNumber, blockHash, nil
+}
+
+func (cs *cacheService) VerifyRecentEntries(ctx context.Context) (err error) {
+	// entries are read page by page, so memory does not grow with the number of entries
+	// at most maxRecentEntries are checked at once, others are checked on the next call
+	var lastId primitive.ObjectID
+	for checked := 0; checked < maxRecentEntries; {
+		// 1 - get next page of entries that are not final yet
+		items, err := cs.findRecentEntries(ctx, lastId)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		lastId = items[len(items)-1].Id
+		checked += len(items)
+
+		// 2 - check each block
+		for _, item := range items {
+			err = cs.verifyRecentEntry(ctx, &item.NameDataItem)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info("too many non-final entries, others will be checked later", zap.Int("checked", maxRecentEntries))
+	return nil
+}
+
+type recentEntry struct {
+	Id           primitive.ObjectID `bson:"_id"`
+	NameDataItem `bson:",inline"`
+}
+
+// entries are sorted by _id, the page starts after afterId
+func (cs *cacheService) findRecentEntries(ctx context.Context, afterId primitive.ObjectID) ([]recentEntry, error) {
+	filter := bson.D{{Key: "final", Value: false}}
+	if !afterId.IsZero() {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: afterId}}})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(recentEntriesPerPage)
+
+	cursor, err := cs.itemColl.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to find non-final entries", zap.Error(err))
+		return nil, err
+	}
 
-	own, err := cs.contracts.GetScwOwner(ctx, common.HexToAddress(ea))
+	var items []recentEntry
+	err = cursor.All(ctx, &items)
 	if err != nil {
-		log.Warn("failed to get SCW -> owner", zap.Error(err))
+		log.Error("failed to decode non-final entries", zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
 
-		ndi.OwnerScwEthAddress = ""
-		ndi.OwnerEthAddress = strings.ToLower(ea)
-	} else {
-		ndi.OwnerScwEthAddress = strings.ToLower(ea)
-		ndi.OwnerEthAddress = strings.ToLower(own.Hex())
+func (cs *cacheService) verifyRecentEntry(ctx context.Context, item *NameDataItem) error {
+	confirmed, err := cs.contracts.IsBlockConfirmed(ctx, uint64(item.BlockNumber), common.HexToHash(item.BlockHash))
+
+	if errors.Is(err, contracts.ErrBlockReorged) {
+		log.Warn("block was reorged out, re-reading name data",
+			zap.String("FullName", item.FullName), zap.Int64("block", item.BlockNumber))
+
+		return cs.rollbackNameData(ctx, item.FullName)
+	}
+	if err != nil {
+		log.Error("failed to check block", zap.Int64("block", item.BlockNumber), zap.Error(err))
+		return err
+	}
+	if !confirmed {
+		return nil
 	}
 
-	// convert unixtime (big int) to string
-	//timestamp := time.Unix(exp.Int64(), 0)
-	//timeString := timestamp.Format("2001-01-02 15:04:05")
+	// entry could be already re-read at a newer block, do not overwrite it
+	filter := findNameDataByBlock{FullName: item.FullName, BlockHash: item.BlockHash}
+	_, err = cs.itemColl.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: findNameDataByFinal{Final: true}}})
+	if err != nil {
+		log.Error("failed to mark name data as final", zap.Error(err))
+		return err
+	}
+	return nil
+}
 
-	err = cs.setNameData(ctx, &ndi)
+// remove data that was derived from a reorged block and read it again
+func (cs *cacheService) rollbackNameData(ctx context.Context, fullName string) error {
+	_, err := cs.itemColl.DeleteOne(ctx, findNameDataByName{FullName: fullName})
 	if err != nil {
-		log.Error("failed to update name data after reading from smart contracts", zap.Error(err))
+		log.Error("failed to remove name data", zap.String("FullName", fullName), zap.Error(err))
+		return err
+	}
+	cs.invalidateMemory(fullName, "", "")
+
+	err = cs.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+		FullName: fullName,
+	})
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
 		return err
 	}
 
-	// success
 	return nil
 }
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
@@ -47,13 +48,15 @@ func newFixture(t *testing.T) *fixture {
 	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
 	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
 	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().GenerateAuthOptsForAdmin().MaxTimes(2)
-	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GenerateAuthOptsForAdmin(gomock.Any()).MaxTimes(2)
+	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().ConnectToPrivateController().AnyTimes()
 	fx.contracts.EXPECT().TxByHash(gomock.Any(), gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().MakeCommitment(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().MakeCommitment(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().WaitForTxToStartMining(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().IsContractDeployed(gomock.Any(), gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	fx.contracts.EXPECT().GetBlockHash(gomock.Any(), gomock.Any()).Return(common.HexToHash("0x01"), nil).AnyTimes()
 
 	fx.config.Mongo = config.Mongo{
 		Connect:  "mongodb://localhost:27017",
@@ -280,14 +283,12 @@ func TestCacheService_setNameData(t *testing.T) {
 }
 
 func TestCacheService_UpdateInCache(t *testing.T) {
-	t.Run("return error if GetOwnerForNamehash fails", func(t *testing.T) {
+	t.Run("return error if reading from smart contracts fails", func(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-		fx.contracts.EXPECT().GetOwnerForNamehash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}) (common.Address, error) {
-			return common.Address{}, errors.New("SOME BIG ERROR")
-		})
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return(nil, errors.New("SOME BIG ERROR"))
 
 		// call it
 		err := fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
@@ -296,72 +297,78 @@ func TestCacheService_UpdateInCache(t *testing.T) {
 		require.Error(t, err)
 	})
 
-	t.Run("return error if GetAdditionalNameInfo fails", func(t *testing.T) {
+	t.Run("do not create new item if not found", func(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
 
-		fx.contracts.EXPECT().GetOwnerForNamehash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}) (common.Address, error) {
-			notEmptyAddr := common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
-			return notEmptyAddr, nil
-		})
-		fx.contracts.EXPECT().GetAdditionalNameInfo(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}, owner interface{}) (string, string, string, *big.Int, error) {
-			return "", "", "", big.NewInt(0), errors.New("SOME BIG ERROR")
-		})
+		// name has no owner -> it is not registered
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return([]*contracts.NameInfo{
+			{FullName: "test.any"},
+		}, nil)
 
 		// call it
 		err := fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
 			FullName: "test.any",
 		})
+		require.NoError(t, err)
+
+		// it should not create new item in Mongo
+		// 2 - check if item is in DB
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
 		require.Error(t, err)
 	})
 
-	t.Run("do not create new item if not found", func(t *testing.T) {
+	t.Run("remove outdated item if not registered anymore", func(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-
-		// if this returns some address -> it means name is taken
-		fx.contracts.EXPECT().GetOwnerForNamehash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}) (common.Address, error) {
-			return common.Address{}, errors.New("not found")
+		err := fx.setNameData(ctx, &NameDataItem{
+			FullName:        "test.any",
+			OwnerEthAddress: "owner",
 		})
+		require.NoError(t, err)
 
-		// call it
-		err := fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+		// name has no owner -> it was burned
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return([]*contracts.NameInfo{
+			{FullName: "test.any"},
+		}, nil)
+
+		// not an error for the callers
+		err = fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
 			FullName: "test.any",
 		})
-		require.Error(t, err)
-		require.Equal(t, "not found", err.Error())
+		require.NoError(t, err)
 
-		// it should create new item in Mongo
-		// 2 - check if item is in DB
 		item := &NameDataItem{}
 		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
-		require.Error(t, err)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
 	})
 
 	t.Run("create new item if found", func(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-
-		// if this returns some address -> it means name is taken
-		fx.contracts.EXPECT().GetOwnerForNamehash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}) (common.Address, error) {
-			notEmptyAddr := common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
-			return notEmptyAddr, nil
-		})
-
-		fx.contracts.EXPECT().GetAdditionalNameInfo(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}, owner interface{}) (string, string, string, *big.Int, error) {
-			return "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51", "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS", "", big.NewInt(12390243), nil
-		})
-
-		// >>> see this:
-		fx.contracts.EXPECT().GetScwOwner(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, addr interface{}) (common.Address, error) {
-			return common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"), nil
-		})
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+		fx.confContracts.ConfirmationBlocks = 6
+
+		// >>> see this: owner is an SCW
+		// all data is read at the same block
+		at := contracts.BlockRef{Number: big.NewInt(100), Hash: common.HexToHash("0x01")}
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, at).Return([]*contracts.NameInfo{
+			{
+				FullName:        "test.any",
+				Registered:      true,
+				Owner:           "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+				IsScw:           true,
+				ScwOwner:        common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"),
+				OwnerAnyAddress: "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS",
+				NameExpires:     big.NewInt(12390243),
+			},
+		}, nil)
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("", nil)
 
 		// call it
 		err := fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
@@ -380,6 +387,38 @@ func TestCacheService_UpdateInCache(t *testing.T) {
 		require.Equal(t, "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5", item.OwnerEthAddress)
 		require.Equal(t, "0x10d5b0e279e5e4c1d1df5f57dfb7e84813920a51", item.OwnerScwEthAddress)
 		require.Equal(t, "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS", item.OwnerAnyAddress)
+		// block that data was read at
+		require.Equal(t, int64(100), item.BlockNumber)
+		require.Equal(t, common.HexToHash("0x01").Hex(), item.BlockHash)
+	})
+
+	t.Run("read at the latest block if confirmations are not needed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		// block is not pinned
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, contracts.BlockRef{}).Return([]*contracts.NameInfo{
+			{
+				FullName:        "test.any",
+				Registered:      true,
+				Owner:           "0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5",
+				OwnerAnyAddress: "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS",
+				NameExpires:     big.NewInt(12390243),
+			},
+		}, nil)
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("", nil)
+
+		err := fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+			FullName: "test.any",
+		})
+		require.NoError(t, err)
+
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
+		require.NoError(t, err)
+		require.True(t, item.Final)
+		require.Equal(t, int64(0), item.BlockNumber)
+		require.Equal(t, "", item.BlockHash)
 	})
 
 	t.Run("update item if found", func(t *testing.T) {
@@ -396,23 +435,22 @@ func TestCacheService_UpdateInCache(t *testing.T) {
 		require.NoError(t, err)
 
 		// 2 - call it
-		fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-
-		// if this returns some address -> it means name is taken
-		fx.contracts.EXPECT().GetOwnerForNamehash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}) (common.Address, error) {
-			// this was changed!
-			anotherAddr := common.HexToAddress("0xAAB27b150451726EC7738aa1d0A94505c8729bd1")
-			return anotherAddr, nil
-		})
-
-		fx.contracts.EXPECT().GetAdditionalNameInfo(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, namehash interface{}, owner interface{}) (string, string, string, *big.Int, error) {
-			return "0xAAB27b150451726EC7738aa1d0A94505c8729bd1", "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS", "", big.NewInt(12390243), nil
-		})
-
-		// >>> see this:
-		fx.contracts.EXPECT().GetScwOwner(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, addr interface{}) (common.Address, error) {
-			return common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"), nil
-		})
+		fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return([]*contracts.NameInfo{
+			{
+				FullName:   "test.any",
+				Registered: true,
+				// this was changed!
+				Owner:           "0xAAB27b150451726EC7738aa1d0A94505c8729bd1",
+				IsScw:           true,
+				ScwOwner:        common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"),
+				OwnerAnyAddress: "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS",
+				NameExpires:     big.NewInt(12390243),
+			},
+		}, nil)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("", nil)
 
 		err = fx.UpdateInCache(ctx, &nsp.NameAvailableRequest{
 			FullName: "test.any",
@@ -432,3 +470,159 @@ func TestCacheService_UpdateInCache(t *testing.T) {
 		require.Equal(t, "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS", item.OwnerAnyAddress)
 	})
 }
+
+func TestCacheService_VerifyRecentEntries(t *testing.T) {
+	t.Run("mark entry as final if block is confirmed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		err := fx.setNameData(ctx, &NameDataItem{
+			FullName:    "test.any",
+			BlockNumber: 100,
+			BlockHash:   common.HexToHash("0x01").Hex(),
+		})
+		require.NoError(t, err)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), uint64(100), common.HexToHash("0x01")).Return(true, nil)
+
+		err = fx.VerifyRecentEntries(ctx)
+		require.NoError(t, err)
+
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
+		require.NoError(t, err)
+		require.True(t, item.Final)
+	})
+
+	t.Run("keep entry if block is not confirmed yet", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		err := fx.setNameData(ctx, &NameDataItem{
+			FullName:    "test.any",
+			BlockNumber: 100,
+			BlockHash:   common.HexToHash("0x01").Hex(),
+		})
+		require.NoError(t, err)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil)
+
+		err = fx.VerifyRecentEntries(ctx)
+		require.NoError(t, err)
+
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
+		require.NoError(t, err)
+		require.False(t, item.Final)
+	})
+
+	t.Run("remove entry if block was reorged out and name is not registered anymore", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		err := fx.setNameData(ctx, &NameDataItem{
+			FullName:    "test.any",
+			BlockNumber: 100,
+			BlockHash:   common.HexToHash("0x02").Hex(),
+		})
+		require.NoError(t, err)
+
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, contracts.ErrBlockReorged)
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return([]*contracts.NameInfo{{FullName: "test.any"}}, nil)
+
+		err = fx.VerifyRecentEntries(ctx)
+		require.NoError(t, err)
+
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Decode(&item)
+		require.Error(t, err)
+	})
+
+	t.Run("check all pages", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertRecentEntries(t, fx, recentEntriesPerPage*2+50)
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).Times(recentEntriesPerPage*2 + 50)
+
+		err := fx.VerifyRecentEntries(ctx)
+		require.NoError(t, err)
+
+		count, err := fx.itemColl.CountDocuments(ctx, findNameDataByFinal{Final: false})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("check at most maxRecentEntries at once", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertRecentEntries(t, fx, maxRecentEntries+10)
+		fx.contracts.EXPECT().IsBlockConfirmed(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(maxRecentEntries)
+
+		err := fx.VerifyRecentEntries(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func insertRecentEntries(t *testing.T, fx *fixture, n int) {
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = NameDataItem{
+			FullName:    fmt.Sprintf("test%d.any", i),
+			BlockNumber: 100,
+			BlockHash:   common.HexToHash("0x01").Hex(),
+		}
+	}
+	_, err := fx.itemColl.InsertMany(ctx, items)
+	require.NoError(t, err)
+}
+
+func TestCacheService_RebuildCache(t *testing.T) {
+	t.Run("replace the whole cache", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		// 1 - old item that is not on chain anymore
+		err := fx.setNameData(ctx, &NameDataItem{
+			FullName: "old.any",
+		})
+		require.NoError(t, err)
+
+		// 2 - only "new.any" is registered
+		// all names are read at once
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"new.any", "burned.any"}, gomock.Any()).Return([]*contracts.NameInfo{
+			{
+				FullName:        "new.any",
+				Registered:      true,
+				Owner:           "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+				IsScw:           true,
+				ScwOwner:        common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"),
+				OwnerAnyAddress: "12D3KooWA8EXV3KjBxEU5EnsPfneLx84vMWAtTBQBeyooN82KSuS",
+				NameExpires:     big.NewInt(12390243),
+			},
+			{FullName: "burned.any"},
+		}, nil)
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")).Return("new.any", nil)
+
+		err = fx.RebuildCache(ctx, []string{"new.any", "burned.any"})
+		require.NoError(t, err)
+
+		// 3 - check
+		item := &NameDataItem{}
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "new.any"}).Decode(&item)
+		require.NoError(t, err)
+		require.Equal(t, "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5", item.OwnerEthAddress)
+
+		// reverse record of the owner is read too
+		out, err := fx.GetNameByAddress(ctx, &nsp.NameByAddressRequest{OwnerScwEthAddress: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"})
+		require.NoError(t, err)
+		require.Equal(t, "new.any", out.Name)
+
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "old.any"}).Decode(&item)
+		require.Error(t, err)
+
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "burned.any"}).Decode(&item)
+		require.Error(t, err)
+	})
+}
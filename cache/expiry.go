@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+const defaultExpiryCheckIntervalSec = 10 * 60
+
+type NameState int
+
+const (
+	// never registered (not in the cache)
+	NameState_Available NameState = iota
+	NameState_Taken
+	// expired, but only the previous owner can renew it
+	NameState_GracePeriod
+	// grace period is over and it was verified on chain
+	NameState_AvailableAfterExpiry
+)
+
+// expired names are reported as free only after grace period is over
+// AND expiration date was re-read from the chain after that
+func GetNameState(item *NameDataItem, now int64, gracePeriodSec int64) NameState {
+	if item == nil {
+		return NameState_Available
+	}
+
+	// expiration is unknown -> can not say it is free
+	if item.NameExpires == 0 || now <= item.NameExpires {
+		return NameState_Taken
+	}
+
+	graceEnds := item.NameExpires + gracePeriodSec
+	if now <= graceEnds {
+		return NameState_GracePeriod
+	}
+
+	// could be renewed, wait for the background check
+	if item.ExpiryCheckedAt <= graceEnds {
+		return NameState_GracePeriod
+	}
+
+	return NameState_AvailableAfterExpiry
+}
+
+func (s NameState) String() string {
+	switch s {
+	case NameState_Available:
+		return "available"
+	case NameState_Taken:
+		return "taken"
+	case NameState_GracePeriod:
+		return "gracePeriod"
+	case NameState_AvailableAfterExpiry:
+		return "availableAfterExpiry"
+	}
+	return "unknown"
+}
+
+// NameAvailableResponse has no state field, so it is encoded with Available and NameExpires
+// (see ResponseToNameState)
+func nameStateToResponse(state NameState, item *NameDataItem) *nsp.NameAvailableResponse {
+	switch state {
+	case NameState_Taken, NameState_GracePeriod:
+		// for NameState_GracePeriod NameExpires is in the past
+		// for NameState_Taken it is in the future or 0 (unknown)
+		return &nsp.NameAvailableResponse{
+			Available:          false,
+			OwnerEthAddress:    item.OwnerEthAddress,
+			OwnerScwEthAddress: item.OwnerScwEthAddress,
+			OwnerAnyAddress:    item.OwnerAnyAddress,
+			SpaceId:            item.SpaceId,
+			NameExpires:        item.NameExpires,
+		}
+	case NameState_AvailableAfterExpiry:
+		// previous expiration date is returned, but there is no owner anymore
+		return &nsp.NameAvailableResponse{
+			Available:   true,
+			NameExpires: item.NameExpires,
+		}
+	}
+
+	return &nsp.NameAvailableResponse{Available: true}
+}
+
+// state of the name as it was reported by IsNameAvailable at the moment now:
+// 1. not available, NameExpires is 0 or in the future -> taken
+// 2. not available, NameExpires is in the past -> grace period
+// 3. available, NameExpires is set -> available after the grace period is over
+// 4. available, no NameExpires -> never registered
+func ResponseToNameState(out *nsp.NameAvailableResponse, now int64) NameState {
+	if out.Available {
+		if out.NameExpires != 0 {
+			return NameState_AvailableAfterExpiry
+		}
+		return NameState_Available
+	}
+
+	if out.NameExpires != 0 && out.NameExpires < now {
+		return NameState_GracePeriod
+	}
+	return NameState_Taken
+}
+
+// grace period never changes, so read it only once
+func (cs *cacheService) getGracePeriod(ctx context.Context) (int64, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.gracePeriodSec != nil {
+		return *cs.gracePeriodSec, nil
+	}
+
+	gp, err := cs.contracts.GetGracePeriod(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	value := gp.Int64()
+	cs.gracePeriodSec = &value
+	return value, nil
+}
+
+func (cs *cacheService) expiryWorker(ctx context.Context) {
+	log.Info("expiry worker started")
+
+	interval := cs.confCache.ExpiryCheckIntervalSec
+	if interval == 0 {
+		interval = defaultExpiryCheckIntervalSec
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("expiry worker stopped")
+			close(cs.done)
+			return
+		case <-ticker.C:
+		}
+
+		err := cs.VerifyExpiredEntries(ctx)
+		if err != nil {
+			// in case of error - do not stop, try again next time
+			log.Warn("failed to verify expired entries", zap.Error(err))
+		}
+	}
+}
+
+func (cs *cacheService) VerifyExpiredEntries(ctx context.Context) (err error) {
+	gracePeriodSec, err := cs.getGracePeriod(ctx)
+	if err != nil {
+		log.Error("failed to get grace period", zap.Error(err))
+		return err
+	}
+
+	// 1 - find all entries with grace period over, but not checked after that
+	now := time.Now().Unix()
+	graceEnds := bson.D{{Key: "$add", Value: bson.A{"$name_expires", gracePeriodSec}}}
+
+	filter := bson.D{
+		{Key: "name_expires", Value: bson.D{
+			{Key: "$gt", Value: 0},
+			{Key: "$lt", Value: now - gracePeriodSec},
+		}},
+		{Key: "$expr", Value: bson.D{{Key: "$lte", Value: bson.A{"$expiry_checked_at", graceEnds}}}},
+	}
+
+	cursor, err := cs.itemColl.Find(ctx, filter)
+	if err != nil {
+		log.Error("failed to find expired entries", zap.Error(err))
+		return err
+	}
+
+	var items []NameDataItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		log.Error("failed to decode expired entries", zap.Error(err))
+		return err
+	}
+
+	// 2 - re-read expiration date
+	for _, item := range items {
+		exp, err := cs.contracts.GetNameExpiration(ctx, item.FullName)
+		if err != nil {
+			log.Error("failed to get expiration date", zap.String("FullName", item.FullName), zap.Error(err))
+			return err
+		}
+
+		// name was renewed -> read all data again
+		if exp.Int64() > item.NameExpires {
+			log.Info("expired name was renewed", zap.String("FullName", item.FullName))
+
+			err = cs.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+				FullName: item.FullName,
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// still expired -> can be reported as free
+		// entry could be already re-read, do not overwrite it
+		item.ExpiryCheckedAt = now
+		filter := findNameDataByBlock{FullName: item.FullName, BlockHash: item.BlockHash}
+
+		_, err = cs.itemColl.ReplaceOne(ctx, filter, item)
+		if err != nil {
+			log.Error("failed to update expired entry", zap.String("FullName", item.FullName), zap.Error(err))
+			return err
+		}
+		cs.invalidateMemory(item.FullName, "", "")
+	}
+
+	log.Debug("verified expired entries", zap.Int("count", len(items)))
+	return nil
+}
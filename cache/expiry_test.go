@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"math/big"
+	"testing"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCacheService_GetNameState(t *testing.T) {
+	const now = 1000
+	const grace = 100
+
+	// 1 - not in the cache
+	assert.Equal(t, GetNameState(nil, now, grace), NameState_Available)
+
+	// 2 - expiration is unknown
+	assert.Equal(t, GetNameState(&NameDataItem{}, now, grace), NameState_Taken)
+
+	// 3 - not expired yet
+	assert.Equal(t, GetNameState(&NameDataItem{NameExpires: 2000}, now, grace), NameState_Taken)
+
+	// 4 - expired, but grace period is not over
+	assert.Equal(t, GetNameState(&NameDataItem{NameExpires: 950, ExpiryCheckedAt: now}, now, grace), NameState_GracePeriod)
+
+	// 5 - grace period is over, but was not checked after that
+	assert.Equal(t, GetNameState(&NameDataItem{NameExpires: 800, ExpiryCheckedAt: 850}, now, grace), NameState_GracePeriod)
+
+	// 6 - grace period is over and verified
+	assert.Equal(t, GetNameState(&NameDataItem{NameExpires: 800, ExpiryCheckedAt: 950}, now, grace), NameState_AvailableAfterExpiry)
+}
+
+func TestCacheService_ResponseToNameState(t *testing.T) {
+	const now = 1000
+	const grace = 100
+
+	items := []*NameDataItem{
+		nil,
+		{},
+		{NameExpires: now},
+		{NameExpires: 2000},
+		{NameExpires: 950, ExpiryCheckedAt: now},
+		{NameExpires: 800, ExpiryCheckedAt: 850},
+		{NameExpires: 800, ExpiryCheckedAt: 950},
+	}
+
+	// every state can be told from the response
+	for _, item := range items {
+		state := GetNameState(item, now, grace)
+		assert.Equal(t, ResponseToNameState(nameStateToResponse(state, item), now), state)
+	}
+}
+
+func TestCacheService_IsNameAvailableExpired(t *testing.T) {
+	t.Run("report expired name as taken until it is verified", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().GetGracePeriod(gomock.Any()).Return(big.NewInt(90*24*60*60), nil)
+
+		// grace period is over, but it was not verified after that
+		_, err := fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:        "test.any",
+			OwnerEthAddress: "owner",
+			NameExpires:     1,
+			ExpiryCheckedAt: 2,
+		})
+		require.NoError(t, err)
+
+		out, err := fx.IsNameAvailable(ctx, &nsp.NameAvailableRequest{FullName: "test.any"})
+		require.NoError(t, err)
+		assert.False(t, out.Available)
+		assert.Equal(t, "owner", out.OwnerEthAddress)
+	})
+
+	t.Run("report name as available after verified expiry", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().GetGracePeriod(gomock.Any()).Return(big.NewInt(10), nil)
+
+		_, err := fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:        "test.any",
+			OwnerEthAddress: "owner",
+			NameExpires:     1,
+			ExpiryCheckedAt: 100,
+		})
+		require.NoError(t, err)
+
+		out, err := fx.IsNameAvailable(ctx, &nsp.NameAvailableRequest{FullName: "test.any"})
+		require.NoError(t, err)
+		assert.True(t, out.Available)
+		assert.Equal(t, "", out.OwnerEthAddress)
+		assert.Equal(t, int64(1), out.NameExpires)
+	})
+
+	t.Run("verify expired entries", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().GetGracePeriod(gomock.Any()).Return(big.NewInt(10), nil)
+		fx.contracts.EXPECT().GetNameExpiration(gomock.Any(), "test.any").Return(big.NewInt(1), nil)
+
+		_, err := fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:        "test.any",
+			OwnerEthAddress: "owner",
+			NameExpires:     1,
+			ExpiryCheckedAt: 5,
+		})
+		require.NoError(t, err)
+
+		err = fx.VerifyExpiredEntries(ctx)
+		require.NoError(t, err)
+
+		out, err := fx.IsNameAvailable(ctx, &nsp.NameAvailableRequest{FullName: "test.any"})
+		require.NoError(t, err)
+		assert.True(t, out.Available)
+	})
+}
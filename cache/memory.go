@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMemorySize           = 10000
+	defaultMemoryPositiveTtlSec = 30
+	defaultMemoryNegativeTtlSec = 5
+
+	// coalesced reads are not bound to the caller that started them
+	sharedCallTimeout = time.Minute
+
+	// key prefixes
+	memoryKeyAvailable = "available:"
+	memoryKeyAddress   = "address:"
+	memoryKeyAnyId     = "anyid:"
+	// name was read from the chain by this node
+	memoryKeyChain = "chain:"
+)
+
+// LRU with per-entry TTL
+// every entry can reference a name, so all lookups that returned it can be invalidated at once
+type memoryTier struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+	// name -> keys of the entries that reference it
+	byName map[string]map[string]bool
+	// is increased on every removal
+	gen uint64
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   interface{}
+	name    string
+	expires time.Time
+}
+
+// size == 0 -> nothing is stored
+func newMemoryTier(size int) *memoryTier {
+	return &memoryTier{
+		size:   size,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element),
+		byName: make(map[string]map[string]bool),
+		now:    time.Now,
+	}
+}
+
+func (m *memoryTier) get(key string) (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !m.now().Before(entry.expires) {
+		m.removeElement(el)
+		return nil, false
+	}
+
+	m.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (m *memoryTier) generation() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.gen
+}
+
+// name is the name that value references ("" if none)
+func (m *memoryTier) set(key string, value interface{}, name string, ttl time.Duration) {
+	m.setIfGeneration(key, value, name, ttl, m.generation())
+}
+
+// will not save the value if anything was removed after gen was received
+// (value could be read before invalidation)
+func (m *memoryTier) setIfGeneration(key string, value interface{}, name string, ttl time.Duration, gen uint64) {
+	if m.size == 0 || ttl <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.gen != gen {
+		return
+	}
+
+	if el, ok := m.items[key]; ok {
+		m.removeElement(el)
+	}
+
+	entry := &memoryEntry{
+		key:     key,
+		value:   value,
+		name:    name,
+		expires: m.now().Add(ttl),
+	}
+	m.items[key] = m.ll.PushFront(entry)
+
+	if name != "" {
+		if m.byName[name] == nil {
+			m.byName[name] = make(map[string]bool)
+		}
+		m.byName[name][key] = true
+	}
+
+	// evict the least recently used
+	for m.ll.Len() > m.size {
+		m.removeElement(m.ll.Back())
+	}
+}
+
+func (m *memoryTier) remove(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	if el, ok := m.items[key]; ok {
+		m.removeElement(el)
+	}
+}
+
+// removes all entries that reference the name
+func (m *memoryTier) invalidateName(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	for key := range m.byName[name] {
+		if el, ok := m.items[key]; ok {
+			m.removeElement(el)
+		}
+	}
+}
+
+func (m *memoryTier) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	m.ll.Init()
+	m.items = make(map[string]*list.Element)
+	m.byName = make(map[string]map[string]bool)
+}
+
+func (m *memoryTier) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ll.Len()
+}
+
+func (m *memoryTier) removeElement(el *list.Element) {
+	entry := el.Value.(*memoryEntry)
+	m.ll.Remove(el)
+	delete(m.items, entry.key)
+
+	if entry.name != "" {
+		delete(m.byName[entry.name], entry.key)
+		if len(m.byName[entry.name]) == 0 {
+			delete(m.byName, entry.name)
+		}
+	}
+}
+
+func (cs *cacheService) setMemoryDefaults() {
+	c := &cs.confCache
+	if c.MemorySize == 0 {
+		c.MemorySize = defaultMemorySize
+	}
+	if c.MemoryPositiveTtlSec == 0 {
+		c.MemoryPositiveTtlSec = defaultMemoryPositiveTtlSec
+	}
+	if c.MemoryNegativeTtlSec == 0 {
+		c.MemoryNegativeTtlSec = defaultMemoryNegativeTtlSec
+	}
+}
+
+func (cs *cacheService) memoryTtl(positive bool) time.Duration {
+	if positive {
+		return time.Duration(cs.confCache.MemoryPositiveTtlSec) * time.Second
+	}
+	return time.Duration(cs.confCache.MemoryNegativeTtlSec) * time.Second
+}
+
+// returns value from memory or reads it with read (concurrent reads of the same key are coalesced)
+// describe returns the name that value references and if it is positive (name is taken, address has a name)
+func (cs *cacheService) tieredLookup(ctx context.Context, key string, read func(ctx context.Context) (interface{}, error), describe func(v interface{}) (name string, positive bool)) (interface{}, error) {
+	// 1 - memory
+	if v, ok := cs.memory.get(key); ok {
+		cs.metrics.lookup(tierMemory, true)
+		return v, nil
+	}
+	cs.metrics.lookup(tierMemory, false)
+
+	// 2 - Mongo
+	return cs.doShared(ctx, key, func(ctx context.Context) (interface{}, error) {
+		// do not save if it was invalidated during the read
+		gen := cs.memory.generation()
+
+		v, err := read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		name, positive := describe(v)
+		cs.metrics.lookup(tierMongo, positive)
+		cs.memory.setIfGeneration(key, v, name, cs.memoryTtl(positive), gen)
+		return v, nil
+	})
+}
+
+// concurrent calls with the same key are done only once
+// shared call is not canceled when the caller that started it goes away (it has its own timeout),
+// but every caller stops waiting when its own ctx is done
+func (cs *cacheService) doShared(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ch := cs.group.DoChan(key, func() (interface{}, error) {
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedCallTimeout)
+		defer cancel()
+		return fn(sharedCtx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			cs.metrics.coalesced.Inc()
+		}
+		return res.Val, res.Err
+	}
+}
+
+// all lookups that could return or depend on this name are removed from memory
+// lookups of the new owner are removed too (they could return "not found" before)
+func (cs *cacheService) invalidateMemory(fullName string, scwAddress string, anyAddress string) {
+	cs.memory.invalidateName(fullName)
+	cs.memory.remove(memoryKeyAvailable + fullName)
+	cs.memory.remove(memoryKeyChain + fullName)
+
+	if scwAddress != "" {
+		cs.memory.remove(memoryKeyAddress + scwAddress)
+	}
+	if anyAddress != "" {
+		cs.memory.remove(memoryKeyAnyId + anyAddress)
+	}
+}
+
+func (cs *cacheService) InvalidateName(fullName string) {
+	cs.invalidateMemory(fullName, "", "")
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+)
+
+func newTestMemory(size int) (*memoryTier, *time.Time) {
+	now := time.Unix(1000, 0)
+	m := newMemoryTier(size)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestMemoryTier(t *testing.T) {
+	t.Run("expires after ttl", func(t *testing.T) {
+		m, now := newTestMemory(10)
+		m.set("a", 1, "", 10*time.Second)
+
+		v, ok := m.get("a")
+		require.True(t, ok)
+		assert.Equal(t, v, 1)
+
+		*now = now.Add(10 * time.Second)
+		_, ok = m.get("a")
+		require.False(t, ok)
+		assert.Equal(t, m.len(), 0)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		m, _ := newTestMemory(2)
+		m.set("a", 1, "", time.Minute)
+		m.set("b", 2, "", time.Minute)
+
+		// "a" is used -> "b" is evicted
+		_, ok := m.get("a")
+		require.True(t, ok)
+		m.set("c", 3, "", time.Minute)
+
+		_, ok = m.get("b")
+		require.False(t, ok)
+		_, ok = m.get("a")
+		require.True(t, ok)
+		_, ok = m.get("c")
+		require.True(t, ok)
+		assert.Equal(t, m.len(), 2)
+	})
+
+	t.Run("invalidate all entries of the name", func(t *testing.T) {
+		m, _ := newTestMemory(10)
+		m.set(memoryKeyAvailable+"hello.any", 1, "hello.any", time.Minute)
+		m.set(memoryKeyAddress+"0x1", 2, "hello.any", time.Minute)
+		m.set(memoryKeyAddress+"0x2", 3, "other.any", time.Minute)
+
+		m.invalidateName("hello.any")
+
+		_, ok := m.get(memoryKeyAvailable + "hello.any")
+		require.False(t, ok)
+		_, ok = m.get(memoryKeyAddress + "0x1")
+		require.False(t, ok)
+		_, ok = m.get(memoryKeyAddress + "0x2")
+		require.True(t, ok)
+	})
+
+	t.Run("do not save value read before invalidation", func(t *testing.T) {
+		m, _ := newTestMemory(10)
+
+		gen := m.generation()
+		m.invalidateName("hello.any")
+		m.setIfGeneration("a", 1, "hello.any", time.Minute, gen)
+
+		_, ok := m.get("a")
+		require.False(t, ok)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m, _ := newTestMemory(0)
+		m.set("a", 1, "", time.Minute)
+
+		_, ok := m.get("a")
+		require.False(t, ok)
+	})
+}
+
+func TestCacheService_tieredLookup(t *testing.T) {
+	newService := func() *cacheService {
+		cs := &cacheService{
+			memory:  newMemoryTier(10),
+			metrics: newCacheMetrics(new(app.App)),
+		}
+		cs.setMemoryDefaults()
+		return cs
+	}
+
+	t.Run("second lookup is served from memory", func(t *testing.T) {
+		cs := newService()
+
+		var reads int
+		read := func(ctx context.Context) (interface{}, error) {
+			reads++
+			return "hello.any", nil
+		}
+		describe := func(v interface{}) (string, bool) {
+			return v.(string), true
+		}
+
+		for i := 0; i < 3; i++ {
+			v, err := cs.tieredLookup(ctx, "key", read, describe)
+			require.NoError(t, err)
+			assert.Equal(t, v, "hello.any")
+		}
+		assert.Equal(t, reads, 1)
+
+		// read again after invalidation
+		cs.InvalidateName("hello.any")
+		_, err := cs.tieredLookup(ctx, "key", read, describe)
+		require.NoError(t, err)
+		assert.Equal(t, reads, 2)
+	})
+
+	t.Run("negative result expires earlier", func(t *testing.T) {
+		cs := newService()
+		now := time.Now()
+		cs.memory.now = func() time.Time { return now }
+
+		describe := func(v interface{}) (string, bool) {
+			return "", v.(bool)
+		}
+		_, err := cs.tieredLookup(ctx, "positive", func(ctx context.Context) (interface{}, error) { return true, nil }, describe)
+		require.NoError(t, err)
+		_, err = cs.tieredLookup(ctx, "negative", func(ctx context.Context) (interface{}, error) { return false, nil }, describe)
+		require.NoError(t, err)
+
+		now = now.Add(defaultMemoryNegativeTtlSec * time.Second)
+		_, ok := cs.memory.get("positive")
+		require.True(t, ok)
+		_, ok = cs.memory.get("negative")
+		require.False(t, ok)
+	})
+
+	t.Run("concurrent lookups are coalesced", func(t *testing.T) {
+		cs := newService()
+
+		var reads int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		read := func(ctx context.Context) (interface{}, error) {
+			if atomic.AddInt32(&reads, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return "hello.any", nil
+		}
+		describe := func(v interface{}) (string, bool) {
+			return v.(string), true
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cs.tieredLookup(ctx, "key", read, describe)
+		}()
+		<-started
+
+		// the second lookup waits for the first one
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := cs.tieredLookup(ctx, "key", read, describe)
+				require.NoError(t, err)
+				assert.Equal(t, v, "hello.any")
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, atomic.LoadInt32(&reads), int32(1))
+	})
+
+	t.Run("shared lookup is not canceled with the first caller", func(t *testing.T) {
+		cs := newService()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		read := func(ctx context.Context) (interface{}, error) {
+			close(started)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+			}
+			return "hello.any", nil
+		}
+		describe := func(v interface{}) (string, bool) {
+			return v.(string), true
+		}
+
+		// 1 - first caller goes away
+		firstCtx, cancel := context.WithCancel(ctx)
+		firstDone := make(chan error)
+		go func() {
+			_, err := cs.tieredLookup(firstCtx, "key", read, describe)
+			firstDone <- err
+		}()
+		<-started
+
+		secondDone := make(chan error)
+		go func() {
+			v, err := cs.tieredLookup(ctx, "key", read, describe)
+			if err == nil {
+				assert.Equal(t, v, "hello.any")
+			}
+			secondDone <- err
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-firstDone, context.Canceled)
+
+		// 2 - others still get the result
+		close(release)
+		require.NoError(t, <-secondDone)
+	})
+}
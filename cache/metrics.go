@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	tierMemory = "memory"
+	tierMongo  = "mongo"
+
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+type cacheMetrics struct {
+	// labels are tier and result
+	// for mongo "miss" means that name/address is not in the cache
+	lookups *prometheus.CounterVec
+	// lookups and chain reads that were served by the concurrent call for the same key
+	coalesced prometheus.Counter
+
+	refreshChecked prometheus.Counter
+	refreshErrors  prometheus.Counter
+	// label is the name of the field that was changed on chain
+	refreshDrifted *prometheus.CounterVec
+}
+
+func newCacheMetrics(a *app.App) *cacheMetrics {
+	m := &cacheMetrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Number of cache lookups by tier and result",
+		}, []string{"tier", "result"}),
+		coalesced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "cache",
+			Name:      "coalesced_total",
+			Help:      "Number of lookups that were served by the concurrent lookup of the same key",
+		}),
+		refreshChecked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "cache",
+			Name:      "refresh_checked_total",
+			Help:      "Number of stale cache entries that were re-read from the chain",
+		}),
+		refreshErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "cache",
+			Name:      "refresh_errors_total",
+			Help:      "Number of stale cache entries that failed to refresh",
+		}),
+		refreshDrifted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "cache",
+			Name:      "refresh_drifted_total",
+			Help:      "Number of stale cache entries that were different on chain",
+		}, []string{"field"}),
+	}
+
+	// metric component is optional
+	if mc := a.Component(metric.CName); mc != nil {
+		mc.(metric.Metric).Registry().MustRegister(
+			m.lookups,
+			m.coalesced,
+			m.refreshChecked,
+			m.refreshErrors,
+			m.refreshDrifted,
+		)
+	}
+	return m
+}
+
+func (m *cacheMetrics) lookup(tier string, hit bool) {
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+	m.lookups.WithLabelValues(tier, result).Inc()
+}
@@ -13,8 +13,11 @@ import (
 	context "context"
 	reflect "reflect"
 
+	cache "github.com/anyproto/any-ns-node/cache"
+	nsextproto "github.com/anyproto/any-ns-node/nsext/nsextproto"
 	app "github.com/anyproto/any-sync/app"
 	nameserviceproto "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	common "github.com/ethereum/go-ethereum/common"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +44,48 @@ func (m *MockCacheService) EXPECT() *MockCacheServiceMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockCacheService) Close(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockCacheServiceMockRecorder) Close(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCacheService)(nil).Close), ctx)
+}
+
+// EnsureFresh mocks base method.
+func (m *MockCacheService) EnsureFresh(ctx context.Context, in *nameserviceproto.NameAvailableRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureFresh", ctx, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureFresh indicates an expected call of EnsureFresh.
+func (mr *MockCacheServiceMockRecorder) EnsureFresh(ctx, in any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureFresh", reflect.TypeOf((*MockCacheService)(nil).EnsureFresh), ctx, in)
+}
+
+// EnsureFreshBatch mocks base method.
+func (m *MockCacheService) EnsureFreshBatch(ctx context.Context, fullNames []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureFreshBatch", ctx, fullNames)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureFreshBatch indicates an expected call of EnsureFreshBatch.
+func (mr *MockCacheServiceMockRecorder) EnsureFreshBatch(ctx, fullNames any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureFreshBatch", reflect.TypeOf((*MockCacheService)(nil).EnsureFreshBatch), ctx, fullNames)
+}
+
 // GetNameByAddress mocks base method.
 func (m *MockCacheService) GetNameByAddress(ctx context.Context, in *nameserviceproto.NameByAddressRequest) (*nameserviceproto.NameByAddressResponse, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +116,36 @@ func (mr *MockCacheServiceMockRecorder) GetNameByAnyId(ctx, in any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameByAnyId", reflect.TypeOf((*MockCacheService)(nil).GetNameByAnyId), ctx, in)
 }
 
+// GetNameBySpaceId mocks base method.
+func (m *MockCacheService) GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (*nsextproto.NameBySpaceIdResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNameBySpaceId", ctx, in)
+	ret0, _ := ret[0].(*nsextproto.NameBySpaceIdResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNameBySpaceId indicates an expected call of GetNameBySpaceId.
+func (mr *MockCacheServiceMockRecorder) GetNameBySpaceId(ctx, in any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameBySpaceId", reflect.TypeOf((*MockCacheService)(nil).GetNameBySpaceId), ctx, in)
+}
+
+// GetNamesByOwner mocks base method.
+func (m *MockCacheService) GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (*nsextproto.NamesByOwnerResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNamesByOwner", ctx, in)
+	ret0, _ := ret[0].(*nsextproto.NamesByOwnerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNamesByOwner indicates an expected call of GetNamesByOwner.
+func (mr *MockCacheServiceMockRecorder) GetNamesByOwner(ctx, in any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamesByOwner", reflect.TypeOf((*MockCacheService)(nil).GetNamesByOwner), ctx, in)
+}
+
 // Init mocks base method.
 func (m *MockCacheService) Init(a *app.App) error {
 	m.ctrl.T.Helper()
@@ -85,6 +160,18 @@ func (mr *MockCacheServiceMockRecorder) Init(a any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockCacheService)(nil).Init), a)
 }
 
+// InvalidateName mocks base method.
+func (m *MockCacheService) InvalidateName(fullName string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateName", fullName)
+}
+
+// InvalidateName indicates an expected call of InvalidateName.
+func (mr *MockCacheServiceMockRecorder) InvalidateName(fullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateName", reflect.TypeOf((*MockCacheService)(nil).InvalidateName), fullName)
+}
+
 // IsNameAvailable mocks base method.
 func (m *MockCacheService) IsNameAvailable(ctx context.Context, in *nameserviceproto.NameAvailableRequest) (*nameserviceproto.NameAvailableResponse, error) {
 	m.ctrl.T.Helper()
@@ -114,6 +201,64 @@ func (mr *MockCacheServiceMockRecorder) Name() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockCacheService)(nil).Name))
 }
 
+// ReadNameAtBlock mocks base method.
+func (m *MockCacheService) ReadNameAtBlock(ctx context.Context, fullName string, blockNumber uint64, blockHash common.Hash) (*cache.NameDataItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadNameAtBlock", ctx, fullName, blockNumber, blockHash)
+	ret0, _ := ret[0].(*cache.NameDataItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadNameAtBlock indicates an expected call of ReadNameAtBlock.
+func (mr *MockCacheServiceMockRecorder) ReadNameAtBlock(ctx, fullName, blockNumber, blockHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadNameAtBlock", reflect.TypeOf((*MockCacheService)(nil).ReadNameAtBlock), ctx, fullName, blockNumber, blockHash)
+}
+
+// RebuildCache mocks base method.
+func (m *MockCacheService) RebuildCache(ctx context.Context, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildCache", ctx, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildCache indicates an expected call of RebuildCache.
+func (mr *MockCacheServiceMockRecorder) RebuildCache(ctx, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildCache", reflect.TypeOf((*MockCacheService)(nil).RebuildCache), ctx, names)
+}
+
+// RefreshStaleEntries mocks base method.
+func (m *MockCacheService) RefreshStaleEntries(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshStaleEntries", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshStaleEntries indicates an expected call of RefreshStaleEntries.
+func (mr *MockCacheServiceMockRecorder) RefreshStaleEntries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshStaleEntries", reflect.TypeOf((*MockCacheService)(nil).RefreshStaleEntries), ctx)
+}
+
+// Run mocks base method.
+func (m *MockCacheService) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockCacheServiceMockRecorder) Run(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockCacheService)(nil).Run), ctx)
+}
+
 // UpdateInCache mocks base method.
 func (m *MockCacheService) UpdateInCache(ctx context.Context, in *nameserviceproto.NameAvailableRequest) error {
 	m.ctrl.T.Helper()
@@ -127,3 +272,59 @@ func (mr *MockCacheServiceMockRecorder) UpdateInCache(ctx, in any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInCache", reflect.TypeOf((*MockCacheService)(nil).UpdateInCache), ctx, in)
 }
+
+// UpdateReverseRecord mocks base method.
+func (m *MockCacheService) UpdateReverseRecord(ctx context.Context, address string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReverseRecord", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReverseRecord indicates an expected call of UpdateReverseRecord.
+func (mr *MockCacheServiceMockRecorder) UpdateReverseRecord(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReverseRecord", reflect.TypeOf((*MockCacheService)(nil).UpdateReverseRecord), ctx, address)
+}
+
+// UpdateReverseRecordByNode mocks base method.
+func (m *MockCacheService) UpdateReverseRecordByNode(ctx context.Context, node [32]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReverseRecordByNode", ctx, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReverseRecordByNode indicates an expected call of UpdateReverseRecordByNode.
+func (mr *MockCacheServiceMockRecorder) UpdateReverseRecordByNode(ctx, node any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReverseRecordByNode", reflect.TypeOf((*MockCacheService)(nil).UpdateReverseRecordByNode), ctx, node)
+}
+
+// VerifyExpiredEntries mocks base method.
+func (m *MockCacheService) VerifyExpiredEntries(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyExpiredEntries", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyExpiredEntries indicates an expected call of VerifyExpiredEntries.
+func (mr *MockCacheServiceMockRecorder) VerifyExpiredEntries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyExpiredEntries", reflect.TypeOf((*MockCacheService)(nil).VerifyExpiredEntries), ctx)
+}
+
+// VerifyRecentEntries mocks base method.
+func (m *MockCacheService) VerifyRecentEntries(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRecentEntries", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyRecentEntries indicates an expected call of VerifyRecentEntries.
+func (mr *MockCacheServiceMockRecorder) VerifyRecentEntries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRecentEntries", reflect.TypeOf((*MockCacheService)(nil).VerifyRecentEntries), ctx)
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
+)
+
+const (
+	defaultNamesPageSize = 100
+	maxNamesPageSize     = 1000
+)
+
+var (
+	ErrInvalidOwner  = errors.New("exactly one of AnyAddress, OwnerEthAddress, OwnerScwEthAddress should be set")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// cursor is the last returned name, clients should treat it as opaque
+func encodeNamesCursor(lastName string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastName))
+}
+
+func decodeNamesCursor(cursor string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(b), nil
+}
+
+func namesPageSize(limit uint32) int64 {
+	if limit == 0 {
+		return defaultNamesPageSize
+	}
+	if limit > maxNamesPageSize {
+		return maxNamesPageSize
+	}
+	return int64(limit)
+}
+
+func (cs *cacheService) GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (out *nsextproto.NamesByOwnerResponse, err error) {
+	// 1 - build the filter
+	var (
+		field   string
+		value   string
+		primary *NameDataItem
+		set     int
+	)
+
+	if in.AnyAddress != "" {
+		// WARNING: DO NOT convert to lower!
+		field, value = "owner_any_address", in.AnyAddress
+		set++
+	}
+	if in.OwnerEthAddress != "" {
+		// WARNING: convert to lower!
+		field, value = "owner_eth_address", strings.ToLower(in.OwnerEthAddress)
+		set++
+	}
+	if in.OwnerScwEthAddress != "" {
+		field, value = "owner_scw_eth_address", strings.ToLower(in.OwnerScwEthAddress)
+		set++
+	}
+	if set != 1 {
+		return nil, ErrInvalidOwner
+	}
+
+	filter := bson.D{{Key: field, Value: value}}
+	if in.Cursor != "" {
+		last, err := decodeNamesCursor(in.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, bson.E{Key: "name", Value: bson.D{{Key: "$gt", Value: last}}})
+	}
+
+	// 2 - read one more item to know if there is a next page
+	limit := namesPageSize(in.Limit)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetLimit(limit + 1)
+
+	cursor, err := cs.itemColl.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to find names by owner", zap.Error(err))
+		return nil, err
+	}
+
+	var items []NameDataItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		log.Error("failed to decode names by owner", zap.Error(err))
+		return nil, err
+	}
+
+	// 3 - the same name that reverse lookup returns
+	switch field {
+	case "owner_any_address":
+		primary, err = cs.getPrimaryNameByAnyId(ctx, value)
+	case "owner_eth_address":
+		primary, err = cs.getPrimaryNameByEthAddress(ctx, value)
+	default:
+		primary, err = cs.getPrimaryNameByAddress(ctx, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out = &nsextproto.NamesByOwnerResponse{
+		Names: make([]*nsextproto.OwnedName, 0, len(items)),
+	}
+
+	if int64(len(items)) > limit {
+		items = items[:limit]
+		out.NextCursor = encodeNamesCursor(items[len(items)-1].FullName)
+	}
+
+	for _, item := range items {
+		out.Names = append(out.Names, &nsextproto.OwnedName{
+			FullName:    item.FullName,
+			NameExpires: item.NameExpires,
+			SpaceId:     item.SpaceId,
+			IsPrimary:   primary != nil && primary.FullName == item.FullName,
+		})
+	}
+
+	return out, nil
+}
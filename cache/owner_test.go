@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
+)
+
+func TestCacheService_namesCursor(t *testing.T) {
+	// 1 - round trip
+	name, err := decodeNamesCursor(encodeNamesCursor("hello.any"))
+	assert.NoError(t, err)
+	assert.Equal(t, name, "hello.any")
+
+	// 2 - garbage
+	_, err = decodeNamesCursor("!!!")
+	assert.Equal(t, err, ErrInvalidCursor)
+
+	// 3 - page size
+	assert.Equal(t, namesPageSize(0), int64(defaultNamesPageSize))
+	assert.Equal(t, namesPageSize(10), int64(10))
+	assert.Equal(t, namesPageSize(100000), int64(maxNamesPageSize))
+}
+
+func TestCacheService_GetNamesByOwner(t *testing.T) {
+	t.Run("fail if owner is not set or set twice", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		_, err := fx.GetNamesByOwner(ctx, &nsextproto.NamesByOwnerRequest{})
+		require.Equal(t, ErrInvalidOwner, err)
+
+		_, err = fx.GetNamesByOwner(ctx, &nsextproto.NamesByOwnerRequest{
+			AnyAddress:      "anyid",
+			OwnerEthAddress: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+		})
+		require.Equal(t, ErrInvalidOwner, err)
+	})
+
+	t.Run("return all names page by page", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "carol.any", OwnerScwEthAddress: testScwAddress, OwnerAnyAddress: "anyid", NameExpires: 300, SpaceId: "space"},
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, OwnerAnyAddress: "anyid", NameExpires: 100},
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: testScwAddress, OwnerAnyAddress: "anyid", NameExpires: 200},
+			NameDataItem{FullName: "other.any", OwnerScwEthAddress: "0xanother", OwnerAnyAddress: "another"},
+		)
+
+		// 1 - first page
+		out, err := fx.GetNamesByOwner(ctx, &nsextproto.NamesByOwnerRequest{AnyAddress: "anyid", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, out.Names, 2)
+		require.Equal(t, "alice.any", out.Names[0].FullName)
+		require.Equal(t, "bob.any", out.Names[1].FullName)
+		require.Equal(t, int64(200), out.Names[1].NameExpires)
+		require.NotEmpty(t, out.NextCursor)
+
+		// 2 - last page
+		out, err = fx.GetNamesByOwner(ctx, &nsextproto.NamesByOwnerRequest{AnyAddress: "anyid", Limit: 2, Cursor: out.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, out.Names, 1)
+		require.Equal(t, "carol.any", out.Names[0].FullName)
+		require.Equal(t, "space", out.Names[0].SpaceId)
+		require.Empty(t, out.NextCursor)
+
+		// no reverse record -> the one that expires last is primary
+		require.True(t, out.Names[0].IsPrimary)
+	})
+
+	t.Run("find by SCW address in any case", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress},
+		)
+
+		out, err := fx.GetNamesByOwner(ctx, &nsextproto.NamesByOwnerRequest{OwnerScwEthAddress: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"})
+		require.NoError(t, err)
+		require.Len(t, out.Names, 1)
+		require.True(t, out.Names[0].IsPrimary)
+	})
+}
+
+func TestCacheService_GetNameBySpaceId(t *testing.T) {
+	t.Run("find nothing", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		out, err := fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{SpaceId: "space"})
+		require.NoError(t, err)
+		require.False(t, out.Found)
+
+		// empty space ID should not match names without space
+		insertNames(t, fx, NameDataItem{FullName: "alice.any"})
+
+		out, err = fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{})
+		require.NoError(t, err)
+		require.False(t, out.Found)
+	})
+
+	t.Run("return the name that expires last", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", SpaceId: "space", NameExpires: 100},
+			NameDataItem{FullName: "bob.any", SpaceId: "space", NameExpires: 200},
+			NameDataItem{FullName: "carol.any", SpaceId: "another", NameExpires: 300},
+		)
+
+		out, err := fx.GetNameBySpaceId(ctx, &nsextproto.NameBySpaceIdRequest{SpaceId: "space"})
+		require.NoError(t, err)
+		require.True(t, out.Found)
+		require.Equal(t, "bob.any", out.Name)
+	})
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRefreshTtlSec           = 24 * 60 * 60
+	defaultRefreshNearExpirySec    = 7 * 24 * 60 * 60
+	defaultRefreshNearExpiryTtlSec = 60 * 60
+	defaultRefreshIntervalSec      = 5 * 60
+	defaultRefreshBatchSize        = 500
+	defaultRefreshConcurrency      = 4
+	defaultRefreshRatePerSec       = 10
+)
+
+func (cs *cacheService) setRefreshDefaults() {
+	c := &cs.confCache
+	if c.RefreshTtlSec == 0 {
+		c.RefreshTtlSec = defaultRefreshTtlSec
+	}
+	if c.RefreshNearExpirySec == 0 {
+		c.RefreshNearExpirySec = defaultRefreshNearExpirySec
+	}
+	if c.RefreshNearExpiryTtlSec == 0 {
+		c.RefreshNearExpiryTtlSec = defaultRefreshNearExpiryTtlSec
+	}
+	if c.RefreshIntervalSec == 0 {
+		c.RefreshIntervalSec = defaultRefreshIntervalSec
+	}
+	if c.RefreshBatchSize == 0 {
+		c.RefreshBatchSize = defaultRefreshBatchSize
+	}
+	if c.RefreshConcurrency == 0 {
+		c.RefreshConcurrency = defaultRefreshConcurrency
+	}
+	if c.RefreshRatePerSec == 0 {
+		c.RefreshRatePerSec = defaultRefreshRatePerSec
+	}
+}
+
+func (cs *cacheService) refreshWorker(ctx context.Context) {
+	log.Info("refresh worker started")
+
+	ticker := time.NewTicker(time.Duration(cs.confCache.RefreshIntervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("refresh worker stopped")
+			close(cs.refreshDone)
+			return
+		case <-ticker.C:
+		}
+
+		_, err := cs.RefreshStaleEntries(ctx)
+		if err != nil {
+			// in case of error - do not stop, try again next time
+			log.Warn("failed to refresh stale entries", zap.Error(err))
+		}
+	}
+}
+
+func (cs *cacheService) RefreshStaleEntries(ctx context.Context) (refreshed int, err error) {
+	// 1 - find entries that were not refreshed for a long time
+	// or that are close to expiry and were not refreshed recently
+	now := time.Now().Unix()
+	staleBefore := now - int64(cs.confCache.RefreshTtlSec)
+	nearExpiryStaleBefore := now - int64(cs.confCache.RefreshNearExpiryTtlSec)
+	nearExpiry := now + int64(cs.confCache.RefreshNearExpirySec)
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		// written before refresh metadata was added
+		bson.D{{Key: "last_refreshed", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "last_refreshed", Value: bson.D{{Key: "$lt", Value: staleBefore}}}},
+		bson.D{
+			{Key: "name_expires", Value: bson.D{{Key: "$gt", Value: now}, {Key: "$lt", Value: nearExpiry}}},
+			{Key: "last_refreshed", Value: bson.D{{Key: "$lt", Value: nearExpiryStaleBefore}}},
+		},
+	}}}
+
+	// oldest first
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_refreshed", Value: 1}}).
+		SetLimit(int64(cs.confCache.RefreshBatchSize))
+
+	cursor, err := cs.itemColl.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to find stale entries", zap.Error(err))
+		return 0, err
+	}
+
+	var items []NameDataItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		log.Error("failed to decode stale entries", zap.Error(err))
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	// 2 - refresh them with bounded concurrency and rate
+	// each chunk is read from the chain at once
+	chunkSize := min(namesPerRead, len(items))
+	limiter := rate.NewLimiter(rate.Limit(cs.confCache.RefreshRatePerSec), chunkSize)
+	sem := make(chan struct{}, cs.confCache.RefreshConcurrency)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for from := 0; from < len(items); from += chunkSize {
+		chunk := items[from:min(from+chunkSize, len(items))]
+
+		if err = limiter.WaitN(ctx, len(chunk)); err != nil {
+			// context is cancelled
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(chunk []NameDataItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			n := cs.refreshEntries(ctx, chunk)
+			mu.Lock()
+			refreshed += n
+			mu.Unlock()
+		}(chunk)
+	}
+	wg.Wait()
+
+	log.Info("refreshed stale entries", zap.Int("found", len(items)), zap.Int("refreshed", refreshed))
+	return refreshed, err
+}
+
+// returns number of entries that were refreshed
+func (cs *cacheService) refreshEntries(ctx context.Context, old []NameDataItem) (refreshed int) {
+	cs.metrics.refreshChecked.Add(float64(len(old)))
+
+	names := make([]string, 0, len(old))
+	for _, item := range old {
+		names = append(names, item.FullName)
+	}
+
+	items, err := cs.readNamesData(ctx, names)
+	if err != nil {
+		log.Warn("failed to refresh entries", zap.Strings("FullNames", names), zap.Error(err))
+		cs.metrics.refreshErrors.Add(float64(len(old)))
+		return 0
+	}
+
+	for i := range old {
+		if cs.refreshEntry(ctx, &old[i], items[i]) == nil {
+			refreshed++
+		}
+	}
+	return refreshed
+}
+
+func (cs *cacheService) refreshEntry(ctx context.Context, old *NameDataItem, ndi *NameDataItem) error {
+	updated, err := cs.storeNameData(ctx, old.FullName, ndi)
+	if err != nil {
+		log.Warn("failed to refresh entry", zap.String("FullName", old.FullName), zap.Error(err))
+		cs.metrics.refreshErrors.Inc()
+		return err
+	}
+
+	// name is not registered anymore (entry was removed)
+	if updated == nil {
+		cs.metrics.refreshDrifted.WithLabelValues("removed").Inc()
+		return nil
+	}
+
+	for _, field := range driftedFields(old, updated) {
+		log.Info("cache entry drifted", zap.String("FullName", old.FullName), zap.String("field", field))
+		cs.metrics.refreshDrifted.WithLabelValues(field).Inc()
+	}
+	return nil
+}
+
+// returns names of the fields that were changed on chain
+func driftedFields(old *NameDataItem, updated *NameDataItem) (fields []string) {
+	if old.OwnerEthAddress != updated.OwnerEthAddress || old.OwnerScwEthAddress != updated.OwnerScwEthAddress {
+		fields = append(fields, "owner")
+	}
+	if old.OwnerAnyAddress != updated.OwnerAnyAddress {
+		fields = append(fields, "contenthash")
+	}
+	if old.SpaceId != updated.SpaceId {
+		fields = append(fields, "space_id")
+	}
+	if old.NameExpires != updated.NameExpires {
+		fields = append(fields, "expiration")
+	}
+	return fields
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-ns-node/contracts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCacheService_driftedFields(t *testing.T) {
+	old := &NameDataItem{
+		FullName:           "test.any",
+		OwnerEthAddress:    "0x1",
+		OwnerScwEthAddress: "0x2",
+		OwnerAnyAddress:    "any",
+		SpaceId:            "space",
+		NameExpires:        100,
+	}
+
+	// 1 - nothing changed (block and refresh time are not compared)
+	same := *old
+	same.BlockNumber = 10
+	same.LastRefreshed = 20
+	assert.Equal(t, len(driftedFields(old, &same)), 0)
+
+	// 2 - renewed and transferred
+	changed := *old
+	changed.OwnerScwEthAddress = "0x3"
+	changed.NameExpires = 200
+	assert.DeepEqual(t, driftedFields(old, &changed), []string{"owner", "expiration"})
+
+	// 3 - everything changed
+	changed = NameDataItem{FullName: "test.any"}
+	assert.DeepEqual(t, driftedFields(old, &changed), []string{"owner", "contenthash", "space_id", "expiration"})
+}
+
+func TestCacheService_RefreshStaleEntries(t *testing.T) {
+	t.Run("refresh only stale entries", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		now := time.Now().Unix()
+		owner := common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
+
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"stale.any"}, gomock.Any()).Return([]*contracts.NameInfo{
+			{
+				FullName:        "stale.any",
+				Registered:      true,
+				Owner:           owner.Hex(),
+				OwnerAnyAddress: "new_any",
+				SpaceId:         "space",
+				NameExpires:     big.NewInt(now + 1000000),
+			},
+		}, nil)
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("", nil)
+
+		_, err := fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:        "stale.any",
+			OwnerAnyAddress: "old_any",
+			NameExpires:     now + 1000000,
+			LastRefreshed:   now - int64(fx.confCache.RefreshTtlSec) - 1,
+		})
+		require.NoError(t, err)
+
+		_, err = fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:      "fresh.any",
+			NameExpires:   now + 1000000,
+			LastRefreshed: now,
+		})
+		require.NoError(t, err)
+
+		refreshed, err := fx.RefreshStaleEntries(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed, 1)
+
+		var item NameDataItem
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "stale.any"}).Decode(&item)
+		require.NoError(t, err)
+		assert.Equal(t, item.OwnerAnyAddress, "new_any")
+		assert.True(t, item.LastRefreshed >= now)
+	})
+
+	t.Run("remove entry if name is not registered anymore", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		now := time.Now().Unix()
+		fx.contracts.EXPECT().GetNamesInfo(gomock.Any(), []string{"test.any"}, gomock.Any()).Return([]*contracts.NameInfo{{FullName: "test.any"}}, nil)
+
+		// expires soon and was not refreshed recently
+		_, err := fx.itemColl.InsertOne(ctx, NameDataItem{
+			FullName:      "test.any",
+			NameExpires:   now + 60,
+			LastRefreshed: now - int64(fx.confCache.RefreshNearExpiryTtlSec) - 1,
+		})
+		require.NoError(t, err)
+
+		refreshed, err := fx.RefreshStaleEntries(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed, 1)
+
+		err = fx.itemColl.FindOne(ctx, findNameDataByName{FullName: "test.any"}).Err()
+		assert.Equal(t, err, mongo.ErrNoDocuments)
+	})
+}
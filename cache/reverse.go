@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/contracts"
+)
+
+// primary name that the address has chosen (on-chain reverse record)
+type ReverseRecordItem struct {
+	// always store in LOWER CASE!
+	Address string `bson:"address"`
+	// namehash of "<address>.addr.reverse" in hex
+	// NameChanged events only have it
+	Node string `bson:"node"`
+	// WARNING: can point to the name that address does not own anymore
+	Name string `bson:"name"`
+
+	// unixtime when record was read from the chain last time
+	LastRefreshed int64 `bson:"last_refreshed"`
+}
+
+type findReverseByAddress struct {
+	Address string `bson:"address"`
+}
+
+type findReverseByNode struct {
+	Node string `bson:"node"`
+}
+
+func (cs *cacheService) UpdateReverseRecord(ctx context.Context, address string) (err error) {
+	if !common.IsHexAddress(address) {
+		return errors.New("invalid ETH address")
+	}
+	addr := common.HexToAddress(address)
+	lower := strings.ToLower(addr.Hex())
+
+	// 1 - read reverse record from the resolver
+	name, err := cs.contracts.GetNameByAddress(ctx, addr)
+	if err != nil {
+		log.Error("failed to get reverse record", zap.String("Address", lower), zap.Error(err))
+		return err
+	}
+
+	// primary name of the address could change
+	defer cs.memory.remove(memoryKeyAddress + lower)
+
+	// 2 - no reverse record (or it was cleared)
+	if name == "" {
+		_, err = cs.reverseColl.DeleteOne(ctx, findReverseByAddress{Address: lower})
+		return err
+	}
+
+	node, err := contracts.ReverseNode(addr)
+	if err != nil {
+		return err
+	}
+
+	// 3 - save it
+	item := ReverseRecordItem{
+		Address:       lower,
+		Node:          common.Hash(node).Hex(),
+		Name:          name,
+		LastRefreshed: time.Now().Unix(),
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	_, err = cs.reverseColl.ReplaceOne(ctx, findReverseByAddress{Address: lower}, item, opts)
+	if err != nil {
+		log.Error("failed to update reverse record", zap.String("Address", lower), zap.Error(err))
+		return err
+	}
+	cs.memory.invalidateName(name)
+	return nil
+}
+
+func (cs *cacheService) UpdateReverseRecordByNode(ctx context.Context, node [32]byte) (err error) {
+	var item ReverseRecordItem
+	err = cs.reverseColl.FindOne(ctx, findReverseByNode{Node: common.Hash(node).Hex()}).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		// address can not be restored from the node
+		// (it is updated together with the name it owns)
+		return nil
+	}
+	if err != nil {
+		log.Error("failed to get reverse record", zap.Error(err))
+		return err
+	}
+
+	return cs.UpdateReverseRecord(ctx, item.Address)
+}
+
+// updating reverse record is not critical, so only log errors
+func (cs *cacheService) updateReverseRecordForOwner(ctx context.Context, ndi *NameDataItem) {
+	if ndi.OwnerScwEthAddress == "" {
+		return
+	}
+
+	err := cs.UpdateReverseRecord(ctx, ndi.OwnerScwEthAddress)
+	if err != nil {
+		log.Warn("failed to update reverse record for owner", zap.String("FullName", ndi.FullName), zap.Error(err))
+	}
+}
+
+// returns the name from the reverse record
+// only if it is still owned by the address (forward resolution matches)
+// or nil if there is no such name
+func (cs *cacheService) getReverseName(ctx context.Context, address string) (*NameDataItem, error) {
+	var rec ReverseRecordItem
+	err := cs.reverseColl.FindOne(ctx, findReverseByAddress{Address: address}).Decode(&rec)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error("failed to get reverse record", zap.Error(err))
+		return nil, err
+	}
+
+	var item NameDataItem
+	err = cs.itemColl.FindOne(ctx, findNameDataByName{FullName: rec.Name}).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error("failed to get item from DB", zap.Error(err))
+		return nil, err
+	}
+
+	if item.OwnerScwEthAddress != address {
+		// name was transferred, but reverse record was not updated
+		return nil, nil
+	}
+	return &item, nil
+}
+
+// if there is no valid reverse record -> choose one of the names deterministically:
+// the one that expires last (usually the latest registered/renewed), then by name
+func (cs *cacheService) getFallbackName(ctx context.Context, filter interface{}) (*NameDataItem, error) {
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "name_expires", Value: -1},
+		{Key: "name", Value: 1},
+	})
+
+	var item NameDataItem
+	err := cs.itemColl.FindOne(ctx, filter, opts).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error("failed to get item from DB", zap.Error(err))
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (cs *cacheService) getPrimaryNameByAddress(ctx context.Context, address string) (*NameDataItem, error) {
+	item, err := cs.getReverseName(ctx, address)
+	if err != nil || item != nil {
+		return item, err
+	}
+
+	return cs.getFallbackName(ctx, findNameDataByAddress{OwnerScwEthAddress: address})
+}
+
+func (cs *cacheService) getPrimaryNameByAnyId(ctx context.Context, anyAddress string) (*NameDataItem, error) {
+	return cs.getPrimaryNameOfOwners(ctx, findNameDataByAnyAddress{OwnerAnyAddress: anyAddress}, func(item *NameDataItem) bool {
+		return item.OwnerAnyAddress == anyAddress
+	})
+}
+
+func (cs *cacheService) getPrimaryNameByEthAddress(ctx context.Context, ethAddress string) (*NameDataItem, error) {
+	return cs.getPrimaryNameOfOwners(ctx, findNameDataByEthAddress{OwnerEthAddress: ethAddress}, func(item *NameDataItem) bool {
+		return item.OwnerEthAddress == ethAddress
+	})
+}
+
+// names that match the filter can be owned by several SCWs
+// so primary names of all of them are checked (sorted by address)
+func (cs *cacheService) getPrimaryNameOfOwners(ctx context.Context, filter interface{}, matches func(item *NameDataItem) bool) (*NameDataItem, error) {
+	// 1 - primary names of all owners
+	values, err := cs.itemColl.Distinct(ctx, "owner_scw_eth_address", filter)
+	if err != nil {
+		log.Error("failed to get owners", zap.Error(err))
+		return nil, err
+	}
+
+	owners := make([]string, 0, len(values))
+	for _, v := range values {
+		if owner, ok := v.(string); ok && owner != "" {
+			owners = append(owners, owner)
+		}
+	}
+	sort.Strings(owners)
+
+	for _, owner := range owners {
+		item, err := cs.getReverseName(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil && matches(item) {
+			return item, nil
+		}
+	}
+
+	// 2 - no primary name
+	return cs.getFallbackName(ctx, filter)
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"testing"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/anyproto/any-ns-node/contracts"
+)
+
+const testScwAddress = "0x10d5b0e279e5e4c1d1df5f57dfb7e84813920a51"
+
+func insertNames(t *testing.T, fx *fixture, items ...NameDataItem) {
+	for _, item := range items {
+		err := fx.setNameData(ctx, &item)
+		require.NoError(t, err)
+	}
+}
+
+func TestCacheService_GetNameByAddressPrimary(t *testing.T) {
+	t.Run("return primary name from reverse record", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, NameExpires: 300},
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: testScwAddress, NameExpires: 100},
+		)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), common.HexToAddress(testScwAddress)).Return("bob.any", nil)
+		require.NoError(t, fx.UpdateReverseRecord(ctx, testScwAddress))
+
+		out, err := fx.GetNameByAddress(ctx, &nsp.NameByAddressRequest{OwnerScwEthAddress: testScwAddress})
+		require.NoError(t, err)
+		require.True(t, out.Found)
+		require.Equal(t, "bob.any", out.Name)
+	})
+
+	t.Run("ignore reverse record if name is not owned anymore", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, NameExpires: 100},
+			NameDataItem{FullName: "carol.any", OwnerScwEthAddress: testScwAddress, NameExpires: 300},
+			// was transferred
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: "0xanother", NameExpires: 500},
+		)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("bob.any", nil)
+		require.NoError(t, fx.UpdateReverseRecord(ctx, testScwAddress))
+
+		// fallback: the one that expires last
+		out, err := fx.GetNameByAddress(ctx, &nsp.NameByAddressRequest{OwnerScwEthAddress: testScwAddress})
+		require.NoError(t, err)
+		require.True(t, out.Found)
+		require.Equal(t, "carol.any", out.Name)
+	})
+
+	t.Run("fall back deterministically if there is no reverse record", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "carol.any", OwnerScwEthAddress: testScwAddress, NameExpires: 300},
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, NameExpires: 300},
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: testScwAddress, NameExpires: 100},
+		)
+
+		// same expiration -> by name
+		out, err := fx.GetNameByAddress(ctx, &nsp.NameByAddressRequest{OwnerScwEthAddress: testScwAddress})
+		require.NoError(t, err)
+		require.True(t, out.Found)
+		require.Equal(t, "alice.any", out.Name)
+	})
+
+	t.Run("remove reverse record if it was cleared", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, NameExpires: 300},
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: testScwAddress, NameExpires: 100},
+		)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("bob.any", nil)
+		require.NoError(t, fx.UpdateReverseRecord(ctx, testScwAddress))
+
+		// NameChanged event only has the node
+		node, err := contracts.ReverseNode(common.HexToAddress(testScwAddress))
+		require.NoError(t, err)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("", nil)
+		require.NoError(t, fx.UpdateReverseRecordByNode(ctx, node))
+
+		out, err := fx.GetNameByAddress(ctx, &nsp.NameByAddressRequest{OwnerScwEthAddress: testScwAddress})
+		require.NoError(t, err)
+		require.Equal(t, "alice.any", out.Name)
+	})
+}
+
+func TestCacheService_GetNameByAnyIdPrimary(t *testing.T) {
+	t.Run("return primary name of the owner", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertNames(t, fx,
+			NameDataItem{FullName: "alice.any", OwnerScwEthAddress: testScwAddress, OwnerAnyAddress: "anyid", NameExpires: 300},
+			NameDataItem{FullName: "bob.any", OwnerScwEthAddress: testScwAddress, OwnerAnyAddress: "anyid", NameExpires: 100},
+		)
+
+		fx.contracts.EXPECT().GetNameByAddress(gomock.Any(), gomock.Any()).Return("bob.any", nil)
+		require.NoError(t, fx.UpdateReverseRecord(ctx, testScwAddress))
+
+		out, err := fx.GetNameByAnyId(ctx, &nsp.NameByAnyIdRequest{AnyAddress: "anyid"})
+		require.NoError(t, err)
+		require.True(t, out.Found)
+		require.Equal(t, "bob.any", out.Name)
+	})
+
+	t.Run("find nothing", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		out, err := fx.GetNameByAnyId(ctx, &nsp.NameByAnyIdRequest{AnyAddress: "anyid"})
+		require.NoError(t, err)
+		require.False(t, out.Found)
+	})
+}
@@ -14,12 +14,18 @@ import (
 	"github.com/anyproto/any-ns-node/anynsrpc"
 	"github.com/anyproto/any-ns-node/cache"
 	mongo "github.com/anyproto/any-ns-node/db"
+	"github.com/anyproto/any-ns-node/indexer"
+	"github.com/anyproto/any-ns-node/migrations"
 	"github.com/anyproto/any-ns-node/nonce_manager"
 	"github.com/anyproto/any-ns-node/queue"
+	"github.com/anyproto/any-ns-node/selfcheck"
+	"github.com/anyproto/any-ns-node/signer"
 	"github.com/getsentry/sentry-go"
 
 	"github.com/anyproto/any-ns-node/config"
 	"github.com/anyproto/any-ns-node/contracts"
+	"github.com/anyproto/any-ns-node/nsext/nsextclient"
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
 	"github.com/anyproto/any-sync/accountservice"
 	"github.com/anyproto/any-sync/metric"
 	nsclient "github.com/anyproto/any-sync/nameservice/nameserviceclient"
@@ -62,8 +68,9 @@ var (
 	flagVersion    = flag.Bool("v", false, "show version and exit")
 	flagHelp       = flag.Bool("h", false, "show help and exit")
 	flagClient     = flag.Bool("cl", false, "run nsp client")
-	command        = flag.String("cmd", "", "command to run: [admin-name-register, admin-name-renew, admin-fund-user, is-name-available, name-by-address, get-operation, batch-is-name-available, batch-name-by-anyid, name-by-anyid]")
+	command        = flag.String("cmd", "", "command to run: [admin-name-register, admin-name-renew, admin-fund-user, is-name-available, name-by-address, get-operation, batch-is-name-available, batch-name-by-anyid, name-by-anyid, names-by-owner, name-by-space-id, name-at-block, admin-queue-dead-items, admin-queue-abandon, admin-queue-items, admin-queue-item, admin-queue-counts, admin-queue-cancel, admin-queue-advance, admin-queue-requeue]; without -cl: [reindex, migrate, self-check]")
 	params         = flag.String("params", "", "command params in json format")
+	flagDryRun     = flag.Bool("dry-run", false, "migrate: only show what would be changed")
 )
 
 func main() {
@@ -122,6 +129,19 @@ func main() {
 		return
 	}
 
+	// server-side commands
+	switch *command {
+	case "reindex":
+		runReindex(a, ctx, conf)
+		return
+	case "migrate":
+		runMigrate(a, ctx, conf)
+		return
+	case "self-check":
+		runSelfCheck(a, ctx, conf)
+		return
+	}
+
 	BootstrapServer(a)
 
 	// start app
@@ -178,6 +198,28 @@ func runAsClient(a *app.App, ctx context.Context) {
 		clientNameByAddress(ctx, client)
 	case "name-by-anyid":
 		clientNameByAnyid(ctx, client)
+	case "names-by-owner":
+		clientNamesByOwner(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "name-by-space-id":
+		clientNameBySpaceId(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "name-at-block":
+		clientNameAtBlock(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "admin-queue-dead-items":
+		adminQueueDeadItems(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "admin-queue-abandon":
+		adminQueueAbandon(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "admin-queue-items":
+		adminQueueItems(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "admin-queue-counts":
+		adminQueueCounts(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService))
+	case "admin-queue-item":
+		adminQueueItem(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService).AdminQueueGetItem)
+	case "admin-queue-cancel":
+		adminQueueItem(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService).AdminQueueCancelItem)
+	case "admin-queue-advance":
+		adminQueueItem(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService).AdminQueueAdvanceItem)
+	case "admin-queue-requeue":
+		adminQueueItem(ctx, a.MustComponent(nsextclient.CName).(nsextclient.AnyNsExtClientService).AdminQueueRequeueItem)
 	// hidden command
 	case "benchmark":
 		clientBenchmark(ctx, client)
@@ -196,6 +238,108 @@ func runAsClient(a *app.App, ctx context.Context) {
 	}
 }
 
+// rebuild the whole cache from the contract logs and exit
+// nodes can keep running: names that they change during the rebuild are read again at the end
+func runReindex(a *app.App, ctx context.Context, conf *config.Config) {
+	log.Info("running a full reindex...")
+
+	// do not start tailing logs in the background
+	conf.Indexer.Enabled = false
+
+	a.Register(migrations.New()).
+		Register(contracts.New()).
+		Register(cache.New()).
+		Register(indexer.New())
+
+	if err := a.Start(ctx); err != nil {
+		log.Fatal("can't start app", zap.Error(err))
+	}
+
+	start := time.Now()
+	err := a.MustComponent(indexer.CName).(indexer.IndexerService).Reindex(ctx)
+	if err != nil {
+		log.Fatal("reindex failed", zap.Error(err))
+	}
+	log.Info("reindex finished", zap.Duration("elapsed", time.Since(start)))
+
+	if err := a.Close(ctx); err != nil {
+		log.Fatal("close error", zap.Error(err))
+	}
+}
+
+// check contracts and permissions of the admin and exit
+// exit code is 1 if any check has failed
+func runSelfCheck(a *app.App, ctx context.Context, conf *config.Config) {
+	// will call Check manually
+	conf.SelfCheck.Skip = true
+
+	a.Register(contracts.New()).
+		Register(selfcheck.New())
+
+	if err := a.Start(ctx); err != nil {
+		log.Fatal("can't start app", zap.Error(err))
+	}
+
+	timeout := time.Duration(conf.SelfCheck.TimeoutSec) * time.Second
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	results := a.MustComponent(selfcheck.CName).(selfcheck.SelfCheckService).Check(checkCtx)
+	cancel()
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			fmt.Printf("OK    %s\n", r.Name)
+		case r.Warning:
+			fmt.Printf("WARN  %s: %s\n", r.Name, r.Err)
+		default:
+			fmt.Printf("FAIL  %s: %s\n", r.Name, r.Err)
+			failed++
+		}
+	}
+
+	if err := a.Close(ctx); err != nil {
+		log.Error("close error", zap.Error(err))
+	}
+	if failed > 0 {
+		fmt.Printf("%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+}
+
+// apply all pending schema migrations (or just show them with -dry-run) and exit
+func runMigrate(a *app.App, ctx context.Context, conf *config.Config) {
+	log.Info("running migrations...", zap.Bool("dry run", *flagDryRun))
+
+	// will call Migrate manually
+	conf.Mongo.SkipMigrations = true
+
+	a.Register(migrations.New())
+
+	if err := a.Start(ctx); err != nil {
+		log.Fatal("can't start app", zap.Error(err))
+	}
+
+	ms := a.MustComponent(migrations.CName).(migrations.MigrationService)
+	applied, err := ms.Migrate(ctx, *flagDryRun)
+	if err != nil {
+		log.Fatal("migration failed", zap.Error(err))
+	}
+
+	version, err := ms.GetSchemaVersion(ctx)
+	if err != nil {
+		log.Fatal("can't get schema version", zap.Error(err))
+	}
+	log.Info("migrations finished", zap.Ints("migrations", applied), zap.Int("schema version", version))
+
+	if err := a.Close(ctx); err != nil {
+		log.Fatal("close error", zap.Error(err))
+	}
+}
+
 func clientIsNameAvailable(ctx context.Context, client nsclient.AnyNsClientService) {
 	var req = &nsp.NameAvailableRequest{}
 	err := json.Unmarshal([]byte(*params), &req)
@@ -209,7 +353,8 @@ func clientIsNameAvailable(ctx context.Context, client nsclient.AnyNsClientServi
 	if err != nil {
 		log.Fatal("can't get response", zap.Error(err))
 	}
-	log.Info("got response", zap.Any("response", resp))
+	log.Info("got response", zap.Any("response", resp),
+		zap.Stringer("state", cache.ResponseToNameState(resp, time.Now().Unix())))
 }
 
 func clientBatchIsNameAvailable(ctx context.Context, client nsclient.AnyNsClientService) {
@@ -392,6 +537,132 @@ func clientNameByAnyid(ctx context.Context, client nsclient.AnyNsClientService)
 	log.Info("got response", zap.Any("response", resp))
 }
 
+func clientNamesByOwner(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.NamesByOwnerRequest{}
+	err := json.Unmarshal([]byte(*params), &req)
+	if err != nil {
+		log.Fatal("wrong command parameters", zap.Error(err))
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.GetNamesByOwner(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+func clientNameBySpaceId(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.NameBySpaceIdRequest{}
+	err := json.Unmarshal([]byte(*params), &req)
+	if err != nil {
+		log.Fatal("wrong command parameters", zap.Error(err))
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.GetNameBySpaceId(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+func clientNameAtBlock(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.NameAtBlockRequest{}
+	err := json.Unmarshal([]byte(*params), &req)
+	if err != nil {
+		log.Fatal("wrong command parameters", zap.Error(err))
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.GetNameAtBlock(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+// client should be started with the admin peer key
+func adminQueueDeadItems(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.DeadQueueItemsRequest{}
+	if *params != "" {
+		err := json.Unmarshal([]byte(*params), &req)
+		if err != nil {
+			log.Fatal("wrong command parameters", zap.Error(err))
+		}
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.AdminQueueGetDeadItems(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+func adminQueueItems(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.QueueItemsRequest{}
+	if *params != "" {
+		err := json.Unmarshal([]byte(*params), &req)
+		if err != nil {
+			log.Fatal("wrong command parameters", zap.Error(err))
+		}
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.AdminQueueGetItems(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+func adminQueueCounts(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	resp, err := client.AdminQueueGetCounts(ctx, &nsextproto.QueueCountsRequest{})
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+// get, cancel, advance or requeue one item
+func adminQueueItem(ctx context.Context, fn func(ctx context.Context, in *nsextproto.QueueItemRequest) (*nsextproto.QueueItemResponse, error)) {
+	var req = &nsextproto.QueueItemRequest{}
+	err := json.Unmarshal([]byte(*params), &req)
+	if err != nil {
+		log.Fatal("wrong command parameters", zap.Error(err))
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := fn(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
+func adminQueueAbandon(ctx context.Context, client nsextclient.AnyNsExtClientService) {
+	var req = &nsextproto.DeadQueueItemRequest{}
+	err := json.Unmarshal([]byte(*params), &req)
+	if err != nil {
+		log.Fatal("wrong command parameters", zap.Error(err))
+	}
+
+	log.Info("sending request", zap.Any("request", req))
+
+	resp, err := client.AdminQueueAbandonDeadItem(ctx, req)
+	if err != nil {
+		log.Fatal("can't get response", zap.Error(err))
+	}
+	log.Info("got response", zap.Any("response", resp))
+}
+
 func clientGetUserAccount(ctx context.Context, client nsclient.AnyNsClientService) {
 	var req = &nsp.GetUserAccountRequest{}
 	err := json.Unmarshal([]byte(*params), &req)
@@ -479,12 +750,16 @@ func BootstrapClient(a *app.App) {
 		Register(quic.New()).
 		Register(secureservice.New()).
 		Register(server.New()).
-		Register(nsclient.New())
+		Register(nsclient.New()).
+		Register(nsextclient.New())
 }
 
 func BootstrapServer(a *app.App) {
 	a.Register(account.New()).
+		Register(signer.New()).
 		Register(contracts.New()).
+		// fail fast if contracts or permissions of the admin are misconfigured
+		Register(selfcheck.New()).
 		Register(metric.New()).
 		Register(nodeconf.New()).
 		Register(nodeconfstore.New()).
@@ -492,6 +767,8 @@ func BootstrapServer(a *app.App) {
 		Register(coordinatorclient.New()).
 		Register(alchemysdk.New()).
 		Register(limiter.New()).
+		// should be before any other component that uses Mongo in Run
+		Register(migrations.New()).
 		Register(cache.New()).
 		Register(pool.New()).
 		Register(peerservice.New()).
@@ -501,6 +778,7 @@ func BootstrapServer(a *app.App) {
 		Register(anynsrpc.New()).
 		Register(anynsaarpc.New()).
 		Register(queue.New()).
+		Register(indexer.New()).
 		Register(mongo.New()).
 		Register(nonce_manager.New()).
 		Register(yamux.New()).
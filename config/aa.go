@@ -8,4 +8,7 @@ type AA struct {
 	GasPolicyId       string `yaml:"gasPolicyID"`
 	ChainID           int    `yaml:"chainID"`
 	NameTokensPerName uint8  `yaml:"nameTokensPerName"`
+	// each request to the bundler is aborted after N seconds
+	// (or earlier if the client has disconnected)
+	BundlerTimeoutSec uint `yaml:"bundlerTimeoutSec"`
 }
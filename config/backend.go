@@ -0,0 +1,17 @@
+package config
+
+// which service sends name operations of the admin to the chain
+const (
+	// user operations are sent to the bundler, gas is paid by the paymaster
+	BackendAA = "aa"
+	// txs are sent by the queue directly from the admin's account
+	// use it when the bundler or paymaster is down or on chains without ERC-4337
+	BackendQueue = "queue"
+)
+
+type Backend struct {
+	// AdminNameRegisterSigned: "aa" (default) or "queue"
+	NameRegister string `yaml:"nameRegister"`
+	// AdminNameRenewSigned: "aa" (default) or "queue"
+	NameRenew string `yaml:"nameRenew"`
+}
@@ -0,0 +1,31 @@
+package config
+
+type Cache struct {
+	// how often to re-verify cached names whose grace period is over
+	// (they are reported as taken until verified)
+	ExpiryCheckIntervalSec uint `yaml:"expiryCheckIntervalSec"`
+
+	// entries that were not read from the chain for that long are refreshed
+	RefreshTtlSec uint `yaml:"refreshTtlSec"`
+	// entries that expire soon (in less than RefreshNearExpirySec)
+	// are refreshed more often - every RefreshNearExpiryTtlSec
+	RefreshNearExpirySec    uint `yaml:"refreshNearExpirySec"`
+	RefreshNearExpiryTtlSec uint `yaml:"refreshNearExpiryTtlSec"`
+	// how often to look for stale entries
+	RefreshIntervalSec uint `yaml:"refreshIntervalSec"`
+	// max number of entries to refresh in one run
+	RefreshBatchSize uint `yaml:"refreshBatchSize"`
+	// max number of chunks (up to 100 entries, read in one multicall) that are refreshed in parallel
+	RefreshConcurrency uint `yaml:"refreshConcurrency"`
+	// max number of entries refreshed per second
+	RefreshRatePerSec uint `yaml:"refreshRatePerSec"`
+
+	// in-memory tier in front of Mongo
+	MemoryDisabled bool `yaml:"memoryDisabled"`
+	// max number of lookups kept in memory
+	MemorySize uint `yaml:"memorySize"`
+	// for names that are taken and addresses that have a name
+	MemoryPositiveTtlSec uint `yaml:"memoryPositiveTtlSec"`
+	// for names that are available and addresses without a name
+	MemoryNegativeTtlSec uint `yaml:"memoryNegativeTtlSec"`
+}
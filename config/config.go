@@ -41,12 +41,17 @@ type Config struct {
 	Yamux            yamux.Config           `yaml:"yamux"`
 	Mongo            Mongo                  `yaml:"mongo"`
 	Contracts        Contracts              `yaml:"contracts"`
+	Signer           Signer                 `yaml:"signer"`
 	Aa               AA                     `yaml:"accountAbstraction"`
 	Metric           metric.Config          `yaml:"metric"`
 	Nonce            Nonce                  `yaml:"nonce"`
 	Queue            Queue                  `yaml:"queue"`
+	Indexer          Indexer                `yaml:"indexer"`
+	Cache            Cache                  `yaml:"cache"`
 	Limiter          limiter.Config         `yaml:"limiter"`
 	Sentry           Sentry                 `yaml:"sentry"`
+	SelfCheck        SelfCheck              `yaml:"selfCheck"`
+	Backend          Backend                `yaml:"backend"`
 	// use mongo cache to read data from
 	ReadFromCache bool `yaml:"readFromCache"`
 
@@ -72,6 +77,10 @@ func (c *Config) GetContracts() Contracts {
 	return c.Contracts
 }
 
+func (c *Config) GetSigner() Signer {
+	return c.Signer
+}
+
 func (c *Config) GetNodeConf() nodeconf.Configuration {
 	return c.Network
 }
@@ -112,6 +121,14 @@ func (c *Config) GetQueue() Queue {
 	return c.Queue
 }
 
+func (c *Config) GetIndexer() Indexer {
+	return c.Indexer
+}
+
+func (c *Config) GetCache() Cache {
+	return c.Cache
+}
+
 func (c *Config) GetLimiterConf() limiter.Config {
 	return c.Limiter
 }
@@ -119,3 +136,11 @@ func (c *Config) GetLimiterConf() limiter.Config {
 func (c *Config) GetSentry() Sentry {
 	return c.Sentry
 }
+
+func (c *Config) GetSelfCheck() SelfCheck {
+	return c.SelfCheck
+}
+
+func (c *Config) GetBackend() Backend {
+	return c.Backend
+}
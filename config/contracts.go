@@ -1,7 +1,11 @@
 package config
 
+import "gopkg.in/yaml.v3"
+
 type Contracts struct {
-	GethUrl                        string `yaml:"gethUrl"`
+	// RPC endpoints in the order of preference
+	// (can be a single URL or a list)
+	GethUrl                        Urls   `yaml:"gethUrl"`
 	AddrRegistry                   string `yaml:"ensRegistry"`
 	AddrResolver                   string `yaml:"resolver"`
 	AddrRegistrarImplementation    string `yaml:"registrarImplementation"`
@@ -10,13 +14,74 @@ type Contracts struct {
 	AddrToken                      string `yaml:"nameToken"`
 	TokenDecimals                  uint8  `yaml:"tokenDecimals"`
 	AddrNameWrapper                string `yaml:"nameWrapper"`
+	// Multicall3 contract, all name data is read in one call with it
+	// if empty (or not deployed) -> each value is read with a separate call
+	AddrMulticall string `yaml:"multicall"`
+
+	// block where contracts were deployed
+	// full reindex scans all logs starting from it
+	DeploymentBlock uint64 `yaml:"deploymentBlock"`
 
+	// key of the admin is kept by the signer (see Signer)
 	AddrAdmin string `yaml:"admin"`
-	AdminPk   string `yaml:"adminPk"`
 
 	// when tx is sent, we will first try to get it N times
 	// each time waiting for X seconds. If we will not get it -> we will think that TX
 	// was immediately rejected without mining, which is a "high nonce" sign
 	// (probabilistic, but it's ok)
 	WaitMiningRetryCount uint `yaml:"waitMintingRetryCount"`
+
+	// data that was read from (or written to) block B is treated as final
+	// only when the chain head is at least B + N. Until then the block can be
+	// reorged out and all data derived from it should be re-read.
+	// 0 means "final as soon as it is mined"
+	ConfirmationBlocks uint64 `yaml:"confirmationBlocks"`
+
+	// how often to check if RPC endpoints are alive
+	RpcHealthCheckIntervalSec uint `yaml:"rpcHealthCheckIntervalSec"`
+	// how many times to retry the call if endpoint failed (each time with the next endpoint)
+	RpcRetryCount uint `yaml:"rpcRetryCount"`
+	// delay before the first retry, is doubled each time
+	RpcRetryBackoffMs uint `yaml:"rpcRetryBackoffMs"`
+	// each RPC call is aborted after N seconds and retried with the next endpoint
+	// (the whole call is also aborted if the client has disconnected or the node is stopping)
+	RpcCallTimeoutSec uint `yaml:"rpcCallTimeoutSec"`
+	// critical reads (owner of the name, expiration, owner of the SCW) are sent to all endpoints
+	// and the result is accepted only if at least N of them returned the same data
+	// 0 or 1 - disabled (first healthy endpoint is used)
+	RpcQuorum uint `yaml:"rpcQuorum"`
+
+	// fees of the admin txs: "eip1559" (default) or "legacy"
+	FeeStrategy string `yaml:"feeStrategy"`
+	// priority fee is this percentile of the priority fees paid in the last blocks (50 by default)
+	PriorityFeePercentile float64 `yaml:"priorityFeePercentile"`
+	// fees are never higher than these caps (0 - no cap)
+	// for legacy txs MaxFeePerGasGwei is the cap of the gas price
+	MaxFeePerGasGwei         float64 `yaml:"maxFeePerGasGwei"`
+	MaxPriorityFeePerGasGwei float64 `yaml:"maxPriorityFeePerGasGwei"`
+	// gas limit is estimated and then increased by N percent
+	GasLimitMarginPercent uint `yaml:"gasLimitMarginPercent"`
+
+	// tx that was not mined in N seconds is replaced by the same tx (with the same nonce)
+	// with fees increased by M percent (at least 10, otherwise nodes reject it), up to K times
+	TxReplaceTimeoutSec  uint `yaml:"txReplaceTimeoutSec"`
+	TxReplaceBumpPercent uint `yaml:"txReplaceBumpPercent"`
+	TxReplaceMaxCount    uint `yaml:"txReplaceMaxCount"`
+}
+
+type Urls []string
+
+// old configs have a single URL
+func (u *Urls) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*u = Urls{value.Value}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*u = list
+	return nil
 }
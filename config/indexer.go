@@ -0,0 +1,17 @@
+package config
+
+type Indexer struct {
+	// if false - do not tail contract logs at all
+	Enabled bool `yaml:"enabled"`
+
+	// first block to scan if nothing was indexed yet
+	// (usually it is the block where contracts were deployed)
+	StartBlock uint64 `yaml:"startBlock"`
+
+	// max number of blocks to request in one eth_getLogs call
+	// (providers usually limit it)
+	BlockBatchSize uint64 `yaml:"blockBatchSize"`
+
+	// how often to check for new blocks
+	PollIntervalSec uint `yaml:"pollIntervalSec"`
+}
@@ -4,4 +4,8 @@ type Mongo struct {
 	Connect    string `yaml:"connect"`
 	Database   string `yaml:"database"`
 	Collection string `yaml:"collection"`
+
+	// if true - do not apply pending schema migrations on startup
+	// (use "-cmd=migrate" to apply them manually)
+	SkipMigrations bool `yaml:"skipMigrations"`
 }
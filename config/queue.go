@@ -14,4 +14,29 @@ type Queue struct {
 	LowNonceRetryCount uint `yaml:"retryCountNonce"`
 
 	HighNonceRetryCount uint `yaml:"retryCountHighNonce"`
+
+	Retry QueueRetry `yaml:"retry"`
+
+	// item is owned by one node while it is processed, so several nodes can share one queue
+	// lease is extended every 1/3 of the timeout, items of the stopped nodes are processed
+	// by other nodes after the timeout, default is 60s
+	LeaseTimeoutSec uint `yaml:"leaseTimeoutSec"`
+}
+
+// failed states of the queue items are retried before the item is moved to the queue-dead collection
+// permanent errors (i.e. tx was reverted by the contract) are never retried
+// nonce errors are retried separately (see retryCountNonce)
+type QueueRetry struct {
+	// attempts of each state (including the first one), default is 3
+	MaxAttempts uint `yaml:"maxAttempts"`
+	// overrides MaxAttempts for some states, i.e. "commitSent: 10"
+	// initial, commitSent, commitDone, registerSent, renewSent
+	MaxAttemptsPerState map[string]uint `yaml:"maxAttemptsPerState"`
+
+	// delay before the second attempt, then it is doubled with every attempt
+	// defaults are 1s and 1m
+	InitialBackoffMs uint `yaml:"initialBackoffMs"`
+	MaxBackoffMs     uint `yaml:"maxBackoffMs"`
+	// random part of the delay, i.e. 0.2 means ±20% (default)
+	Jitter float64 `yaml:"jitter"`
 }
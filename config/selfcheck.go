@@ -0,0 +1,10 @@
+package config
+
+type SelfCheck struct {
+	// do not check contracts and permissions of the admin on start
+	// ("-cmd self-check" can be used to run the check manually)
+	Skip bool `yaml:"skip"`
+
+	// the whole check is aborted after N seconds
+	TimeoutSec uint `yaml:"timeoutSec"`
+}
@@ -0,0 +1,18 @@
+package config
+
+// admin txs and user operations are signed with it
+type Signer struct {
+	// "keystore" (default) or "web3signer"
+	Type string `yaml:"type"`
+
+	// encrypted geth keystore file (type: keystore)
+	KeystorePath string `yaml:"keystorePath"`
+	// passphrase of the keystore is read from this env variable or from this file
+	PassphraseEnv  string `yaml:"passphraseEnv"`
+	PassphraseFile string `yaml:"passphraseFile"`
+
+	// Web3Signer-compatible JSON-RPC API (type: web3signer)
+	// the key of contracts.admin should be loaded into it
+	RemoteUrl        string `yaml:"remoteUrl"`
+	RemoteTimeoutSec uint   `yaml:"remoteTimeoutSec"`
+}
@@ -2,11 +2,12 @@ package contracts
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -19,11 +20,11 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	ac "github.com/anyproto/any-ns-node/anytype_crypto"
 	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/signer"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
 )
 
@@ -66,6 +67,25 @@ type RegisterParams struct {
 	RegisterPeriodMonths uint32
 }
 
+// all reads of one snapshot are done at this block
+// zero value means the latest block
+type BlockRef struct {
+	Number *big.Int
+	// used instead of Number if set: data is read from exactly this block
+	// (call fails if the node does not know it, e.g. it was reorged out)
+	Hash common.Hash
+}
+
+func (b BlockRef) callOpts(ctx context.Context) *bind.CallOpts {
+	opts := &bind.CallOpts{Context: ctx}
+	if b.Hash != (common.Hash{}) {
+		opts.BlockHash = b.Hash
+	} else {
+		opts.BlockNumber = b.Number
+	}
+	return opts
+}
+
 type RenewParams struct {
 	TxOpts      *bind.TransactOpts
 	FullName    string
@@ -76,27 +96,43 @@ type RenewParams struct {
 // TODO: refactor, split into several interfaces
 // Low-level calls to contracts
 type ContractsService interface {
-	CreateEthConnection() (*ethclient.Client, error)
+	// returns long-lived client of the current healthy RPC endpoint (do not close it)
+	// prefer other methods: they are retried with another endpoint if this one fails
+	CreateEthConnection(ctx context.Context) (*ethclient.Client, error)
 
 	// generic method to call any contract
 	CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+	// is read from the node once
+	GetChainID(ctx context.Context) (*big.Int, error)
 
 	// AA methods:
 	IsContractDeployed(ctx context.Context, address common.Address) (bool, error)
 	// will return .owner of the contract
-	GetScwOwner(ctx context.Context, address common.Address) (common.Address, error)
+	GetScwOwner(ctx context.Context, address common.Address, at BlockRef) (common.Address, error)
 
 	// ENS methods
-	GetOwnerForNamehash(ctx context.Context, namehash [32]byte) (common.Address, error)
-	GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string) (ownerEthAddress string, ownerAnyAddress string, spaceId string, expiration *big.Int, err error)
-
+	// all name data is read at the block "at", so it is consistent even if name is changed in the meantime
+	GetOwnerForNamehash(ctx context.Context, namehash [32]byte, at BlockRef) (common.Address, error)
+	GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string, at BlockRef) (ownerEthAddress string, ownerAnyAddress string, spaceId string, expiration *big.Int, err error)
+	// reads everything above for all names at once (using Multicall3 if it is configured)
+	// results are in the same order as names
+	GetNamesInfo(ctx context.Context, fullNames []string, at BlockRef) ([]*NameInfo, error)
+	// returns unixtime when name expires (0 if name was never registered)
+	GetNameExpiration(ctx context.Context, fullName string) (*big.Int, error)
+	// after name is expired, only previous owner can renew it during the grace period (in seconds)
+	GetGracePeriod(ctx context.Context) (*big.Int, error)
+	// name can be registered with the commitment only during this time after it was mined (in seconds)
+	GetMaxCommitmentAge(ctx context.Context) (*big.Int, error)
+
+	// each tx is simulated against the pending block before it is sent
+	// if simulation is reverted -> tx is not sent and RevertError is returned (see ErrNameNotAvailable, etc)
 	Commit(ctx context.Context, params *CommitParams) (*types.Transaction, error)
 	Register(ctx context.Context, params *RegisterParams) (*types.Transaction, error)
 	Renew(ctx context.Context, params *RenewParams) (*types.Transaction, error)
 
 	// Aux methods
-	MakeCommitment(params *MakeCommitmentParams) ([32]byte, error)
-	GetNameByAddress(address common.Address) (string, error)
+	MakeCommitment(ctx context.Context, params *MakeCommitmentParams) ([32]byte, error)
+	GetNameByAddress(ctx context.Context, address common.Address) (string, error)
 	GetBalanceOf(ctx context.Context, tokenAddress common.Address, address common.Address) (*big.Int, error)
 
 	ConnectToRegistryContract() (*ac.ENSRegistry, error)
@@ -105,19 +141,63 @@ type ContractsService interface {
 	ConnectToRegistrar() (*ac.AnytypeRegistrarImplementation, error)
 	ConnectToPrivateController() (*ac.AnytypeRegistrarControllerPrivate, error)
 
-	GenerateAuthOptsForAdmin() (*bind.TransactOpts, error)
-	CalculateTxParams(conn *ethclient.Client, address common.Address) (*big.Int, uint64, error)
+	// tx is sent with ctx of the returned opts
+	// fees are set by FeeStrategy, gas limit is not set (it is estimated when tx is sent)
+	GenerateAuthOptsForAdmin(ctx context.Context) (*bind.TransactOpts, error)
+	CalculateTxParams(ctx context.Context, conn *ethclient.Client, address common.Address) (*big.Int, uint64, error)
 
 	// Check if tx is even started to mine
 	WaitForTxToStartMining(ctx context.Context, txHash common.Hash) error
-	WaitMined(ctx context.Context, tx *types.Transaction) (wasMined bool, err error)
+	// will wait until tx is mined AND has ConfirmationBlocks on top of it
+	// if tx is not mined in TxReplaceTimeoutSec -> it is replaced by the same tx with bumped fees
+	// (onReplace is called with each replacement, can be nil)
+	// sentBefore are hashes of the txs with the same nonce that were replaced by tx earlier,
+	// any of them can be mined instead of tx
+	// returns receipt of the tx that was mined (check its status)
+	WaitMined(ctx context.Context, tx *types.Transaction, sentBefore []common.Hash, onReplace func(replacement *types.Transaction)) (*types.Receipt, error)
 	TxByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, error)
+	TxReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+
+	// Reorg protection
+	// returns hash of the canonical block with this number
+	GetBlockHash(ctx context.Context, blockNumber uint64) (common.Hash, error)
+	// returns true if block has at least ConfirmationBlocks on top of it
+	// returns ErrBlockReorged if block is not in the canonical chain anymore
+	IsBlockConfirmed(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error)
+
+	// Indexer methods
+	GetLatestBlockNumber(ctx context.Context) (uint64, error)
+	// will return full name (like "hello.any") for the namehash
+	// NameWrapper keeps namehash -> DNS-encoded name mapping for all wrapped names
+	// returns empty string if name is unknown
+	GetNameByNamehash(ctx context.Context, namehash [32]byte) (string, error)
 
 	app.Component
 }
 
 type anynsContracts struct {
 	config config.Contracts
+
+	pool    *ethPool
+	backend *poolBackend
+	// critical reads (ownership and expiration) are done with it
+	// backend or quorumCaller if quorum mode is enabled
+	reader contractCaller
+	mc     multicall
+	// fees of the admin txs
+	fees FeeStrategy
+	// signs admin txs (optional, only for the components that send them)
+	signer signer.Signer
+
+	// is read from the node once
+	chainMu sync.Mutex
+	chainID *big.Int
+
+	// how often receipts are checked (1 second by default)
+	pollInterval time.Duration
+
+	cancel context.CancelFunc
+	done   chan bool
 }
 
 func (acontracts *anynsContracts) Name() (name string) {
@@ -126,17 +206,114 @@ func (acontracts *anynsContracts) Name() (name string) {
 
 func (acontracts *anynsContracts) Init(a *app.App) (err error) {
 	acontracts.config = a.MustComponent(config.CName).(*config.Config).GetContracts()
+	acontracts.setRpcDefaults()
+	acontracts.setTxDefaults()
+
+	acontracts.pool = newEthPool(
+		acontracts.config.GethUrl,
+		int(acontracts.config.RpcRetryCount),
+		time.Duration(acontracts.config.RpcRetryBackoffMs)*time.Millisecond,
+	)
+	acontracts.pool.callTimeout = time.Duration(acontracts.config.RpcCallTimeoutSec) * time.Second
+	acontracts.backend = &poolBackend{pool: acontracts.pool}
+	acontracts.reader = acontracts.backend
+
+	acontracts.fees, err = newFeeStrategy(acontracts.config, acontracts.backend)
+	if err != nil {
+		return err
+	}
+
+	if s := a.Component(signer.CName); s != nil {
+		acontracts.signer = s.(signer.Signer)
+	}
+
+	if quorum := int(acontracts.config.RpcQuorum); quorum > 1 {
+		if quorum > len(acontracts.config.GethUrl) {
+			return fmt.Errorf("rpcQuorum (%d) is bigger than the number of RPC endpoints (%d)", quorum, len(acontracts.config.GethUrl))
+		}
+		acontracts.reader = &quorumCaller{
+			pool:    acontracts.pool,
+			quorum:  quorum,
+			metrics: newQuorumMetrics(a),
+		}
+	}
+
+	acontracts.done = make(chan bool)
 	return nil
 }
 
-func (acontracts *anynsContracts) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
-	client, err := ethclient.Dial(acontracts.config.GethUrl)
-	if err != nil {
-		log.Error("failed to dial geth", zap.Error(err))
-		return nil, err
+func (acontracts *anynsContracts) Run(ctx context.Context) (err error) {
+	if q, ok := acontracts.reader.(*quorumCaller); ok {
+		err = q.metrics.register()
+		if err != nil {
+			return err
+		}
+	}
+
+	// do not use ctx here, it is used only during app start
+	var workerCtx context.Context
+	workerCtx, acontracts.cancel = context.WithCancel(context.Background())
+
+	interval := time.Duration(acontracts.config.RpcHealthCheckIntervalSec) * time.Second
+	go acontracts.pool.healthWorker(workerCtx, interval, acontracts.done)
+	return nil
+}
+
+func (acontracts *anynsContracts) Close(ctx context.Context) (err error) {
+	if acontracts.cancel != nil {
+		acontracts.cancel()
+
+		select {
+		case <-acontracts.done:
+		case <-ctx.Done():
+		}
+	}
+
+	acontracts.pool.close()
+	return nil
+}
+
+func (acontracts *anynsContracts) setRpcDefaults() {
+	c := &acontracts.config
+	if c.RpcHealthCheckIntervalSec == 0 {
+		c.RpcHealthCheckIntervalSec = defaultRpcHealthCheckIntervalSec
+	}
+	if c.RpcRetryCount == 0 {
+		c.RpcRetryCount = defaultRpcRetryCount
+	}
+	if c.RpcRetryBackoffMs == 0 {
+		c.RpcRetryBackoffMs = defaultRpcRetryBackoffMs
+	}
+	if c.RpcCallTimeoutSec == 0 {
+		c.RpcCallTimeoutSec = defaultRpcCallTimeoutSec
+	}
+}
+
+func (acontracts *anynsContracts) setTxDefaults() {
+	c := &acontracts.config
+	if c.PriorityFeePercentile == 0 {
+		c.PriorityFeePercentile = defaultPriorityFeePercentile
+	}
+	if c.GasLimitMarginPercent == 0 {
+		c.GasLimitMarginPercent = defaultGasLimitMarginPercent
+	}
+	if c.TxReplaceTimeoutSec == 0 {
+		c.TxReplaceTimeoutSec = defaultTxReplaceTimeoutSec
+	}
+	if c.TxReplaceBumpPercent == 0 {
+		c.TxReplaceBumpPercent = defaultTxReplaceBumpPercent
+	}
+	if c.TxReplaceBumpPercent < minTxReplaceBumpPercent {
+		log.Warn("txReplaceBumpPercent is too small, using the minimum", zap.Uint("bump", c.TxReplaceBumpPercent))
+		c.TxReplaceBumpPercent = minTxReplaceBumpPercent
 	}
+	if c.TxReplaceMaxCount == 0 {
+		c.TxReplaceMaxCount = defaultTxReplaceMaxCount
+	}
+}
 
-	res, err := client.CallContract(ctx, msg, nil)
+func (acontracts *anynsContracts) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	res, err := acontracts.backend.CallContract(ctx, msg, nil)
 	if err != nil {
 		log.Error("failed to CallContract", zap.Error(err))
 		return nil, err
@@ -146,12 +323,6 @@ func (acontracts *anynsContracts) CallContract(ctx context.Context, msg ethereum
 }
 
 func (acontracts *anynsContracts) GetBalanceOf(ctx context.Context, tokenAddress common.Address, address common.Address) (*big.Int, error) {
-	client, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return big.NewInt(0), err
-	}
-
 	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return big.NewInt(0), err
@@ -167,7 +338,7 @@ func (acontracts *anynsContracts) GetBalanceOf(ctx context.Context, tokenAddress
 		Data: input,
 	}
 
-	res, err := client.CallContract(ctx, callMsg, nil)
+	res, err := acontracts.backend.CallContract(ctx, callMsg, nil)
 	if err != nil {
 		log.Error("failed to call balanceOf", zap.Error(err))
 		return big.NewInt(0), err
@@ -179,13 +350,17 @@ func (acontracts *anynsContracts) GetBalanceOf(ctx context.Context, tokenAddress
 }
 
 func (acontracts *anynsContracts) IsContractDeployed(ctx context.Context, address common.Address) (bool, error) {
-	client, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return false, err
-	}
+	return isContractDeployedAt(ctx, acontracts.backend, address, BlockRef{})
+}
 
-	bs, err := client.CodeAt(ctx, address, nil)
+func isContractDeployedAt(ctx context.Context, caller contractCaller, address common.Address, at BlockRef) (bool, error) {
+	var bs []byte
+	var err error
+	if at.Hash != (common.Hash{}) {
+		bs, err = caller.CodeAtHash(ctx, address, at.Hash)
+	} else {
+		bs, err = caller.CodeAt(ctx, address, at.Number)
+	}
 	if err != nil {
 		log.Error("failed to get code", zap.Error(err))
 		return false, err
@@ -199,28 +374,21 @@ func (acontracts *anynsContracts) IsContractDeployed(ctx context.Context, addres
 	return true, nil
 }
 
-func (acontracts *anynsContracts) GetOwnerForNamehash(ctx context.Context, nh [32]byte) (common.Address, error) {
-	reg, err := acontracts.ConnectToRegistryContract()
+func (acontracts *anynsContracts) GetOwnerForNamehash(ctx context.Context, nh [32]byte, at BlockRef) (common.Address, error) {
+	reg, err := ac.NewENSRegistryCaller(common.HexToAddress(acontracts.config.AddrRegistry), acontracts.reader)
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
 		return common.Address{}, err
 	}
 
-	callOpts := bind.CallOpts{}
-	own, err := reg.Owner(&callOpts, nh)
+	own, err := reg.Owner(at.callOpts(ctx), nh)
 
 	return own, err
 }
 
-func (acontracts *anynsContracts) GetScwOwner(ctx context.Context, scwAddress common.Address) (common.Address, error) {
-	client, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return common.Address{}, err
-	}
-
+func (acontracts *anynsContracts) GetScwOwner(ctx context.Context, scwAddress common.Address, at BlockRef) (common.Address, error) {
 	// 1 - check if address is a smart contract
-	isDeployed, err := acontracts.IsContractDeployed(ctx, scwAddress)
+	isDeployed, err := isContractDeployedAt(ctx, acontracts.reader, scwAddress, at)
 	if err != nil {
 		log.Error("failed to check if contract is deployed", zap.Error(err))
 		return common.Address{}, err
@@ -231,15 +399,14 @@ func (acontracts *anynsContracts) GetScwOwner(ctx context.Context, scwAddress co
 		return common.Address{}, errors.New("address is not a smart contract")
 	}
 
-	scw, err := acontracts.ConnectToSCW(client, scwAddress)
+	scw, err := ac.NewSCWCaller(scwAddress, acontracts.reader)
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
 		return common.Address{}, err
 	}
 
 	// 2.2 - call contract's method
-	callOpts := bind.CallOpts{}
-	owner, err := scw.Owner(&callOpts)
+	owner, err := scw.Owner(at.callOpts(ctx))
 	if err != nil {
 		log.Error("failed to get Owner", zap.Error(err))
 		return common.Address{}, err
@@ -248,7 +415,7 @@ func (acontracts *anynsContracts) GetScwOwner(ctx context.Context, scwAddress co
 	return owner, nil
 }
 
-func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string) (ownerEthAddress string, ownerAnyAddress string, spaceId string, expiration *big.Int, err error) {
+func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string, at BlockRef) (ownerEthAddress string, ownerAnyAddress string, spaceId string, expiration *big.Int, err error) {
 	var res nsp.NameAvailableResponse
 	res.Available = false
 
@@ -259,7 +426,7 @@ func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, cur
 	if currentOwner == nwAddressBytes {
 		log.Info("address is owned by NameWrapper contract, ask it to retrieve real owner")
 
-		realOwner, err := acontracts.getRealOwner(fullName)
+		realOwner, err := acontracts.getRealOwner(ctx, fullName, at)
 		if err != nil {
 			log.Warn("failed to get real owner of the name", zap.Error(err))
 			// do not panic, try to continue
@@ -274,7 +441,7 @@ func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, cur
 	}
 
 	// 2 - get content hash and spaceID
-	owner, spaceID, err := acontracts.getAdditionalData(fullName)
+	owner, spaceID, err := acontracts.getAdditionalData(ctx, fullName, at)
 	if err != nil {
 		log.Error("failed to get real additional data of the name", zap.Error(err))
 		return "", "", "", nil, err
@@ -287,7 +454,7 @@ func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, cur
 	}
 
 	// 3 - get expiration date
-	expiration, err = acontracts.getExpirationDate(fullName)
+	expiration, err = acontracts.getExpirationDate(ctx, fullName, at)
 	if err != nil {
 		log.Error("failed to get expiration of the name", zap.Error(err))
 		return "", "", "", nil, err
@@ -296,9 +463,9 @@ func (acontracts *anynsContracts) GetAdditionalNameInfo(ctx context.Context, cur
 	return ownerEthAddress, ownerAnyAddress, spaceId, expiration, nil
 }
 
-func (acontracts *anynsContracts) getRealOwner(fullName string) (*string, error) {
+func (acontracts *anynsContracts) getRealOwner(ctx context.Context, fullName string, at BlockRef) (*string, error) {
 	// 1 - connect to contract
-	nw, err := acontracts.ConnectToNamewrapperContract()
+	nw, err := ac.NewAnytypeNameWrapperCaller(common.HexToAddress(acontracts.config.AddrNameWrapper), acontracts.reader)
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
 		return nil, err
@@ -314,11 +481,9 @@ func (acontracts *anynsContracts) getRealOwner(fullName string) (*string, error)
 	// 3 - call contract's method
 	log.Info("getting real owner for name", zap.String("Full name", fullName))
 
-	callOpts := bind.CallOpts{}
-
 	// convert bytes32 -> uin256 (also 32 bytes long)
 	id := new(big.Int).SetBytes(nh[:])
-	addr, err := nw.OwnerOf(&callOpts, id)
+	addr, err := nw.OwnerOf(at.callOpts(ctx), id)
 	if err != nil {
 		log.Error("failed to convert Owner", zap.Error(err))
 		return nil, err
@@ -332,7 +497,7 @@ func (acontracts *anynsContracts) getRealOwner(fullName string) (*string, error)
 	return &out, nil
 }
 
-func (acontracts *anynsContracts) getAdditionalData(fullName string) (*string, *string, error) {
+func (acontracts *anynsContracts) getAdditionalData(ctx context.Context, fullName string, at BlockRef) (*string, *string, error) {
 	// 1 - connect to contract
 	ar, err := acontracts.ConnectToResolver()
 	if err != nil {
@@ -348,14 +513,14 @@ func (acontracts *anynsContracts) getAdditionalData(fullName string) (*string, *
 	}
 
 	// 3 - get content hash and space ID
-	callOpts := bind.CallOpts{}
-	hash, err := ar.Contenthash(&callOpts, nh)
+	callOpts := at.callOpts(ctx)
+	hash, err := ar.Contenthash(callOpts, nh)
 	if err != nil {
 		log.Error("can not get contenthash", zap.Error(err))
 		return nil, nil, err
 	}
 
-	space, err := ar.SpaceId(&callOpts, nh)
+	space, err := ar.SpaceId(callOpts, nh)
 	if err != nil {
 		log.Error("can not get SpaceID", zap.Error(err))
 		return nil, nil, err
@@ -373,7 +538,11 @@ func (acontracts *anynsContracts) getAdditionalData(fullName string) (*string, *
 	return &ownerAnyAddressOut, &spaceIDOut, nil
 }
 
-func (acontracts *anynsContracts) getExpirationDate(fullName string) (*big.Int, error) {
+func (acontracts *anynsContracts) GetNameExpiration(ctx context.Context, fullName string) (*big.Int, error) {
+	return acontracts.getExpirationDate(ctx, fullName, BlockRef{})
+}
+
+func (acontracts *anynsContracts) GetGracePeriod(ctx context.Context) (*big.Int, error) {
 	// 1 - connect to contract
 	ar, err := acontracts.ConnectToRegistrar()
 	if err != nil {
@@ -381,44 +550,67 @@ func (acontracts *anynsContracts) getExpirationDate(fullName string) (*big.Int,
 		return nil, err
 	}
 
-	// 2 - convert to name hash
-	parts := strings.Split(fullName, ".")
-	if len(parts) != 2 {
-		return nil, errors.New("invalid full name")
+	// 2 - call contract's method
+	callOpts := bind.CallOpts{Context: ctx}
+	out, err := ar.GRACEPERIOD(&callOpts)
+	if err != nil {
+		log.Error("can not get grace period", zap.Error(err))
+		return nil, err
 	}
+	return out, nil
+}
 
-	label := parts[0]
-	labelHash := crypto.Keccak256([]byte(label))
-	// convert nh (32 bytes array) to big.Int
-	nhAsTokenID := new(big.Int).SetBytes(labelHash[:])
+func (acontracts *anynsContracts) GetMaxCommitmentAge(ctx context.Context) (*big.Int, error) {
+	// 1 - connect to contract
+	controller, err := acontracts.ConnectToPrivateController()
+	if err != nil {
+		log.Error("failed to connect to contract", zap.Error(err))
+		return nil, err
+	}
 
-	// 3 - get content hash and space ID
-	callOpts := bind.CallOpts{}
-	out, err := ar.NameExpires(&callOpts, nhAsTokenID)
+	// 2 - call contract's method
+	callOpts := bind.CallOpts{Context: ctx}
+	out, err := controller.MaxCommitmentAge(&callOpts)
 	if err != nil {
-		log.Error("can not get nameexpires", zap.Error(err))
+		log.Error("can not get max commitment age", zap.Error(err))
 		return nil, err
 	}
 	return out, nil
 }
 
-func (acontracts *anynsContracts) CreateEthConnection() (*ethclient.Client, error) {
-	connStr := acontracts.config.GethUrl
-	conn, err := ethclient.Dial(connStr)
-	return conn, err
-}
+func (acontracts *anynsContracts) getExpirationDate(ctx context.Context, fullName string, at BlockRef) (*big.Int, error) {
+	// 1 - connect to contract
+	ar, err := ac.NewAnytypeRegistrarImplementationCaller(common.HexToAddress(acontracts.config.AddrRegistrarImplementation), acontracts.reader)
+	if err != nil {
+		log.Error("failed to connect to contract", zap.Error(err))
+		return nil, err
+	}
 
-func (acontracts *anynsContracts) ConnectToRegistryContract() (*ac.ENSRegistry, error) {
-	conn, err := acontracts.CreateEthConnection()
+	// 2 - convert to name hash
+	nhAsTokenID, err := labelTokenId(fullName)
 	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
 		return nil, err
 	}
 
+	// 3 - get expiration date
+	out, err := ar.NameExpires(at.callOpts(ctx), nhAsTokenID)
+	if err != nil {
+		log.Error("can not get nameexpires", zap.Error(err))
+		return nil, err
+	}
+	return out, nil
+}
+
+func (acontracts *anynsContracts) CreateEthConnection(ctx context.Context) (*ethclient.Client, error) {
+	_, conn, err := acontracts.pool.client(ctx)
+	return conn, err
+}
+
+func (acontracts *anynsContracts) ConnectToRegistryContract() (*ac.ENSRegistry, error) {
 	// 1 - create new contract instance
 	contractRegAddr := acontracts.config.AddrRegistry
 
-	reg, err := ac.NewENSRegistry(common.HexToAddress(contractRegAddr), conn)
+	reg, err := ac.NewENSRegistry(common.HexToAddress(contractRegAddr), acontracts.backend)
 	if err != nil || reg == nil {
 		log.Error("failed to instantiate ENSRegistry contract", zap.Error(err))
 		return nil, err
@@ -428,16 +620,10 @@ func (acontracts *anynsContracts) ConnectToRegistryContract() (*ac.ENSRegistry,
 }
 
 func (acontracts *anynsContracts) ConnectToNamewrapperContract() (*ac.AnytypeNameWrapper, error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return nil, err
-	}
-
 	// 1 - create new contract instance
 	contractAddr := acontracts.config.AddrNameWrapper
 
-	nw, err := ac.NewAnytypeNameWrapper(common.HexToAddress(contractAddr), conn)
+	nw, err := ac.NewAnytypeNameWrapper(common.HexToAddress(contractAddr), acontracts.backend)
 	if err != nil || nw == nil {
 		log.Error("failed to instantiate AnytypeNameWrapper contract", zap.Error(err))
 		return nil, err
@@ -447,16 +633,10 @@ func (acontracts *anynsContracts) ConnectToNamewrapperContract() (*ac.AnytypeNam
 }
 
 func (acontracts *anynsContracts) ConnectToResolver() (*ac.AnytypeResolver, error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return nil, err
-	}
-
 	// 1 - create new contract instance
 	contractAddr := acontracts.config.AddrResolver
 
-	ar, err := ac.NewAnytypeResolver(common.HexToAddress(contractAddr), conn)
+	ar, err := ac.NewAnytypeResolver(common.HexToAddress(contractAddr), acontracts.backend)
 	if err != nil || ar == nil {
 		log.Error("failed to instantiate AnytypeResolver contract", zap.Error(err))
 		return nil, err
@@ -466,16 +646,10 @@ func (acontracts *anynsContracts) ConnectToResolver() (*ac.AnytypeResolver, erro
 }
 
 func (acontracts *anynsContracts) ConnectToRegistrar() (*ac.AnytypeRegistrarImplementation, error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return nil, err
-	}
-
 	// 1 - create new contract instance
 	contractAddr := acontracts.config.AddrRegistrarImplementation
 
-	ar, err := ac.NewAnytypeRegistrarImplementation(common.HexToAddress(contractAddr), conn)
+	ar, err := ac.NewAnytypeRegistrarImplementation(common.HexToAddress(contractAddr), acontracts.backend)
 	if err != nil || ar == nil {
 		log.Error("failed to instantiate AnytypeRegistrar contract", zap.Error(err))
 		return nil, err
@@ -485,16 +659,10 @@ func (acontracts *anynsContracts) ConnectToRegistrar() (*ac.AnytypeRegistrarImpl
 }
 
 func (acontracts *anynsContracts) ConnectToPrivateController() (*ac.AnytypeRegistrarControllerPrivate, error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return nil, err
-	}
-
 	// 1 - create new contract instance
 	contractAddr := acontracts.config.AddrRegistrarPrivateController
 
-	ac, err := ac.NewAnytypeRegistrarControllerPrivate(common.HexToAddress(contractAddr), conn)
+	ac, err := ac.NewAnytypeRegistrarControllerPrivate(common.HexToAddress(contractAddr), acontracts.backend)
 	if err != nil || ac == nil {
 		log.Error("failed to instantiate AnytypeRegistrarControllerPrivate contract", zap.Error(err))
 		return nil, err
@@ -503,7 +671,7 @@ func (acontracts *anynsContracts) ConnectToPrivateController() (*ac.AnytypeRegis
 	return ac, err
 }
 
-func (acontracts *anynsContracts) ConnectToSCW(conn *ethclient.Client, address common.Address) (*ac.SCW, error) {
+func (acontracts *anynsContracts) ConnectToSCW(conn bind.ContractBackend, address common.Address) (*ac.SCW, error) {
 	// 1 - create new contract instance
 	scw, err := ac.NewSCW(address, conn)
 
@@ -515,60 +683,77 @@ func (acontracts *anynsContracts) ConnectToSCW(conn *ethclient.Client, address c
 	return scw, err
 }
 
-func (acontracts *anynsContracts) GenerateAuthOptsForAdmin() (*bind.TransactOpts, error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return nil, err
+func (acontracts *anynsContracts) GenerateAuthOptsForAdmin(ctx context.Context) (*bind.TransactOpts, error) {
+	// 1 - key of the admin is kept by the signer
+	if acontracts.signer == nil {
+		return nil, errors.New("signer component is not registered")
 	}
+	fromAddress := acontracts.signer.Address()
 
-	// 1 - load private key
-	// TODO: move PK to secure place
-	privateKey, err := crypto.HexToECDSA(acontracts.config.AdminPk)
-
+	// 2 - nonce and fees
+	nonce, err := acontracts.backend.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
-		log.Error("can not get admin PK", zap.Error(err))
+		log.Error("can not get nonce", zap.Error(err))
 		return nil, err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Error("error casting public key to ECDSA")
+	fees, err := acontracts.fees.Fees(ctx)
+	if err != nil {
+		log.Error("can not calculate fees", zap.Error(err))
+		return nil, err
 	}
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-	// 2 - get gas costs, etc
-	gasPrice, nonce, err := acontracts.CalculateTxParams(conn, fromAddress)
+	chainID, err := acontracts.GetChainID(ctx)
 	if err != nil {
-		log.Error("can not calculate tx params", zap.Error(err))
+		log.Error("can not get chain ID", zap.Error(err))
 		return nil, err
 	}
 
-	// increase gas price - multiply gasPrice BigInt twice
-	gasPrice.Mul(gasPrice, big.NewInt(2))
-
-	// TODO: change to
-	//bind.NewKeyedTransactorWithChainID()
-	auth := bind.NewKeyedTransactor(privateKey)
+	auth := &bind.TransactOpts{
+		From: fromAddress,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != fromAddress {
+				return nil, bind.ErrNotAuthorized
+			}
+			return acontracts.signer.SignTx(ctx, tx, chainID)
+		},
+	}
 
 	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)     // in wei
-	auth.GasLimit = uint64(500000) // in units
-	auth.GasPrice = gasPrice
+	auth.Value = big.NewInt(0) // in wei
+	// gas limit is estimated when tx is sent (see sendTx)
+	fees.apply(auth)
+	auth.Context = ctx
 
 	return auth, nil
 }
 
-func (acontracts *anynsContracts) CalculateTxParams(conn *ethclient.Client, address common.Address) (*big.Int, uint64, error) {
-	nonce, err := conn.PendingNonceAt(context.Background(), address)
+func (acontracts *anynsContracts) GetChainID(ctx context.Context) (*big.Int, error) {
+	acontracts.chainMu.Lock()
+	defer acontracts.chainMu.Unlock()
+
+	if acontracts.chainID == nil {
+		chainID, err := acontracts.backend.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		acontracts.chainID = chainID
+	}
+	return acontracts.chainID, nil
+}
+
+func (acontracts *anynsContracts) CalculateTxParams(ctx context.Context, conn *ethclient.Client, address common.Address) (*big.Int, uint64, error) {
+	return calculateTxParams(ctx, conn, address)
+}
+
+func calculateTxParams(ctx context.Context, conn bind.ContractTransactor, address common.Address) (*big.Int, uint64, error) {
+	nonce, err := conn.PendingNonceAt(ctx, address)
 	if err != nil {
 		log.Error("can not get nonce", zap.Error(err))
 		return nil, 0, err
 	}
 
-	gasPrice, err := conn.SuggestGasPrice(context.Background())
+	gasPrice, err := conn.SuggestGasPrice(ctx)
 	if err != nil {
 		log.Error("can not get gas price", zap.Error(err))
 		return nil, 0, err
@@ -577,20 +762,6 @@ func (acontracts *anynsContracts) CalculateTxParams(conn *ethclient.Client, addr
 	return gasPrice, nonce, nil
 }
 
-func (acontracts *anynsContracts) checkTransactionReceipt(conn *ethclient.Client, txHash common.Hash) bool {
-	tx, err := conn.TransactionReceipt(context.Background(), txHash)
-	if err != nil {
-		return false
-	}
-
-	// success
-	if tx.Status == 1 {
-		return true
-	}
-
-	return false
-}
-
 func (acontracts *anynsContracts) WaitForTxToStartMining(ctx context.Context, txHash common.Hash) (err error) {
 	// if transaction is not returned by node immediately after it is sent... it is either:
 	// 1. "nonce is too high" error
@@ -608,11 +779,16 @@ func (acontracts *anynsContracts) WaitForTxToStartMining(ctx context.Context, tx
 			return nil
 		}
 
-		if err.Error() == "not found" {
+		// tx can be returned without error if node does not know it
+		if errors.Is(err, ethereum.NotFound) || err == nil {
 			// wait and try again
 			log.Warn("tx is still not found. waiting...", zap.Any("tx hash", txHash), zap.Any("try", i))
 
-			time.Sleep(5 * time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
 			continue
 		}
 		// for any other error -> return it
@@ -623,33 +799,135 @@ func (acontracts *anynsContracts) WaitForTxToStartMining(ctx context.Context, tx
 	return ErrNonceTooHigh
 }
 
-func (acontracts *anynsContracts) WaitMined(ctx context.Context, tx *types.Transaction) (wasMined bool, err error) {
-	conn, err := acontracts.CreateEthConnection()
-	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
-		return false, err
+func (acontracts *anynsContracts) WaitMined(ctx context.Context, tx *types.Transaction, sentBefore []common.Hash, onReplace func(replacement *types.Transaction)) (*types.Receipt, error) {
+	// all txs with this nonce, any of them can be mined
+	sent := append(append([]common.Hash{}, sentBefore...), tx.Hash())
+	// only the last one is replaced if it is stuck
+	last := tx
+	replaceTimeout := time.Duration(acontracts.config.TxReplaceTimeoutSec) * time.Second
+
+	for {
+		// 1 - wait for any of the sent txs
+		var timeout time.Duration
+		if uint(len(sent)) <= acontracts.config.TxReplaceMaxCount {
+			timeout = replaceTimeout
+		}
+
+		receipt, err := acontracts.waitAnyMined(ctx, sent, timeout)
+		if errors.Is(err, errNotMinedInTime) {
+			// 2 - tx is stuck, send it again with higher fees
+			replacement, err := acontracts.replaceTx(ctx, last)
+			if err != nil {
+				// for example, one of the txs was mined (ErrNonceTooLow) or fee cap is reached
+				log.Warn("can not replace stuck tx, waiting for it again", zap.Error(err), zap.String("tx hash", last.Hash().Hex()))
+				continue
+			}
+
+			log.Warn("tx was not mined in time, replaced with higher fees",
+				zap.String("tx hash", last.Hash().Hex()), zap.String("replacement", replacement.Hash().Hex()))
+			sent = append(sent, replacement.Hash())
+			last = replacement
+			if onReplace != nil {
+				onReplace(replacement)
+			}
+			continue
+		}
+		if err != nil {
+			log.Error("failed to wait for tx", zap.Error(err))
+			return nil, err
+		}
+
+		// 3 - wait until block with tx is confirmed
+		err = acontracts.waitConfirmed(ctx, receipt.BlockNumber.Uint64(), receipt.BlockHash)
+		if errors.Is(err, ErrBlockReorged) {
+			// tx is back in the mempool (or will be included into another block)
+			log.Warn("block with tx was reorged out, waiting for tx again",
+				zap.String("tx hash", receipt.TxHash.Hex()), zap.Uint64("block", receipt.BlockNumber.Uint64()))
+			continue
+		}
+		if err != nil {
+			log.Error("failed to wait for tx confirmations", zap.Error(err))
+			return nil, err
+		}
+		return receipt, nil
 	}
+}
 
-	// receipt is not used
-	_, err = bind.WaitMined(ctx, conn, tx)
-	if err != nil {
-		log.Error("failed to wait for tx", zap.Error(err))
-		return false, err
+// returns receipt of the first mined tx
+// returns errNotMinedInTime if none of them was mined during timeout (0 - no timeout)
+func (acontracts *anynsContracts) waitAnyMined(ctx context.Context, txHashes []common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(acontracts.minedPollInterval())
+	defer ticker.Stop()
+
+	for {
+		for _, txHash := range txHashes {
+			receipt, err := acontracts.backend.TransactionReceipt(ctx, txHash)
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				log.Debug("failed to get tx receipt", zap.Error(err), zap.String("tx hash", txHash.Hex()))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, errNotMinedInTime
+		case <-ticker.C:
+		}
 	}
+}
 
-	// please note that transaction receipts are not available for pending transactions
-	wasMined = acontracts.checkTransactionReceipt(conn, tx.Hash())
-	return wasMined, nil
+func (acontracts *anynsContracts) minedPollInterval() time.Duration {
+	if acontracts.pollInterval > 0 {
+		return acontracts.pollInterval
+	}
+	return time.Second
 }
 
-func (acontracts *anynsContracts) TxByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, error) {
-	client, err := acontracts.CreateEthConnection()
+func (acontracts *anynsContracts) waitConfirmed(ctx context.Context, blockNumber uint64, blockHash common.Hash) error {
+	for {
+		confirmed, err := acontracts.IsBlockConfirmed(ctx, blockNumber, blockHash)
+		if err != nil || confirmed {
+			return err
+		}
+
+		log.Debug("waiting for block confirmations", zap.Uint64("block", blockNumber),
+			zap.Uint64("confirmations", acontracts.config.ConfirmationBlocks))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (acontracts *anynsContracts) TxReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := acontracts.backend.TransactionReceipt(ctx, txHash)
 	if err != nil {
-		log.Error("failed to create connection", zap.Error(err))
+		log.Warn("failed to get tx receipt", zap.Error(err))
 		return nil, err
 	}
 
-	tx, _, err := client.TransactionByHash(ctx, txHash)
+	return receipt, nil
+}
+
+func (acontracts *anynsContracts) TxByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, error) {
+	var tx *types.Transaction
+	err := acontracts.pool.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		tx, _, err = client.TransactionByHash(ctx, txHash)
+		return err
+	})
 	if err != nil {
 		// this can happen!
 		log.Warn("failed to get tx", zap.Error(err))
@@ -659,7 +937,7 @@ func (acontracts *anynsContracts) TxByHash(ctx context.Context, txHash common.Ha
 	return tx, nil
 }
 
-func (acontracts *anynsContracts) MakeCommitment(params *MakeCommitmentParams) ([32]byte, error) {
+func (acontracts *anynsContracts) MakeCommitment(ctx context.Context, params *MakeCommitmentParams) ([32]byte, error) {
 	var adminAddr common.Address = common.HexToAddress(acontracts.config.AddrAdmin)
 	var resolverAddr common.Address = common.HexToAddress(acontracts.config.AddrResolver)
 
@@ -672,7 +950,7 @@ func (acontracts *anynsContracts) MakeCommitment(params *MakeCommitmentParams) (
 	}
 
 	var ownerControlledFuses uint16 = 0
-	callOpts := bind.CallOpts{}
+	callOpts := bind.CallOpts{Context: ctx}
 	callOpts.From = adminAddr
 
 	return params.Controller.MakeCommitment(
@@ -687,16 +965,108 @@ func (acontracts *anynsContracts) MakeCommitment(params *MakeCommitmentParams) (
 		ownerControlledFuses)
 }
 
-func (acontracts *anynsContracts) Commit(ctx context.Context, params *CommitParams) (*types.Transaction, error) {
-	tx, err := params.Controller.Commit(params.Opts, params.Commitment)
+// builds and signs tx, simulates it against the pending block and only then sends it
+// revert of the simulation is returned as RevertError, tx is not sent in this case
+// if opts has no GasLimit -> it is estimated and increased by GasLimitMarginPercent
+func (acontracts *anynsContracts) sendTx(ctx context.Context, opts *bind.TransactOpts, build func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	// 1 - build, but do not send
+	buildOpts := *opts
+	buildOpts.NoSend = true
+	if buildOpts.Context == nil {
+		buildOpts.Context = ctx
+	}
+	estimate := buildOpts.GasLimit == 0
+	if estimate {
+		// bind estimates gas without the revert data, so it is done below
+		buildOpts.GasLimit = maxGasLimit
+	}
+
+	tx, err := build(&buildOpts)
 	if err != nil {
-		// TODO - handle the "replacement transaction underpriced" error
-		log.Error("failed to commit", zap.Error(err), zap.Any("tx", tx))
+		return nil, classifyTxError(err)
+	}
+
+	// 2 - simulate
+	msg := ethereum.CallMsg{
+		From:  opts.From,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, err = acontracts.backend.PendingCallContract(ctx, msg)
+	if err != nil {
+		err = DecodeRevert(err)
+		log.Error("tx simulation failed, tx is not sent", zap.Error(err), zap.String("tx hash", tx.Hash().Hex()))
+		return nil, err
+	}
 
-		if err.Error() == "nonce too low" {
-			return tx, ErrNonceTooLow
+	// 3 - estimate gas and sign again
+	if estimate {
+		gas, err := acontracts.backend.EstimateGas(ctx, msg)
+		if err != nil {
+			err = DecodeRevert(err)
+			log.Error("can not estimate gas, tx is not sent", zap.Error(err))
+			return nil, err
 		}
+		gas = gas * uint64(100+acontracts.config.GasLimitMarginPercent) / 100
+
+		tx, err = opts.Signer(opts.From, txWith(tx, gas, feesOf(tx)))
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	// 4 - send
+	err = acontracts.backend.SendTransaction(ctx, tx)
+	if err != nil {
+		return tx, classifyTxError(err)
+	}
+	return tx, nil
+}
+
+// sends the same tx (with the same nonce) with bumped fees
+// only txs of the admin can be replaced
+func (acontracts *anynsContracts) replaceTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	// 1 - current fees and the signer
+	opts, err := acontracts.GenerateAuthOptsForAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	if from != opts.From {
+		return nil, fmt.Errorf("tx is sent by %s, not by the admin", from.Hex())
+	}
+
+	current := &TxFees{GasPrice: opts.GasPrice, GasFeeCap: opts.GasFeeCap, GasTipCap: opts.GasTipCap}
+	fees, err := bumpFees(tx, current, acontracts.config.TxReplaceBumpPercent, newFeeCaps(acontracts.config))
+	if err != nil {
+		return nil, err
+	}
+
+	// 2 - sign and send
+	replacement, err := opts.Signer(opts.From, txWith(tx, tx.Gas(), fees))
+	if err != nil {
+		return nil, err
+	}
+
+	err = acontracts.backend.SendTransaction(ctx, replacement)
+	if err != nil {
+		return nil, classifyTxError(err)
+	}
+	return replacement, nil
+}
+
+func (acontracts *anynsContracts) Commit(ctx context.Context, params *CommitParams) (*types.Transaction, error) {
+	tx, err := acontracts.sendTx(ctx, params.Opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return params.Controller.Commit(opts, params.Commitment)
+	})
+	if err != nil {
+		// TODO - handle the "replacement transaction underpriced" error
+		log.Error("failed to commit", zap.Error(err), zap.Any("tx", tx))
 		return tx, err
 	}
 
@@ -724,24 +1094,20 @@ func (acontracts *anynsContracts) Register(ctx context.Context, params *Register
 
 	var ownerControlledFuses uint16 = 0
 
-	tx, err := params.Controller.Register(
-		params.AuthOpts,
-		params.NameFirstPart,
-		params.RegistrantAccount,
-		&regTime,
-		params.Secret,
-		resolverAddr,
-		callData,
-		params.IsReverseRecord,
-		ownerControlledFuses)
-
+	tx, err := acontracts.sendTx(ctx, params.AuthOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return params.Controller.Register(
+			opts,
+			params.NameFirstPart,
+			params.RegistrantAccount,
+			&regTime,
+			params.Secret,
+			resolverAddr,
+			callData,
+			params.IsReverseRecord,
+			ownerControlledFuses)
+	})
 	if err != nil {
 		log.Error("failed to register", zap.Error(err), zap.Any("tx", tx))
-
-		if err.Error() == "nonce too low" {
-			return tx, ErrNonceTooLow
-		}
-
 		return tx, err
 	}
 
@@ -758,18 +1124,15 @@ func (acontracts *anynsContracts) Register(ctx context.Context, params *Register
 }
 
 func (acontracts *anynsContracts) Renew(ctx context.Context, params *RenewParams) (*types.Transaction, error) {
-	tx, err := params.Controller.Renew(
-		params.TxOpts,
-		params.FullName,
-		big.NewInt(int64(params.DurationSec)),
-	)
-
+	tx, err := acontracts.sendTx(ctx, params.TxOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return params.Controller.Renew(
+			opts,
+			params.FullName,
+			big.NewInt(int64(params.DurationSec)),
+		)
+	})
 	if err != nil {
 		log.Error("failed to renew", zap.Error(err), zap.Any("tx", tx))
-
-		if err.Error() == "nonce too low" {
-			return tx, ErrNonceTooLow
-		}
 		return tx, err
 	}
 
@@ -785,7 +1148,7 @@ func (acontracts *anynsContracts) Renew(ctx context.Context, params *RenewParams
 	return tx, nil
 }
 
-func (acontracts *anynsContracts) GetNameByAddress(address common.Address) (string, error) {
+func (acontracts *anynsContracts) GetNameByAddress(ctx context.Context, address common.Address) (string, error) {
 	// 1 - connect to contract
 	ar, err := acontracts.ConnectToResolver()
 	if err != nil {
@@ -794,11 +1157,7 @@ func (acontracts *anynsContracts) GetNameByAddress(address common.Address) (stri
 	}
 
 	// 2 - convert address to .addr.reverse
-	// remove 0x
-	fullName := strings.ToLower(address.Hex()[2:] + ".addr.reverse")
-
-	// convert to name hash
-	nh, err := NameHash(fullName)
+	nh, err := ReverseNode(address)
 	if err != nil {
 		log.Error("can not convert FullName to namehash", zap.Error(err))
 		return "", err
@@ -809,11 +1168,10 @@ func (acontracts *anynsContracts) GetNameByAddress(address common.Address) (stri
 
 	log.Info("getting name for address",
 		zap.String("Address", address.Hex()),
-		zap.String("FullName", fullName),
 		zap.String("NameHash", nhStr))
 
 	// 3 - call contract's method
-	callOpts := bind.CallOpts{}
+	callOpts := bind.CallOpts{Context: ctx}
 	name, err := ar.Name(&callOpts, nh)
 	if err != nil {
 		log.Error("can not get SpaceID", zap.Error(err))
@@ -823,3 +1181,76 @@ func (acontracts *anynsContracts) GetNameByAddress(address common.Address) (stri
 
 	return name, nil
 }
+
+func (acontracts *anynsContracts) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	var num uint64
+	err := acontracts.pool.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		num, err = client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error("failed to get latest block number", zap.Error(err))
+		return 0, err
+	}
+
+	return num, nil
+}
+
+func (acontracts *anynsContracts) GetNameByNamehash(ctx context.Context, nh [32]byte) (string, error) {
+	// 1 - connect to contract
+	nw, err := acontracts.ConnectToNamewrapperContract()
+	if err != nil {
+		log.Error("failed to connect to contract", zap.Error(err))
+		return "", err
+	}
+
+	// 2 - call contract's method
+	callOpts := bind.CallOpts{Context: ctx}
+	encoded, err := nw.Names(&callOpts, nh)
+	if err != nil {
+		log.Error("can not get name by namehash", zap.Error(err))
+		return "", err
+	}
+
+	// name is not wrapped
+	if len(encoded) == 0 {
+		return "", nil
+	}
+
+	// 3 - convert DNS wire format to string
+	return DecodeDnsName(encoded)
+}
+
+func (acontracts *anynsContracts) GetBlockHash(ctx context.Context, blockNumber uint64) (common.Hash, error) {
+	header, err := acontracts.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		log.Error("failed to get block header", zap.Uint64("block", blockNumber), zap.Error(err))
+		return common.Hash{}, err
+	}
+
+	return header.Hash(), nil
+}
+
+func (acontracts *anynsContracts) IsBlockConfirmed(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error) {
+	// 1 - nothing to wait for
+	if acontracts.config.ConfirmationBlocks == 0 {
+		return true, nil
+	}
+
+	// 2 - is block still in the canonical chain?
+	canonical, err := acontracts.GetBlockHash(ctx, blockNumber)
+	if err != nil {
+		return false, err
+	}
+	if canonical != blockHash {
+		return false, ErrBlockReorged
+	}
+
+	// 3 - does it have enough blocks on top?
+	head, err := acontracts.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return IsConfirmed(blockNumber, head, acontracts.config.ConfirmationBlocks), nil
+}
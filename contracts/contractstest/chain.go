@@ -0,0 +1,312 @@
+// Package contractstest deploys AnyNS contracts to the simulated chain,
+// so code that talks to the real contracts can be tested offline
+package contractstest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+)
+
+// top level domain of all names
+const Tld = "any"
+
+// contract that returns 32 zero bytes for any call:
+// constructor copies the runtime code (PUSH1 0x20 PUSH1 0x00 RETURN) and returns it
+var sinkCode = common.FromHex("0x6005600c60003960056000f3" + "60206000f3")
+
+const (
+	defaultMinCommitmentAge = 1
+	defaultMaxCommitmentAge = 24 * 60 * 60
+	// how often the chain checks for new txs to mine
+	defaultMineInterval = 20 * time.Millisecond
+)
+
+type Options struct {
+	// of the private controller (in seconds)
+	MinCommitmentAge uint64
+	MaxCommitmentAge uint64
+	// blocks are mined only when there are pending txs
+	// if 0 -> blocks are mined only with Commit
+	MineInterval time.Duration
+}
+
+// Chain is a simulated chain with all AnyNS contracts deployed and wired together:
+// ENSRegistry -> registrar of the "any" TLD -> NameWrapper -> private controller (owned by the admin)
+// and resolver that trusts the private controller
+type Chain struct {
+	Backend *simulated.Backend
+	// IPC endpoint of the chain, use it as contracts.gethUrl
+	Url     string
+	ChainID *big.Int
+
+	// deployer and owner of all contracts
+	AdminKey *ecdsa.PrivateKey
+	Admin    common.Address
+
+	Registry          common.Address
+	Registrar         common.Address
+	NameWrapper       common.Address
+	Resolver          common.Address
+	PrivateController common.Address
+
+	// answers all calls with zero, is used instead of the contracts that are not deployed
+	// (previous registry and reverse registrar)
+	Sink common.Address
+
+	// blocks are mined either by the miner or by Commit
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewChain deploys all contracts, chain is closed with t.Cleanup
+func NewChain(t testing.TB, opts Options) *Chain {
+	t.Helper()
+
+	if opts.MaxCommitmentAge == 0 {
+		opts.MinCommitmentAge = defaultMinCommitmentAge
+		opts.MaxCommitmentAge = defaultMaxCommitmentAge
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unix sockets have short paths, t.TempDir can be too long for it
+	dir, err := os.MkdirTemp("", "anyns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	c := &Chain{
+		Url:      filepath.Join(dir, "sim.ipc"),
+		AdminKey: key,
+		Admin:    crypto.PubkeyToAddress(key.PublicKey),
+	}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	c.Backend = simulated.NewBackend(types.GenesisAlloc{c.Admin: {Balance: balance}}, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		nodeConf.IPCPath = c.Url
+	})
+	t.Cleanup(c.Close)
+
+	c.ChainID, err = c.Backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.deploy(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.MineInterval > 0 {
+		c.startMiner(opts.MineInterval)
+	}
+	return c
+}
+
+// MineInterval that is good for most tests
+func DefaultOptions() Options {
+	return Options{MineInterval: defaultMineInterval}
+}
+
+func (c *Chain) Close() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+		c.cancel = nil
+	}
+	if c.Backend != nil {
+		_ = c.Backend.Close()
+		c.Backend = nil
+	}
+}
+
+// mines a new block with all pending txs
+func (c *Chain) Commit() common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Backend.Commit()
+}
+
+// moves time of the next block forward (i.e. to pass the commitment age or the name expiration)
+// new block is mined
+func (c *Chain) AdjustTime(d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// simulated beacon of geth v1.13 adds the duration to the block time as is,
+	// i.e. nanoseconds are treated as seconds
+	return c.Backend.AdjustTime(time.Duration(d / time.Second))
+}
+
+// opts to send txs from the admin with the bindings
+func (c *Chain) AdminOpts() *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(c.AdminKey, c.ChainID)
+	if err != nil {
+		// can not happen, key and chain ID are valid
+		panic(err)
+	}
+	return opts
+}
+
+// namehash of "label.any"
+func NameHash(label string) [32]byte {
+	return subnode(namehash(Tld), label)
+}
+
+func (c *Chain) startMiner(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			pending, err := c.Backend.Client().PendingTransactionCount(ctx)
+			if err == nil && pending > 0 {
+				c.Commit()
+			}
+		}
+	}()
+}
+
+func (c *Chain) deploy(opts Options) error {
+	client := c.Backend.Client()
+	auth := c.AdminOpts()
+
+	// each step is mined right away, so the next one can use its result
+	mined := func(step string, tx *types.Transaction, err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+		c.Commit()
+
+		receipt, err := client.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("%s: tx is reverted", step)
+		}
+		return nil
+	}
+
+	// 1 - sink
+	var err error
+	var tx *types.Transaction
+	c.Sink, tx, _, err = bind.DeployContract(auth, abi.ABI{}, sinkCode, client)
+	if err = mined("deploy sink", tx, err); err != nil {
+		return err
+	}
+
+	// 2 - registry, the admin owns the root node
+	// owner of the unknown node is read from the previous registry
+	var registry *ac.ENSRegistry
+	c.Registry, tx, registry, err = ac.DeployENSRegistry(auth, client, c.Sink)
+	if err = mined("deploy ENSRegistry", tx, err); err != nil {
+		return err
+	}
+
+	// NameWrapper and controller claim their reverse records when they are deployed
+	tx, err = registry.SetSubnodeOwner(auth, [32]byte{}, crypto.Keccak256Hash([]byte("reverse")), c.Admin)
+	if err = mined("set owner of the reverse node", tx, err); err != nil {
+		return err
+	}
+	tx, err = registry.SetSubnodeOwner(auth, namehash("reverse"), crypto.Keccak256Hash([]byte("addr")), c.Sink)
+	if err = mined("set reverse registrar", tx, err); err != nil {
+		return err
+	}
+
+	// 3 - registrar of the TLD
+	var registrar *ac.AnytypeRegistrarImplementation
+	c.Registrar, tx, registrar, err = ac.DeployAnytypeRegistrarImplementation(auth, client, c.Registry, namehash(Tld))
+	if err = mined("deploy registrar", tx, err); err != nil {
+		return err
+	}
+
+	tx, err = registry.SetSubnodeOwner(auth, [32]byte{}, crypto.Keccak256Hash([]byte(Tld)), c.Registrar)
+	if err = mined("set owner of the TLD", tx, err); err != nil {
+		return err
+	}
+
+	// 4 - NameWrapper, it registers names in the registrar
+	var nameWrapper *ac.AnytypeNameWrapper
+	c.NameWrapper, tx, nameWrapper, err = ac.DeployAnytypeNameWrapper(auth, client, c.Registry, c.Registrar, common.Address{})
+	if err = mined("deploy NameWrapper", tx, err); err != nil {
+		return err
+	}
+
+	tx, err = registrar.AddController(auth, c.NameWrapper)
+	if err = mined("add NameWrapper to controllers of the registrar", tx, err); err != nil {
+		return err
+	}
+
+	// 5 - private controller, only the admin can register names with it
+	// reverse registrar is not deployed, so reverse records are not set
+	c.PrivateController, tx, _, err = ac.DeployAnytypeRegistrarControllerPrivate(auth, client, c.Registrar,
+		new(big.Int).SetUint64(opts.MinCommitmentAge), new(big.Int).SetUint64(opts.MaxCommitmentAge),
+		c.Sink, c.NameWrapper, c.Registry)
+	if err = mined("deploy private controller", tx, err); err != nil {
+		return err
+	}
+
+	tx, err = nameWrapper.SetController(auth, c.PrivateController, true)
+	if err = mined("add private controller to controllers of the NameWrapper", tx, err); err != nil {
+		return err
+	}
+
+	// 6 - resolver, private controller sets records of the new names
+	c.Resolver, tx, _, err = ac.DeployAnytypeResolver(auth, client, c.Registry, c.NameWrapper, c.PrivateController, c.PrivateController, c.Sink)
+	if err = mined("deploy resolver", tx, err); err != nil {
+		return err
+	}
+	return nil
+}
+
+func namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = subnode(node, labels[i])
+	}
+	return node
+}
+
+func subnode(node [32]byte, label string) [32]byte {
+	return crypto.Keccak256Hash(node[:], crypto.Keccak256([]byte(label)))
+}
@@ -7,4 +7,17 @@ import (
 var (
 	ErrNonceTooLow  = errors.New("nonce too low")
 	ErrNonceTooHigh = errors.New("nonce too high")
+	ErrBlockReorged = errors.New("block was reorged out")
+	// fees of the stuck tx can not be bumped without exceeding maxFeePerGasGwei
+	ErrFeeCapReached = errors.New("fee cap reached")
+
+	// tx (or its simulation) was reverted by the contract
+	// errors below are returned as RevertError and match ErrReverted too
+	ErrReverted = errors.New("execution reverted")
+
+	ErrCommitmentTooNew          = errors.New("commitment is too new")
+	ErrCommitmentTooOld          = errors.New("commitment is too old")
+	ErrUnexpiredCommitmentExists = errors.New("unexpired commitment exists")
+	ErrNameNotAvailable          = errors.New("name is not available")
+	ErrDurationTooShort          = errors.New("duration is too short")
 )
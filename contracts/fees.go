@@ -0,0 +1,276 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+const (
+	FeeStrategyEip1559 = "eip1559"
+	FeeStrategyLegacy  = "legacy"
+
+	defaultPriorityFeePercentile = 50
+	defaultGasLimitMarginPercent = 20
+	defaultTxReplaceTimeoutSec   = 180
+	defaultTxReplaceBumpPercent  = 12
+	defaultTxReplaceMaxCount     = 5
+
+	// nodes reject replacements with smaller bump ("replacement transaction underpriced")
+	minTxReplaceBumpPercent = 10
+
+	// priority fee is calculated from the fees paid in the last N blocks
+	feeHistoryBlocks = 10
+	// tx is built with it first, real gas limit is estimated after the simulation
+	maxGasLimit = 30_000_000
+)
+
+var errNotMinedInTime = errors.New("tx was not mined in time")
+
+// fees of the tx
+// GasPrice is set for legacy txs, GasFeeCap and GasTipCap - for EIP-1559 txs
+type TxFees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+func feesOf(tx *types.Transaction) *TxFees {
+	if tx.Type() == types.LegacyTxType {
+		return &TxFees{GasPrice: tx.GasPrice()}
+	}
+	return &TxFees{GasFeeCap: tx.GasFeeCap(), GasTipCap: tx.GasTipCap()}
+}
+
+// bind builds legacy or EIP-1559 tx depending on the fees that are set
+func (f *TxFees) apply(opts *bind.TransactOpts) {
+	opts.GasPrice = f.GasPrice
+	opts.GasFeeCap = f.GasFeeCap
+	opts.GasTipCap = f.GasTipCap
+}
+
+// chooses fees of the new admin txs
+type FeeStrategy interface {
+	Fees(ctx context.Context) (*TxFees, error)
+}
+
+type feeBackend interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+func newFeeStrategy(c config.Contracts, backend feeBackend) (FeeStrategy, error) {
+	caps := newFeeCaps(c)
+	switch c.FeeStrategy {
+	case "", FeeStrategyEip1559:
+		return &eip1559Fees{backend: backend, percentile: c.PriorityFeePercentile, caps: caps}, nil
+	case FeeStrategyLegacy:
+		return &legacyFees{backend: backend, caps: caps}, nil
+	}
+	return nil, fmt.Errorf("unknown fee strategy: %s", c.FeeStrategy)
+}
+
+// nil means "no cap"
+type feeCaps struct {
+	maxFee *big.Int
+	maxTip *big.Int
+}
+
+func newFeeCaps(c config.Contracts) feeCaps {
+	return feeCaps{
+		maxFee: gweiToWei(c.MaxFeePerGasGwei),
+		maxTip: gweiToWei(c.MaxPriorityFeePerGasGwei),
+	}
+}
+
+func (c feeCaps) apply(f *TxFees) *TxFees {
+	out := &TxFees{
+		GasPrice:  minBig(f.GasPrice, c.maxFee),
+		GasFeeCap: minBig(f.GasFeeCap, c.maxFee),
+		GasTipCap: minBig(f.GasTipCap, c.maxTip),
+	}
+	// priority fee is a part of the max fee
+	if out.GasFeeCap != nil {
+		out.GasTipCap = minBig(out.GasTipCap, out.GasFeeCap)
+	}
+	return out
+}
+
+// EIP-1559 fees:
+// priority fee is the percentile of the priority fees paid in the last blocks
+// max fee is 2 * base fee of the next block + priority fee, so tx stays valid even after 6 full blocks in a row
+type eip1559Fees struct {
+	backend    feeBackend
+	percentile float64
+	caps       feeCaps
+}
+
+func (f *eip1559Fees) Fees(ctx context.Context) (*TxFees, error) {
+	// 1 - fees of the last blocks
+	history, err := f.backend.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{f.percentile})
+	if err != nil {
+		log.Error("can not get fee history", zap.Error(err))
+		return nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, errors.New("no base fee in fee history, use the legacy fee strategy")
+	}
+
+	// 2 - priority fee
+	tip := medianReward(history.Reward)
+	if tip == nil {
+		// some nodes do not return rewards
+		tip, err = f.backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			log.Error("can not get priority fee", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	// 3 - max fee, the last base fee is the one of the next block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+
+	fees := f.caps.apply(&TxFees{GasFeeCap: feeCap, GasTipCap: tip})
+	if fees.GasFeeCap.Cmp(baseFee) < 0 {
+		log.Warn("base fee is above maxFeePerGasGwei, tx will wait until it goes down",
+			zap.String("base fee", baseFee.String()), zap.String("max fee", fees.GasFeeCap.String()))
+	}
+	return fees, nil
+}
+
+// median of the percentile of all blocks
+// returns nil if there are no rewards
+func medianReward(rewards [][]*big.Int) *big.Int {
+	values := make([]*big.Int, 0, len(rewards))
+	for _, r := range rewards {
+		if len(r) > 0 && r[0] != nil {
+			values = append(values, r[0])
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	return new(big.Int).Set(values[len(values)/2])
+}
+
+// legacy fees: gas price suggested by the node * 2
+type legacyFees struct {
+	backend feeBackend
+	caps    feeCaps
+}
+
+func (f *legacyFees) Fees(ctx context.Context) (*TxFees, error) {
+	gasPrice, err := f.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		log.Error("can not get gas price", zap.Error(err))
+		return nil, err
+	}
+
+	gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(2))
+	return f.caps.apply(&TxFees{GasPrice: gasPrice}), nil
+}
+
+// fees of the replacement of the stuck tx: fees of the tx increased by bumpPercent
+// (or current fees if they are higher)
+// returns ErrFeeCapReached if caps do not allow to increase them enough to be accepted by nodes
+func bumpFees(tx *types.Transaction, current *TxFees, bumpPercent uint, caps feeCaps) (*TxFees, error) {
+	old := feesOf(tx)
+	wanted := &TxFees{
+		GasPrice:  maxBig(increase(old.GasPrice, bumpPercent), current.GasPrice),
+		GasFeeCap: maxBig(increase(old.GasFeeCap, bumpPercent), current.GasFeeCap),
+		GasTipCap: maxBig(increase(old.GasTipCap, bumpPercent), current.GasTipCap),
+	}
+	// replacement has the same type as the stuck tx
+	if old.GasPrice != nil {
+		wanted.GasFeeCap, wanted.GasTipCap = nil, nil
+	} else {
+		wanted.GasPrice = nil
+	}
+
+	bumped := caps.apply(wanted)
+	for _, pair := range [][2]*big.Int{
+		{old.GasPrice, bumped.GasPrice},
+		{old.GasFeeCap, bumped.GasFeeCap},
+		{old.GasTipCap, bumped.GasTipCap},
+	} {
+		if pair[0] != nil && pair[1].Cmp(increase(pair[0], minTxReplaceBumpPercent)) < 0 {
+			return nil, ErrFeeCapReached
+		}
+	}
+	return bumped, nil
+}
+
+// the same tx with another gas limit and fees (unsigned)
+func txWith(tx *types.Transaction, gas uint64, fees *TxFees) *types.Transaction {
+	if fees.GasPrice != nil {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: fees.GasPrice,
+			Gas:      gas,
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    tx.ChainId(),
+		Nonce:      tx.Nonce(),
+		GasTipCap:  fees.GasTipCap,
+		GasFeeCap:  fees.GasFeeCap,
+		Gas:        gas,
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	})
+}
+
+func increase(v *big.Int, percent uint) *big.Int {
+	if v == nil {
+		return nil
+	}
+	out := new(big.Int).Mul(v, big.NewInt(int64(100+percent)))
+	// round up, so small values are increased too
+	out.Add(out, big.NewInt(99))
+	return out.Div(out, big.NewInt(100))
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	if gwei <= 0 {
+		return nil
+	}
+	wei := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei))
+	// round to the nearest wei
+	out, _ := wei.Add(wei, big.NewFloat(0.5)).Int(nil)
+	return out
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a == nil || b == nil || a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a == nil || (b != nil && b.Cmp(a) > 0) {
+		return b
+	}
+	return a
+}
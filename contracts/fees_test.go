@@ -0,0 +1,296 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeebo/assert"
+
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/signer"
+)
+
+type testFeeBackend struct {
+	gasPrice *big.Int
+	tip      *big.Int
+	history  *ethereum.FeeHistory
+}
+
+func (b *testFeeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *testFeeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.tip, nil
+}
+
+func (b *testFeeBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return b.history, nil
+}
+
+func TestFeeStrategy(t *testing.T) {
+	backend := &testFeeBackend{
+		gasPrice: big.NewInt(100),
+		tip:      big.NewInt(7),
+		history: &ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{big.NewInt(3)}, {big.NewInt(1)}, {big.NewInt(2)}},
+			BaseFee: []*big.Int{big.NewInt(50), big.NewInt(60), big.NewInt(70), big.NewInt(80)},
+		},
+	}
+
+	t.Run("eip1559 by default", func(t *testing.T) {
+		strategy, err := newFeeStrategy(config.Contracts{}, backend)
+		assert.NoError(t, err)
+
+		fees, err := strategy.Fees(context.Background())
+		assert.NoError(t, err)
+		assert.Nil(t, fees.GasPrice)
+		// median of the rewards
+		assert.Equal(t, fees.GasTipCap.Int64(), int64(2))
+		// 2 * base fee of the next block + tip
+		assert.Equal(t, fees.GasFeeCap.Int64(), int64(162))
+	})
+
+	t.Run("eip1559 with caps", func(t *testing.T) {
+		strategy, err := newFeeStrategy(config.Contracts{MaxFeePerGasGwei: 0.0000001, MaxPriorityFeePerGasGwei: 0.000000001}, backend)
+		assert.NoError(t, err)
+
+		fees, err := strategy.Fees(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, fees.GasTipCap.Int64(), int64(1))
+		assert.Equal(t, fees.GasFeeCap.Int64(), int64(100))
+	})
+
+	t.Run("eip1559 without rewards", func(t *testing.T) {
+		strategy, err := newFeeStrategy(config.Contracts{}, &testFeeBackend{
+			tip:     big.NewInt(7),
+			history: &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(10)}},
+		})
+		assert.NoError(t, err)
+
+		fees, err := strategy.Fees(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, fees.GasTipCap.Int64(), int64(7))
+		assert.Equal(t, fees.GasFeeCap.Int64(), int64(27))
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		strategy, err := newFeeStrategy(config.Contracts{FeeStrategy: FeeStrategyLegacy}, backend)
+		assert.NoError(t, err)
+
+		fees, err := strategy.Fees(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, fees.GasPrice.Int64(), int64(200))
+		assert.Nil(t, fees.GasFeeCap)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := newFeeStrategy(config.Contracts{FeeStrategy: "cheap"}, backend)
+		assert.Error(t, err)
+	})
+}
+
+func TestBumpFees(t *testing.T) {
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(10)})
+	legacyTx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)})
+
+	t.Run("bump dynamic fees", func(t *testing.T) {
+		fees, err := bumpFees(dynamicTx, &TxFees{GasFeeCap: big.NewInt(50), GasTipCap: big.NewInt(50)}, 12, feeCaps{})
+		assert.NoError(t, err)
+		assert.Equal(t, fees.GasFeeCap.Int64(), int64(112))
+		// current fee is higher than the bumped one
+		assert.Equal(t, fees.GasTipCap.Int64(), int64(50))
+	})
+
+	t.Run("bump legacy tx with dynamic current fees", func(t *testing.T) {
+		fees, err := bumpFees(legacyTx, &TxFees{GasFeeCap: big.NewInt(500), GasTipCap: big.NewInt(5)}, 12, feeCaps{})
+		assert.NoError(t, err)
+		assert.Equal(t, fees.GasPrice.Int64(), int64(112))
+		assert.Nil(t, fees.GasFeeCap)
+		assert.Nil(t, fees.GasTipCap)
+	})
+
+	t.Run("fail if cap is reached", func(t *testing.T) {
+		_, err := bumpFees(dynamicTx, &TxFees{}, 12, feeCaps{maxFee: big.NewInt(105)})
+		assert.True(t, errors.Is(err, ErrFeeCapReached))
+
+		_, err = bumpFees(legacyTx, &TxFees{}, 12, feeCaps{maxFee: big.NewInt(110)})
+		assert.NoError(t, err)
+	})
+}
+
+// mines only the txs that are chosen by mine()
+type testMempool struct {
+	*httptest.Server
+	mu    sync.Mutex
+	sent  []*types.Transaction
+	mined map[common.Hash]bool
+}
+
+func newTestMempool(t *testing.T) *testMempool {
+	pool := &testMempool{mined: map[common.Hash]bool{}}
+	pool.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+
+		var result interface{}
+		switch req.Method {
+		case "eth_chainId", "eth_getTransactionCount":
+			result = "0x1"
+		case "eth_gasPrice":
+			result = "0x64"
+		case "eth_sendRawTransaction":
+			var raw hexutil.Bytes
+			assert.NoError(t, json.Unmarshal(req.Params[0], &raw))
+			tx := new(types.Transaction)
+			assert.NoError(t, tx.UnmarshalBinary(raw))
+			pool.sent = append(pool.sent, tx)
+			result = tx.Hash()
+		case "eth_getTransactionReceipt":
+			var hash common.Hash
+			assert.NoError(t, json.Unmarshal(req.Params[0], &hash))
+			if pool.mined[hash] {
+				result = &types.Receipt{
+					Status:      types.ReceiptStatusSuccessful,
+					TxHash:      hash,
+					BlockNumber: big.NewInt(10),
+					Logs:        []*types.Log{},
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": result})
+	}))
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func (p *testMempool) mine(hash common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mined[hash] = true
+}
+
+func TestAnynsContracts_WaitForTxToStartMining(t *testing.T) {
+	pool := newTestMempool(t)
+	acontracts := newTestContracts(pool.URL, "")
+	acontracts.config.WaitMiningRetryCount = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// unknown tx is not an error, it is waited for
+	err := acontracts.WaitForTxToStartMining(ctx, common.HexToHash("0x01"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestAnynsContracts_WaitMined(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	newContracts := func(url string) *anynsContracts {
+		acontracts := newTestContracts(url, "")
+		acontracts.signer = signer.NewWithKey(key)
+		acontracts.config.FeeStrategy = FeeStrategyLegacy
+		acontracts.config.TxReplaceTimeoutSec = 1
+		acontracts.config.TxReplaceBumpPercent = 12
+		acontracts.config.TxReplaceMaxCount = 1
+		acontracts.fees, err = newFeeStrategy(acontracts.config, acontracts.backend)
+		assert.NoError(t, err)
+		acontracts.pollInterval = 10 * time.Millisecond
+		return acontracts
+	}
+
+	signedTx := func(t *testing.T) *types.Transaction {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{
+			Nonce:    1,
+			GasPrice: big.NewInt(150),
+			Gas:      21000,
+			To:       &testRegistrar,
+			Value:    big.NewInt(0),
+		})
+		assert.NoError(t, err)
+		return tx
+	}
+
+	t.Run("mined without replacement", func(t *testing.T) {
+		pool := newTestMempool(t)
+		acontracts := newContracts(pool.URL)
+		tx := signedTx(t)
+		pool.mine(tx.Hash())
+
+		receipt, err := acontracts.WaitMined(context.Background(), tx, nil, func(*types.Transaction) {
+			t.Fatal("tx should not be replaced")
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, receipt.TxHash, tx.Hash())
+		assert.Equal(t, len(pool.sent), 0)
+	})
+
+	t.Run("replace stuck tx with higher fees", func(t *testing.T) {
+		pool := newTestMempool(t)
+		acontracts := newContracts(pool.URL)
+		tx := signedTx(t)
+
+		var replacements []*types.Transaction
+		receipt, err := acontracts.WaitMined(context.Background(), tx, nil, func(replacement *types.Transaction) {
+			replacements = append(replacements, replacement)
+			pool.mine(replacement.Hash())
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, len(replacements), 1)
+		assert.Equal(t, receipt.TxHash, replacements[0].Hash())
+
+		// same nonce and data, fees are max(150 * 1.12, 100 * 2)
+		replacement := pool.sent[0]
+		assert.Equal(t, replacement.Nonce(), tx.Nonce())
+		assert.Equal(t, replacement.To(), tx.To())
+		assert.Equal(t, replacement.GasPrice().Int64(), int64(200))
+	})
+
+	t.Run("replaced tx is mined", func(t *testing.T) {
+		pool := newTestMempool(t)
+		acontracts := newContracts(pool.URL)
+		tx := signedTx(t)
+		replaced := common.HexToHash("0x01")
+		pool.mine(replaced)
+
+		receipt, err := acontracts.WaitMined(context.Background(), tx, []common.Hash{replaced}, func(*types.Transaction) {
+			t.Fatal("tx should not be replaced")
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, receipt.TxHash, replaced)
+		assert.Equal(t, len(pool.sent), 0)
+	})
+
+	t.Run("give up on replacements after max count", func(t *testing.T) {
+		pool := newTestMempool(t)
+		acontracts := newContracts(pool.URL)
+		tx := signedTx(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+
+		_, err := acontracts.WaitMined(ctx, tx, nil, nil)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, len(pool.sent), 1)
+	})
+}
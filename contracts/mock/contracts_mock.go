@@ -49,9 +49,9 @@ func (m *MockContractsService) EXPECT() *MockContractsServiceMockRecorder {
 }
 
 // CalculateTxParams mocks base method.
-func (m *MockContractsService) CalculateTxParams(conn *ethclient.Client, address common.Address) (*big.Int, uint64, error) {
+func (m *MockContractsService) CalculateTxParams(ctx context.Context, conn *ethclient.Client, address common.Address) (*big.Int, uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CalculateTxParams", conn, address)
+	ret := m.ctrl.Call(m, "CalculateTxParams", ctx, conn, address)
 	ret0, _ := ret[0].(*big.Int)
 	ret1, _ := ret[1].(uint64)
 	ret2, _ := ret[2].(error)
@@ -59,9 +59,9 @@ func (m *MockContractsService) CalculateTxParams(conn *ethclient.Client, address
 }
 
 // CalculateTxParams indicates an expected call of CalculateTxParams.
-func (mr *MockContractsServiceMockRecorder) CalculateTxParams(conn, address any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) CalculateTxParams(ctx, conn, address any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateTxParams", reflect.TypeOf((*MockContractsService)(nil).CalculateTxParams), conn, address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateTxParams", reflect.TypeOf((*MockContractsService)(nil).CalculateTxParams), ctx, conn, address)
 }
 
 // CallContract mocks base method.
@@ -170,39 +170,39 @@ func (mr *MockContractsServiceMockRecorder) ConnectToResolver() *gomock.Call {
 }
 
 // CreateEthConnection mocks base method.
-func (m *MockContractsService) CreateEthConnection() (*ethclient.Client, error) {
+func (m *MockContractsService) CreateEthConnection(ctx context.Context) (*ethclient.Client, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEthConnection")
+	ret := m.ctrl.Call(m, "CreateEthConnection", ctx)
 	ret0, _ := ret[0].(*ethclient.Client)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateEthConnection indicates an expected call of CreateEthConnection.
-func (mr *MockContractsServiceMockRecorder) CreateEthConnection() *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) CreateEthConnection(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEthConnection", reflect.TypeOf((*MockContractsService)(nil).CreateEthConnection))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEthConnection", reflect.TypeOf((*MockContractsService)(nil).CreateEthConnection), ctx)
 }
 
 // GenerateAuthOptsForAdmin mocks base method.
-func (m *MockContractsService) GenerateAuthOptsForAdmin() (*bind.TransactOpts, error) {
+func (m *MockContractsService) GenerateAuthOptsForAdmin(ctx context.Context) (*bind.TransactOpts, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateAuthOptsForAdmin")
+	ret := m.ctrl.Call(m, "GenerateAuthOptsForAdmin", ctx)
 	ret0, _ := ret[0].(*bind.TransactOpts)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateAuthOptsForAdmin indicates an expected call of GenerateAuthOptsForAdmin.
-func (mr *MockContractsServiceMockRecorder) GenerateAuthOptsForAdmin() *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) GenerateAuthOptsForAdmin(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAuthOptsForAdmin", reflect.TypeOf((*MockContractsService)(nil).GenerateAuthOptsForAdmin))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAuthOptsForAdmin", reflect.TypeOf((*MockContractsService)(nil).GenerateAuthOptsForAdmin), ctx)
 }
 
 // GetAdditionalNameInfo mocks base method.
-func (m *MockContractsService) GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string) (string, string, string, *big.Int, error) {
+func (m *MockContractsService) GetAdditionalNameInfo(ctx context.Context, currentOwner common.Address, fullName string, at contracts.BlockRef) (string, string, string, *big.Int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAdditionalNameInfo", ctx, currentOwner, fullName)
+	ret := m.ctrl.Call(m, "GetAdditionalNameInfo", ctx, currentOwner, fullName, at)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(string)
@@ -212,9 +212,9 @@ func (m *MockContractsService) GetAdditionalNameInfo(ctx context.Context, curren
 }
 
 // GetAdditionalNameInfo indicates an expected call of GetAdditionalNameInfo.
-func (mr *MockContractsServiceMockRecorder) GetAdditionalNameInfo(ctx, currentOwner, fullName any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) GetAdditionalNameInfo(ctx, currentOwner, fullName, at any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdditionalNameInfo", reflect.TypeOf((*MockContractsService)(nil).GetAdditionalNameInfo), ctx, currentOwner, fullName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdditionalNameInfo", reflect.TypeOf((*MockContractsService)(nil).GetAdditionalNameInfo), ctx, currentOwner, fullName, at)
 }
 
 // GetBalanceOf mocks base method.
@@ -232,49 +232,169 @@ func (mr *MockContractsServiceMockRecorder) GetBalanceOf(ctx, tokenAddress, addr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceOf", reflect.TypeOf((*MockContractsService)(nil).GetBalanceOf), ctx, tokenAddress, address)
 }
 
+// GetBlockHash mocks base method.
+func (m *MockContractsService) GetBlockHash(ctx context.Context, blockNumber uint64) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockHash", ctx, blockNumber)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHash indicates an expected call of GetBlockHash.
+func (mr *MockContractsServiceMockRecorder) GetBlockHash(ctx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHash", reflect.TypeOf((*MockContractsService)(nil).GetBlockHash), ctx, blockNumber)
+}
+
+// GetChainID mocks base method.
+func (m *MockContractsService) GetChainID(ctx context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChainID", ctx)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChainID indicates an expected call of GetChainID.
+func (mr *MockContractsServiceMockRecorder) GetChainID(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChainID", reflect.TypeOf((*MockContractsService)(nil).GetChainID), ctx)
+}
+
+// GetGracePeriod mocks base method.
+func (m *MockContractsService) GetGracePeriod(ctx context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGracePeriod", ctx)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGracePeriod indicates an expected call of GetGracePeriod.
+func (mr *MockContractsServiceMockRecorder) GetGracePeriod(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGracePeriod", reflect.TypeOf((*MockContractsService)(nil).GetGracePeriod), ctx)
+}
+
+// GetLatestBlockNumber mocks base method.
+func (m *MockContractsService) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestBlockNumber", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestBlockNumber indicates an expected call of GetLatestBlockNumber.
+func (mr *MockContractsServiceMockRecorder) GetLatestBlockNumber(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestBlockNumber", reflect.TypeOf((*MockContractsService)(nil).GetLatestBlockNumber), ctx)
+}
+
+// GetMaxCommitmentAge mocks base method.
+func (m *MockContractsService) GetMaxCommitmentAge(ctx context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxCommitmentAge", ctx)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxCommitmentAge indicates an expected call of GetMaxCommitmentAge.
+func (mr *MockContractsServiceMockRecorder) GetMaxCommitmentAge(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxCommitmentAge", reflect.TypeOf((*MockContractsService)(nil).GetMaxCommitmentAge), ctx)
+}
+
 // GetNameByAddress mocks base method.
-func (m *MockContractsService) GetNameByAddress(address common.Address) (string, error) {
+func (m *MockContractsService) GetNameByAddress(ctx context.Context, address common.Address) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNameByAddress", address)
+	ret := m.ctrl.Call(m, "GetNameByAddress", ctx, address)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNameByAddress indicates an expected call of GetNameByAddress.
-func (mr *MockContractsServiceMockRecorder) GetNameByAddress(address any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) GetNameByAddress(ctx, address any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameByAddress", reflect.TypeOf((*MockContractsService)(nil).GetNameByAddress), address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameByAddress", reflect.TypeOf((*MockContractsService)(nil).GetNameByAddress), ctx, address)
+}
+
+// GetNameByNamehash mocks base method.
+func (m *MockContractsService) GetNameByNamehash(ctx context.Context, namehash [32]byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNameByNamehash", ctx, namehash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNameByNamehash indicates an expected call of GetNameByNamehash.
+func (mr *MockContractsServiceMockRecorder) GetNameByNamehash(ctx, namehash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameByNamehash", reflect.TypeOf((*MockContractsService)(nil).GetNameByNamehash), ctx, namehash)
+}
+
+// GetNameExpiration mocks base method.
+func (m *MockContractsService) GetNameExpiration(ctx context.Context, fullName string) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNameExpiration", ctx, fullName)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNameExpiration indicates an expected call of GetNameExpiration.
+func (mr *MockContractsServiceMockRecorder) GetNameExpiration(ctx, fullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNameExpiration", reflect.TypeOf((*MockContractsService)(nil).GetNameExpiration), ctx, fullName)
+}
+
+// GetNamesInfo mocks base method.
+func (m *MockContractsService) GetNamesInfo(ctx context.Context, fullNames []string, at contracts.BlockRef) ([]*contracts.NameInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNamesInfo", ctx, fullNames, at)
+	ret0, _ := ret[0].([]*contracts.NameInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNamesInfo indicates an expected call of GetNamesInfo.
+func (mr *MockContractsServiceMockRecorder) GetNamesInfo(ctx, fullNames, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamesInfo", reflect.TypeOf((*MockContractsService)(nil).GetNamesInfo), ctx, fullNames, at)
 }
 
 // GetOwnerForNamehash mocks base method.
-func (m *MockContractsService) GetOwnerForNamehash(ctx context.Context, namehash [32]byte) (common.Address, error) {
+func (m *MockContractsService) GetOwnerForNamehash(ctx context.Context, namehash [32]byte, at contracts.BlockRef) (common.Address, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOwnerForNamehash", ctx, namehash)
+	ret := m.ctrl.Call(m, "GetOwnerForNamehash", ctx, namehash, at)
 	ret0, _ := ret[0].(common.Address)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOwnerForNamehash indicates an expected call of GetOwnerForNamehash.
-func (mr *MockContractsServiceMockRecorder) GetOwnerForNamehash(ctx, namehash any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) GetOwnerForNamehash(ctx, namehash, at any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnerForNamehash", reflect.TypeOf((*MockContractsService)(nil).GetOwnerForNamehash), ctx, namehash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnerForNamehash", reflect.TypeOf((*MockContractsService)(nil).GetOwnerForNamehash), ctx, namehash, at)
 }
 
 // GetScwOwner mocks base method.
-func (m *MockContractsService) GetScwOwner(ctx context.Context, address common.Address) (common.Address, error) {
+func (m *MockContractsService) GetScwOwner(ctx context.Context, address common.Address, at contracts.BlockRef) (common.Address, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetScwOwner", ctx, address)
+	ret := m.ctrl.Call(m, "GetScwOwner", ctx, address, at)
 	ret0, _ := ret[0].(common.Address)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetScwOwner indicates an expected call of GetScwOwner.
-func (mr *MockContractsServiceMockRecorder) GetScwOwner(ctx, address any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) GetScwOwner(ctx, address, at any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScwOwner", reflect.TypeOf((*MockContractsService)(nil).GetScwOwner), ctx, address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScwOwner", reflect.TypeOf((*MockContractsService)(nil).GetScwOwner), ctx, address, at)
 }
 
 // Init mocks base method.
@@ -291,6 +411,21 @@ func (mr *MockContractsServiceMockRecorder) Init(a any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockContractsService)(nil).Init), a)
 }
 
+// IsBlockConfirmed mocks base method.
+func (m *MockContractsService) IsBlockConfirmed(ctx context.Context, blockNumber uint64, blockHash common.Hash) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlockConfirmed", ctx, blockNumber, blockHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlockConfirmed indicates an expected call of IsBlockConfirmed.
+func (mr *MockContractsServiceMockRecorder) IsBlockConfirmed(ctx, blockNumber, blockHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlockConfirmed", reflect.TypeOf((*MockContractsService)(nil).IsBlockConfirmed), ctx, blockNumber, blockHash)
+}
+
 // IsContractDeployed mocks base method.
 func (m *MockContractsService) IsContractDeployed(ctx context.Context, address common.Address) (bool, error) {
 	m.ctrl.T.Helper()
@@ -307,18 +442,18 @@ func (mr *MockContractsServiceMockRecorder) IsContractDeployed(ctx, address any)
 }
 
 // MakeCommitment mocks base method.
-func (m *MockContractsService) MakeCommitment(params *contracts.MakeCommitmentParams) ([32]byte, error) {
+func (m *MockContractsService) MakeCommitment(ctx context.Context, params *contracts.MakeCommitmentParams) ([32]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MakeCommitment", params)
+	ret := m.ctrl.Call(m, "MakeCommitment", ctx, params)
 	ret0, _ := ret[0].([32]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // MakeCommitment indicates an expected call of MakeCommitment.
-func (mr *MockContractsServiceMockRecorder) MakeCommitment(params any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) MakeCommitment(ctx, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeCommitment", reflect.TypeOf((*MockContractsService)(nil).MakeCommitment), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeCommitment", reflect.TypeOf((*MockContractsService)(nil).MakeCommitment), ctx, params)
 }
 
 // Name mocks base method.
@@ -380,6 +515,21 @@ func (mr *MockContractsServiceMockRecorder) TxByHash(ctx, txHash any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxByHash", reflect.TypeOf((*MockContractsService)(nil).TxByHash), ctx, txHash)
 }
 
+// TxReceipt mocks base method.
+func (m *MockContractsService) TxReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxReceipt", ctx, txHash)
+	ret0, _ := ret[0].(*types.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxReceipt indicates an expected call of TxReceipt.
+func (mr *MockContractsServiceMockRecorder) TxReceipt(ctx, txHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxReceipt", reflect.TypeOf((*MockContractsService)(nil).TxReceipt), ctx, txHash)
+}
+
 // WaitForTxToStartMining mocks base method.
 func (m *MockContractsService) WaitForTxToStartMining(ctx context.Context, txHash common.Hash) error {
 	m.ctrl.T.Helper()
@@ -395,16 +545,16 @@ func (mr *MockContractsServiceMockRecorder) WaitForTxToStartMining(ctx, txHash a
 }
 
 // WaitMined mocks base method.
-func (m *MockContractsService) WaitMined(ctx context.Context, tx *types.Transaction) (bool, error) {
+func (m *MockContractsService) WaitMined(ctx context.Context, tx *types.Transaction, sentBefore []common.Hash, onReplace func(*types.Transaction)) (*types.Receipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitMined", ctx, tx)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "WaitMined", ctx, tx, sentBefore, onReplace)
+	ret0, _ := ret[0].(*types.Receipt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // WaitMined indicates an expected call of WaitMined.
-func (mr *MockContractsServiceMockRecorder) WaitMined(ctx, tx any) *gomock.Call {
+func (mr *MockContractsServiceMockRecorder) WaitMined(ctx, tx, sentBefore, onReplace any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitMined", reflect.TypeOf((*MockContractsService)(nil).WaitMined), ctx, tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitMined", reflect.TypeOf((*MockContractsService)(nil).WaitMined), ctx, tx, sentBefore, onReplace)
 }
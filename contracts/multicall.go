@@ -0,0 +1,353 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+)
+
+// max number of calls in one aggregate3 call
+// (providers limit gas and size of eth_call)
+const multicallMaxCalls = 500
+
+// calls that are made for every name
+const callsPerName = 5
+
+// https://github.com/mds1/multicall
+const multicall3ABI = `
+[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]
+`
+
+// all data about the name that is stored in the cache
+type NameInfo struct {
+	FullName string
+	// false if name has no owner in the registry
+	Registered bool
+	// address that owns the name (NameWrapper is resolved to the real owner)
+	// usually it is an SCW
+	Owner string
+	// if Owner is an SCW -> this is its owner (EOA)
+	IsScw    bool
+	ScwOwner common.Address
+
+	OwnerAnyAddress string
+	SpaceId         string
+	NameExpires     *big.Int
+}
+
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type call3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type call struct {
+	target common.Address
+	abi    *abi.ABI
+	method string
+	args   []interface{}
+}
+
+type multicall struct {
+	// will not change, so it is checked only once
+	mu       sync.Mutex
+	deployed *bool
+}
+
+var multicallAbi = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(multicall3ABI))
+})
+
+// sends all calls in as few eth_calls as possible
+// results are returned in the same order, failed calls have nil result
+func (acontracts *anynsContracts) aggregate(ctx context.Context, calls []call, at BlockRef) ([][]interface{}, error) {
+	mcAbi, err := multicallAbi()
+	if err != nil {
+		return nil, err
+	}
+	mcAddr := common.HexToAddress(acontracts.config.AddrMulticall)
+
+	out := make([][]interface{}, len(calls))
+	for from := 0; from < len(calls); from += multicallMaxCalls {
+		to := min(from+multicallMaxCalls, len(calls))
+
+		// 1 - encode
+		batch := make([]call3, 0, to-from)
+		for _, c := range calls[from:to] {
+			data, err := c.abi.Pack(c.method, c.args...)
+			if err != nil {
+				return nil, err
+			}
+			batch = append(batch, call3{Target: c.target, AllowFailure: true, CallData: data})
+		}
+
+		input, err := mcAbi.Pack("aggregate3", batch)
+		if err != nil {
+			return nil, err
+		}
+
+		// 2 - call
+		msg := ethereum.CallMsg{To: &mcAddr, Data: input}
+		var res []byte
+		if at.Hash != (common.Hash{}) {
+			res, err = acontracts.reader.CallContractAtHash(ctx, msg, at.Hash)
+		} else {
+			res, err = acontracts.reader.CallContract(ctx, msg, at.Number)
+		}
+		if err != nil {
+			log.Error("failed to call multicall", zap.Error(err))
+			return nil, err
+		}
+
+		// 3 - decode
+		unpacked, err := mcAbi.Unpack("aggregate3", res)
+		if err != nil || len(unpacked) != 1 {
+			log.Error("failed to decode multicall result", zap.Error(err))
+			return nil, errors.New("failed to decode multicall result")
+		}
+		results := *abi.ConvertType(unpacked[0], new([]call3Result)).(*[]call3Result)
+		if len(results) != len(batch) {
+			return nil, errors.New("wrong number of multicall results")
+		}
+
+		for i, r := range results {
+			c := calls[from+i]
+			if !r.Success || len(r.ReturnData) == 0 {
+				continue
+			}
+
+			values, err := c.abi.Unpack(c.method, r.ReturnData)
+			if err != nil {
+				// not a contract we expected (SCW without owner(), etc)
+				continue
+			}
+			out[from+i] = values
+		}
+	}
+
+	return out, nil
+}
+
+// multicall is used only if it is configured and deployed
+func (acontracts *anynsContracts) useMulticall(ctx context.Context) bool {
+	if acontracts.config.AddrMulticall == "" {
+		return false
+	}
+
+	acontracts.mc.mu.Lock()
+	defer acontracts.mc.mu.Unlock()
+
+	if acontracts.mc.deployed == nil {
+		deployed, err := acontracts.IsContractDeployed(ctx, common.HexToAddress(acontracts.config.AddrMulticall))
+		if err != nil {
+			// check next time
+			return false
+		}
+		if !deployed {
+			log.Warn("multicall contract is not deployed, reading names one by one", zap.String("address", acontracts.config.AddrMulticall))
+		}
+		acontracts.mc.deployed = &deployed
+	}
+	return *acontracts.mc.deployed
+}
+
+func (acontracts *anynsContracts) GetNamesInfo(ctx context.Context, fullNames []string, at BlockRef) ([]*NameInfo, error) {
+	if acontracts.useMulticall(ctx) {
+		return acontracts.getNamesInfoMulticall(ctx, fullNames, at)
+	}
+	return acontracts.getNamesInfoLocal(ctx, fullNames, at)
+}
+
+// 2 round trips for any number of names:
+// 1. registry owner, NameWrapper owner, contenthash, space ID and expiration of all names
+// 2. owner() of all name owners (fails or returns nothing if owner is not an SCW)
+func (acontracts *anynsContracts) getNamesInfoMulticall(ctx context.Context, fullNames []string, at BlockRef) ([]*NameInfo, error) {
+	regAbi, err := ac.ENSRegistryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	nwAbi, err := ac.AnytypeNameWrapperMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	resolverAbi, err := ac.AnytypeResolverMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	registrarAbi, err := ac.AnytypeRegistrarImplementationMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	scwAbi, err := ac.SCWMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	registry := common.HexToAddress(acontracts.config.AddrRegistry)
+	nameWrapper := common.HexToAddress(acontracts.config.AddrNameWrapper)
+	resolver := common.HexToAddress(acontracts.config.AddrResolver)
+	registrar := common.HexToAddress(acontracts.config.AddrRegistrarImplementation)
+
+	// 1 - read all name data
+	calls := make([]call, 0, len(fullNames)*callsPerName)
+	for _, fullName := range fullNames {
+		nh, err := NameHash(fullName)
+		if err != nil {
+			log.Error("can not convert FullName to namehash", zap.Error(err))
+			return nil, err
+		}
+		tokenId, err := labelTokenId(fullName)
+		if err != nil {
+			return nil, err
+		}
+
+		calls = append(calls,
+			call{target: registry, abi: regAbi, method: "owner", args: []interface{}{nh}},
+			call{target: nameWrapper, abi: nwAbi, method: "ownerOf", args: []interface{}{new(big.Int).SetBytes(nh[:])}},
+			call{target: resolver, abi: resolverAbi, method: "contenthash", args: []interface{}{nh}},
+			call{target: resolver, abi: resolverAbi, method: "spaceId", args: []interface{}{nh}},
+			call{target: registrar, abi: registrarAbi, method: "nameExpires", args: []interface{}{tokenId}},
+		)
+	}
+
+	res, err := acontracts.aggregate(ctx, calls, at)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*NameInfo, len(fullNames))
+	owners := make(map[common.Address]bool)
+	for i, fullName := range fullNames {
+		r := res[i*callsPerName : (i+1)*callsPerName]
+		info := &NameInfo{FullName: fullName}
+		infos[i] = info
+
+		if r[0] == nil {
+			log.Error("failed to get owner", zap.String("FullName", fullName))
+			return nil, errors.New("failed to get owner")
+		}
+		regOwner := r[0][0].(common.Address)
+		if (regOwner == common.Address{}) {
+			continue
+		}
+		info.Registered = true
+
+		// the owner can be NameWrapper
+		if regOwner == nameWrapper {
+			if r[1] != nil {
+				info.Owner = r[1][0].(common.Address).Hex()
+			} else {
+				log.Warn("failed to get real owner of the name", zap.String("FullName", fullName))
+			}
+		} else {
+			info.Owner = regOwner.Hex()
+		}
+
+		if r[2] == nil || r[3] == nil || r[4] == nil {
+			log.Error("failed to get additional data of the name", zap.String("FullName", fullName))
+			return nil, errors.New("failed to get additional data of the name")
+		}
+		info.OwnerAnyAddress = string(r[2][0].([]byte))
+		info.SpaceId = string(r[3][0].([]byte))
+		info.NameExpires = r[4][0].(*big.Int)
+
+		if info.Owner != "" {
+			owners[common.HexToAddress(info.Owner)] = true
+		}
+	}
+
+	// 2 - check which owners are SCWs
+	ownerList := make([]common.Address, 0, len(owners))
+	calls = calls[:0]
+	for owner := range owners {
+		ownerList = append(ownerList, owner)
+		calls = append(calls, call{target: owner, abi: scwAbi, method: "owner"})
+	}
+
+	res, err = acontracts.aggregate(ctx, calls, at)
+	if err != nil {
+		return nil, err
+	}
+
+	scwOwners := make(map[common.Address]common.Address)
+	for i, owner := range ownerList {
+		if res[i] != nil {
+			scwOwners[owner] = res[i][0].(common.Address)
+		}
+	}
+
+	for _, info := range infos {
+		if !info.Registered || info.Owner == "" {
+			continue
+		}
+		info.ScwOwner, info.IsScw = scwOwners[common.HexToAddress(info.Owner)]
+	}
+
+	return infos, nil
+}
+
+// for chains without multicall: same data, but each value is a separate call
+func (acontracts *anynsContracts) getNamesInfoLocal(ctx context.Context, fullNames []string, at BlockRef) ([]*NameInfo, error) {
+	infos := make([]*NameInfo, 0, len(fullNames))
+
+	for _, fullName := range fullNames {
+		info := &NameInfo{FullName: fullName}
+		infos = append(infos, info)
+
+		nh, err := NameHash(fullName)
+		if err != nil {
+			log.Error("can not convert FullName to namehash", zap.Error(err))
+			return nil, err
+		}
+
+		regOwner, err := acontracts.GetOwnerForNamehash(ctx, nh, at)
+		if err != nil {
+			return nil, err
+		}
+		if (regOwner == common.Address{}) {
+			continue
+		}
+		info.Registered = true
+
+		info.Owner, info.OwnerAnyAddress, info.SpaceId, info.NameExpires, err = acontracts.GetAdditionalNameInfo(ctx, regOwner, fullName, at)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Owner != "" {
+			scwOwner, err := acontracts.GetScwOwner(ctx, common.HexToAddress(info.Owner), at)
+			if err == nil {
+				info.IsScw = true
+				info.ScwOwner = scwOwner
+			}
+		}
+	}
+
+	return infos, nil
+}
+
+// registrar token ID is the hash of the first label
+func labelTokenId(fullName string) (*big.Int, error) {
+	parts := strings.Split(fullName, ".")
+	if len(parts) != 2 {
+		return nil, errors.New("invalid full name")
+	}
+
+	labelHash := crypto.Keccak256([]byte(parts[0]))
+	return new(big.Int).SetBytes(labelHash), nil
+}
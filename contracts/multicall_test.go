@@ -0,0 +1,230 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeebo/assert"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+	"github.com/anyproto/any-ns-node/config"
+)
+
+var (
+	testMulticall   = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	testRegistry    = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	testNameWrapper = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	testResolver    = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	testRegistrar   = common.HexToAddress("0x0000000000000000000000000000000000000004")
+
+	testScw = common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
+	testEoa = common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5")
+)
+
+type testChain struct {
+	*httptest.Server
+	ethCalls atomic.Int32
+	// block parameter of the last eth_call
+	lastBlock atomic.Value
+}
+
+// answers calls of Multicall3 like the real chain with:
+// "scw.any" owned by SCW, "wrapped.any" owned by NameWrapper (real owner is EOA)
+// and all other names not registered
+func newTestChain(t *testing.T) *testChain {
+	mcAbi, err := multicallAbi()
+	assert.NoError(t, err)
+
+	chain := &testChain{}
+	owners := map[[32]byte]common.Address{}
+	for name, owner := range map[string]common.Address{"scw.any": testScw, "wrapped.any": testNameWrapper} {
+		nh, _ := NameHash(name)
+		owners[nh] = owner
+	}
+
+	answer := func(target common.Address, data []byte) (bool, []byte) {
+		var md *bind.MetaData
+		switch target {
+		case testRegistry:
+			md = ac.ENSRegistryMetaData
+		case testNameWrapper:
+			md = ac.AnytypeNameWrapperMetaData
+		case testResolver:
+			md = ac.AnytypeResolverMetaData
+		case testRegistrar:
+			md = ac.AnytypeRegistrarImplementationMetaData
+		case testScw:
+			md = ac.SCWMetaData
+		default:
+			// EOA
+			return true, nil
+		}
+
+		a, _ := md.GetAbi()
+		method, err := a.MethodById(data[:4])
+		assert.NoError(t, err)
+		args, err := method.Inputs.Unpack(data[4:])
+		assert.NoError(t, err)
+
+		var out []byte
+		switch method.Name {
+		case "owner":
+			if target == testScw {
+				out, err = method.Outputs.Pack(testEoa)
+				break
+			}
+			out, err = method.Outputs.Pack(owners[args[0].([32]byte)])
+		case "ownerOf":
+			out, err = method.Outputs.Pack(testEoa)
+		case "contenthash":
+			out, err = method.Outputs.Pack([]byte("anyid"))
+		case "spaceId":
+			out, err = method.Outputs.Pack([]byte("space"))
+		case "nameExpires":
+			out, err = method.Outputs.Pack(big.NewInt(100))
+		}
+		assert.NoError(t, err)
+		return true, out
+	}
+
+	chain.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getCode":
+			result = "0x6000"
+		case "eth_call":
+			chain.ethCalls.Add(1)
+			chain.lastBlock.Store(string(req.Params[1]))
+
+			var msg struct {
+				To    common.Address `json:"to"`
+				Input hexutil.Bytes  `json:"input"`
+				Data  hexutil.Bytes  `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(req.Params[0], &msg))
+			assert.Equal(t, msg.To, testMulticall)
+			input := msg.Input
+			if len(input) == 0 {
+				input = msg.Data
+			}
+
+			args, err := mcAbi.Methods["aggregate3"].Inputs.Unpack(input[4:])
+			assert.NoError(t, err)
+			calls := *abi.ConvertType(args[0], new([]call3)).(*[]call3)
+
+			results := make([]call3Result, 0, len(calls))
+			for _, c := range calls {
+				ok, data := answer(c.Target, c.CallData)
+				results = append(results, call3Result{Success: ok, ReturnData: data})
+			}
+
+			out, err := mcAbi.Methods["aggregate3"].Outputs.Pack(results)
+			assert.NoError(t, err)
+			result = hexutil.Bytes(out)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": result})
+	}))
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+func newTestContracts(url string, multicall string) *anynsContracts {
+	acontracts := &anynsContracts{
+		config: config.Contracts{
+			AddrRegistry:                testRegistry.Hex(),
+			AddrNameWrapper:             testNameWrapper.Hex(),
+			AddrResolver:                testResolver.Hex(),
+			AddrRegistrarImplementation: testRegistrar.Hex(),
+			AddrMulticall:               multicall,
+		},
+		pool: newEthPool([]string{url}, 0, time.Millisecond),
+	}
+	acontracts.backend = &poolBackend{pool: acontracts.pool}
+	acontracts.reader = acontracts.backend
+	return acontracts
+}
+
+func TestAnynsContracts_GetNamesInfo(t *testing.T) {
+	t.Run("read all names with multicall", func(t *testing.T) {
+		chain := newTestChain(t)
+		acontracts := newTestContracts(chain.URL, testMulticall.Hex())
+
+		infos, err := acontracts.GetNamesInfo(context.Background(), []string{"scw.any", "free.any", "wrapped.any"}, BlockRef{})
+		assert.NoError(t, err)
+		assert.Equal(t, len(infos), 3)
+
+		// 1 - owned by SCW
+		assert.Equal(t, infos[0].FullName, "scw.any")
+		assert.True(t, infos[0].Registered)
+		assert.Equal(t, infos[0].Owner, testScw.Hex())
+		assert.True(t, infos[0].IsScw)
+		assert.Equal(t, infos[0].ScwOwner, testEoa)
+		assert.Equal(t, infos[0].OwnerAnyAddress, "anyid")
+		assert.Equal(t, infos[0].SpaceId, "space")
+		assert.Equal(t, infos[0].NameExpires.Int64(), int64(100))
+
+		// 2 - not registered
+		assert.False(t, infos[1].Registered)
+
+		// 3 - real owner is read from NameWrapper, it is not an SCW
+		assert.True(t, infos[2].Registered)
+		assert.Equal(t, infos[2].Owner, testEoa.Hex())
+		assert.False(t, infos[2].IsScw)
+
+		// names and then owners
+		assert.Equal(t, chain.ethCalls.Load(), int32(2))
+		assert.Equal(t, chain.lastBlock.Load(), `"latest"`)
+	})
+
+	t.Run("read all values at the same block", func(t *testing.T) {
+		chain := newTestChain(t)
+		acontracts := newTestContracts(chain.URL, testMulticall.Hex())
+
+		// 1 - by number
+		_, err := acontracts.GetNamesInfo(context.Background(), []string{"scw.any"}, BlockRef{Number: big.NewInt(100)})
+		assert.NoError(t, err)
+		assert.Equal(t, chain.lastBlock.Load(), `"0x64"`)
+
+		// 2 - by hash (preferred)
+		hash := common.HexToHash("0x01")
+		_, err = acontracts.GetNamesInfo(context.Background(), []string{"scw.any"}, BlockRef{Number: big.NewInt(100), Hash: hash})
+		assert.NoError(t, err)
+		assert.True(t, strings.Contains(chain.lastBlock.Load().(string), hash.Hex()))
+	})
+
+	t.Run("fail for invalid name", func(t *testing.T) {
+		chain := newTestChain(t)
+		acontracts := newTestContracts(chain.URL, testMulticall.Hex())
+
+		_, err := acontracts.GetNamesInfo(context.Background(), []string{"sub.name.any"}, BlockRef{})
+		assert.Error(t, err)
+	})
+}
+
+func TestLabelTokenId(t *testing.T) {
+	id, err := labelTokenId("hello.any")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(common.BigToHash(id).Hex(), "0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8"))
+
+	_, err = labelTokenId("hello")
+	assert.Error(t, err)
+}
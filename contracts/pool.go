@@ -0,0 +1,481 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRpcHealthCheckIntervalSec = 30
+	defaultRpcRetryCount             = 3
+	defaultRpcRetryBackoffMs         = 200
+	defaultRpcCallTimeoutSec         = 15
+
+	healthCheckTimeout = 10 * time.Second
+)
+
+var ErrNoEndpoints = errors.New("no RPC endpoints configured")
+
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+	// false after transient error, true again after successful health check
+	healthy bool
+}
+
+// long-lived clients for all RPC endpoints
+// calls are sent to the first healthy endpoint (in the order of preference)
+// and are retried with the next one in case of transient errors
+type ethPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+
+	retryCount int
+	backoff    time.Duration
+	// each attempt is aborted after it (0 - only the caller's deadline is used)
+	// timed out attempt is retried with the next endpoint
+	callTimeout time.Duration
+
+	dial func(ctx context.Context, url string) (*ethclient.Client, error)
+}
+
+func newEthPool(urls []string, retryCount int, backoff time.Duration) *ethPool {
+	p := &ethPool{
+		retryCount: retryCount,
+		backoff:    backoff,
+		dial:       ethclient.DialContext,
+	}
+
+	for _, url := range urls {
+		p.endpoints = append(p.endpoints, &endpoint{url: url, healthy: true})
+	}
+	return p
+}
+
+// returns the client of the first healthy endpoint
+// (or of the first one that can be dialed if all of them are unhealthy)
+func (p *ethPool) client(ctx context.Context) (*endpoint, *ethclient.Client, error) {
+	p.mu.Lock()
+	endpoints := make([]*endpoint, len(p.endpoints))
+	copy(endpoints, p.endpoints)
+	p.mu.Unlock()
+
+	if len(endpoints) == 0 {
+		return nil, nil, ErrNoEndpoints
+	}
+
+	var lastErr error
+	for _, wantHealthy := range []bool{true, false} {
+		for _, ep := range endpoints {
+			p.mu.Lock()
+			healthy := ep.healthy
+			p.mu.Unlock()
+			if healthy != wantHealthy {
+				continue
+			}
+
+			client, err := p.connect(ctx, ep)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return ep, client, nil
+		}
+	}
+	return nil, nil, lastErr
+}
+
+// endpoint is dialed without holding the lock, so slow endpoint does not block others
+func (p *ethPool) connect(ctx context.Context, ep *endpoint) (*ethclient.Client, error) {
+	p.mu.Lock()
+	client := ep.client
+	p.mu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+
+	client, err := p.dial(ctx, ep.url)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		log.Warn("failed to dial RPC endpoint", zap.String("url", redactUrl(ep.url)), zap.Error(err))
+		ep.healthy = false
+		return nil, err
+	}
+
+	// was dialed concurrently
+	if ep.client != nil {
+		client.Close()
+		return ep.client, nil
+	}
+	ep.client = client
+	return client, nil
+}
+
+// endpoint will not be used until it passes the health check
+// client is kept: it could be used by other calls right now
+// (RPC client reconnects by itself, so there is no need to dial again)
+func (p *ethPool) markFailed(ep *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ep.healthy {
+		log.Warn("RPC endpoint failed, switching to the next one", zap.String("url", redactUrl(ep.url)), zap.Error(err))
+	}
+	ep.healthy = false
+}
+
+// calls fn with the client of the healthy endpoint
+// transient errors are retried with backoff (each time with the next healthy endpoint)
+// all other errors (reverts, "not found", etc) are returned immediately
+func (p *ethPool) do(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) (err error) {
+	delay := p.backoff
+
+	for attempt := 0; attempt <= p.retryCount; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		ep, client, dialErr := p.client(ctx)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+
+		err = p.call(ctx, client, fn)
+		if err == nil || !isTransient(ctx, err) {
+			return err
+		}
+		p.markFailed(ep, err)
+	}
+
+	log.Error("RPC call failed after retries", zap.Int("retries", p.retryCount), zap.Error(err))
+	return err
+}
+
+// same as do, but always with this endpoint
+func (p *ethPool) doWith(ctx context.Context, ep *endpoint, fn func(ctx context.Context, client *ethclient.Client) error) (err error) {
+	delay := p.backoff
+
+	for attempt := 0; attempt <= p.retryCount; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		client, dialErr := p.connect(ctx, ep)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+
+		err = p.call(ctx, client, fn)
+		if err == nil || !isTransient(ctx, err) {
+			return err
+		}
+		p.markFailed(ep, err)
+	}
+	return err
+}
+
+// fn gets the context with the per-call timeout
+func (p *ethPool) call(ctx context.Context, client *ethclient.Client, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	if p.callTimeout == 0 {
+		return fn(ctx, client)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, p.callTimeout)
+	defer cancel()
+	return fn(callCtx, client)
+}
+
+// checks all endpoints once
+func (p *ethPool) checkHealth(ctx context.Context) {
+	p.mu.Lock()
+	endpoints := make([]*endpoint, len(p.endpoints))
+	copy(endpoints, p.endpoints)
+	p.mu.Unlock()
+
+	for _, ep := range endpoints {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := p.checkEndpoint(checkCtx, ep)
+		cancel()
+
+		if err != nil {
+			p.markFailed(ep, err)
+			continue
+		}
+
+		p.mu.Lock()
+		if !ep.healthy {
+			log.Info("RPC endpoint is healthy again", zap.String("url", redactUrl(ep.url)))
+		}
+		ep.healthy = true
+		p.mu.Unlock()
+	}
+}
+
+func (p *ethPool) checkEndpoint(ctx context.Context, ep *endpoint) error {
+	client, err := p.connect(ctx, ep)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.BlockNumber(ctx)
+	return err
+}
+
+func (p *ethPool) healthWorker(ctx context.Context, interval time.Duration, done chan bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(done)
+			return
+		case <-ticker.C:
+		}
+
+		p.checkHealth(ctx)
+	}
+}
+
+func (p *ethPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ep := range p.endpoints {
+		if ep.client != nil {
+			ep.client.Close()
+			ep.client = nil
+		}
+	}
+}
+
+// errors that can be fixed by calling again (or calling another endpoint)
+func isTransient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		// caller does not wait anymore
+		return false
+	}
+
+	// 1 - not an RPC problem
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	// 2 - HTTP errors: rate limits and server errors
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	// 3 - network errors
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// 4 - JSON-RPC errors (reverts have their own codes)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		// limit exceeded, internal error
+		case -32005, -32603:
+			return true
+		}
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "too many requests")
+}
+
+// API keys are usually part of the URL, do not log them
+func redactUrl(url string) string {
+	if i := strings.LastIndex(url, "/"); i > strings.Index(url, "://")+2 && i < len(url)-1 {
+		return url[:i+1] + "***"
+	}
+	return url
+}
+
+// bind.ContractBackend and bind.DeployBackend on top of the pool
+// so contract bindings survive endpoint failures too
+type poolBackend struct {
+	pool *ethPool
+}
+
+func (b *poolBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		code, err = c.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (b *poolBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (res []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		res, err = c.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+// bind.BlockHashContractCaller: calls that are pinned to the block hash (EIP-1898)
+func (b *poolBackend) CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) (code []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		code, err = c.CodeAtHash(ctx, contract, blockHash)
+		return err
+	})
+	return code, err
+}
+
+func (b *poolBackend) CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) (res []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		res, err = c.CallContractAtHash(ctx, call, blockHash)
+		return err
+	})
+	return res, err
+}
+
+// eth_call against the pending block (used to simulate tx before it is sent)
+func (b *poolBackend) PendingCallContract(ctx context.Context, call ethereum.CallMsg) (res []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		res, err = c.PendingCallContract(ctx, call)
+		return err
+	})
+	return res, err
+}
+
+func (b *poolBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (b *poolBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		code, err = c.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (b *poolBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		nonce, err = c.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (b *poolBackend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		price, err = c.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (b *poolBackend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		tip, err = c.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (b *poolBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		gas, err = c.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// signed tx can be safely sent again: it has the same hash and nonce
+func (b *poolBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) error {
+		err := c.SendTransaction(ctx, tx)
+		if err != nil && strings.Contains(strings.ToLower(err.Error()), "already known") {
+			// previous attempt reached the node
+			return nil
+		}
+		return err
+	})
+}
+
+func (b *poolBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		logs, err = c.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+// subscriptions are bound to the connection, so they are not retried
+func (b *poolBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	_, client, err := b.pool.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (b *poolBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		receipt, err = c.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (b *poolBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (history *ethereum.FeeHistory, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		history, err = c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return history, err
+}
+
+func (b *poolBackend) ChainID(ctx context.Context) (id *big.Int, err error) {
+	err = b.pool.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+		id, err = c.ChainID(ctx)
+		return err
+	})
+	return id, err
+}
@@ -0,0 +1,251 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zeebo/assert"
+)
+
+type testRpcServer struct {
+	*httptest.Server
+	calls atomic.Int32
+	// 0 - answer eth_blockNumber, otherwise respond with this HTTP status
+	status atomic.Int32
+	// respond with JSON-RPC error
+	revert atomic.Bool
+	// respond after this delay (in ms)
+	delayMs atomic.Int64
+	// stops waiting for the delay
+	release chan struct{}
+}
+
+func newTestRpcServer(t *testing.T) *testRpcServer {
+	s := &testRpcServer{release: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.calls.Add(1)
+
+		if delay := s.delayMs.Load(); delay != 0 {
+			select {
+			case <-time.After(time.Duration(delay) * time.Millisecond):
+			case <-s.release:
+				return
+			}
+		}
+
+		if status := s.status.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+
+		var req struct {
+			Id json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+		if s.revert.Load() {
+			res["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+		} else {
+			res["result"] = "0x10"
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(s.Close)
+	// cleanups are called in reverse order: release handlers first
+	t.Cleanup(func() { close(s.release) })
+	return s
+}
+
+func blockNumber(p *ethPool) (num uint64, err error) {
+	err = p.do(context.Background(), func(ctx context.Context, c *ethclient.Client) (err error) {
+		num, err = c.BlockNumber(ctx)
+		return err
+	})
+	return num, err
+}
+
+func TestEthPool_Failover(t *testing.T) {
+	t.Run("switch to the next endpoint if the first one is down", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		second := newTestRpcServer(t)
+		first.status.Store(http.StatusServiceUnavailable)
+
+		p := newEthPool([]string{first.URL, second.URL}, 3, time.Millisecond)
+
+		num, err := blockNumber(p)
+		assert.NoError(t, err)
+		assert.Equal(t, num, uint64(16))
+		assert.Equal(t, first.calls.Load(), int32(1))
+
+		// first endpoint is not used until it is healthy again
+		_, err = blockNumber(p)
+		assert.NoError(t, err)
+		assert.Equal(t, first.calls.Load(), int32(1))
+		assert.Equal(t, second.calls.Load(), int32(2))
+
+		// health check brings it back
+		first.status.Store(0)
+		p.checkHealth(context.Background())
+
+		_, err = blockNumber(p)
+		assert.NoError(t, err)
+		assert.Equal(t, first.calls.Load(), int32(3))
+	})
+
+	t.Run("do not retry non-transient errors", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		second := newTestRpcServer(t)
+		first.revert.Store(true)
+
+		p := newEthPool([]string{first.URL, second.URL}, 3, time.Millisecond)
+
+		_, err := blockNumber(p)
+		assert.Error(t, err)
+		assert.Equal(t, first.calls.Load(), int32(1))
+		assert.Equal(t, second.calls.Load(), int32(0))
+	})
+
+	t.Run("give up after all retries", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		first.status.Store(http.StatusTooManyRequests)
+
+		p := newEthPool([]string{first.URL}, 2, time.Millisecond)
+
+		_, err := blockNumber(p)
+		assert.Error(t, err)
+		assert.Equal(t, first.calls.Load(), int32(3))
+	})
+
+	t.Run("fail if there are no endpoints", func(t *testing.T) {
+		p := newEthPool(nil, 2, time.Millisecond)
+
+		_, err := blockNumber(p)
+		assert.True(t, errors.Is(err, ErrNoEndpoints))
+	})
+}
+
+func TestEthPool_Clients(t *testing.T) {
+	t.Run("do not close client of the failed endpoint", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		p := newEthPool([]string{first.URL}, 0, time.Millisecond)
+		defer p.close()
+
+		ep, client, err := p.client(context.Background())
+		assert.NoError(t, err)
+
+		// other call has failed, but this one still uses the client
+		p.markFailed(ep, errors.New("failed"))
+
+		_, err = client.BlockNumber(context.Background())
+		assert.NoError(t, err)
+
+		// same client is used after the health check
+		p.checkHealth(context.Background())
+		_, again, err := p.client(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, again == client)
+	})
+
+	t.Run("dial without holding the lock", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		p := newEthPool([]string{first.URL}, 0, time.Millisecond)
+		defer p.close()
+
+		dialing := make(chan struct{})
+		release := make(chan struct{})
+		p.dial = func(ctx context.Context, url string) (*ethclient.Client, error) {
+			close(dialing)
+			<-release
+			return ethclient.DialContext(ctx, url)
+		}
+
+		done := make(chan error)
+		go func() {
+			_, err := blockNumber(p)
+			done <- err
+		}()
+		<-dialing
+
+		// pool is not blocked by the slow dial
+		marked := make(chan struct{})
+		go func() {
+			p.markFailed(p.endpoints[0], errors.New("failed"))
+			close(marked)
+		}()
+		select {
+		case <-marked:
+		case <-time.After(time.Second):
+			t.Fatal("pool is locked while dialing")
+		}
+
+		close(release)
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestEthPool_Timeouts(t *testing.T) {
+	t.Run("switch to the next endpoint if the call timed out", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		second := newTestRpcServer(t)
+		first.delayMs.Store(time.Minute.Milliseconds())
+
+		p := newEthPool([]string{first.URL, second.URL}, 3, time.Millisecond)
+		p.callTimeout = 50 * time.Millisecond
+
+		num, err := blockNumber(p)
+		assert.NoError(t, err)
+		assert.Equal(t, num, uint64(16))
+		assert.Equal(t, second.calls.Load(), int32(1))
+	})
+
+	t.Run("do not retry if caller has cancelled the call", func(t *testing.T) {
+		first := newTestRpcServer(t)
+		second := newTestRpcServer(t)
+		first.delayMs.Store(time.Minute.Milliseconds())
+
+		p := newEthPool([]string{first.URL, second.URL}, 3, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := p.do(ctx, func(ctx context.Context, c *ethclient.Client) (err error) {
+			_, err = c.BlockNumber(ctx)
+			return err
+		})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, second.calls.Load(), int32(0))
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	ctx := context.Background()
+
+	assert.True(t, isTransient(ctx, rpc.HTTPError{StatusCode: 503}))
+	assert.True(t, isTransient(ctx, rpc.HTTPError{StatusCode: 429}))
+	assert.True(t, isTransient(ctx, errors.New("dial tcp: connection refused")))
+
+	assert.False(t, isTransient(ctx, rpc.HTTPError{StatusCode: 401}))
+	assert.False(t, isTransient(ctx, ethereum.NotFound))
+	assert.False(t, isTransient(ctx, errors.New("execution reverted")))
+
+	// caller does not wait anymore
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isTransient(cancelled, rpc.HTTPError{StatusCode: 503}))
+}
+
+func TestRedactUrl(t *testing.T) {
+	assert.Equal(t, redactUrl("https://sepolia.infura.io/v3/secret"), "https://sepolia.infura.io/v3/***")
+	assert.Equal(t, redactUrl("http://localhost:8545"), "http://localhost:8545")
+	assert.Equal(t, redactUrl("http://localhost:8545/"), "http://localhost:8545/")
+}
@@ -0,0 +1,208 @@
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var ErrNoQuorum = errors.New("RPC endpoints did not agree on the result")
+
+// reads that are pinned to the block (see BlockRef)
+type contractCaller interface {
+	bind.ContractCaller
+	bind.BlockHashContractCaller
+}
+
+type quorumMetrics struct {
+	// label is the name of the RPC method
+	disagreements *prometheus.CounterVec
+	failures      *prometheus.CounterVec
+
+	// nil if metric component is not used
+	metric metric.Metric
+}
+
+func newQuorumMetrics(a *app.App) *quorumMetrics {
+	m := &quorumMetrics{
+		disagreements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "rpc",
+			Name:      "quorum_disagreements_total",
+			Help:      "Number of quorum reads where RPC endpoints returned different results",
+		}, []string{"method"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "anyns",
+			Subsystem: "rpc",
+			Name:      "quorum_failures_total",
+			Help:      "Number of quorum reads that failed because there was no quorum",
+		}, []string{"method"}),
+	}
+
+	// metric component is optional
+	if mc := a.Component(metric.CName); mc != nil {
+		m.metric = mc.(metric.Metric)
+	}
+	return m
+}
+
+// metric component could be registered after contracts (and be not initialized yet in Init)
+// so it should be called in Run
+func (m *quorumMetrics) register() error {
+	if m.metric == nil {
+		return nil
+	}
+	reg := m.metric.Registry()
+	if err := reg.Register(m.disagreements); err != nil {
+		return err
+	}
+	return reg.Register(m.failures)
+}
+
+// sends every call to all endpoints of the pool
+// result is accepted only if at least "quorum" endpoints returned exactly the same bytes
+// otherwise ErrNoQuorum is returned (fail closed)
+type quorumCaller struct {
+	pool    *ethPool
+	quorum  int
+	metrics *quorumMetrics
+}
+
+type quorumVote struct {
+	url string
+	res []byte
+	err error
+}
+
+func (q *quorumCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return q.call(ctx, "eth_getCode", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (q *quorumCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return q.call(ctx, "eth_call", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (q *quorumCaller) CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) ([]byte, error) {
+	return q.call(ctx, "eth_getCode", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CodeAtHash(ctx, contract, blockHash)
+	})
+}
+
+func (q *quorumCaller) CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	return q.call(ctx, "eth_call", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CallContractAtHash(ctx, call, blockHash)
+	})
+}
+
+func (q *quorumCaller) call(ctx context.Context, method string, fn func(ctx context.Context, c *ethclient.Client) ([]byte, error)) ([]byte, error) {
+	q.pool.mu.Lock()
+	endpoints := make([]*endpoint, len(q.pool.endpoints))
+	copy(endpoints, q.pool.endpoints)
+	q.pool.mu.Unlock()
+
+	if len(endpoints) < q.quorum {
+		return nil, ErrNoQuorum
+	}
+
+	// 1 - ask all endpoints at once
+	votes := make(chan quorumVote, len(endpoints))
+	for _, ep := range endpoints {
+		go func(ep *endpoint) {
+			var res []byte
+			err := q.pool.doWith(ctx, ep, func(ctx context.Context, c *ethclient.Client) (err error) {
+				res, err = fn(ctx, c)
+				return err
+			})
+			votes <- quorumVote{url: ep.url, res: res, err: err}
+		}(ep)
+	}
+
+	// 2 - count the same results until one of them has enough votes
+	var received []quorumVote
+	var agreed []byte
+	found := false
+	for range endpoints {
+		v := <-votes
+		received = append(received, v)
+		if v.err != nil {
+			continue
+		}
+
+		count := 0
+		for _, r := range received {
+			if r.err == nil && bytes.Equal(r.res, v.res) {
+				count++
+			}
+		}
+		if count >= q.quorum {
+			agreed = v.res
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		q.reportDisagreement(method, received, nil, false)
+		q.metrics.failures.WithLabelValues(method).Inc()
+		log.Error("no quorum for RPC call", zap.String("method", method), zap.Int("quorum", q.quorum))
+		return nil, ErrNoQuorum
+	}
+
+	// 3 - do not wait for the rest, but check that they agree too
+	pending := len(endpoints) - len(received)
+	go func() {
+		rest := received
+		for i := 0; i < pending; i++ {
+			rest = append(rest, <-votes)
+		}
+		q.reportDisagreement(method, rest, agreed, true)
+	}()
+
+	return agreed, nil
+}
+
+// logs endpoints that failed or returned something else
+// (than the agreed result or than each other if there was no quorum)
+func (q *quorumCaller) reportDisagreement(method string, votes []quorumVote, agreed []byte, hasAgreed bool) {
+	disagreed := false
+	for _, v := range votes {
+		if v.err != nil {
+			// caller does not wait anymore
+			if errors.Is(v.err, context.Canceled) {
+				continue
+			}
+			log.Warn("RPC endpoint failed during quorum read", zap.String("method", method),
+				zap.String("url", redactUrl(v.url)), zap.Error(v.err))
+			continue
+		}
+
+		if !hasAgreed {
+			// first successful result is the one to compare with
+			agreed, hasAgreed = v.res, true
+			continue
+		}
+		if !bytes.Equal(v.res, agreed) {
+			log.Warn("RPC endpoint returned different result", zap.String("method", method),
+				zap.String("url", redactUrl(v.url)))
+			disagreed = true
+		}
+	}
+
+	if disagreed {
+		q.metrics.disagreements.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,115 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zeebo/assert"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+// answers every eth_call with the same result
+func newTestCallServer(t *testing.T, result string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestQuorum(quorum int, urls ...string) *quorumCaller {
+	return &quorumCaller{
+		pool:    newEthPool(urls, 0, time.Millisecond),
+		quorum:  quorum,
+		metrics: newQuorumMetrics(new(app.App)),
+	}
+}
+
+func TestQuorumCaller(t *testing.T) {
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+	msg := ethereum.CallMsg{To: &to}
+
+	t.Run("accept result if enough endpoints agree", func(t *testing.T) {
+		q := newTestQuorum(2,
+			newTestCallServer(t, "0x01").URL,
+			newTestCallServer(t, "0x02").URL,
+			newTestCallServer(t, "0x01").URL,
+		)
+
+		res, err := q.CallContract(ctx, msg, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, res, []byte{1})
+	})
+
+	t.Run("fail closed if there is no quorum", func(t *testing.T) {
+		q := newTestQuorum(2,
+			newTestCallServer(t, "0x01").URL,
+			newTestCallServer(t, "0x02").URL,
+		)
+
+		_, err := q.CallContract(ctx, msg, nil)
+		assert.True(t, errors.Is(err, ErrNoQuorum))
+		assert.Equal(t, testutil.ToFloat64(q.metrics.failures.WithLabelValues("eth_call")), float64(1))
+		assert.Equal(t, testutil.ToFloat64(q.metrics.disagreements.WithLabelValues("eth_call")), float64(1))
+	})
+
+	t.Run("failed endpoints do not count", func(t *testing.T) {
+		down := newTestRpcServer(t)
+		down.status.Store(http.StatusServiceUnavailable)
+
+		q := newTestQuorum(2, newTestCallServer(t, "0x01").URL, down.URL)
+
+		_, err := q.CallContract(ctx, msg, nil)
+		assert.True(t, errors.Is(err, ErrNoQuorum))
+		assert.Equal(t, testutil.ToFloat64(q.metrics.disagreements.WithLabelValues("eth_call")), float64(0))
+	})
+}
+
+func TestQuorumMetrics(t *testing.T) {
+	t.Run("register when metric component goes after contracts", func(t *testing.T) {
+		ctx := context.Background()
+
+		conf := &config.Config{}
+		conf.Contracts.GethUrl = config.Urls{
+			newTestCallServer(t, "0x01").URL,
+			newTestCallServer(t, "0x02").URL,
+		}
+		conf.Contracts.RpcQuorum = 2
+
+		// same order as in BootstrapServer
+		a := new(app.App)
+		mc := metric.New()
+		a.Register(conf).
+			Register(New()).
+			Register(mc)
+
+		assert.NoError(t, a.Start(ctx))
+		defer a.Close(ctx)
+
+		// no quorum -> counters are exported
+		c := a.MustComponent(CName).(*anynsContracts)
+		to := common.HexToAddress("0x01")
+		_, err := c.reader.CallContract(ctx, ethereum.CallMsg{To: &to}, nil)
+		assert.True(t, errors.Is(err, ErrNoQuorum))
+
+		count, err := testutil.GatherAndCount(mc.Registry(), "anyns_rpc_quorum_failures_total", "anyns_rpc_quorum_disagreements_total")
+		assert.NoError(t, err)
+		assert.Equal(t, count, 2)
+	})
+}
@@ -0,0 +1,185 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+)
+
+// custom errors of the contracts that we can handle
+// all other custom errors are returned as ErrReverted
+var customErrors = map[string]error{
+	"CommitmentTooNew":          ErrCommitmentTooNew,
+	"CommitmentTooOld":          ErrCommitmentTooOld,
+	"UnexpiredCommitmentExists": ErrUnexpiredCommitmentExists,
+	"NameNotAvailable":          ErrNameNotAvailable,
+	"DurationTooShort":          ErrDurationTooShort,
+}
+
+// error of the contract decoded from the revert data
+type RevertError struct {
+	// name of the custom error (like "NameNotAvailable")
+	// empty for require() and assert() reverts
+	Name string
+	Args []interface{}
+	// require() message or custom error with its arguments
+	Reason string
+	// raw revert data
+	Data []byte
+
+	err error
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + ": " + e.Reason
+}
+
+func (e *RevertError) Unwrap() []error {
+	if e.err == ErrReverted {
+		return []error{ErrReverted}
+	}
+	return []error{e.err, ErrReverted}
+}
+
+// returns the name of the custom error if err was reverted with it
+func RevertName(err error) string {
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return revertErr.Name
+	}
+	return ""
+}
+
+var (
+	errorsOnce sync.Once
+	// custom errors of all our contracts by selector
+	errorsById map[[4]byte]abi.Error
+)
+
+func contractErrors() map[[4]byte]abi.Error {
+	errorsOnce.Do(func() {
+		errorsById = make(map[[4]byte]abi.Error)
+
+		for _, md := range []*bind.MetaData{
+			ac.ENSRegistryMetaData,
+			ac.AnytypeNameWrapperMetaData,
+			ac.AnytypeResolverMetaData,
+			ac.AnytypeRegistrarImplementationMetaData,
+			ac.AnytypeRegistrarControllerPrivateMetaData,
+			ac.SCWMetaData,
+		} {
+			parsed, err := md.GetAbi()
+			if err != nil {
+				log.Error("failed to parse ABI", zap.Error(err))
+				continue
+			}
+			for _, e := range parsed.Errors {
+				var id [4]byte
+				copy(id[:], e.ID[:4])
+				errorsById[id] = e
+			}
+		}
+	})
+	return errorsById
+}
+
+// converts the revert data to RevertError
+func DecodeRevertData(data []byte) *RevertError {
+	out := &RevertError{Data: data, err: ErrReverted}
+
+	// 1 - require() and assert()
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		out.Reason = reason
+		return out
+	}
+
+	if len(data) < 4 {
+		return out
+	}
+
+	// 2 - custom errors of our contracts
+	var id [4]byte
+	copy(id[:], data[:4])
+
+	e, ok := contractErrors()[id]
+	if !ok {
+		out.Reason = hexutil.Encode(data)
+		return out
+	}
+
+	out.Name = e.Name
+	if sentinel, ok := customErrors[e.Name]; ok {
+		out.err = sentinel
+	}
+
+	args, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		out.Reason = e.Name
+		return out
+	}
+	out.Args = args
+
+	formatted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if b, ok := arg.([32]byte); ok {
+			formatted = append(formatted, hexutil.Encode(b[:]))
+			continue
+		}
+		formatted = append(formatted, fmt.Sprint(arg))
+	}
+	out.Reason = fmt.Sprintf("%s(%s)", e.Name, strings.Join(formatted, ", "))
+	return out
+}
+
+// converts RPC error with the revert data (eth_call, eth_estimateGas) to RevertError
+// all other errors are returned as is
+func DecodeRevert(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+
+	// usually it is a hex string
+	str, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+
+	data, decodeErr := hexutil.Decode(str)
+	if decodeErr != nil {
+		return err
+	}
+	return DecodeRevertData(data)
+}
+
+// node rejects tx with these messages before it is added to the mempool
+func classifyTxError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "nonce too low"):
+		return ErrNonceTooLow
+	case strings.Contains(msg, "nonce too high"):
+		return ErrNonceTooHigh
+	}
+	return DecodeRevert(err)
+}
@@ -0,0 +1,195 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeebo/assert"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+)
+
+func packCustomError(t *testing.T, name string, args ...interface{}) []byte {
+	parsed, err := ac.AnytypeRegistrarControllerPrivateMetaData.GetAbi()
+	assert.NoError(t, err)
+
+	e := parsed.Errors[name]
+	data, err := e.Inputs.Pack(args...)
+	assert.NoError(t, err)
+	return append(e.ID[:4:4], data...)
+}
+
+func TestDecodeRevertData(t *testing.T) {
+	t.Run("custom error of the controller", func(t *testing.T) {
+		out := DecodeRevertData(packCustomError(t, "NameNotAvailable", "hello"))
+
+		assert.True(t, errors.Is(out, ErrNameNotAvailable))
+		assert.True(t, errors.Is(out, ErrReverted))
+		assert.Equal(t, out.Name, "NameNotAvailable")
+		assert.Equal(t, out.Args[0], "hello")
+		assert.Equal(t, out.Error(), "name is not available: NameNotAvailable(hello)")
+		assert.Equal(t, RevertName(out), "NameNotAvailable")
+	})
+
+	t.Run("commitment errors", func(t *testing.T) {
+		var commitment [32]byte
+		commitment[31] = 1
+
+		out := DecodeRevertData(packCustomError(t, "CommitmentTooNew", commitment))
+		assert.True(t, errors.Is(out, ErrCommitmentTooNew))
+
+		out = DecodeRevertData(packCustomError(t, "UnexpiredCommitmentExists", commitment))
+		assert.True(t, errors.Is(out, ErrUnexpiredCommitmentExists))
+		assert.Equal(t, out.Reason, "UnexpiredCommitmentExists("+hexutil.Encode(commitment[:])+")")
+	})
+
+	t.Run("require with message", func(t *testing.T) {
+		// Error(string) with "not owner"
+		data := hexutil.MustDecode("0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000009" +
+			"6e6f74206f776e65720000000000000000000000000000000000000000000000")
+
+		out := DecodeRevertData(data)
+		assert.True(t, errors.Is(out, ErrReverted))
+		assert.False(t, errors.Is(out, ErrNameNotAvailable))
+		assert.Equal(t, out.Name, "")
+		assert.Equal(t, out.Reason, "not owner")
+	})
+
+	t.Run("unknown error", func(t *testing.T) {
+		out := DecodeRevertData([]byte{1, 2, 3, 4})
+		assert.True(t, errors.Is(out, ErrReverted))
+		assert.Equal(t, out.Reason, "0x01020304")
+	})
+}
+
+func TestClassifyTxError(t *testing.T) {
+	assert.Equal(t, classifyTxError(errors.New("nonce too low: next nonce 5, tx nonce 4")), ErrNonceTooLow)
+	assert.Equal(t, classifyTxError(errors.New("Nonce too high")), ErrNonceTooHigh)
+
+	other := errors.New("insufficient funds")
+	assert.Equal(t, classifyTxError(other), other)
+	assert.Nil(t, classifyTxError(nil))
+}
+
+// answers eth_call with the revert (if set) and counts sent txs
+type testTxChain struct {
+	*httptest.Server
+	revertData atomic.Value
+	sent       atomic.Int32
+	lastBlock  atomic.Value
+	lastTx     atomic.Value
+}
+
+func newTestTxChain(t *testing.T) *testTxChain {
+	chain := &testTxChain{}
+	chain.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+		switch req.Method {
+		case "eth_call":
+			chain.lastBlock.Store(string(req.Params[1]))
+			if data, ok := chain.revertData.Load().([]byte); ok {
+				res["error"] = map[string]interface{}{"code": 3, "message": "execution reverted", "data": hexutil.Encode(data)}
+				break
+			}
+			res["result"] = "0x"
+		case "eth_estimateGas":
+			res["result"] = "0x5208"
+		case "eth_sendRawTransaction":
+			chain.sent.Add(1)
+			var raw hexutil.Bytes
+			assert.NoError(t, json.Unmarshal(req.Params[0], &raw))
+			tx := new(types.Transaction)
+			assert.NoError(t, tx.UnmarshalBinary(raw))
+			chain.lastTx.Store(tx)
+			res["result"] = tx.Hash()
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+func TestAnynsContracts_sendTx(t *testing.T) {
+	newOpts := func(t *testing.T) *bind.TransactOpts {
+		key, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+
+		opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+		assert.NoError(t, err)
+		opts.Nonce = big.NewInt(1)
+		opts.GasPrice = big.NewInt(1)
+		opts.GasLimit = 100000
+		return opts
+	}
+
+	t.Run("send tx if simulation succeeded", func(t *testing.T) {
+		chain := newTestTxChain(t)
+		acontracts := newTestContracts(chain.URL, "")
+		controller, err := ac.NewAnytypeRegistrarControllerPrivate(testRegistrar, acontracts.backend)
+		assert.NoError(t, err)
+
+		tx, err := acontracts.sendTx(context.Background(), newOpts(t), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return controller.Commit(opts, [32]byte{1})
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, tx)
+		assert.Equal(t, chain.sent.Load(), int32(1))
+		assert.Equal(t, chain.lastBlock.Load(), `"pending"`)
+	})
+
+	t.Run("estimate gas limit if it is not set", func(t *testing.T) {
+		chain := newTestTxChain(t)
+		acontracts := newTestContracts(chain.URL, "")
+		acontracts.config.GasLimitMarginPercent = 20
+		controller, err := ac.NewAnytypeRegistrarControllerPrivate(testRegistrar, acontracts.backend)
+		assert.NoError(t, err)
+
+		opts := newOpts(t)
+		opts.GasLimit = 0
+		tx, err := acontracts.sendTx(context.Background(), opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return controller.Commit(opts, [32]byte{1})
+		})
+		assert.NoError(t, err)
+
+		// estimation + 20%
+		sent := chain.lastTx.Load().(*types.Transaction)
+		assert.Equal(t, sent.Gas(), uint64(25200))
+		assert.Equal(t, sent.Hash(), tx.Hash())
+		assert.Equal(t, sent.Nonce(), uint64(1))
+		assert.Equal(t, sent.GasPrice().Int64(), int64(1))
+	})
+
+	t.Run("do not send tx if simulation was reverted", func(t *testing.T) {
+		chain := newTestTxChain(t)
+		chain.revertData.Store(packCustomError(t, "UnexpiredCommitmentExists", [32]byte{1}))
+
+		acontracts := newTestContracts(chain.URL, "")
+		controller, err := ac.NewAnytypeRegistrarControllerPrivate(testRegistrar, acontracts.backend)
+		assert.NoError(t, err)
+
+		_, err = acontracts.sendTx(context.Background(), newOpts(t), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return controller.Commit(opts, [32]byte{1})
+		})
+		assert.True(t, errors.Is(err, ErrUnexpiredCommitmentExists))
+		assert.Equal(t, chain.sent.Load(), int32(0))
+	})
+}
@@ -0,0 +1,244 @@
+//go:build simulated
+
+package contracts
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/contracts/contractstest"
+	"github.com/anyproto/any-ns-node/signer"
+)
+
+// real contracts on the simulated chain
+// run with: make test-simulated
+type simFixture struct {
+	a     *app.App
+	chain *contractstest.Chain
+
+	*anynsContracts
+}
+
+func newSimFixture(t *testing.T) *simFixture {
+	chain := contractstest.NewChain(t, contractstest.DefaultOptions())
+
+	conf := new(config.Config)
+	conf.Contracts = config.Contracts{
+		GethUrl:                        config.Urls{chain.Url},
+		AddrRegistry:                   chain.Registry.Hex(),
+		AddrResolver:                   chain.Resolver.Hex(),
+		AddrRegistrarImplementation:    chain.Registrar.Hex(),
+		AddrRegistrarPrivateController: chain.PrivateController.Hex(),
+		AddrNameWrapper:                chain.NameWrapper.Hex(),
+		AddrAdmin:                      chain.Admin.Hex(),
+		WaitMiningRetryCount:           3,
+	}
+
+	fx := &simFixture{
+		a:              new(app.App),
+		chain:          chain,
+		anynsContracts: New().(*anynsContracts),
+	}
+	fx.a.Register(conf).
+		Register(signer.NewWithKey(chain.AdminKey)).
+		Register(fx.anynsContracts)
+
+	require.NoError(t, fx.a.Start(context.Background()))
+	t.Cleanup(func() { _ = fx.a.Close(context.Background()) })
+
+	fx.pollInterval = 10 * time.Millisecond
+	return fx
+}
+
+func (fx *simFixture) waitMined(t *testing.T, ctx context.Context, tx *types.Transaction) *types.Receipt {
+	receipt, err := fx.WaitMined(ctx, tx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, receipt.Status, types.ReceiptStatusSuccessful)
+	return receipt
+}
+
+// commit -> register, same as the queue does it
+func (fx *simFixture) register(t *testing.T, ctx context.Context, name string, owner common.Address, ownerAnyAddr string, spaceId string) error {
+	controller, err := fx.ConnectToPrivateController()
+	require.NoError(t, err)
+
+	var secret [32]byte
+	_, err = rand.Read(secret[:])
+	require.NoError(t, err)
+
+	commitment, err := fx.MakeCommitment(ctx, &MakeCommitmentParams{
+		NameFirstPart:         RemoveTLD(name),
+		RegistrantAccount:     owner,
+		Secret:                secret,
+		Controller:            controller,
+		OwnerAnyAddr:          ownerAnyAddr,
+		FullName:              name,
+		SpaceId:               spaceId,
+		IsReverseRecordUpdate: true,
+		RegisterPeriodMonths:  12,
+	})
+	require.NoError(t, err)
+
+	opts, err := fx.GenerateAuthOptsForAdmin(ctx)
+	require.NoError(t, err)
+
+	tx, err := fx.Commit(ctx, &CommitParams{Opts: opts, Commitment: commitment, Controller: controller})
+	if err != nil {
+		return err
+	}
+	fx.waitMined(t, ctx, tx)
+
+	// commitment should be older than MinCommitmentAge
+	require.NoError(t, fx.chain.AdjustTime(2*time.Second))
+
+	opts, err = fx.GenerateAuthOptsForAdmin(ctx)
+	require.NoError(t, err)
+
+	tx, err = fx.Register(ctx, &RegisterParams{
+		AuthOpts:             opts,
+		NameFirstPart:        RemoveTLD(name),
+		RegistrantAccount:    owner,
+		Secret:               secret,
+		Controller:           controller,
+		FullName:             name,
+		OwnerAnyAddr:         ownerAnyAddr,
+		SpaceId:              spaceId,
+		IsReverseRecord:      true,
+		RegisterPeriodMonths: 12,
+	})
+	if err != nil {
+		return err
+	}
+	fx.waitMined(t, ctx, tx)
+	return nil
+}
+
+func TestAnynsContracts_Simulated(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	const ownerAnyAddr = "12D3KooWPANzVZgHqAL57CchRH4q8NGjoWDpUShVovBE3bhhXczy"
+	const spaceId = "bafyreibs3gomyr6fbsnjh3xsm7ibhdkwepoqdb5l2yzhlhpjbs6bh4ykcu.2mwvhn4m6ey8p"
+	owner := common.HexToAddress("0x0000000000000000000000000000000000000077")
+
+	t.Run("commit, register, resolve and renew", func(t *testing.T) {
+		fx := newSimFixture(t)
+
+		chainID, err := fx.GetChainID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, chainID.Int64(), fx.chain.ChainID.Int64())
+
+		// 1 - register
+		require.NoError(t, fx.register(t, ctx, "hello.any", owner, ownerAnyAddr, spaceId))
+
+		// 2 - resolve
+		nh, err := NameHash("hello.any")
+		require.NoError(t, err)
+		assert.Equal(t, nh, contractstest.NameHash("hello"))
+
+		// wrapped names are owned by the NameWrapper
+		registryOwner, err := fx.GetOwnerForNamehash(ctx, nh, BlockRef{})
+		require.NoError(t, err)
+		assert.Equal(t, registryOwner, fx.chain.NameWrapper)
+
+		ownerEth, ownerAny, gotSpaceId, expiration, err := fx.GetAdditionalNameInfo(ctx, registryOwner, "hello.any", BlockRef{})
+		require.NoError(t, err)
+		assert.Equal(t, common.HexToAddress(ownerEth), owner)
+		assert.Equal(t, ownerAny, ownerAnyAddr)
+		assert.Equal(t, gotSpaceId, spaceId)
+		assert.True(t, expiration.Int64() > time.Now().Add(300*24*time.Hour).Unix())
+
+		infos, err := fx.GetNamesInfo(ctx, []string{"hello.any", "free.any"}, BlockRef{})
+		require.NoError(t, err)
+		assert.True(t, infos[0].Registered)
+		assert.Equal(t, common.HexToAddress(infos[0].Owner), owner)
+		assert.False(t, infos[1].Registered)
+
+		// 3 - renew
+		opts, err := fx.GenerateAuthOptsForAdmin(ctx)
+		require.NoError(t, err)
+		controller, err := fx.ConnectToPrivateController()
+		require.NoError(t, err)
+
+		const year = 365 * 24 * 60 * 60
+		tx, err := fx.Renew(ctx, &RenewParams{TxOpts: opts, FullName: "hello", DurationSec: year, Controller: controller})
+		require.NoError(t, err)
+		fx.waitMined(t, ctx, tx)
+
+		renewed, err := fx.GetNameExpiration(ctx, "hello.any")
+		require.NoError(t, err)
+		assert.Equal(t, renewed.Int64(), expiration.Int64()+year)
+	})
+
+	t.Run("fail to register the name twice", func(t *testing.T) {
+		fx := newSimFixture(t)
+
+		require.NoError(t, fx.register(t, ctx, "twice.any", owner, ownerAnyAddr, ""))
+
+		err := fx.register(t, ctx, "twice.any", owner, ownerAnyAddr, "")
+		assert.True(t, errors.Is(err, ErrNameNotAvailable))
+	})
+
+	t.Run("fail to register without commitment", func(t *testing.T) {
+		fx := newSimFixture(t)
+
+		controller, err := fx.ConnectToPrivateController()
+		require.NoError(t, err)
+		opts, err := fx.GenerateAuthOptsForAdmin(ctx)
+		require.NoError(t, err)
+
+		_, err = fx.Register(ctx, &RegisterParams{
+			AuthOpts:             opts,
+			NameFirstPart:        "nocommit",
+			RegistrantAccount:    owner,
+			Controller:           controller,
+			FullName:             "nocommit.any",
+			OwnerAnyAddr:         ownerAnyAddr,
+			RegisterPeriodMonths: 12,
+		})
+		var revert *RevertError
+		assert.True(t, errors.As(err, &revert))
+	})
+
+	t.Run("name is available until it is registered", func(t *testing.T) {
+		fx := newSimFixture(t)
+
+		controller, err := fx.ConnectToPrivateController()
+		require.NoError(t, err)
+		available, err := controller.Available(nil, "free")
+		require.NoError(t, err)
+		assert.True(t, available)
+
+		require.NoError(t, fx.register(t, ctx, "free.any", owner, ownerAnyAddr, ""))
+
+		available, err = controller.Available(nil, "free")
+		require.NoError(t, err)
+		assert.False(t, available)
+	})
+}
+
+// controller is deployed with the configured commitment ages
+func TestAnynsContracts_SimulatedCommitmentAge(t *testing.T) {
+	fx := newSimFixture(t)
+	controller, err := fx.ConnectToPrivateController()
+	require.NoError(t, err)
+
+	minAge, err := controller.MinCommitmentAge(nil)
+	require.NoError(t, err)
+	assert.Equal(t, minAge.Int64(), int64(1))
+
+	maxAge, err := controller.MaxCommitmentAge(nil)
+	require.NoError(t, err)
+	assert.Equal(t, maxAge, big.NewInt(24*60*60))
+}
@@ -9,6 +9,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"golang.org/x/net/idna"
@@ -127,6 +128,14 @@ func NameHash(name string) (hash [32]byte, err error) {
 	return ens.NameHash(name)
 }
 
+// ReverseNode returns the node of the reverse record for the address
+//
+// Example: 0xABCD... -> namehash("abcd....addr.reverse")
+func ReverseNode(address common.Address) (node [32]byte, err error) {
+	// remove 0x
+	return NameHash(strings.ToLower(address.Hex()[2:] + ".addr.reverse"))
+}
+
 func RemoveTLD(str string) string {
 	suffix := ".any"
 
@@ -136,6 +145,37 @@ func RemoveTLD(str string) string {
 	return str
 }
 
+// DecodeDnsName converts name from DNS wire format (as stored in the NameWrapper)
+// to the dotted form
+//
+// Example: "\x05hello\x03any\x00" -> "hello.any"
+func DecodeDnsName(encoded []byte) (string, error) {
+	var labels []string
+
+	offset := 0
+	for offset < len(encoded) {
+		labelLen := int(encoded[offset])
+		offset++
+
+		// zero length label is the root
+		if labelLen == 0 {
+			if offset != len(encoded) {
+				return "", errors.New("unexpected data after the root label")
+			}
+			return strings.Join(labels, "."), nil
+		}
+
+		if offset+labelLen > len(encoded) {
+			return "", errors.New("label is out of bounds")
+		}
+
+		labels = append(labels, string(encoded[offset:offset+labelLen]))
+		offset += labelLen
+	}
+
+	return "", errors.New("no root label")
+}
+
 func GenerateRandomSecret() ([32]byte, error) {
 	var byteArray [32]byte
 
@@ -240,3 +280,8 @@ func PrepareCallData_SetContentHashSpaceID(fullName string, contentHash string,
 
 	return out, nil
 }
+
+// block is confirmed when there are at least N blocks on top of it
+func IsConfirmed(blockNumber uint64, head uint64, confirmations uint64) bool {
+	return head >= blockNumber+confirmations
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"unicode/utf8"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/zeebo/assert"
 	"golang.org/x/net/idna"
 )
@@ -209,3 +210,53 @@ func TestNameHash2_ENSIP15_FromFile(t *testing.T) {
 	}
 }
 */
+
+func TestDecodeDnsName(t *testing.T) {
+	// 1
+	out, err := DecodeDnsName([]byte("\x05hello\x03any\x00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.any", out)
+
+	// 2 - root only
+	out, err = DecodeDnsName([]byte("\x00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+
+	// 3 - no root label
+	_, err = DecodeDnsName([]byte("\x05hello\x03any"))
+	assert.Error(t, err)
+
+	// 4 - label is out of bounds
+	_, err = DecodeDnsName([]byte("\x10hello\x00"))
+	assert.Error(t, err)
+
+	// 5 - data after the root label
+	_, err = DecodeDnsName([]byte("\x03any\x00\x01"))
+	assert.Error(t, err)
+}
+
+func TestReverseNode(t *testing.T) {
+	addr := common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
+
+	// address should be in lower case and without 0x
+	expected, err := NameHash("10d5b0e279e5e4c1d1df5f57dfb7e84813920a51.addr.reverse")
+	assert.NoError(t, err)
+
+	out, err := ReverseNode(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, out)
+}
+
+func TestIsConfirmed(t *testing.T) {
+	// 1 - no confirmations required
+	assert.True(t, IsConfirmed(100, 100, 0))
+
+	// 2 - not enough blocks on top
+	assert.False(t, IsConfirmed(100, 104, 5))
+
+	// 3 - exactly N blocks on top
+	assert.True(t, IsConfirmed(100, 105, 5))
+
+	// 4 - head is behind (other node is lagging)
+	assert.False(t, IsConfirmed(100, 99, 1))
+}
@@ -19,7 +19,6 @@ const CName = "any-ns.db"
 
 var log = logger.NewNamed(CName)
 
-// TODO: index it
 type AAUser struct {
 	Address         string `bson:"address"`
 	AnyID           string `bson:"any_id"`
@@ -41,6 +40,11 @@ type AAUserOperation struct {
 	OwnerEthAddress string `bson:"owner_eth_address"`
 	OwnerAnyID      string `bson:"owner_any_id"`
 	FullName        string `bson:"full_name"`
+
+	// block that operation was included into
+	// is set only after operation has ConfirmationBlocks on top of it
+	BlockNumber int64  `bson:"block_number"`
+	BlockHash   string `bson:"block_hash"`
 }
 
 type findUserOperationByID struct {
@@ -59,6 +63,7 @@ type DbService interface {
 
 	SaveOperation(ctx context.Context, opID string, cuor nsp.CreateUserOperationRequest) error
 	GetOperation(ctx context.Context, opID string) (op AAUserOperation, err error)
+	SetOperationBlock(ctx context.Context, opID string, blockNumber uint64, blockHash string) error
 
 	app.Component
 }
@@ -261,3 +266,24 @@ func (arpc *anynsDb) GetOperation(ctx context.Context, opID string) (op AAUserOp
 
 	return op, nil
 }
+
+func (arpc *anynsDb) SetOperationBlock(ctx context.Context, opID string, blockNumber uint64, blockHash string) error {
+	// 1 - get operation
+	op, err := arpc.GetOperation(ctx, opID)
+	if err != nil {
+		return err
+	}
+
+	// 2 - update it
+	op.BlockNumber = int64(blockNumber)
+	op.BlockHash = blockHash
+
+	optns := options.Replace().SetUpsert(false)
+	_, err = arpc.opColl.ReplaceOne(ctx, findUserOperationByID{OperationID: opID}, op, optns)
+	if err != nil {
+		log.Error("failed to update operation in DB", zap.String("opID", opID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -142,3 +142,17 @@ func (mr *MockDbServiceMockRecorder) SaveOperation(ctx, opID, cuor any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOperation", reflect.TypeOf((*MockDbService)(nil).SaveOperation), ctx, opID, cuor)
 }
+
+// SetOperationBlock mocks base method.
+func (m *MockDbService) SetOperationBlock(ctx context.Context, opID string, blockNumber uint64, blockHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOperationBlock", ctx, opID, blockNumber, blockHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOperationBlock indicates an expected call of SetOperationBlock.
+func (mr *MockDbServiceMockRecorder) SetOperationBlock(ctx, opID, blockNumber, blockHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOperationBlock", reflect.TypeOf((*MockDbService)(nil).SetOperationBlock), ctx, opID, blockNumber, blockHash)
+}
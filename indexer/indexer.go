@@ -0,0 +1,669 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/cache"
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/contracts"
+)
+
+const CName = "any-ns.indexer"
+
+var log = logger.NewNamed(CName)
+
+const (
+	defaultBlockBatchSize  = 1000
+	defaultPollIntervalSec = 15
+
+	// if last processed block was reorged out -> re-process at least that many blocks
+	defaultReorgRewindBlocks = 64
+
+	// the only document in the "indexer" collection
+	stateName = "indexer"
+)
+
+type IndexerState struct {
+	Name string `bson:"name"`
+	// last block that was fully processed
+	LastBlock int64 `bson:"last_block"`
+	// used to detect reorgs deeper than ConfirmationBlocks
+	LastBlockHash string `bson:"last_block_hash"`
+}
+
+type findStateByName struct {
+	Name string `bson:"name"`
+}
+
+func New() app.ComponentRunnable {
+	return &anynsIndexer{}
+}
+
+// Indexer tails logs of all AnyNS contracts and keeps the name cache in sync
+// with the chain, even if names were registered/renewed/transferred not by this node
+type IndexerService interface {
+	// will read all logs in [fromBlock, toBlock] and update every touched name in the cache
+	ProcessBlockRange(ctx context.Context, fromBlock uint64, toBlock uint64) error
+	// returns last block that was fully processed (or startBlock-1 if nothing was processed yet)
+	GetLastProcessedBlock(ctx context.Context) (uint64, error)
+	// will scan all logs from the deployment block up to the last confirmed block
+	// and will rebuild the whole cache from scratch
+	Reindex(ctx context.Context) error
+
+	app.ComponentRunnable
+}
+
+type anynsIndexer struct {
+	confMongo     config.Mongo
+	confIndexer   config.Indexer
+	confContracts config.Contracts
+
+	stateColl *mongo.Collection
+	contracts contracts.ContractsService
+	cache     cache.CacheService
+
+	cancel context.CancelFunc
+	done   chan bool
+}
+
+func (ai *anynsIndexer) Name() (name string) {
+	return CName
+}
+
+func (ai *anynsIndexer) Init(a *app.App) (err error) {
+	ai.confMongo = a.MustComponent(config.CName).(*config.Config).Mongo
+	ai.confIndexer = a.MustComponent(config.CName).(*config.Config).GetIndexer()
+	ai.confContracts = a.MustComponent(config.CName).(*config.Config).GetContracts()
+
+	ai.contracts = a.MustComponent(contracts.CName).(contracts.ContractsService)
+	ai.cache = a.MustComponent(cache.CName).(cache.CacheService)
+
+	if ai.confIndexer.BlockBatchSize == 0 {
+		ai.confIndexer.BlockBatchSize = defaultBlockBatchSize
+	}
+	if ai.confIndexer.PollIntervalSec == 0 {
+		ai.confIndexer.PollIntervalSec = defaultPollIntervalSec
+	}
+
+	ai.done = make(chan bool)
+	return nil
+}
+
+func (ai *anynsIndexer) Run(ctx context.Context) (err error) {
+	uri := ai.confMongo.Connect
+	dbName := ai.confMongo.Database
+	collectionName := "indexer"
+
+	// 1 - connect to DB
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return err
+	}
+
+	ai.stateColl = client.Database(dbName).Collection(collectionName)
+	if ai.stateColl == nil {
+		return errors.New("failed to connect to MongoDB")
+	}
+
+	log.Info("mongo connected!")
+
+	if !ai.confIndexer.Enabled {
+		log.Info("indexer is disabled")
+		return nil
+	}
+
+	// 2 - start one worker
+	// do not use ctx here, it is used only during app start
+	var workerCtx context.Context
+	workerCtx, ai.cancel = context.WithCancel(context.Background())
+	go ai.worker(workerCtx)
+
+	return nil
+}
+
+func (ai *anynsIndexer) Close(ctx context.Context) (err error) {
+	if ai.cancel != nil {
+		ai.cancel()
+
+		// wait for the worker to stop
+		select {
+		case <-ai.done:
+		case <-ctx.Done():
+		}
+	}
+
+	if ai.stateColl != nil {
+		err = ai.stateColl.Database().Client().Disconnect(ctx)
+		ai.stateColl = nil
+	}
+	return
+}
+
+func (ai *anynsIndexer) worker(ctx context.Context) {
+	log.Info("worker started")
+
+	ticker := time.NewTicker(time.Duration(ai.confIndexer.PollIntervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err := ai.indexNewBlocks(ctx)
+		if err != nil {
+			// in case of error - do not stop, try again next time
+			log.Warn("failed to index new blocks", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("worker stopped")
+			close(ai.done)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process all blocks from the last processed one up to the last confirmed block
+func (ai *anynsIndexer) indexNewBlocks(ctx context.Context) error {
+	// 1 - re-check data that was written from blocks that are not final yet
+	err := ai.cache.VerifyRecentEntries(ctx)
+	if err != nil {
+		// do not stop indexing
+		log.Warn("failed to verify recent cache entries", zap.Error(err))
+	}
+
+	// 2 - only process blocks that have enough confirmations
+	head, err := ai.contracts.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	confirmations := ai.confContracts.ConfirmationBlocks
+	if head < confirmations {
+		return nil
+	}
+	head -= confirmations
+
+	// 3 - check if last processed block is still in the canonical chain
+	last, err := ai.getLastProcessedBlockChecked(ctx)
+	if err != nil {
+		return err
+	}
+
+	for from := last + 1; from <= head; {
+		to := from + ai.confIndexer.BlockBatchSize - 1
+		if to > head {
+			to = head
+		}
+
+		err = ai.ProcessBlockRange(ctx, from, to)
+		if err != nil {
+			return err
+		}
+
+		// persist progress after each batch, so we can resume after restart
+		hash, err := ai.contracts.GetBlockHash(ctx, to)
+		if err != nil {
+			return err
+		}
+
+		err = ai.saveLastProcessedBlock(ctx, to, hash)
+		if err != nil {
+			return err
+		}
+
+		from = to + 1
+	}
+
+	return nil
+}
+
+// will rewind if last processed block was reorged out
+func (ai *anynsIndexer) getLastProcessedBlockChecked(ctx context.Context) (uint64, error) {
+	state, err := ai.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	last := uint64(state.LastBlock)
+
+	// nothing was indexed yet or state was written by the older version
+	if state.LastBlockHash == "" {
+		return last, nil
+	}
+
+	hash, err := ai.contracts.GetBlockHash(ctx, last)
+	if err != nil {
+		return 0, err
+	}
+	if hash == common.HexToHash(state.LastBlockHash) {
+		return last, nil
+	}
+
+	// reorg is deeper than ConfirmationBlocks -> process some blocks again
+	rewind := ai.getReorgRewindBlocks()
+	start := ai.getFirstBlock()
+	if last < start+rewind {
+		last = start
+	} else {
+		last -= rewind
+	}
+
+	log.Warn("last processed block was reorged out, rewinding",
+		zap.Int64("block", state.LastBlock), zap.Uint64("new last block", last))
+	return last, nil
+}
+
+func (ai *anynsIndexer) getReorgRewindBlocks() uint64 {
+	if ai.confContracts.ConfirmationBlocks > defaultReorgRewindBlocks {
+		return ai.confContracts.ConfirmationBlocks
+	}
+	return defaultReorgRewindBlocks
+}
+
+// returns startBlock-1 (block "before" the first one)
+func (ai *anynsIndexer) getFirstBlock() uint64 {
+	if ai.confIndexer.StartBlock > 0 {
+		return ai.confIndexer.StartBlock - 1
+	}
+	if ai.confContracts.DeploymentBlock > 0 {
+		return ai.confContracts.DeploymentBlock - 1
+	}
+	return 0
+}
+
+func (ai *anynsIndexer) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
+	state, err := ai.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Warning: convert int64 -> uint64
+	return uint64(state.LastBlock), nil
+}
+
+func (ai *anynsIndexer) getState(ctx context.Context) (IndexerState, error) {
+	var state IndexerState
+	err := ai.stateColl.FindOne(ctx, findStateByName{Name: stateName}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		// nothing was indexed yet
+		return IndexerState{
+			Name:      stateName,
+			LastBlock: int64(ai.getFirstBlock()),
+		}, nil
+	}
+	if err != nil {
+		log.Error("failed to get indexer state from DB", zap.Error(err))
+		return IndexerState{}, err
+	}
+
+	return state, nil
+}
+
+func (ai *anynsIndexer) saveLastProcessedBlock(ctx context.Context, block uint64, hash common.Hash) error {
+	opts := options.Replace().SetUpsert(true)
+
+	state := IndexerState{
+		Name:          stateName,
+		LastBlock:     int64(block),
+		LastBlockHash: hash.Hex(),
+	}
+
+	_, err := ai.stateColl.ReplaceOne(ctx, findStateByName{Name: stateName}, state, opts)
+	if err != nil {
+		log.Error("failed to save indexer state to DB", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (ai *anynsIndexer) ProcessBlockRange(ctx context.Context, fromBlock uint64, toBlock uint64) error {
+	log.Debug("processing blocks", zap.Uint64("from", fromBlock), zap.Uint64("to", toBlock))
+
+	// 1 - collect all names and reverse records touched in these blocks
+	names := make(map[string]bool)
+	reverseNodes := make(map[[32]byte]bool)
+	err := ai.collectNames(ctx, fromBlock, toBlock, names, reverseNodes)
+	if err != nil {
+		return err
+	}
+
+	// 2 - read actual data from smart contracts -> cache
+	for name := range names {
+		err = ai.cache.UpdateInCache(ctx, &nsp.NameAvailableRequest{
+			FullName: name,
+		})
+		if err != nil {
+			log.Error("failed to update name in cache", zap.String("FullName", name), zap.Error(err))
+			return err
+		}
+	}
+
+	// 3 - reverse records (owners of updated names are already refreshed)
+	for node := range reverseNodes {
+		err = ai.cache.UpdateReverseRecordByNode(ctx, node)
+		if err != nil {
+			log.Error("failed to update reverse record in cache", zap.String("node", common.Hash(node).Hex()), zap.Error(err))
+			return err
+		}
+	}
+
+	log.Info("processed blocks",
+		zap.Uint64("from", fromBlock),
+		zap.Uint64("to", toBlock),
+		zap.Int("names updated", len(names)),
+		zap.Int("reverse records updated", len(reverseNodes)))
+
+	return nil
+}
+
+func (ai *anynsIndexer) Reindex(ctx context.Context) error {
+	// 1 - only process blocks that have enough confirmations
+	head, err := ai.contracts.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	confirmations := ai.confContracts.ConfirmationBlocks
+	if head < confirmations {
+		return errors.New("chain is too short")
+	}
+	head -= confirmations
+
+	headHash, err := ai.contracts.GetBlockHash(ctx, head)
+	if err != nil {
+		return err
+	}
+
+	// 2 - collect all names ever touched
+	first := ai.getFirstBlock()
+	log.Info("reindexing", zap.Uint64("from", first+1), zap.Uint64("to", head))
+
+	names := make(map[string]bool)
+	// reverse records of all owners are read during the rebuild
+	reverseNodes := make(map[[32]byte]bool)
+	for from := first + 1; from <= head; {
+		to := from + ai.confIndexer.BlockBatchSize - 1
+		if to > head {
+			to = head
+		}
+
+		err = ai.collectNames(ctx, from, to, names, reverseNodes)
+		if err != nil {
+			return err
+		}
+
+		log.Info("scanned blocks", zap.Uint64("to", to), zap.Int("names found", len(names)))
+		from = to + 1
+	}
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	// 3 - read data for every name and replace the cache
+	err = ai.cache.RebuildCache(ctx, list)
+	if err != nil {
+		log.Error("failed to rebuild cache", zap.Error(err))
+		return err
+	}
+
+	// owners of all names are already refreshed, but cleared reverse records are not
+	for node := range reverseNodes {
+		err = ai.cache.UpdateReverseRecordByNode(ctx, node)
+		if err != nil {
+			log.Error("failed to update reverse record in cache", zap.String("node", common.Hash(node).Hex()), zap.Error(err))
+			return err
+		}
+	}
+
+	// 4 - indexer can continue from here
+	err = ai.saveLastProcessedBlock(ctx, head, headHash)
+	if err != nil {
+		return err
+	}
+
+	// 5 - names that were changed during the rebuild could be written to the old cache (i.e. by running nodes)
+	// so they are lost after it was replaced -> read them again
+	log.Info("processing blocks that were added during the reindex", zap.Uint64("from", head+1))
+	return ai.indexNewBlocks(ctx)
+}
+
+// will add all .any names touched in these blocks to names
+// and all changed reverse records to reverseNodes
+func (ai *anynsIndexer) collectNames(ctx context.Context, fromBlock uint64, toBlock uint64, names map[string]bool, reverseNodes map[[32]byte]bool) error {
+	// some events have full names, others only have namehashes
+	touched := make(map[string]bool)
+	nodes := make(map[[32]byte]bool)
+
+	opts := &bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &toBlock,
+		Context: ctx,
+	}
+
+	// 1 - collect all names/nodes touched in these blocks
+	err := ai.collectFromController(opts, touched)
+	if err != nil {
+		log.Error("failed to read controller logs", zap.Error(err))
+		return err
+	}
+
+	err = ai.collectFromResolver(opts, touched, nodes, reverseNodes)
+	if err != nil {
+		log.Error("failed to read resolver logs", zap.Error(err))
+		return err
+	}
+
+	err = ai.collectFromRegistry(opts, nodes)
+	if err != nil {
+		log.Error("failed to read registry logs", zap.Error(err))
+		return err
+	}
+
+	err = ai.collectFromNameWrapper(opts, touched, nodes)
+	if err != nil {
+		log.Error("failed to read NameWrapper logs", zap.Error(err))
+		return err
+	}
+
+	// 2 - convert namehashes to names
+	for node := range nodes {
+		name, err := ai.contracts.GetNameByNamehash(ctx, node)
+		if err != nil {
+			log.Error("failed to get name by namehash", zap.String("node", common.Hash(node).Hex()), zap.Error(err))
+			return err
+		}
+
+		// not a wrapped name (like reverse records or .any TLD itself)
+		if name == "" {
+			continue
+		}
+		touched[name] = true
+	}
+
+	// 3 - we only need .any names
+	for name := range touched {
+		if isAnyName(name) {
+			names[name] = true
+		}
+	}
+
+	return nil
+}
+
+// NameRegistered/NameRenewed of the controller have the first part of the name (label)
+func (ai *anynsIndexer) collectFromController(opts *bind.FilterOpts, names map[string]bool) error {
+	controller, err := ai.contracts.ConnectToPrivateController()
+	if err != nil {
+		return err
+	}
+
+	regIt, err := controller.FilterNameRegistered(opts, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer regIt.Close()
+
+	for regIt.Next() {
+		names[regIt.Event.Name+".any"] = true
+	}
+	if regIt.Error() != nil {
+		return regIt.Error()
+	}
+
+	renewIt, err := controller.FilterNameRenewed(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer renewIt.Close()
+
+	for renewIt.Next() {
+		names[renewIt.Event.Name+".any"] = true
+	}
+	return renewIt.Error()
+}
+
+func (ai *anynsIndexer) collectFromResolver(opts *bind.FilterOpts, names map[string]bool, nodes map[[32]byte]bool, reverseNodes map[[32]byte]bool) error {
+	resolver, err := ai.contracts.ConnectToResolver()
+	if err != nil {
+		return err
+	}
+
+	chIt, err := resolver.FilterContenthashChanged(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer chIt.Close()
+
+	for chIt.Next() {
+		nodes[chIt.Event.Node] = true
+	}
+	if chIt.Error() != nil {
+		return chIt.Error()
+	}
+
+	spaceIt, err := resolver.FilterSpaceIDChanged(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer spaceIt.Close()
+
+	for spaceIt.Next() {
+		nodes[spaceIt.Event.Node] = true
+	}
+	if spaceIt.Error() != nil {
+		return spaceIt.Error()
+	}
+
+	// reverse record was changed: node is "xxx.addr.reverse", name is the .any name
+	nameIt, err := resolver.FilterNameChanged(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer nameIt.Close()
+
+	for nameIt.Next() {
+		// name is empty if reverse record was cleared
+		reverseNodes[nameIt.Event.Node] = true
+
+		if nameIt.Event.Name != "" {
+			names[nameIt.Event.Name] = true
+		}
+	}
+	return nameIt.Error()
+}
+
+func (ai *anynsIndexer) collectFromRegistry(opts *bind.FilterOpts, nodes map[[32]byte]bool) error {
+	registry, err := ai.contracts.ConnectToRegistryContract()
+	if err != nil {
+		return err
+	}
+
+	// NewOwner has parent node + label hash
+	ownerIt, err := registry.FilterNewOwner(opts, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer ownerIt.Close()
+
+	for ownerIt.Next() {
+		nodes[childNode(ownerIt.Event.Node, ownerIt.Event.Label)] = true
+	}
+	if ownerIt.Error() != nil {
+		return ownerIt.Error()
+	}
+
+	transferIt, err := registry.FilterTransfer(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer transferIt.Close()
+
+	for transferIt.Next() {
+		nodes[transferIt.Event.Node] = true
+	}
+	return transferIt.Error()
+}
+
+func (ai *anynsIndexer) collectFromNameWrapper(opts *bind.FilterOpts, names map[string]bool, nodes map[[32]byte]bool) error {
+	nw, err := ai.contracts.ConnectToNamewrapperContract()
+	if err != nil {
+		return err
+	}
+
+	wrappedIt, err := nw.FilterNameWrapped(opts, nil)
+	if err != nil {
+		return err
+	}
+	defer wrappedIt.Close()
+
+	for wrappedIt.Next() {
+		name, err := contracts.DecodeDnsName(wrappedIt.Event.Name)
+		if err != nil {
+			// can not decode -> will ask NameWrapper later
+			nodes[wrappedIt.Event.Node] = true
+			continue
+		}
+		names[name] = true
+	}
+	if wrappedIt.Error() != nil {
+		return wrappedIt.Error()
+	}
+
+	// ERC1155 token ID is the namehash
+	transferIt, err := nw.FilterTransferSingle(opts, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer transferIt.Close()
+
+	for transferIt.Next() {
+		nodes[common.BigToHash(transferIt.Event.Id)] = true
+	}
+	return transferIt.Error()
+}
+
+// namehash of the subname: keccak256(parentNode, labelHash)
+func childNode(parent [32]byte, label [32]byte) [32]byte {
+	return crypto.Keccak256Hash(parent[:], label[:])
+}
+
+// we only keep first level .any names in the cache
+func isAnyName(name string) bool {
+	parts := strings.Split(name, ".")
+	return len(parts) == 2 && parts[0] != "" && parts[1] == "any"
+}
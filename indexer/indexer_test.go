@@ -0,0 +1,398 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/mock/gomock"
+
+	ac "github.com/anyproto/any-ns-node/anytype_crypto"
+	mock_cache "github.com/anyproto/any-ns-node/cache/mock"
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/contracts"
+	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
+)
+
+var ctx = context.Background()
+
+var (
+	addrController  = common.HexToAddress("0x01")
+	addrResolver    = common.HexToAddress("0x02")
+	addrRegistry    = common.HexToAddress("0x03")
+	addrNameWrapper = common.HexToAddress("0x04")
+)
+
+func TestIndexer_ChildNode(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		parent, err := contracts.NameHash("any")
+		assert.NoError(t, err)
+
+		expected, err := contracts.NameHash("hello.any")
+		assert.NoError(t, err)
+
+		var label [32]byte
+		copy(label[:], crypto.Keccak256([]byte("hello")))
+
+		assert.Equal(t, expected, childNode(parent, label))
+	})
+}
+
+func TestIndexer_IsAnyName(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		assert.True(t, isAnyName("hello.any"))
+	})
+
+	t.Run("fail if not .any", func(t *testing.T) {
+		assert.False(t, isAnyName("hello.eth"))
+		assert.False(t, isAnyName("any"))
+		assert.False(t, isAnyName(".any"))
+	})
+
+	t.Run("fail if subname or reverse record", func(t *testing.T) {
+		assert.False(t, isAnyName("sub.hello.any"))
+		assert.False(t, isAnyName("e595e2ba3f0ce990d8037e07250c5c78ce40f8ff.addr.reverse"))
+	})
+}
+
+func TestIndexer_ProcessBlockRange(t *testing.T) {
+	t.Run("all kinds of logs", func(t *testing.T) {
+		fx := newFixture(t)
+
+		anyNode, err := contracts.NameHash("any")
+		require.NoError(t, err)
+		var transferredLabel [32]byte
+		copy(transferredLabel[:], crypto.Keccak256([]byte("transferred")))
+		transferred := childNode(anyNode, transferredLabel)
+		contenthash := namehash(t, "contenthash.any")
+		wrapped := namehash(t, "wrapped.any")
+		reverse := namehash(t, "e595e2ba3f0ce990d8037e07250c5c78ce40f8ff.addr.reverse")
+
+		fx.backend.add(
+			controllerLog(t, 10, "NameRegistered", "registered", namehash(t, "registered"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			controllerLog(t, 11, "NameRenewed", "renewed", namehash(t, "renewed"), big.NewInt(1)),
+			resolverLog(t, 12, "ContenthashChanged", contenthash, []byte("hash")),
+			resolverLog(t, 13, "NameChanged", reverse, "primary.any"),
+			registryLog(t, 14, "NewOwner", anyNode, transferredLabel, common.HexToAddress("0xbb")),
+			nameWrapperLog(t, 15, "NameWrapped", wrapped, dnsName("wrapped", "any"), common.HexToAddress("0xbb"), uint32(0), uint64(0)),
+			// subnames are not kept in the cache
+			nameWrapperLog(t, 16, "NameWrapped", namehash(t, "sub.wrapped.any"), dnsName("sub", "wrapped", "any"), common.HexToAddress("0xbb"), uint32(0), uint64(0)),
+			// out of the range
+			controllerLog(t, 21, "NameRegistered", "later", namehash(t, "later"), common.HexToAddress("0xaa"), big.NewInt(1)),
+		)
+		fx.contracts.EXPECT().GetNameByNamehash(gomock.Any(), transferred).Return("transferred.any", nil)
+		fx.contracts.EXPECT().GetNameByNamehash(gomock.Any(), contenthash).Return("contenthash.any", nil)
+
+		fx.cache.EXPECT().UpdateReverseRecordByNode(gomock.Any(), reverse).Return(nil)
+
+		err = fx.ProcessBlockRange(ctx, 10, 20)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"contenthash.any", "primary.any", "registered.any", "renewed.any", "transferred.any", "wrapped.any"}, fx.updatedNames())
+	})
+
+	t.Run("fail if logs can not be read", func(t *testing.T) {
+		fx := newFixture(t)
+		fx.backend.err = ethereum.NotFound
+
+		err := fx.ProcessBlockRange(ctx, 10, 20)
+		require.Error(t, err)
+		require.Empty(t, fx.updatedNames())
+	})
+}
+
+func TestIndexer_IndexNewBlocks(t *testing.T) {
+	t.Run("resume from the saved block", func(t *testing.T) {
+		fx := newFixture(t)
+		fx.connectMongo(t)
+		fx.confIndexer.BlockBatchSize = 15
+		fx.confContracts.ConfirmationBlocks = 10
+
+		require.NoError(t, fx.saveLastProcessedBlock(ctx, 100, blockHash(100)))
+		fx.backend.add(
+			controllerLog(t, 99, "NameRegistered", "processed", namehash(t, "processed"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			controllerLog(t, 105, "NameRegistered", "first", namehash(t, "first"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			controllerLog(t, 118, "NameRegistered", "second", namehash(t, "second"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			// not confirmed yet
+			controllerLog(t, 125, "NameRegistered", "unconfirmed", namehash(t, "unconfirmed"), common.HexToAddress("0xaa"), big.NewInt(1)),
+		)
+		fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(130), nil)
+		fx.contracts.EXPECT().GetBlockHash(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, block uint64) (common.Hash, error) {
+			return blockHash(block), nil
+		}).AnyTimes()
+
+		require.NoError(t, fx.indexNewBlocks(ctx))
+
+		require.Equal(t, []string{"first.any", "second.any"}, fx.updatedNames())
+		// in batches of BlockBatchSize
+		require.Equal(t, [][2]uint64{{101, 115}, {116, 120}}, fx.backend.ranges())
+
+		state, err := fx.getState(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(120), state.LastBlock)
+		require.Equal(t, blockHash(120).Hex(), state.LastBlockHash)
+	})
+
+	t.Run("start from the start block", func(t *testing.T) {
+		fx := newFixture(t)
+		fx.connectMongo(t)
+		fx.confIndexer.StartBlock = 50
+
+		fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(60), nil)
+		fx.contracts.EXPECT().GetBlockHash(gomock.Any(), uint64(60)).Return(blockHash(60), nil)
+
+		require.NoError(t, fx.indexNewBlocks(ctx))
+		require.Equal(t, [][2]uint64{{50, 60}}, fx.backend.ranges())
+	})
+
+	t.Run("rewind if last block was reorged out", func(t *testing.T) {
+		fx := newFixture(t)
+		fx.connectMongo(t)
+		fx.confIndexer.StartBlock = 10
+
+		require.NoError(t, fx.saveLastProcessedBlock(ctx, 100, blockHash(100)))
+		fx.backend.add(
+			// was processed before the reorg, but its block is not canonical anymore
+			controllerLog(t, 90, "NameRegistered", "reorged", namehash(t, "reorged"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			controllerLog(t, 30, "NameRegistered", "deep", namehash(t, "deep"), common.HexToAddress("0xaa"), big.NewInt(1)),
+		)
+		fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(110), nil)
+		fx.contracts.EXPECT().GetBlockHash(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, block uint64) (common.Hash, error) {
+			// new chain
+			return crypto.Keccak256Hash(blockHash(block).Bytes()), nil
+		}).AnyTimes()
+
+		require.NoError(t, fx.indexNewBlocks(ctx))
+
+		// 64 blocks back
+		require.Equal(t, [][2]uint64{{37, 110}}, fx.backend.ranges())
+		require.Equal(t, []string{"reorged.any"}, fx.updatedNames())
+	})
+}
+
+func TestIndexer_Reindex(t *testing.T) {
+	t.Run("read again names that were changed during the rebuild", func(t *testing.T) {
+		fx := newFixture(t)
+		fx.connectMongo(t)
+		fx.confIndexer.StartBlock = 1
+
+		reverse := namehash(t, "e595e2ba3f0ce990d8037e07250c5c78ce40f8ff.addr.reverse")
+		fx.backend.add(
+			controllerLog(t, 50, "NameRegistered", "old", namehash(t, "old"), common.HexToAddress("0xaa"), big.NewInt(1)),
+			resolverLog(t, 60, "NameChanged", reverse, "primary.any"),
+			// added while the cache was rebuilt
+			controllerLog(t, 105, "NameRegistered", "changed", namehash(t, "changed"), common.HexToAddress("0xaa"), big.NewInt(1)),
+		)
+		gomock.InOrder(
+			fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(100), nil),
+			fx.contracts.EXPECT().GetLatestBlockNumber(gomock.Any()).Return(uint64(110), nil),
+		)
+		fx.contracts.EXPECT().GetBlockHash(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, block uint64) (common.Hash, error) {
+			return blockHash(block), nil
+		}).AnyTimes()
+
+		gomock.InOrder(
+			fx.cache.EXPECT().RebuildCache(gomock.Any(), []string{"old.any", "primary.any"}).Return(nil),
+			// cleared reverse records are not updated by the rebuild
+			fx.cache.EXPECT().UpdateReverseRecordByNode(gomock.Any(), reverse).Return(nil),
+		)
+
+		require.NoError(t, fx.Reindex(ctx))
+
+		require.Equal(t, [][2]uint64{{1, 100}, {101, 110}}, fx.backend.ranges())
+		require.Equal(t, []string{"changed.any"}, fx.updatedNames())
+
+		state, err := fx.getState(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(110), state.LastBlock)
+	})
+}
+
+type fixture struct {
+	ctrl      *gomock.Controller
+	contracts *mock_contracts.MockContractsService
+	cache     *mock_cache.MockCacheService
+	backend   *logsBackend
+
+	mu      sync.Mutex
+	updated []string
+
+	*anynsIndexer
+}
+
+func newFixture(t *testing.T) *fixture {
+	fx := &fixture{
+		ctrl:    gomock.NewController(t),
+		backend: &logsBackend{},
+	}
+	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
+	fx.cache = mock_cache.NewMockCacheService(fx.ctrl)
+	fx.anynsIndexer = &anynsIndexer{
+		confIndexer: config.Indexer{BlockBatchSize: defaultBlockBatchSize},
+		contracts:   fx.contracts,
+		cache:       fx.cache,
+	}
+
+	controller, err := ac.NewAnytypeRegistrarControllerPrivate(addrController, fx.backend)
+	require.NoError(t, err)
+	resolver, err := ac.NewAnytypeResolver(addrResolver, fx.backend)
+	require.NoError(t, err)
+	registry, err := ac.NewENSRegistry(addrRegistry, fx.backend)
+	require.NoError(t, err)
+	nameWrapper, err := ac.NewAnytypeNameWrapper(addrNameWrapper, fx.backend)
+	require.NoError(t, err)
+
+	fx.contracts.EXPECT().ConnectToPrivateController().Return(controller, nil).AnyTimes()
+	fx.contracts.EXPECT().ConnectToResolver().Return(resolver, nil).AnyTimes()
+	fx.contracts.EXPECT().ConnectToRegistryContract().Return(registry, nil).AnyTimes()
+	fx.contracts.EXPECT().ConnectToNamewrapperContract().Return(nameWrapper, nil).AnyTimes()
+
+	fx.cache.EXPECT().VerifyRecentEntries(gomock.Any()).Return(nil).AnyTimes()
+	fx.cache.EXPECT().UpdateInCache(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, in *nsp.NameAvailableRequest) error {
+		fx.mu.Lock()
+		defer fx.mu.Unlock()
+		fx.updated = append(fx.updated, in.FullName)
+		return nil
+	}).AnyTimes()
+	return fx
+}
+
+func (fx *fixture) connectMongo(t *testing.T) {
+	// TODO: mock Mongo!
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	fx.stateColl = client.Database("any-ns").Collection("indexer")
+	require.NoError(t, fx.stateColl.Drop(ctx))
+	t.Cleanup(func() { _ = client.Disconnect(ctx) })
+}
+
+func (fx *fixture) updatedNames() []string {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	names := slices.Clone(fx.updated)
+	sort.Strings(names)
+	return names
+}
+
+// eth_getLogs of the contracts
+type logsBackend struct {
+	bind.ContractBackend
+
+	mu      sync.Mutex
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+	err     error
+}
+
+func (b *logsBackend) add(logs ...types.Log) {
+	b.logs = append(b.logs, logs...)
+}
+
+func (b *logsBackend) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queries = append(b.queries, q)
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var out []types.Log
+	for _, l := range b.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() {
+			continue
+		}
+		if !slices.Contains(q.Addresses, l.Address) || !slices.Contains(q.Topics[0], l.Topics[0]) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// unique block ranges that were requested
+func (b *logsBackend) ranges() [][2]uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out [][2]uint64
+	for _, q := range b.queries {
+		r := [2]uint64{q.FromBlock.Uint64(), q.ToBlock.Uint64()}
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func eventLog(t *testing.T, meta *bind.MetaData, address common.Address, block uint64, name string, args ...interface{}) types.Log {
+	parsed, err := meta.GetAbi()
+	require.NoError(t, err)
+	event := parsed.Events[name]
+	require.Len(t, args, len(event.Inputs))
+
+	topics := []common.Hash{event.ID}
+	var data []interface{}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			continue
+		}
+		topic, err := abi.MakeTopics([]interface{}{args[i]})
+		require.NoError(t, err)
+		topics = append(topics, topic[0][0])
+	}
+
+	packed, err := event.Inputs.NonIndexed().Pack(data...)
+	require.NoError(t, err)
+	return types.Log{Address: address, BlockNumber: block, Topics: topics, Data: packed}
+}
+
+func controllerLog(t *testing.T, block uint64, name string, args ...interface{}) types.Log {
+	return eventLog(t, ac.AnytypeRegistrarControllerPrivateMetaData, addrController, block, name, args...)
+}
+
+func resolverLog(t *testing.T, block uint64, name string, args ...interface{}) types.Log {
+	return eventLog(t, ac.AnytypeResolverMetaData, addrResolver, block, name, args...)
+}
+
+func registryLog(t *testing.T, block uint64, name string, args ...interface{}) types.Log {
+	return eventLog(t, ac.ENSRegistryMetaData, addrRegistry, block, name, args...)
+}
+
+func nameWrapperLog(t *testing.T, block uint64, name string, args ...interface{}) types.Log {
+	return eventLog(t, ac.AnytypeNameWrapperMetaData, addrNameWrapper, block, name, args...)
+}
+
+func namehash(t *testing.T, name string) [32]byte {
+	nh, err := contracts.NameHash(name)
+	require.NoError(t, err)
+	return nh
+}
+
+// DNS wire format, as in NameWrapped
+func dnsName(labels ...string) []byte {
+	var out []byte
+	for _, l := range labels {
+		out = append(out, byte(len(l)))
+		out = append(out, l...)
+	}
+	return append(out, 0)
+}
+
+func blockHash(block uint64) common.Hash {
+	return crypto.Keccak256Hash(new(big.Int).SetUint64(block).Bytes())
+}
@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// WARNING: append only!
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create indexes",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, initialIndexes, dryRun)
+		},
+	},
+	{
+		Version:     2,
+		Description: "convert legacy addresses to lower case",
+		Up:          lowerCaseAddresses,
+	},
+	{
+		Version:     3,
+		Description: "index expiration date of names",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "cache", field: "name_expires"},
+			}, dryRun)
+		},
+	},
+	{
+		Version:     4,
+		Description: "index refresh time of names",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "cache", field: "last_refreshed"},
+			}, dryRun)
+		},
+	},
+	{
+		Version:     5,
+		Description: "index reverse records",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "reverse", field: "address", unique: true},
+				{collection: "reverse", field: "node"},
+			}, dryRun)
+		},
+	},
+	{
+		Version:     6,
+		Description: "index space IDs of names",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "cache", field: "space_id"},
+			}, dryRun)
+		},
+	},
+	{
+		Version:     7,
+		Description: "index dead queue items and retry time of queue items",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "queue-dead", field: "index", unique: true},
+				{collection: "queue", field: "nextAttemptAt"},
+			}, dryRun)
+		},
+	},
+	{
+		Version:     8,
+		Description: "index leases of queue items",
+		Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			return createIndexes(ctx, db, []index{
+				{collection: "queue", field: "leaseOwner"},
+				{collection: "queue", field: "leaseExpires"},
+			}, dryRun)
+		},
+	},
+}
+
+type index struct {
+	collection string
+	field      string
+	unique     bool
+}
+
+var initialIndexes = []index{
+	{collection: collectionName, field: "version", unique: true},
+
+	{collection: "cache", field: "name", unique: true},
+	{collection: "cache", field: "owner_scw_eth_address"},
+	{collection: "cache", field: "owner_eth_address"},
+	{collection: "cache", field: "owner_any_address"},
+	{collection: "cache", field: "final"},
+
+	{collection: "queue", field: "index", unique: true},
+	{collection: "queue", field: "status"},
+
+	{collection: "nonce", field: "address", unique: true},
+
+	{collection: "aa-users", field: "address", unique: true},
+
+	{collection: "aa-operations", field: "operation_id", unique: true},
+
+	{collection: "indexer", field: "name", unique: true},
+}
+
+func createIndexes(ctx context.Context, db *mongo.Database, indexes []index, dryRun bool) error {
+	for _, idx := range indexes {
+		coll := db.Collection(idx.collection)
+
+		// 1 - unique index can not be created if there are duplicates already
+		if idx.unique {
+			dups, err := countDuplicates(ctx, coll, idx.field)
+			if err != nil {
+				return err
+			}
+			if dups > 0 {
+				if !dryRun {
+					return fmt.Errorf("collection %s has %d duplicated values of %s, remove them first", idx.collection, dups, idx.field)
+				}
+				log.Warn("unique index can not be created: duplicated values found",
+					zap.String("collection", idx.collection),
+					zap.String("field", idx.field),
+					zap.Int64("duplicates", dups))
+			}
+		}
+
+		if dryRun {
+			log.Info("will create index",
+				zap.String("collection", idx.collection),
+				zap.String("field", idx.field),
+				zap.Bool("unique", idx.unique))
+			continue
+		}
+
+		// 2 - create it (no-op if the same index already exists)
+		_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: idx.field, Value: 1}},
+			Options: options.Index().SetUnique(idx.unique),
+		})
+		if err != nil {
+			log.Error("failed to create index", zap.String("collection", idx.collection), zap.String("field", idx.field), zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns number of values that are found in more than one document
+func countDuplicates(ctx context.Context, coll *mongo.Collection, field string) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + field},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+		{{Key: "$count", Value: "duplicates"}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var res []struct {
+		Duplicates int64 `bson:"duplicates"`
+	}
+	err = cursor.All(ctx, &res)
+	if err != nil || len(res) == 0 {
+		return 0, err
+	}
+	return res[0].Duplicates, nil
+}
+
+// some old entries were saved before addresses were converted to lower case
+// all lookups are done in lower case, so these entries were never found
+func lowerCaseAddresses(ctx context.Context, db *mongo.Database, dryRun bool) error {
+	fields := []struct {
+		collection string
+		names      []string
+	}{
+		{collection: "cache", names: []string{"owner_eth_address", "owner_scw_eth_address"}},
+		{collection: "aa-operations", names: []string{"owner_eth_address"}},
+	}
+
+	for _, f := range fields {
+		collection := f.collection
+		coll := db.Collection(collection)
+
+		for _, field := range f.names {
+			// only documents where field is not in lower case yet
+			filter := bson.D{{Key: "$expr", Value: bson.D{{Key: "$ne", Value: bson.A{
+				"$" + field,
+				bson.D{{Key: "$toLower", Value: "$" + field}},
+			}}}}}
+
+			if dryRun {
+				count, err := coll.CountDocuments(ctx, filter)
+				if err != nil {
+					return err
+				}
+				log.Info("will convert to lower case",
+					zap.String("collection", collection),
+					zap.String("field", field),
+					zap.Int64("documents", count))
+				continue
+			}
+
+			update := mongo.Pipeline{
+				{{Key: "$set", Value: bson.D{{Key: field, Value: bson.D{{Key: "$toLower", Value: "$" + field}}}}}},
+			}
+
+			res, err := coll.UpdateMany(ctx, filter, update)
+			if err != nil {
+				log.Error("failed to convert to lower case", zap.String("collection", collection), zap.String("field", field), zap.Error(err))
+				return err
+			}
+
+			log.Info("converted to lower case",
+				zap.String("collection", collection),
+				zap.String("field", field),
+				zap.Int64("documents", res.ModifiedCount))
+		}
+	}
+
+	return nil
+}
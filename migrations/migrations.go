@@ -0,0 +1,200 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+const CName = "any-ns.migrations"
+
+var log = logger.NewNamed(CName)
+
+// each applied migration is recorded here
+const collectionName = "migrations"
+
+type Migration struct {
+	// should be strictly increasing
+	// never change the version of the migration that was already released
+	Version     int
+	Description string
+
+	// if dryRun is true -> should not change anything, only log what would be done
+	// should be idempotent: several nodes that start at the same time can apply it
+	Up func(ctx context.Context, db *mongo.Database, dryRun bool) error
+}
+
+type AppliedMigration struct {
+	Version     int    `bson:"version"`
+	Description string `bson:"description"`
+	DateApplied int64  `bson:"dateApplied"`
+}
+
+func New() app.ComponentRunnable {
+	return &anynsMigrations{
+		migrations: migrations,
+	}
+}
+
+// Migrations are applied on startup before any other component starts using Mongo
+type MigrationService interface {
+	// returns the version of the last applied migration (0 if nothing was applied yet)
+	GetSchemaVersion(ctx context.Context) (int, error)
+	// will apply all pending migrations in order
+	// if dryRun is true -> nothing is changed, only the plan is logged
+	Migrate(ctx context.Context, dryRun bool) (applied []int, err error)
+
+	app.ComponentRunnable
+}
+
+type anynsMigrations struct {
+	confMongo config.Mongo
+
+	db         *mongo.Database
+	coll       *mongo.Collection
+	migrations []Migration
+}
+
+func (am *anynsMigrations) Name() (name string) {
+	return CName
+}
+
+func (am *anynsMigrations) Init(a *app.App) (err error) {
+	am.confMongo = a.MustComponent(config.CName).(*config.Config).Mongo
+
+	return validateMigrations(am.migrations)
+}
+
+func (am *anynsMigrations) Run(ctx context.Context) (err error) {
+	// 1 - connect to DB
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(am.confMongo.Connect))
+	if err != nil {
+		return err
+	}
+
+	am.db = client.Database(am.confMongo.Database)
+	am.coll = am.db.Collection(collectionName)
+	if am.coll == nil {
+		return errors.New("failed to connect to MongoDB")
+	}
+
+	log.Info("mongo connected!")
+
+	if am.confMongo.SkipMigrations {
+		log.Info("skipping migrations on startup")
+		return nil
+	}
+
+	// 2 - apply all pending migrations
+	_, err = am.Migrate(ctx, false)
+	return err
+}
+
+func (am *anynsMigrations) Close(ctx context.Context) (err error) {
+	if am.coll != nil {
+		err = am.coll.Database().Client().Disconnect(ctx)
+		am.coll = nil
+	}
+	return
+}
+
+func (am *anynsMigrations) GetSchemaVersion(ctx context.Context) (int, error) {
+	var last AppliedMigration
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	err := am.coll.FindOne(ctx, bson.D{}, opts).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		log.Error("failed to get schema version", zap.Error(err))
+		return 0, err
+	}
+
+	return last.Version, nil
+}
+
+func (am *anynsMigrations) Migrate(ctx context.Context, dryRun bool) (applied []int, err error) {
+	// 1 - get current version
+	current, err := am.GetSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := pendingMigrations(am.migrations, current)
+	if len(pending) == 0 {
+		log.Info("schema is up to date", zap.Int("version", current))
+		return nil, nil
+	}
+
+	log.Info("applying migrations",
+		zap.Int("current version", current),
+		zap.Int("pending", len(pending)),
+		zap.Bool("dry run", dryRun))
+
+	// 2 - apply them one by one
+	for _, m := range pending {
+		log.Info("applying migration", zap.Int("version", m.Version), zap.String("description", m.Description))
+
+		err = m.Up(ctx, am.db, dryRun)
+		if err != nil {
+			log.Error("migration failed", zap.Int("version", m.Version), zap.Error(err))
+			return applied, fmt.Errorf("migration %d failed: %w", m.Version, err)
+		}
+
+		if !dryRun {
+			// 3 - remember that migration was applied
+			_, err = am.coll.InsertOne(ctx, AppliedMigration{
+				Version:     m.Version,
+				Description: m.Description,
+				DateApplied: time.Now().Unix(),
+			})
+			if mongo.IsDuplicateKeyError(err) {
+				log.Info("migration was applied by another node", zap.Int("version", m.Version))
+				continue
+			}
+			if err != nil {
+				log.Error("failed to save migration", zap.Int("version", m.Version), zap.Error(err))
+				return applied, err
+			}
+		}
+
+		applied = append(applied, m.Version)
+	}
+
+	return applied, nil
+}
+
+func validateMigrations(list []Migration) error {
+	prev := 0
+	for _, m := range list {
+		if m.Version <= prev {
+			return fmt.Errorf("migration versions should be strictly increasing: %d after %d", m.Version, prev)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("migration %d has no Up function", m.Version)
+		}
+		prev = m.Version
+	}
+	return nil
+}
+
+// list should be sorted by version
+func pendingMigrations(list []Migration, current int) []Migration {
+	for i, m := range list {
+		if m.Version > current {
+			return list[i:]
+		}
+	}
+	return nil
+}
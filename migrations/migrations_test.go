@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebo/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func noop(ctx context.Context, db *mongo.Database, dryRun bool) error {
+	return nil
+}
+
+func TestMigrations_Validate(t *testing.T) {
+	t.Run("released list is valid", func(t *testing.T) {
+		assert.NoError(t, validateMigrations(migrations))
+	})
+
+	t.Run("fail if versions are not increasing", func(t *testing.T) {
+		list := []Migration{
+			{Version: 1, Up: noop},
+			{Version: 3, Up: noop},
+			{Version: 2, Up: noop},
+		}
+		assert.Error(t, validateMigrations(list))
+	})
+
+	t.Run("fail if version is duplicated", func(t *testing.T) {
+		list := []Migration{
+			{Version: 1, Up: noop},
+			{Version: 1, Up: noop},
+		}
+		assert.Error(t, validateMigrations(list))
+	})
+
+	t.Run("fail if no Up function", func(t *testing.T) {
+		list := []Migration{
+			{Version: 1},
+		}
+		assert.Error(t, validateMigrations(list))
+	})
+}
+
+func TestMigrations_Pending(t *testing.T) {
+	list := []Migration{
+		{Version: 1, Up: noop},
+		{Version: 2, Up: noop},
+		{Version: 5, Up: noop},
+	}
+
+	// 1 - nothing applied yet
+	assert.Equal(t, len(pendingMigrations(list, 0)), 3)
+
+	// 2 - some applied
+	pending := pendingMigrations(list, 2)
+	assert.Equal(t, len(pending), 1)
+	assert.Equal(t, pending[0].Version, 5)
+
+	// 3 - all applied
+	assert.Equal(t, len(pendingMigrations(list, 5)), 0)
+}
+
+func TestMigrations_MigrateConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	// TODO: mock Mongo!
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	assert.NoError(t, err)
+	db := client.Database("any-ns")
+	assert.NoError(t, db.Drop(ctx))
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	am := &anynsMigrations{db: db, coll: db.Collection(collectionName)}
+	assert.NoError(t, createIndexes(ctx, db, []index{{collection: collectionName, field: "version", unique: true}}, false))
+
+	// other node applies the same migration at the same time and saves it first
+	am.migrations = []Migration{
+		{Version: 1, Up: func(ctx context.Context, db *mongo.Database, dryRun bool) error {
+			_, err := am.coll.InsertOne(ctx, AppliedMigration{Version: 1})
+			return err
+		}},
+		{Version: 2, Up: noop},
+	}
+
+	applied, err := am.Migrate(ctx, false)
+	assert.NoError(t, err)
+	assert.DeepEqual(t, applied, []int{2})
+
+	version, err := am.GetSchemaVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, version, 2)
+}
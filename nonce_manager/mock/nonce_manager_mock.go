@@ -5,10 +5,12 @@
 //
 //	mockgen -source=nonce_manager/nonce_manager.go
 //
+
 // Package mock_nonce_manager is a generated GoMock package.
 package mock_nonce_manager
 
 import (
+	context "context"
 	reflect "reflect"
 
 	app "github.com/anyproto/any-sync/app"
@@ -39,34 +41,49 @@ func (m *MockNonceService) EXPECT() *MockNonceServiceMockRecorder {
 	return m.recorder
 }
 
+// AllocateNonce mocks base method.
+func (m *MockNonceService) AllocateNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllocateNonce", ctx, addr)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllocateNonce indicates an expected call of AllocateNonce.
+func (mr *MockNonceServiceMockRecorder) AllocateNonce(ctx, addr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocateNonce", reflect.TypeOf((*MockNonceService)(nil).AllocateNonce), ctx, addr)
+}
+
 // GetCurrentNonce mocks base method.
-func (m *MockNonceService) GetCurrentNonce(addr common.Address) (uint64, error) {
+func (m *MockNonceService) GetCurrentNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCurrentNonce", addr)
+	ret := m.ctrl.Call(m, "GetCurrentNonce", ctx, addr)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCurrentNonce indicates an expected call of GetCurrentNonce.
-func (mr *MockNonceServiceMockRecorder) GetCurrentNonce(addr any) *gomock.Call {
+func (mr *MockNonceServiceMockRecorder) GetCurrentNonce(ctx, addr any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNonce", reflect.TypeOf((*MockNonceService)(nil).GetCurrentNonce), addr)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNonce", reflect.TypeOf((*MockNonceService)(nil).GetCurrentNonce), ctx, addr)
 }
 
 // GetCurrentNonceFromNetwork mocks base method.
-func (m *MockNonceService) GetCurrentNonceFromNetwork(addr common.Address) (uint64, error) {
+func (m *MockNonceService) GetCurrentNonceFromNetwork(ctx context.Context, addr common.Address) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCurrentNonceFromNetwork", addr)
+	ret := m.ctrl.Call(m, "GetCurrentNonceFromNetwork", ctx, addr)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCurrentNonceFromNetwork indicates an expected call of GetCurrentNonceFromNetwork.
-func (mr *MockNonceServiceMockRecorder) GetCurrentNonceFromNetwork(addr any) *gomock.Call {
+func (mr *MockNonceServiceMockRecorder) GetCurrentNonceFromNetwork(ctx, addr any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNonceFromNetwork", reflect.TypeOf((*MockNonceService)(nil).GetCurrentNonceFromNetwork), addr)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNonceFromNetwork", reflect.TypeOf((*MockNonceService)(nil).GetCurrentNonceFromNetwork), ctx, addr)
 }
 
 // Init mocks base method.
@@ -97,17 +114,31 @@ func (mr *MockNonceServiceMockRecorder) Name() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockNonceService)(nil).Name))
 }
 
+// ReleaseNonce mocks base method.
+func (m *MockNonceService) ReleaseNonce(ctx context.Context, addr common.Address, nonce uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseNonce", ctx, addr, nonce)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseNonce indicates an expected call of ReleaseNonce.
+func (mr *MockNonceServiceMockRecorder) ReleaseNonce(ctx, addr, nonce any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseNonce", reflect.TypeOf((*MockNonceService)(nil).ReleaseNonce), ctx, addr, nonce)
+}
+
 // SaveNonce mocks base method.
-func (m *MockNonceService) SaveNonce(addr common.Address, newValue uint64) (uint64, error) {
+func (m *MockNonceService) SaveNonce(ctx context.Context, addr common.Address, newValue uint64) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveNonce", addr, newValue)
+	ret := m.ctrl.Call(m, "SaveNonce", ctx, addr, newValue)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveNonce indicates an expected call of SaveNonce.
-func (mr *MockNonceServiceMockRecorder) SaveNonce(addr, newValue any) *gomock.Call {
+func (mr *MockNonceServiceMockRecorder) SaveNonce(ctx, addr, newValue any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNonce", reflect.TypeOf((*MockNonceService)(nil).SaveNonce), addr, newValue)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNonce", reflect.TypeOf((*MockNonceService)(nil).SaveNonce), ctx, addr, newValue)
 }
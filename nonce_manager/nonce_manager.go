@@ -10,6 +10,7 @@ import (
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
@@ -19,7 +20,6 @@ const CName = "any-ns.nonce-manager"
 
 var log = logger.NewNamed(CName)
 
-// TODO: index it
 type NonceDbItem struct {
 	Address string `bson:"address"`
 	Nonce   int64  `bson:"nonce"`
@@ -43,8 +43,8 @@ func New() app.Component {
 // 3. if nonce is not in DB:
 // - get nonce from network - mined + pending txs count
 //
-// if tx is sent and mined succesfully:
-// - save last nonce to DB
+// each tx is sent with the nonce from AllocateNonce:
+// - nonce is incremented in DB atomically, so several nodes never send txs with the same nonce
 //
 // if we got "nonce is too low" error the tx is immediately rejected. to fix it:
 // - get nonce from network
@@ -57,14 +57,21 @@ func New() app.Component {
 // - retry sending this tx with new nonce
 type NonceService interface {
 	// try to determine nonce by looking in DB first, then use network as a fallback
-	GetCurrentNonce(addr ethcommon.Address) (uint64, error)
+	GetCurrentNonce(ctx context.Context, addr ethcommon.Address) (uint64, error)
+
+	// reserve the nonce for the next tx (see Nonce policy)
+	// if nonce is not in DB yet, it is read from the network first
+	AllocateNonce(ctx context.Context, addr ethcommon.Address) (uint64, error)
+	// return the nonce if tx with it was not sent
+	// it is returned only if no other nonce was allocated after it, otherwise it is a gap (see "nonce is higher than needed")
+	ReleaseNonce(ctx context.Context, addr ethcommon.Address, nonce uint64) error
 
 	// try to determine nonce by looking at current TX count plus pending TXs in the mem pool
 	// (not reliable, but can be used as a fallback)
-	GetCurrentNonceFromNetwork(addr ethcommon.Address) (uint64, error)
+	GetCurrentNonceFromNetwork(ctx context.Context, addr ethcommon.Address) (uint64, error)
 
-	// save nonce to DB
-	SaveNonce(addr ethcommon.Address, newValue uint64) (uint64, error)
+	// save nonce to DB (next AllocateNonce returns it)
+	SaveNonce(ctx context.Context, addr ethcommon.Address, newValue uint64) (uint64, error)
 
 	app.Component
 }
@@ -111,7 +118,7 @@ func (anonce *anynsNonceService) Init(a *app.App) (err error) {
 	return nil
 }
 
-func (anonce *anynsNonceService) GetCurrentNonce(addr ethcommon.Address) (uint64, error) {
+func (anonce *anynsNonceService) GetCurrentNonce(ctx context.Context, addr ethcommon.Address) (uint64, error) {
 	// 1 - if nonce is specified in the config file:
 	// - read it from config and override the value from DB/network
 	if anonce.confNonce.NonceOverride > 0 {
@@ -123,7 +130,6 @@ func (anonce *anynsNonceService) GetCurrentNonce(addr ethcommon.Address) (uint64
 
 	// 2 - if nonce is in DB:
 	// - get nonce from DB
-	ctx := context.Background()
 	err := anonce.nonceColl.FindOne(ctx, findNonceByAddress{Address: addr.Hex()}).Decode(&itemOut)
 	if err == nil {
 		// Warning: convert int64 -> uint64
@@ -131,19 +137,73 @@ func (anonce *anynsNonceService) GetCurrentNonce(addr ethcommon.Address) (uint64
 	}
 
 	// 3 - if nonce is not in DB:
-	return anonce.GetCurrentNonceFromNetwork(addr)
+	return anonce.GetCurrentNonceFromNetwork(ctx, addr)
+}
+
+func (anonce *anynsNonceService) AllocateNonce(ctx context.Context, addr ethcommon.Address) (uint64, error) {
+	if anonce.confNonce.NonceOverride > 0 {
+		return anonce.confNonce.NonceOverride, nil
+	}
+
+	for {
+		// 1 - take the nonce and increment it in one operation
+		itemOut := &NonceDbItem{}
+		err := anonce.nonceColl.FindOneAndUpdate(ctx,
+			findNonceByAddress{Address: addr.Hex()},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "nonce", Value: 1}}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.Before)).Decode(itemOut)
+		if err == nil {
+			// Warning: convert int64 -> uint64
+			return uint64(itemOut.Nonce), nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Error("failed to allocate nonce", zap.Error(err))
+			return 0, err
+		}
+
+		// 2 - nonce is not in DB yet, start from the network
+		// other node can do the same at the same time, $max keeps the highest value
+		// and the address is unique (see migrations), so only one document is created
+		nonce, err := anonce.GetCurrentNonceFromNetwork(ctx, addr)
+		if err != nil {
+			return 0, err
+		}
+		_, err = anonce.nonceColl.UpdateOne(ctx,
+			findNonceByAddress{Address: addr.Hex()},
+			bson.D{{Key: "$max", Value: bson.D{{Key: "nonce", Value: int64(nonce)}}}},
+			options.Update().SetUpsert(true))
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Error("failed to save nonce to DB", zap.Error(err))
+			return 0, err
+		}
+	}
 }
 
-func (anonce *anynsNonceService) GetCurrentNonceFromNetwork(addr ethcommon.Address) (uint64, error) {
+func (anonce *anynsNonceService) ReleaseNonce(ctx context.Context, addr ethcommon.Address, nonce uint64) error {
+	if anonce.confNonce.NonceOverride > 0 {
+		return nil
+	}
+
+	_, err := anonce.nonceColl.UpdateOne(ctx,
+		bson.D{{Key: "address", Value: addr.Hex()}, {Key: "nonce", Value: int64(nonce) + 1}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "nonce", Value: int64(nonce)}}}})
+	if err != nil {
+		log.Error("failed to release nonce", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (anonce *anynsNonceService) GetCurrentNonceFromNetwork(ctx context.Context, addr ethcommon.Address) (uint64, error) {
 	// - get nonce from network - mined + pending txs count
-	conn, err := anonce.contracts.CreateEthConnection()
+	conn, err := anonce.contracts.CreateEthConnection(ctx)
 	if err != nil {
 		log.Error("can not create eth connection", zap.Error(err))
 		return 0, err
 	}
 
 	// 2 - get gas costs, etc
-	_, nonce, err := anonce.contracts.CalculateTxParams(conn, addr)
+	_, nonce, err := anonce.contracts.CalculateTxParams(ctx, conn, addr)
 	if err != nil {
 		log.Error("can not get nonce", zap.Error(err))
 		return 0, err
@@ -151,9 +211,8 @@ func (anonce *anynsNonceService) GetCurrentNonceFromNetwork(addr ethcommon.Addre
 	return nonce, nil
 }
 
-// call this method when tx is sent and mined succesfully
-func (anonce *anynsNonceService) SaveNonce(addr ethcommon.Address, newValue uint64) (uint64, error) {
-	ctx := context.Background()
+// overwrites the nonce, i.e. to start from the network nonce again if it is too high
+func (anonce *anynsNonceService) SaveNonce(ctx context.Context, addr ethcommon.Address, newValue uint64) (uint64, error) {
 	optns := options.Replace().SetUpsert(true)
 
 	dbItem := &NonceDbItem{
@@ -3,6 +3,7 @@ package nonce_manager
 import (
 	"context"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
@@ -43,7 +44,7 @@ func TestNonceManager_GetCurrentNonce(t *testing.T) {
 		require.NoError(t, err)
 
 		// get from DB
-		nonce, err := fx.GetCurrentNonce(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
+		nonce, err := fx.GetCurrentNonce(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
 		require.NoError(t, err)
 		require.Equal(t, uint64(12), nonce)
 	})
@@ -52,13 +53,13 @@ func TestNonceManager_GetCurrentNonce(t *testing.T) {
 		fx := newFixture(t, 0)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (*big.Int, uint64, error) {
+		fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}) (*big.Int, uint64, error) {
 			// return "nonce from network"
 			return nil, 15, nil
 		})
 
 		// get from DB
-		nonce, err := fx.GetCurrentNonce(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
+		nonce, err := fx.GetCurrentNonce(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
 		require.NoError(t, err)
 		require.Equal(t, uint64(15), nonce)
 	})
@@ -68,7 +69,7 @@ func TestNonceManager_GetCurrentNonce(t *testing.T) {
 		defer fx.finish(t)
 
 		// get from DB
-		nonce, err := fx.GetCurrentNonce(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
+		nonce, err := fx.GetCurrentNonce(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
 		require.NoError(t, err)
 		require.Equal(t, uint64(19), nonce)
 	})
@@ -79,13 +80,13 @@ func TestNonceManager_GetCurrentNonceFromNetwork(t *testing.T) {
 		fx := newFixture(t, 20)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (*big.Int, uint64, error) {
+		fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}) (*big.Int, uint64, error) {
 			// return "nonce from network"
 			return nil, 15, nil
 		})
 
 		// get from DB
-		nonce, err := fx.GetCurrentNonceFromNetwork(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
+		nonce, err := fx.GetCurrentNonceFromNetwork(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
 		require.NoError(t, err)
 		require.Equal(t, uint64(15), nonce)
 	})
@@ -103,13 +104,13 @@ func TestNonceManager_SaveNonce(t *testing.T) {
 		coll := client.Database("any-ns").Collection("nonce")
 
 		// get from DB
-		newNonce, err := fx.SaveNonce(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"), 18)
+		newNonce, err := fx.SaveNonce(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"), 18)
 		require.NoError(t, err)
 		require.Equal(t, uint64(18), newNonce)
 
 		// get from DB
 		// convert string to ethcommon.Address
-		nonce, err := fx.GetCurrentNonce(common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
+		nonce, err := fx.GetCurrentNonce(ctx, common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51"))
 		require.NoError(t, err)
 		require.Equal(t, uint64(18), nonce)
 
@@ -125,6 +126,81 @@ func TestNonceManager_SaveNonce(t *testing.T) {
 	})
 }
 
+func TestNonceManager_AllocateNonce(t *testing.T) {
+	addr := common.HexToAddress("0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51")
+
+	t.Run("start from network if not present in DB", func(t *testing.T) {
+		fx := newFixture(t, 0)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, uint64(15), nil)
+
+		nonce, err := fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(15), nonce)
+
+		// next one is from DB
+		nonce, err = fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(16), nonce)
+	})
+
+	t.Run("concurrent callers get different nonces", func(t *testing.T) {
+		fx := newFixture(t, 0)
+		defer fx.finish(t)
+
+		_, err := fx.SaveNonce(ctx, addr, 10)
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		nonces := make(map[uint64]bool)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				nonce, err := fx.AllocateNonce(ctx, addr)
+				assert.NoError(t, err)
+
+				mu.Lock()
+				nonces[nonce] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		require.Len(t, nonces, 20)
+		for nonce := uint64(10); nonce < 30; nonce++ {
+			require.True(t, nonces[nonce])
+		}
+	})
+
+	t.Run("release nonce of the tx that was not sent", func(t *testing.T) {
+		fx := newFixture(t, 0)
+		defer fx.finish(t)
+
+		_, err := fx.SaveNonce(ctx, addr, 10)
+		require.NoError(t, err)
+
+		first, err := fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.NoError(t, fx.ReleaseNonce(ctx, addr, first))
+
+		nonce, err := fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.Equal(t, first, nonce)
+
+		// can not be released if the next nonce is already allocated
+		_, err = fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.NoError(t, fx.ReleaseNonce(ctx, addr, nonce))
+
+		nonce, err = fx.AllocateNonce(ctx, addr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(12), nonce)
+	})
+}
+
 type fixture struct {
 	a         *app.App
 	ctrl      *gomock.Controller
@@ -157,16 +233,16 @@ func newFixture(t *testing.T, nonceOverride uint64) *fixture {
 	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
 	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
 	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-	fx.contracts.EXPECT().GenerateAuthOptsForAdmin().MaxTimes(2)
+	fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GenerateAuthOptsForAdmin(gomock.Any()).MaxTimes(2)
 	fx.contracts.EXPECT().ConnectToPrivateController().AnyTimes()
 	fx.contracts.EXPECT().TxByHash(gomock.Any(), gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().MakeCommitment(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().MakeCommitment(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().WaitForTxToStartMining(gomock.Any(), gomock.Any()).AnyTimes()
 
 	fx.config.Contracts = config.Contracts{
 		AddrAdmin: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
-		GethUrl:   "xxx",
+		GethUrl:   config.Urls{"xxx"},
 	}
 
 	fx.a.Register(fx.ts).
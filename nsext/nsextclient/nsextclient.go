@@ -0,0 +1,186 @@
+package nsextclient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/net/pool"
+	"github.com/anyproto/any-sync/nodeconf"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/nsext/nsextproto"
+)
+
+const CName = "any-ns.nsextclient"
+
+var log = logger.NewNamed(CName)
+
+// Client for the methods that are not available in the nameserviceclient of any-sync
+type AnyNsExtClientService interface {
+	// returns all names owned by AnyID, owner EOA or SCW (one page)
+	GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (out *nsextproto.NamesByOwnerResponse, err error)
+	GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (out *nsextproto.NameBySpaceIdResponse, err error)
+	BatchGetNameBySpaceId(ctx context.Context, in *nsextproto.BatchNameBySpaceIdRequest) (out *nsextproto.BatchNameBySpaceIdResponse, err error)
+	// reads name data from smart contracts as it was at some block (for audits)
+	GetNameAtBlock(ctx context.Context, in *nsextproto.NameAtBlockRequest) (out *nsextproto.NameAtBlockResponse, err error)
+
+	// admin only: items of the queue that have failed
+	AdminQueueGetDeadItems(ctx context.Context, in *nsextproto.DeadQueueItemsRequest) (out *nsextproto.DeadQueueItemsResponse, err error)
+	AdminQueueRequeueDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (out *nsextproto.DeadQueueItemResponse, err error)
+	AdminQueueAbandonDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (out *nsextproto.DeadQueueItemResponse, err error)
+	// admin only: inspect and control the items of the queue
+	AdminQueueGetItems(ctx context.Context, in *nsextproto.QueueItemsRequest) (out *nsextproto.QueueItemsResponse, err error)
+	AdminQueueGetItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error)
+	AdminQueueCancelItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error)
+	AdminQueueAdvanceItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error)
+	AdminQueueRequeueItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error)
+	AdminQueueGetCounts(ctx context.Context, in *nsextproto.QueueCountsRequest) (out *nsextproto.QueueCountsResponse, err error)
+
+	app.Component
+}
+
+type service struct {
+	pool     pool.Pool
+	nodeconf nodeconf.Service
+}
+
+func New() AnyNsExtClientService {
+	return new(service)
+}
+
+func (s *service) Init(a *app.App) (err error) {
+	s.pool = a.MustComponent(pool.CName).(pool.Pool)
+	s.nodeconf = a.MustComponent(nodeconf.CName).(nodeconf.Service)
+	return nil
+}
+
+func (s *service) Name() (name string) {
+	return CName
+}
+
+func (s *service) doClient(ctx context.Context, fn func(cl nsextproto.DRPCAnynsExtClient) error) error {
+	if len(s.nodeconf.NamingNodePeers()) == 0 {
+		return errors.New("no namingNode peers configured. Node config ID: " + s.nodeconf.Id())
+	}
+
+	peer, err := s.pool.GetOneOf(ctx, s.nodeconf.NamingNodePeers())
+	if err != nil {
+		log.Error("failed to get a namingnode peer", zap.Error(err))
+		return err
+	}
+
+	dc, err := peer.AcquireDrpcConn(ctx)
+	if err != nil {
+		log.Error("failed to acquire a DRPC connection to namingnode", zap.Error(err))
+		return err
+	}
+	defer peer.ReleaseDrpcConn(ctx, dc)
+
+	return fn(nsextproto.NewDRPCAnynsExtClient(dc))
+}
+
+func (s *service) GetNamesByOwner(ctx context.Context, in *nsextproto.NamesByOwnerRequest) (out *nsextproto.NamesByOwnerResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.GetNamesByOwner(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) GetNameBySpaceId(ctx context.Context, in *nsextproto.NameBySpaceIdRequest) (out *nsextproto.NameBySpaceIdResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.GetNameBySpaceId(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) BatchGetNameBySpaceId(ctx context.Context, in *nsextproto.BatchNameBySpaceIdRequest) (out *nsextproto.BatchNameBySpaceIdResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.BatchGetNameBySpaceId(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) GetNameAtBlock(ctx context.Context, in *nsextproto.NameAtBlockRequest) (out *nsextproto.NameAtBlockResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.GetNameAtBlock(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueGetDeadItems(ctx context.Context, in *nsextproto.DeadQueueItemsRequest) (out *nsextproto.DeadQueueItemsResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueGetDeadItems(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueRequeueDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (out *nsextproto.DeadQueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueRequeueDeadItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueAbandonDeadItem(ctx context.Context, in *nsextproto.DeadQueueItemRequest) (out *nsextproto.DeadQueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueAbandonDeadItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueGetItems(ctx context.Context, in *nsextproto.QueueItemsRequest) (out *nsextproto.QueueItemsResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueGetItems(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueGetItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueGetItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueCancelItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueCancelItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueAdvanceItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueAdvanceItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueRequeueItem(ctx context.Context, in *nsextproto.QueueItemRequest) (out *nsextproto.QueueItemResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueRequeueItem(ctx, in)
+		return err
+	})
+	return
+}
+
+func (s *service) AdminQueueGetCounts(ctx context.Context, in *nsextproto.QueueCountsRequest) (out *nsextproto.QueueCountsResponse, err error) {
+	err = s.doClient(ctx, func(cl nsextproto.DRPCAnynsExtClient) error {
+		out, err = cl.AdminQueueGetCounts(ctx, in)
+		return err
+	})
+	return
+}
@@ -0,0 +1,1721 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: nsext/nsextproto/protos/nsext.proto
+
+package nsextproto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// exactly one of the owner fields should be set
+type NamesByOwnerRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AnyAddress         string                 `protobuf:"bytes,1,opt,name=anyAddress,proto3" json:"anyAddress,omitempty"`
+	OwnerEthAddress    string                 `protobuf:"bytes,2,opt,name=ownerEthAddress,proto3" json:"ownerEthAddress,omitempty"`
+	OwnerScwEthAddress string                 `protobuf:"bytes,3,opt,name=ownerScwEthAddress,proto3" json:"ownerScwEthAddress,omitempty"`
+	// empty for the first page, then nextCursor of the previous response
+	Cursor string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// 0 means default page size
+	Limit         uint32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NamesByOwnerRequest) Reset() {
+	*x = NamesByOwnerRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamesByOwnerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamesByOwnerRequest) ProtoMessage() {}
+
+func (x *NamesByOwnerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamesByOwnerRequest.ProtoReflect.Descriptor instead.
+func (*NamesByOwnerRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NamesByOwnerRequest) GetAnyAddress() string {
+	if x != nil {
+		return x.AnyAddress
+	}
+	return ""
+}
+
+func (x *NamesByOwnerRequest) GetOwnerEthAddress() string {
+	if x != nil {
+		return x.OwnerEthAddress
+	}
+	return ""
+}
+
+func (x *NamesByOwnerRequest) GetOwnerScwEthAddress() string {
+	if x != nil {
+		return x.OwnerScwEthAddress
+	}
+	return ""
+}
+
+func (x *NamesByOwnerRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *NamesByOwnerRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type OwnedName struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	FullName    string                 `protobuf:"bytes,1,opt,name=fullName,proto3" json:"fullName,omitempty"`
+	NameExpires int64                  `protobuf:"varint,2,opt,name=nameExpires,proto3" json:"nameExpires,omitempty"`
+	SpaceId     string                 `protobuf:"bytes,3,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	// true if this name is returned by the reverse lookup for the owner
+	IsPrimary     bool `protobuf:"varint,4,opt,name=isPrimary,proto3" json:"isPrimary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OwnedName) Reset() {
+	*x = OwnedName{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OwnedName) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OwnedName) ProtoMessage() {}
+
+func (x *OwnedName) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OwnedName.ProtoReflect.Descriptor instead.
+func (*OwnedName) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OwnedName) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *OwnedName) GetNameExpires() int64 {
+	if x != nil {
+		return x.NameExpires
+	}
+	return 0
+}
+
+func (x *OwnedName) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *OwnedName) GetIsPrimary() bool {
+	if x != nil {
+		return x.IsPrimary
+	}
+	return false
+}
+
+type NamesByOwnerResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// sorted by name
+	Names []*OwnedName `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	// empty if this is the last page
+	NextCursor    string `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NamesByOwnerResponse) Reset() {
+	*x = NamesByOwnerResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamesByOwnerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamesByOwnerResponse) ProtoMessage() {}
+
+func (x *NamesByOwnerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamesByOwnerResponse.ProtoReflect.Descriptor instead.
+func (*NamesByOwnerResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NamesByOwnerResponse) GetNames() []*OwnedName {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *NamesByOwnerResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type NameBySpaceIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId       string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameBySpaceIdRequest) Reset() {
+	*x = NameBySpaceIdRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameBySpaceIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameBySpaceIdRequest) ProtoMessage() {}
+
+func (x *NameBySpaceIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameBySpaceIdRequest.ProtoReflect.Descriptor instead.
+func (*NameBySpaceIdRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NameBySpaceIdRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+type NameBySpaceIdResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameBySpaceIdResponse) Reset() {
+	*x = NameBySpaceIdResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameBySpaceIdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameBySpaceIdResponse) ProtoMessage() {}
+
+func (x *NameBySpaceIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameBySpaceIdResponse.ProtoReflect.Descriptor instead.
+func (*NameBySpaceIdResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NameBySpaceIdResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *NameBySpaceIdResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type BatchNameBySpaceIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpaceIds      []string               `protobuf:"bytes,1,rep,name=spaceIds,proto3" json:"spaceIds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchNameBySpaceIdRequest) Reset() {
+	*x = BatchNameBySpaceIdRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchNameBySpaceIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchNameBySpaceIdRequest) ProtoMessage() {}
+
+func (x *BatchNameBySpaceIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchNameBySpaceIdRequest.ProtoReflect.Descriptor instead.
+func (*BatchNameBySpaceIdRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchNameBySpaceIdRequest) GetSpaceIds() []string {
+	if x != nil {
+		return x.SpaceIds
+	}
+	return nil
+}
+
+type BatchNameBySpaceIdResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// in the same order as spaceIds
+	Results       []*NameBySpaceIdResponse `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchNameBySpaceIdResponse) Reset() {
+	*x = BatchNameBySpaceIdResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchNameBySpaceIdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchNameBySpaceIdResponse) ProtoMessage() {}
+
+func (x *BatchNameBySpaceIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchNameBySpaceIdResponse.ProtoReflect.Descriptor instead.
+func (*BatchNameBySpaceIdResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchNameBySpaceIdResponse) GetResults() []*NameBySpaceIdResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// name data as it was at some block (read directly from smart contracts, not from the cache)
+type NameAtBlockRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	FullName string                 `protobuf:"bytes,1,opt,name=fullName,proto3" json:"fullName,omitempty"`
+	// 0 means the latest block
+	BlockNumber uint64 `protobuf:"varint,2,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	// if set - data is read exactly from this block (must be the block with blockNumber)
+	BlockHash     string `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameAtBlockRequest) Reset() {
+	*x = NameAtBlockRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameAtBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameAtBlockRequest) ProtoMessage() {}
+
+func (x *NameAtBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameAtBlockRequest.ProtoReflect.Descriptor instead.
+func (*NameAtBlockRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NameAtBlockRequest) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *NameAtBlockRequest) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *NameAtBlockRequest) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+type NameAtBlockResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Available          bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`
+	OwnerScwEthAddress string                 `protobuf:"bytes,2,opt,name=ownerScwEthAddress,proto3" json:"ownerScwEthAddress,omitempty"`
+	OwnerEthAddress    string                 `protobuf:"bytes,3,opt,name=ownerEthAddress,proto3" json:"ownerEthAddress,omitempty"`
+	OwnerAnyAddress    string                 `protobuf:"bytes,4,opt,name=ownerAnyAddress,proto3" json:"ownerAnyAddress,omitempty"`
+	SpaceId            string                 `protobuf:"bytes,5,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	NameExpires        int64                  `protobuf:"varint,6,opt,name=nameExpires,proto3" json:"nameExpires,omitempty"`
+	// block that all values were read at
+	BlockNumber   uint64 `protobuf:"varint,7,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	BlockHash     string `protobuf:"bytes,8,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameAtBlockResponse) Reset() {
+	*x = NameAtBlockResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameAtBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameAtBlockResponse) ProtoMessage() {}
+
+func (x *NameAtBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameAtBlockResponse.ProtoReflect.Descriptor instead.
+func (*NameAtBlockResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NameAtBlockResponse) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *NameAtBlockResponse) GetOwnerScwEthAddress() string {
+	if x != nil {
+		return x.OwnerScwEthAddress
+	}
+	return ""
+}
+
+func (x *NameAtBlockResponse) GetOwnerEthAddress() string {
+	if x != nil {
+		return x.OwnerEthAddress
+	}
+	return ""
+}
+
+func (x *NameAtBlockResponse) GetOwnerAnyAddress() string {
+	if x != nil {
+		return x.OwnerAnyAddress
+	}
+	return ""
+}
+
+func (x *NameAtBlockResponse) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *NameAtBlockResponse) GetNameExpires() int64 {
+	if x != nil {
+		return x.NameExpires
+	}
+	return 0
+}
+
+func (x *NameAtBlockResponse) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *NameAtBlockResponse) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+// admin only: items of the queue that have failed and are not processed anymore
+type DeadQueueItemsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 means default limit
+	Limit         uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadQueueItemsRequest) Reset() {
+	*x = DeadQueueItemsRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadQueueItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadQueueItemsRequest) ProtoMessage() {}
+
+func (x *DeadQueueItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadQueueItemsRequest.ProtoReflect.Descriptor instead.
+func (*DeadQueueItemsRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeadQueueItemsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type QueueItemAttempt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// state that has failed
+	State         string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	ErrorCode     string `protobuf:"bytes,2,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	ErrorMessage  string `protobuf:"bytes,3,opt,name=errorMessage,proto3" json:"errorMessage,omitempty"`
+	Permanent     bool   `protobuf:"varint,4,opt,name=permanent,proto3" json:"permanent,omitempty"`
+	Date          int64  `protobuf:"varint,5,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemAttempt) Reset() {
+	*x = QueueItemAttempt{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemAttempt) ProtoMessage() {}
+
+func (x *QueueItemAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemAttempt.ProtoReflect.Descriptor instead.
+func (*QueueItemAttempt) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QueueItemAttempt) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *QueueItemAttempt) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *QueueItemAttempt) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *QueueItemAttempt) GetPermanent() bool {
+	if x != nil {
+		return x.Permanent
+	}
+	return false
+}
+
+func (x *QueueItemAttempt) GetDate() int64 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+type DeadQueueItem struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Index    int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	ItemType string                 `protobuf:"bytes,2,opt,name=itemType,proto3" json:"itemType,omitempty"`
+	FullName string                 `protobuf:"bytes,3,opt,name=fullName,proto3" json:"fullName,omitempty"`
+	// item is processed from this state again if it is requeued
+	FailedState string `protobuf:"bytes,4,opt,name=failedState,proto3" json:"failedState,omitempty"`
+	// current state of the item in the queue
+	State         string              `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	ErrorCode     string              `protobuf:"bytes,6,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	ErrorMessage  string              `protobuf:"bytes,7,opt,name=errorMessage,proto3" json:"errorMessage,omitempty"`
+	Attempts      []*QueueItemAttempt `protobuf:"bytes,8,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	DateCreated   int64               `protobuf:"varint,9,opt,name=dateCreated,proto3" json:"dateCreated,omitempty"`
+	DateDied      int64               `protobuf:"varint,10,opt,name=dateDied,proto3" json:"dateDied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadQueueItem) Reset() {
+	*x = DeadQueueItem{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadQueueItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadQueueItem) ProtoMessage() {}
+
+func (x *DeadQueueItem) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadQueueItem.ProtoReflect.Descriptor instead.
+func (*DeadQueueItem) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeadQueueItem) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *DeadQueueItem) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetFailedState() string {
+	if x != nil {
+		return x.FailedState
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *DeadQueueItem) GetAttempts() []*QueueItemAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+func (x *DeadQueueItem) GetDateCreated() int64 {
+	if x != nil {
+		return x.DateCreated
+	}
+	return 0
+}
+
+func (x *DeadQueueItem) GetDateDied() int64 {
+	if x != nil {
+		return x.DateDied
+	}
+	return 0
+}
+
+type DeadQueueItemsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// newest first
+	Items         []*DeadQueueItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadQueueItemsResponse) Reset() {
+	*x = DeadQueueItemsResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadQueueItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadQueueItemsResponse) ProtoMessage() {}
+
+func (x *DeadQueueItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadQueueItemsResponse.ProtoReflect.Descriptor instead.
+func (*DeadQueueItemsResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeadQueueItemsResponse) GetItems() []*DeadQueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// admin only: requeue or abandon the dead item
+type DeadQueueItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadQueueItemRequest) Reset() {
+	*x = DeadQueueItemRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadQueueItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadQueueItemRequest) ProtoMessage() {}
+
+func (x *DeadQueueItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadQueueItemRequest.ProtoReflect.Descriptor instead.
+func (*DeadQueueItemRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeadQueueItemRequest) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type DeadQueueItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadQueueItemResponse) Reset() {
+	*x = DeadQueueItemResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadQueueItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadQueueItemResponse) ProtoMessage() {}
+
+func (x *DeadQueueItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadQueueItemResponse.ProtoReflect.Descriptor instead.
+func (*DeadQueueItemResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeadQueueItemResponse) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+// admin only: items of the queue
+// all statuses if empty: initial, commitSent, commitDone, registerSent, renewSent, completed,
+// commitError, registerError, renewError, error, cancelled
+type QueueItemsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Statuses []string               `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	// only items that were created at least minAgeSec seconds ago
+	MinAgeSec int64 `protobuf:"varint,2,opt,name=minAgeSec,proto3" json:"minAgeSec,omitempty"`
+	// 0 means default limit
+	Limit         uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemsRequest) Reset() {
+	*x = QueueItemsRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemsRequest) ProtoMessage() {}
+
+func (x *QueueItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemsRequest.ProtoReflect.Descriptor instead.
+func (*QueueItemsRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QueueItemsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *QueueItemsRequest) GetMinAgeSec() int64 {
+	if x != nil {
+		return x.MinAgeSec
+	}
+	return 0
+}
+
+func (x *QueueItemsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type QueueItem struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Index                int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	ItemType             string                 `protobuf:"bytes,2,opt,name=itemType,proto3" json:"itemType,omitempty"`
+	State                string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	FullName             string                 `protobuf:"bytes,4,opt,name=fullName,proto3" json:"fullName,omitempty"`
+	OwnerAnyAddress      string                 `protobuf:"bytes,5,opt,name=ownerAnyAddress,proto3" json:"ownerAnyAddress,omitempty"`
+	OwnerEthAddress      string                 `protobuf:"bytes,6,opt,name=ownerEthAddress,proto3" json:"ownerEthAddress,omitempty"`
+	SpaceId              string                 `protobuf:"bytes,7,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	RegisterPeriodMonths uint32                 `protobuf:"varint,8,opt,name=registerPeriodMonths,proto3" json:"registerPeriodMonths,omitempty"`
+	TxCommitHash         string                 `protobuf:"bytes,9,opt,name=txCommitHash,proto3" json:"txCommitHash,omitempty"`
+	TxCommitNonce        uint64                 `protobuf:"varint,10,opt,name=txCommitNonce,proto3" json:"txCommitNonce,omitempty"`
+	TxRegisterHash       string                 `protobuf:"bytes,11,opt,name=txRegisterHash,proto3" json:"txRegisterHash,omitempty"`
+	TxRegisterNonce      uint64                 `protobuf:"varint,12,opt,name=txRegisterNonce,proto3" json:"txRegisterNonce,omitempty"`
+	TxRenewHash          string                 `protobuf:"bytes,13,opt,name=txRenewHash,proto3" json:"txRenewHash,omitempty"`
+	TxRenewNonce         uint64                 `protobuf:"varint,14,opt,name=txRenewNonce,proto3" json:"txRenewNonce,omitempty"`
+	TxCurrentNonce       uint64                 `protobuf:"varint,15,opt,name=txCurrentNonce,proto3" json:"txCurrentNonce,omitempty"`
+	TxCurrentRetry       uint32                 `protobuf:"varint,16,opt,name=txCurrentRetry,proto3" json:"txCurrentRetry,omitempty"`
+	BlockNumber          int64                  `protobuf:"varint,17,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	BlockHash            string                 `protobuf:"bytes,18,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	ErrorCode            string                 `protobuf:"bytes,19,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	ErrorMessage         string                 `protobuf:"bytes,20,opt,name=errorMessage,proto3" json:"errorMessage,omitempty"`
+	StateRetry           uint32                 `protobuf:"varint,21,opt,name=stateRetry,proto3" json:"stateRetry,omitempty"`
+	Attempts             []*QueueItemAttempt    `protobuf:"bytes,22,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	// for the cancelled items
+	CancelledState string `protobuf:"bytes,23,opt,name=cancelledState,proto3" json:"cancelledState,omitempty"`
+	// node that processes the item right now
+	LeaseOwner    string `protobuf:"bytes,24,opt,name=leaseOwner,proto3" json:"leaseOwner,omitempty"`
+	LeaseExpires  int64  `protobuf:"varint,25,opt,name=leaseExpires,proto3" json:"leaseExpires,omitempty"`
+	DateCreated   int64  `protobuf:"varint,26,opt,name=dateCreated,proto3" json:"dateCreated,omitempty"`
+	DateModified  int64  `protobuf:"varint,27,opt,name=dateModified,proto3" json:"dateModified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItem) Reset() {
+	*x = QueueItem{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItem) ProtoMessage() {}
+
+func (x *QueueItem) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItem.ProtoReflect.Descriptor instead.
+func (*QueueItem) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *QueueItem) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *QueueItem) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *QueueItem) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *QueueItem) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *QueueItem) GetOwnerAnyAddress() string {
+	if x != nil {
+		return x.OwnerAnyAddress
+	}
+	return ""
+}
+
+func (x *QueueItem) GetOwnerEthAddress() string {
+	if x != nil {
+		return x.OwnerEthAddress
+	}
+	return ""
+}
+
+func (x *QueueItem) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *QueueItem) GetRegisterPeriodMonths() uint32 {
+	if x != nil {
+		return x.RegisterPeriodMonths
+	}
+	return 0
+}
+
+func (x *QueueItem) GetTxCommitHash() string {
+	if x != nil {
+		return x.TxCommitHash
+	}
+	return ""
+}
+
+func (x *QueueItem) GetTxCommitNonce() uint64 {
+	if x != nil {
+		return x.TxCommitNonce
+	}
+	return 0
+}
+
+func (x *QueueItem) GetTxRegisterHash() string {
+	if x != nil {
+		return x.TxRegisterHash
+	}
+	return ""
+}
+
+func (x *QueueItem) GetTxRegisterNonce() uint64 {
+	if x != nil {
+		return x.TxRegisterNonce
+	}
+	return 0
+}
+
+func (x *QueueItem) GetTxRenewHash() string {
+	if x != nil {
+		return x.TxRenewHash
+	}
+	return ""
+}
+
+func (x *QueueItem) GetTxRenewNonce() uint64 {
+	if x != nil {
+		return x.TxRenewNonce
+	}
+	return 0
+}
+
+func (x *QueueItem) GetTxCurrentNonce() uint64 {
+	if x != nil {
+		return x.TxCurrentNonce
+	}
+	return 0
+}
+
+func (x *QueueItem) GetTxCurrentRetry() uint32 {
+	if x != nil {
+		return x.TxCurrentRetry
+	}
+	return 0
+}
+
+func (x *QueueItem) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *QueueItem) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+func (x *QueueItem) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *QueueItem) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *QueueItem) GetStateRetry() uint32 {
+	if x != nil {
+		return x.StateRetry
+	}
+	return 0
+}
+
+func (x *QueueItem) GetAttempts() []*QueueItemAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+func (x *QueueItem) GetCancelledState() string {
+	if x != nil {
+		return x.CancelledState
+	}
+	return ""
+}
+
+func (x *QueueItem) GetLeaseOwner() string {
+	if x != nil {
+		return x.LeaseOwner
+	}
+	return ""
+}
+
+func (x *QueueItem) GetLeaseExpires() int64 {
+	if x != nil {
+		return x.LeaseExpires
+	}
+	return 0
+}
+
+func (x *QueueItem) GetDateCreated() int64 {
+	if x != nil {
+		return x.DateCreated
+	}
+	return 0
+}
+
+func (x *QueueItem) GetDateModified() int64 {
+	if x != nil {
+		return x.DateModified
+	}
+	return 0
+}
+
+type QueueItemsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// oldest first
+	Items         []*QueueItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemsResponse) Reset() {
+	*x = QueueItemsResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemsResponse) ProtoMessage() {}
+
+func (x *QueueItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemsResponse.ProtoReflect.Descriptor instead.
+func (*QueueItemsResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *QueueItemsResponse) GetItems() []*QueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// admin only: get, cancel, advance or requeue the item
+type QueueItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemRequest) Reset() {
+	*x = QueueItemRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemRequest) ProtoMessage() {}
+
+func (x *QueueItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemRequest.ProtoReflect.Descriptor instead.
+func (*QueueItemRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QueueItemRequest) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type QueueItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          *QueueItem             `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemResponse) Reset() {
+	*x = QueueItemResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemResponse) ProtoMessage() {}
+
+func (x *QueueItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemResponse.ProtoReflect.Descriptor instead.
+func (*QueueItemResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *QueueItemResponse) GetItem() *QueueItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+// admin only: number of the items in each state
+type QueueCountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueCountsRequest) Reset() {
+	*x = QueueCountsRequest{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueCountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueCountsRequest) ProtoMessage() {}
+
+func (x *QueueCountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueCountsRequest.ProtoReflect.Descriptor instead.
+func (*QueueCountsRequest) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{20}
+}
+
+type QueueCountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Counts        map[string]int64       `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueCountsResponse) Reset() {
+	*x = QueueCountsResponse{}
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueCountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueCountsResponse) ProtoMessage() {}
+
+func (x *QueueCountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nsext_nsextproto_protos_nsext_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueCountsResponse.ProtoReflect.Descriptor instead.
+func (*QueueCountsResponse) Descriptor() ([]byte, []int) {
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *QueueCountsResponse) GetCounts() map[string]int64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+var File_nsext_nsextproto_protos_nsext_proto protoreflect.FileDescriptor
+
+const file_nsext_nsextproto_protos_nsext_proto_rawDesc = "" +
+	"\n" +
+	"#nsext/nsextproto/protos/nsext.proto\x12\x05nsext\"\xbd\x01\n" +
+	"\x13NamesByOwnerRequest\x12\x1e\n" +
+	"\n" +
+	"anyAddress\x18\x01 \x01(\tR\n" +
+	"anyAddress\x12(\n" +
+	"\x0fownerEthAddress\x18\x02 \x01(\tR\x0fownerEthAddress\x12.\n" +
+	"\x12ownerScwEthAddress\x18\x03 \x01(\tR\x12ownerScwEthAddress\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\rR\x05limit\"\x81\x01\n" +
+	"\tOwnedName\x12\x1a\n" +
+	"\bfullName\x18\x01 \x01(\tR\bfullName\x12 \n" +
+	"\vnameExpires\x18\x02 \x01(\x03R\vnameExpires\x12\x18\n" +
+	"\aspaceId\x18\x03 \x01(\tR\aspaceId\x12\x1c\n" +
+	"\tisPrimary\x18\x04 \x01(\bR\tisPrimary\"^\n" +
+	"\x14NamesByOwnerResponse\x12&\n" +
+	"\x05names\x18\x01 \x03(\v2\x10.nsext.OwnedNameR\x05names\x12\x1e\n" +
+	"\n" +
+	"nextCursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"0\n" +
+	"\x14NameBySpaceIdRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\"A\n" +
+	"\x15NameBySpaceIdResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"7\n" +
+	"\x19BatchNameBySpaceIdRequest\x12\x1a\n" +
+	"\bspaceIds\x18\x01 \x03(\tR\bspaceIds\"T\n" +
+	"\x1aBatchNameBySpaceIdResponse\x126\n" +
+	"\aresults\x18\x01 \x03(\v2\x1c.nsext.NameBySpaceIdResponseR\aresults\"p\n" +
+	"\x12NameAtBlockRequest\x12\x1a\n" +
+	"\bfullName\x18\x01 \x01(\tR\bfullName\x12 \n" +
+	"\vblockNumber\x18\x02 \x01(\x04R\vblockNumber\x12\x1c\n" +
+	"\tblockHash\x18\x03 \x01(\tR\tblockHash\"\xb3\x02\n" +
+	"\x13NameAtBlockResponse\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12.\n" +
+	"\x12ownerScwEthAddress\x18\x02 \x01(\tR\x12ownerScwEthAddress\x12(\n" +
+	"\x0fownerEthAddress\x18\x03 \x01(\tR\x0fownerEthAddress\x12(\n" +
+	"\x0fownerAnyAddress\x18\x04 \x01(\tR\x0fownerAnyAddress\x12\x18\n" +
+	"\aspaceId\x18\x05 \x01(\tR\aspaceId\x12 \n" +
+	"\vnameExpires\x18\x06 \x01(\x03R\vnameExpires\x12 \n" +
+	"\vblockNumber\x18\a \x01(\x04R\vblockNumber\x12\x1c\n" +
+	"\tblockHash\x18\b \x01(\tR\tblockHash\"-\n" +
+	"\x15DeadQueueItemsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\rR\x05limit\"\x9c\x01\n" +
+	"\x10QueueItemAttempt\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x1c\n" +
+	"\terrorCode\x18\x02 \x01(\tR\terrorCode\x12\"\n" +
+	"\ferrorMessage\x18\x03 \x01(\tR\ferrorMessage\x12\x1c\n" +
+	"\tpermanent\x18\x04 \x01(\bR\tpermanent\x12\x12\n" +
+	"\x04date\x18\x05 \x01(\x03R\x04date\"\xca\x02\n" +
+	"\rDeadQueueItem\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x1a\n" +
+	"\bitemType\x18\x02 \x01(\tR\bitemType\x12\x1a\n" +
+	"\bfullName\x18\x03 \x01(\tR\bfullName\x12 \n" +
+	"\vfailedState\x18\x04 \x01(\tR\vfailedState\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x1c\n" +
+	"\terrorCode\x18\x06 \x01(\tR\terrorCode\x12\"\n" +
+	"\ferrorMessage\x18\a \x01(\tR\ferrorMessage\x123\n" +
+	"\battempts\x18\b \x03(\v2\x17.nsext.QueueItemAttemptR\battempts\x12 \n" +
+	"\vdateCreated\x18\t \x01(\x03R\vdateCreated\x12\x1a\n" +
+	"\bdateDied\x18\n" +
+	" \x01(\x03R\bdateDied\"D\n" +
+	"\x16DeadQueueItemsResponse\x12*\n" +
+	"\x05items\x18\x01 \x03(\v2\x14.nsext.DeadQueueItemR\x05items\",\n" +
+	"\x14DeadQueueItemRequest\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\"-\n" +
+	"\x15DeadQueueItemResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\"c\n" +
+	"\x11QueueItemsRequest\x12\x1a\n" +
+	"\bstatuses\x18\x01 \x03(\tR\bstatuses\x12\x1c\n" +
+	"\tminAgeSec\x18\x02 \x01(\x03R\tminAgeSec\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\"\xcc\a\n" +
+	"\tQueueItem\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x1a\n" +
+	"\bitemType\x18\x02 \x01(\tR\bitemType\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1a\n" +
+	"\bfullName\x18\x04 \x01(\tR\bfullName\x12(\n" +
+	"\x0fownerAnyAddress\x18\x05 \x01(\tR\x0fownerAnyAddress\x12(\n" +
+	"\x0fownerEthAddress\x18\x06 \x01(\tR\x0fownerEthAddress\x12\x18\n" +
+	"\aspaceId\x18\a \x01(\tR\aspaceId\x122\n" +
+	"\x14registerPeriodMonths\x18\b \x01(\rR\x14registerPeriodMonths\x12\"\n" +
+	"\ftxCommitHash\x18\t \x01(\tR\ftxCommitHash\x12$\n" +
+	"\rtxCommitNonce\x18\n" +
+	" \x01(\x04R\rtxCommitNonce\x12&\n" +
+	"\x0etxRegisterHash\x18\v \x01(\tR\x0etxRegisterHash\x12(\n" +
+	"\x0ftxRegisterNonce\x18\f \x01(\x04R\x0ftxRegisterNonce\x12 \n" +
+	"\vtxRenewHash\x18\r \x01(\tR\vtxRenewHash\x12\"\n" +
+	"\ftxRenewNonce\x18\x0e \x01(\x04R\ftxRenewNonce\x12&\n" +
+	"\x0etxCurrentNonce\x18\x0f \x01(\x04R\x0etxCurrentNonce\x12&\n" +
+	"\x0etxCurrentRetry\x18\x10 \x01(\rR\x0etxCurrentRetry\x12 \n" +
+	"\vblockNumber\x18\x11 \x01(\x03R\vblockNumber\x12\x1c\n" +
+	"\tblockHash\x18\x12 \x01(\tR\tblockHash\x12\x1c\n" +
+	"\terrorCode\x18\x13 \x01(\tR\terrorCode\x12\"\n" +
+	"\ferrorMessage\x18\x14 \x01(\tR\ferrorMessage\x12\x1e\n" +
+	"\n" +
+	"stateRetry\x18\x15 \x01(\rR\n" +
+	"stateRetry\x123\n" +
+	"\battempts\x18\x16 \x03(\v2\x17.nsext.QueueItemAttemptR\battempts\x12&\n" +
+	"\x0ecancelledState\x18\x17 \x01(\tR\x0ecancelledState\x12\x1e\n" +
+	"\n" +
+	"leaseOwner\x18\x18 \x01(\tR\n" +
+	"leaseOwner\x12\"\n" +
+	"\fleaseExpires\x18\x19 \x01(\x03R\fleaseExpires\x12 \n" +
+	"\vdateCreated\x18\x1a \x01(\x03R\vdateCreated\x12\"\n" +
+	"\fdateModified\x18\x1b \x01(\x03R\fdateModified\"<\n" +
+	"\x12QueueItemsResponse\x12&\n" +
+	"\x05items\x18\x01 \x03(\v2\x10.nsext.QueueItemR\x05items\"(\n" +
+	"\x10QueueItemRequest\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\"9\n" +
+	"\x11QueueItemResponse\x12$\n" +
+	"\x04item\x18\x01 \x01(\v2\x10.nsext.QueueItemR\x04item\"\x14\n" +
+	"\x12QueueCountsRequest\"\x90\x01\n" +
+	"\x13QueueCountsResponse\x12>\n" +
+	"\x06counts\x18\x01 \x03(\v2&.nsext.QueueCountsResponse.CountsEntryR\x06counts\x1a9\n" +
+	"\vCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012\xb1\b\n" +
+	"\bAnynsExt\x12L\n" +
+	"\x0fGetNamesByOwner\x12\x1a.nsext.NamesByOwnerRequest\x1a\x1b.nsext.NamesByOwnerResponse\"\x00\x12O\n" +
+	"\x10GetNameBySpaceId\x12\x1b.nsext.NameBySpaceIdRequest\x1a\x1c.nsext.NameBySpaceIdResponse\"\x00\x12^\n" +
+	"\x15BatchGetNameBySpaceId\x12 .nsext.BatchNameBySpaceIdRequest\x1a!.nsext.BatchNameBySpaceIdResponse\"\x00\x12I\n" +
+	"\x0eGetNameAtBlock\x12\x19.nsext.NameAtBlockRequest\x1a\x1a.nsext.NameAtBlockResponse\"\x00\x12W\n" +
+	"\x16AdminQueueGetDeadItems\x12\x1c.nsext.DeadQueueItemsRequest\x1a\x1d.nsext.DeadQueueItemsResponse\"\x00\x12X\n" +
+	"\x19AdminQueueRequeueDeadItem\x12\x1b.nsext.DeadQueueItemRequest\x1a\x1c.nsext.DeadQueueItemResponse\"\x00\x12X\n" +
+	"\x19AdminQueueAbandonDeadItem\x12\x1b.nsext.DeadQueueItemRequest\x1a\x1c.nsext.DeadQueueItemResponse\"\x00\x12K\n" +
+	"\x12AdminQueueGetItems\x12\x18.nsext.QueueItemsRequest\x1a\x19.nsext.QueueItemsResponse\"\x00\x12H\n" +
+	"\x11AdminQueueGetItem\x12\x17.nsext.QueueItemRequest\x1a\x18.nsext.QueueItemResponse\"\x00\x12K\n" +
+	"\x14AdminQueueCancelItem\x12\x17.nsext.QueueItemRequest\x1a\x18.nsext.QueueItemResponse\"\x00\x12L\n" +
+	"\x15AdminQueueAdvanceItem\x12\x17.nsext.QueueItemRequest\x1a\x18.nsext.QueueItemResponse\"\x00\x12L\n" +
+	"\x15AdminQueueRequeueItem\x12\x17.nsext.QueueItemRequest\x1a\x18.nsext.QueueItemResponse\"\x00\x12N\n" +
+	"\x13AdminQueueGetCounts\x12\x19.nsext.QueueCountsRequest\x1a\x1a.nsext.QueueCountsResponse\"\x00B\x12Z\x10nsext/nsextprotob\x06proto3"
+
+var (
+	file_nsext_nsextproto_protos_nsext_proto_rawDescOnce sync.Once
+	file_nsext_nsextproto_protos_nsext_proto_rawDescData []byte
+)
+
+func file_nsext_nsextproto_protos_nsext_proto_rawDescGZIP() []byte {
+	file_nsext_nsextproto_protos_nsext_proto_rawDescOnce.Do(func() {
+		file_nsext_nsextproto_protos_nsext_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_nsext_nsextproto_protos_nsext_proto_rawDesc), len(file_nsext_nsextproto_protos_nsext_proto_rawDesc)))
+	})
+	return file_nsext_nsextproto_protos_nsext_proto_rawDescData
+}
+
+var file_nsext_nsextproto_protos_nsext_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_nsext_nsextproto_protos_nsext_proto_goTypes = []any{
+	(*NamesByOwnerRequest)(nil),        // 0: nsext.NamesByOwnerRequest
+	(*OwnedName)(nil),                  // 1: nsext.OwnedName
+	(*NamesByOwnerResponse)(nil),       // 2: nsext.NamesByOwnerResponse
+	(*NameBySpaceIdRequest)(nil),       // 3: nsext.NameBySpaceIdRequest
+	(*NameBySpaceIdResponse)(nil),      // 4: nsext.NameBySpaceIdResponse
+	(*BatchNameBySpaceIdRequest)(nil),  // 5: nsext.BatchNameBySpaceIdRequest
+	(*BatchNameBySpaceIdResponse)(nil), // 6: nsext.BatchNameBySpaceIdResponse
+	(*NameAtBlockRequest)(nil),         // 7: nsext.NameAtBlockRequest
+	(*NameAtBlockResponse)(nil),        // 8: nsext.NameAtBlockResponse
+	(*DeadQueueItemsRequest)(nil),      // 9: nsext.DeadQueueItemsRequest
+	(*QueueItemAttempt)(nil),           // 10: nsext.QueueItemAttempt
+	(*DeadQueueItem)(nil),              // 11: nsext.DeadQueueItem
+	(*DeadQueueItemsResponse)(nil),     // 12: nsext.DeadQueueItemsResponse
+	(*DeadQueueItemRequest)(nil),       // 13: nsext.DeadQueueItemRequest
+	(*DeadQueueItemResponse)(nil),      // 14: nsext.DeadQueueItemResponse
+	(*QueueItemsRequest)(nil),          // 15: nsext.QueueItemsRequest
+	(*QueueItem)(nil),                  // 16: nsext.QueueItem
+	(*QueueItemsResponse)(nil),         // 17: nsext.QueueItemsResponse
+	(*QueueItemRequest)(nil),           // 18: nsext.QueueItemRequest
+	(*QueueItemResponse)(nil),          // 19: nsext.QueueItemResponse
+	(*QueueCountsRequest)(nil),         // 20: nsext.QueueCountsRequest
+	(*QueueCountsResponse)(nil),        // 21: nsext.QueueCountsResponse
+	nil,                                // 22: nsext.QueueCountsResponse.CountsEntry
+}
+var file_nsext_nsextproto_protos_nsext_proto_depIdxs = []int32{
+	1,  // 0: nsext.NamesByOwnerResponse.names:type_name -> nsext.OwnedName
+	4,  // 1: nsext.BatchNameBySpaceIdResponse.results:type_name -> nsext.NameBySpaceIdResponse
+	10, // 2: nsext.DeadQueueItem.attempts:type_name -> nsext.QueueItemAttempt
+	11, // 3: nsext.DeadQueueItemsResponse.items:type_name -> nsext.DeadQueueItem
+	10, // 4: nsext.QueueItem.attempts:type_name -> nsext.QueueItemAttempt
+	16, // 5: nsext.QueueItemsResponse.items:type_name -> nsext.QueueItem
+	16, // 6: nsext.QueueItemResponse.item:type_name -> nsext.QueueItem
+	22, // 7: nsext.QueueCountsResponse.counts:type_name -> nsext.QueueCountsResponse.CountsEntry
+	0,  // 8: nsext.AnynsExt.GetNamesByOwner:input_type -> nsext.NamesByOwnerRequest
+	3,  // 9: nsext.AnynsExt.GetNameBySpaceId:input_type -> nsext.NameBySpaceIdRequest
+	5,  // 10: nsext.AnynsExt.BatchGetNameBySpaceId:input_type -> nsext.BatchNameBySpaceIdRequest
+	7,  // 11: nsext.AnynsExt.GetNameAtBlock:input_type -> nsext.NameAtBlockRequest
+	9,  // 12: nsext.AnynsExt.AdminQueueGetDeadItems:input_type -> nsext.DeadQueueItemsRequest
+	13, // 13: nsext.AnynsExt.AdminQueueRequeueDeadItem:input_type -> nsext.DeadQueueItemRequest
+	13, // 14: nsext.AnynsExt.AdminQueueAbandonDeadItem:input_type -> nsext.DeadQueueItemRequest
+	15, // 15: nsext.AnynsExt.AdminQueueGetItems:input_type -> nsext.QueueItemsRequest
+	18, // 16: nsext.AnynsExt.AdminQueueGetItem:input_type -> nsext.QueueItemRequest
+	18, // 17: nsext.AnynsExt.AdminQueueCancelItem:input_type -> nsext.QueueItemRequest
+	18, // 18: nsext.AnynsExt.AdminQueueAdvanceItem:input_type -> nsext.QueueItemRequest
+	18, // 19: nsext.AnynsExt.AdminQueueRequeueItem:input_type -> nsext.QueueItemRequest
+	20, // 20: nsext.AnynsExt.AdminQueueGetCounts:input_type -> nsext.QueueCountsRequest
+	2,  // 21: nsext.AnynsExt.GetNamesByOwner:output_type -> nsext.NamesByOwnerResponse
+	4,  // 22: nsext.AnynsExt.GetNameBySpaceId:output_type -> nsext.NameBySpaceIdResponse
+	6,  // 23: nsext.AnynsExt.BatchGetNameBySpaceId:output_type -> nsext.BatchNameBySpaceIdResponse
+	8,  // 24: nsext.AnynsExt.GetNameAtBlock:output_type -> nsext.NameAtBlockResponse
+	12, // 25: nsext.AnynsExt.AdminQueueGetDeadItems:output_type -> nsext.DeadQueueItemsResponse
+	14, // 26: nsext.AnynsExt.AdminQueueRequeueDeadItem:output_type -> nsext.DeadQueueItemResponse
+	14, // 27: nsext.AnynsExt.AdminQueueAbandonDeadItem:output_type -> nsext.DeadQueueItemResponse
+	17, // 28: nsext.AnynsExt.AdminQueueGetItems:output_type -> nsext.QueueItemsResponse
+	19, // 29: nsext.AnynsExt.AdminQueueGetItem:output_type -> nsext.QueueItemResponse
+	19, // 30: nsext.AnynsExt.AdminQueueCancelItem:output_type -> nsext.QueueItemResponse
+	19, // 31: nsext.AnynsExt.AdminQueueAdvanceItem:output_type -> nsext.QueueItemResponse
+	19, // 32: nsext.AnynsExt.AdminQueueRequeueItem:output_type -> nsext.QueueItemResponse
+	21, // 33: nsext.AnynsExt.AdminQueueGetCounts:output_type -> nsext.QueueCountsResponse
+	21, // [21:34] is the sub-list for method output_type
+	8,  // [8:21] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_nsext_nsextproto_protos_nsext_proto_init() }
+func file_nsext_nsextproto_protos_nsext_proto_init() {
+	if File_nsext_nsextproto_protos_nsext_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_nsext_nsextproto_protos_nsext_proto_rawDesc), len(file_nsext_nsextproto_protos_nsext_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_nsext_nsextproto_protos_nsext_proto_goTypes,
+		DependencyIndexes: file_nsext_nsextproto_protos_nsext_proto_depIdxs,
+		MessageInfos:      file_nsext_nsextproto_protos_nsext_proto_msgTypes,
+	}.Build()
+	File_nsext_nsextproto_protos_nsext_proto = out.File
+	file_nsext_nsextproto_protos_nsext_proto_goTypes = nil
+	file_nsext_nsextproto_protos_nsext_proto_depIdxs = nil
+}
@@ -0,0 +1,586 @@
+// Code generated by protoc-gen-go-drpc. DO NOT EDIT.
+// protoc-gen-go-drpc version: v0.0.34
+// source: nsext/nsextproto/protos/nsext.proto
+
+package nsextproto
+
+import (
+	context "context"
+	errors "errors"
+	drpc1 "github.com/planetscale/vtprotobuf/codec/drpc"
+	drpc "storj.io/drpc"
+	drpcerr "storj.io/drpc/drpcerr"
+)
+
+type drpcEncoding_File_nsext_nsextproto_protos_nsext_proto struct{}
+
+func (drpcEncoding_File_nsext_nsextproto_protos_nsext_proto) Marshal(msg drpc.Message) ([]byte, error) {
+	return drpc1.Marshal(msg)
+}
+
+func (drpcEncoding_File_nsext_nsextproto_protos_nsext_proto) Unmarshal(buf []byte, msg drpc.Message) error {
+	return drpc1.Unmarshal(buf, msg)
+}
+
+func (drpcEncoding_File_nsext_nsextproto_protos_nsext_proto) JSONMarshal(msg drpc.Message) ([]byte, error) {
+	return drpc1.JSONMarshal(msg)
+}
+
+func (drpcEncoding_File_nsext_nsextproto_protos_nsext_proto) JSONUnmarshal(buf []byte, msg drpc.Message) error {
+	return drpc1.JSONUnmarshal(buf, msg)
+}
+
+type DRPCAnynsExtClient interface {
+	DRPCConn() drpc.Conn
+
+	GetNamesByOwner(ctx context.Context, in *NamesByOwnerRequest) (*NamesByOwnerResponse, error)
+	GetNameBySpaceId(ctx context.Context, in *NameBySpaceIdRequest) (*NameBySpaceIdResponse, error)
+	BatchGetNameBySpaceId(ctx context.Context, in *BatchNameBySpaceIdRequest) (*BatchNameBySpaceIdResponse, error)
+	GetNameAtBlock(ctx context.Context, in *NameAtBlockRequest) (*NameAtBlockResponse, error)
+	AdminQueueGetDeadItems(ctx context.Context, in *DeadQueueItemsRequest) (*DeadQueueItemsResponse, error)
+	AdminQueueRequeueDeadItem(ctx context.Context, in *DeadQueueItemRequest) (*DeadQueueItemResponse, error)
+	AdminQueueAbandonDeadItem(ctx context.Context, in *DeadQueueItemRequest) (*DeadQueueItemResponse, error)
+	AdminQueueGetItems(ctx context.Context, in *QueueItemsRequest) (*QueueItemsResponse, error)
+	AdminQueueGetItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueCancelItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueAdvanceItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueRequeueItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueGetCounts(ctx context.Context, in *QueueCountsRequest) (*QueueCountsResponse, error)
+}
+
+type drpcAnynsExtClient struct {
+	cc drpc.Conn
+}
+
+func NewDRPCAnynsExtClient(cc drpc.Conn) DRPCAnynsExtClient {
+	return &drpcAnynsExtClient{cc}
+}
+
+func (c *drpcAnynsExtClient) DRPCConn() drpc.Conn { return c.cc }
+
+func (c *drpcAnynsExtClient) GetNamesByOwner(ctx context.Context, in *NamesByOwnerRequest) (*NamesByOwnerResponse, error) {
+	out := new(NamesByOwnerResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/GetNamesByOwner", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) GetNameBySpaceId(ctx context.Context, in *NameBySpaceIdRequest) (*NameBySpaceIdResponse, error) {
+	out := new(NameBySpaceIdResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/GetNameBySpaceId", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) BatchGetNameBySpaceId(ctx context.Context, in *BatchNameBySpaceIdRequest) (*BatchNameBySpaceIdResponse, error) {
+	out := new(BatchNameBySpaceIdResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/BatchGetNameBySpaceId", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) GetNameAtBlock(ctx context.Context, in *NameAtBlockRequest) (*NameAtBlockResponse, error) {
+	out := new(NameAtBlockResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/GetNameAtBlock", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueGetDeadItems(ctx context.Context, in *DeadQueueItemsRequest) (*DeadQueueItemsResponse, error) {
+	out := new(DeadQueueItemsResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueGetDeadItems", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueRequeueDeadItem(ctx context.Context, in *DeadQueueItemRequest) (*DeadQueueItemResponse, error) {
+	out := new(DeadQueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueRequeueDeadItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueAbandonDeadItem(ctx context.Context, in *DeadQueueItemRequest) (*DeadQueueItemResponse, error) {
+	out := new(DeadQueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueAbandonDeadItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueGetItems(ctx context.Context, in *QueueItemsRequest) (*QueueItemsResponse, error) {
+	out := new(QueueItemsResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueGetItems", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueGetItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error) {
+	out := new(QueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueGetItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueCancelItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error) {
+	out := new(QueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueCancelItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueAdvanceItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error) {
+	out := new(QueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueAdvanceItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueRequeueItem(ctx context.Context, in *QueueItemRequest) (*QueueItemResponse, error) {
+	out := new(QueueItemResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueRequeueItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAnynsExtClient) AdminQueueGetCounts(ctx context.Context, in *QueueCountsRequest) (*QueueCountsResponse, error) {
+	out := new(QueueCountsResponse)
+	err := c.cc.Invoke(ctx, "/nsext.AnynsExt/AdminQueueGetCounts", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type DRPCAnynsExtServer interface {
+	GetNamesByOwner(context.Context, *NamesByOwnerRequest) (*NamesByOwnerResponse, error)
+	GetNameBySpaceId(context.Context, *NameBySpaceIdRequest) (*NameBySpaceIdResponse, error)
+	BatchGetNameBySpaceId(context.Context, *BatchNameBySpaceIdRequest) (*BatchNameBySpaceIdResponse, error)
+	GetNameAtBlock(context.Context, *NameAtBlockRequest) (*NameAtBlockResponse, error)
+	AdminQueueGetDeadItems(context.Context, *DeadQueueItemsRequest) (*DeadQueueItemsResponse, error)
+	AdminQueueRequeueDeadItem(context.Context, *DeadQueueItemRequest) (*DeadQueueItemResponse, error)
+	AdminQueueAbandonDeadItem(context.Context, *DeadQueueItemRequest) (*DeadQueueItemResponse, error)
+	AdminQueueGetItems(context.Context, *QueueItemsRequest) (*QueueItemsResponse, error)
+	AdminQueueGetItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueCancelItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueAdvanceItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueRequeueItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error)
+	AdminQueueGetCounts(context.Context, *QueueCountsRequest) (*QueueCountsResponse, error)
+}
+
+type DRPCAnynsExtUnimplementedServer struct{}
+
+func (s *DRPCAnynsExtUnimplementedServer) GetNamesByOwner(context.Context, *NamesByOwnerRequest) (*NamesByOwnerResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) GetNameBySpaceId(context.Context, *NameBySpaceIdRequest) (*NameBySpaceIdResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) BatchGetNameBySpaceId(context.Context, *BatchNameBySpaceIdRequest) (*BatchNameBySpaceIdResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) GetNameAtBlock(context.Context, *NameAtBlockRequest) (*NameAtBlockResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueGetDeadItems(context.Context, *DeadQueueItemsRequest) (*DeadQueueItemsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueRequeueDeadItem(context.Context, *DeadQueueItemRequest) (*DeadQueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueAbandonDeadItem(context.Context, *DeadQueueItemRequest) (*DeadQueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueGetItems(context.Context, *QueueItemsRequest) (*QueueItemsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueGetItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueCancelItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueAdvanceItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueRequeueItem(context.Context, *QueueItemRequest) (*QueueItemResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAnynsExtUnimplementedServer) AdminQueueGetCounts(context.Context, *QueueCountsRequest) (*QueueCountsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+type DRPCAnynsExtDescription struct{}
+
+func (DRPCAnynsExtDescription) NumMethods() int { return 13 }
+
+func (DRPCAnynsExtDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
+	switch n {
+	case 0:
+		return "/nsext.AnynsExt/GetNamesByOwner", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					GetNamesByOwner(
+						ctx,
+						in1.(*NamesByOwnerRequest),
+					)
+			}, DRPCAnynsExtServer.GetNamesByOwner, true
+	case 1:
+		return "/nsext.AnynsExt/GetNameBySpaceId", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					GetNameBySpaceId(
+						ctx,
+						in1.(*NameBySpaceIdRequest),
+					)
+			}, DRPCAnynsExtServer.GetNameBySpaceId, true
+	case 2:
+		return "/nsext.AnynsExt/BatchGetNameBySpaceId", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					BatchGetNameBySpaceId(
+						ctx,
+						in1.(*BatchNameBySpaceIdRequest),
+					)
+			}, DRPCAnynsExtServer.BatchGetNameBySpaceId, true
+	case 3:
+		return "/nsext.AnynsExt/GetNameAtBlock", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					GetNameAtBlock(
+						ctx,
+						in1.(*NameAtBlockRequest),
+					)
+			}, DRPCAnynsExtServer.GetNameAtBlock, true
+	case 4:
+		return "/nsext.AnynsExt/AdminQueueGetDeadItems", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueGetDeadItems(
+						ctx,
+						in1.(*DeadQueueItemsRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueGetDeadItems, true
+	case 5:
+		return "/nsext.AnynsExt/AdminQueueRequeueDeadItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueRequeueDeadItem(
+						ctx,
+						in1.(*DeadQueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueRequeueDeadItem, true
+	case 6:
+		return "/nsext.AnynsExt/AdminQueueAbandonDeadItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueAbandonDeadItem(
+						ctx,
+						in1.(*DeadQueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueAbandonDeadItem, true
+	case 7:
+		return "/nsext.AnynsExt/AdminQueueGetItems", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueGetItems(
+						ctx,
+						in1.(*QueueItemsRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueGetItems, true
+	case 8:
+		return "/nsext.AnynsExt/AdminQueueGetItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueGetItem(
+						ctx,
+						in1.(*QueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueGetItem, true
+	case 9:
+		return "/nsext.AnynsExt/AdminQueueCancelItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueCancelItem(
+						ctx,
+						in1.(*QueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueCancelItem, true
+	case 10:
+		return "/nsext.AnynsExt/AdminQueueAdvanceItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueAdvanceItem(
+						ctx,
+						in1.(*QueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueAdvanceItem, true
+	case 11:
+		return "/nsext.AnynsExt/AdminQueueRequeueItem", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueRequeueItem(
+						ctx,
+						in1.(*QueueItemRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueRequeueItem, true
+	case 12:
+		return "/nsext.AnynsExt/AdminQueueGetCounts", drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAnynsExtServer).
+					AdminQueueGetCounts(
+						ctx,
+						in1.(*QueueCountsRequest),
+					)
+			}, DRPCAnynsExtServer.AdminQueueGetCounts, true
+	default:
+		return "", nil, nil, nil, false
+	}
+}
+
+func DRPCRegisterAnynsExt(mux drpc.Mux, impl DRPCAnynsExtServer) error {
+	return mux.Register(impl, DRPCAnynsExtDescription{})
+}
+
+type DRPCAnynsExt_GetNamesByOwnerStream interface {
+	drpc.Stream
+	SendAndClose(*NamesByOwnerResponse) error
+}
+
+type drpcAnynsExt_GetNamesByOwnerStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_GetNamesByOwnerStream) SendAndClose(m *NamesByOwnerResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_GetNameBySpaceIdStream interface {
+	drpc.Stream
+	SendAndClose(*NameBySpaceIdResponse) error
+}
+
+type drpcAnynsExt_GetNameBySpaceIdStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_GetNameBySpaceIdStream) SendAndClose(m *NameBySpaceIdResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_BatchGetNameBySpaceIdStream interface {
+	drpc.Stream
+	SendAndClose(*BatchNameBySpaceIdResponse) error
+}
+
+type drpcAnynsExt_BatchGetNameBySpaceIdStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_BatchGetNameBySpaceIdStream) SendAndClose(m *BatchNameBySpaceIdResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_GetNameAtBlockStream interface {
+	drpc.Stream
+	SendAndClose(*NameAtBlockResponse) error
+}
+
+type drpcAnynsExt_GetNameAtBlockStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_GetNameAtBlockStream) SendAndClose(m *NameAtBlockResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueGetDeadItemsStream interface {
+	drpc.Stream
+	SendAndClose(*DeadQueueItemsResponse) error
+}
+
+type drpcAnynsExt_AdminQueueGetDeadItemsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueGetDeadItemsStream) SendAndClose(m *DeadQueueItemsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueRequeueDeadItemStream interface {
+	drpc.Stream
+	SendAndClose(*DeadQueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueRequeueDeadItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueRequeueDeadItemStream) SendAndClose(m *DeadQueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueAbandonDeadItemStream interface {
+	drpc.Stream
+	SendAndClose(*DeadQueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueAbandonDeadItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueAbandonDeadItemStream) SendAndClose(m *DeadQueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueGetItemsStream interface {
+	drpc.Stream
+	SendAndClose(*QueueItemsResponse) error
+}
+
+type drpcAnynsExt_AdminQueueGetItemsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueGetItemsStream) SendAndClose(m *QueueItemsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueGetItemStream interface {
+	drpc.Stream
+	SendAndClose(*QueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueGetItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueGetItemStream) SendAndClose(m *QueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueCancelItemStream interface {
+	drpc.Stream
+	SendAndClose(*QueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueCancelItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueCancelItemStream) SendAndClose(m *QueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueAdvanceItemStream interface {
+	drpc.Stream
+	SendAndClose(*QueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueAdvanceItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueAdvanceItemStream) SendAndClose(m *QueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueRequeueItemStream interface {
+	drpc.Stream
+	SendAndClose(*QueueItemResponse) error
+}
+
+type drpcAnynsExt_AdminQueueRequeueItemStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueRequeueItemStream) SendAndClose(m *QueueItemResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAnynsExt_AdminQueueGetCountsStream interface {
+	drpc.Stream
+	SendAndClose(*QueueCountsResponse) error
+}
+
+type drpcAnynsExt_AdminQueueGetCountsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAnynsExt_AdminQueueGetCountsStream) SendAndClose(m *QueueCountsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_nsext_nsextproto_protos_nsext_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
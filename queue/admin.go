@@ -0,0 +1,271 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+)
+
+var ErrItemStatusChanged = errors.New("item status was changed, try again")
+var ErrItemLeased = errors.New("item is processed by a node right now, try again later")
+
+// states that can be moved forward by the admin (if tx was mined, but the node did not notice it)
+// other states send txs, so they can not be skipped
+// item is completed only if its register or renew tx was mined successfully
+var advanceStates = map[QueueItemStatus]QueueItemStatus{
+	OperationStatus_CommitSent:   OperationStatus_CommitDone,
+	OperationStatus_RegisterSent: OperationStatus_Completed,
+	OperationStatus_RenewSent:    OperationStatus_Completed,
+}
+
+type ListItemsFilter struct {
+	// all statuses if empty
+	Statuses []QueueItemStatus
+	// only items that were created at least MinAge ago
+	MinAge time.Duration
+	Limit  int64
+}
+
+// oldest first
+func (aqueue *anynsQueue) ListItems(ctx context.Context, filter ListItemsFilter) ([]QueueItem, error) {
+	query := bson.D{}
+	if len(filter.Statuses) > 0 {
+		query = append(query, bson.E{Key: "status", Value: bson.D{{Key: "$in", Value: filter.Statuses}}})
+	}
+	if filter.MinAge > 0 {
+		createdBefore := time.Now().Add(-filter.MinAge).Unix()
+		query = append(query, bson.E{Key: "dateCreated", Value: bson.D{{Key: "$lte", Value: createdBefore}}})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "dateCreated", Value: 1}, {Key: "index", Value: 1}}).SetLimit(filter.Limit)
+	cursor, err := aqueue.itemColl.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []QueueItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (aqueue *anynsQueue) GetItem(ctx context.Context, index int64) (*QueueItem, error) {
+	var queueItem QueueItem
+	err := aqueue.itemColl.FindOne(ctx, findItemByIndexQuery{Index: index}).Decode(&queueItem)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &queueItem, nil
+}
+
+func (aqueue *anynsQueue) CountItemsByStatus(ctx context.Context) (map[QueueItemStatus]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$status"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := aqueue.itemColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		Status QueueItemStatus `bson:"_id"`
+		Count  int64           `bson:"count"`
+	}
+	err = cursor.All(ctx, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[QueueItemStatus]int64, len(groups))
+	for _, g := range groups {
+		counts[g.Status] = g.Count
+	}
+	return counts, nil
+}
+
+// tx that was already sent is not cancelled, it can still be mined
+func (aqueue *anynsQueue) CancelItem(ctx context.Context, index int64) (*QueueItem, error) {
+	queueItem, err := aqueue.GetItem(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	if StatusToState(queueItem.Status) != nsp.OperationState_Pending {
+		return nil, errors.Newf("only pending items can be cancelled, item is in the %q state", queueItem.Status)
+	}
+
+	// worker does not overwrite cancelled items (see SaveItemToDb)
+	err = aqueue.setStatus(ctx, queueItem, OperationStatus_Cancelled, bson.E{Key: "cancelledStatus", Value: queueItem.Status})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("item is cancelled", zap.Int64("Item Index", index), zap.Stringer("state", queueItem.CancelledStatus))
+	return queueItem, nil
+}
+
+// moves the item to the next state without waiting for the tx,
+// i.e. if the tx was mined, but the node has lost it
+func (aqueue *anynsQueue) AdvanceItem(ctx context.Context, index int64) (*QueueItem, error) {
+	queueItem, err := aqueue.GetItem(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	next, ok := advanceStates[queueItem.Status]
+	if !ok {
+		return nil, errors.Newf("item in the %q state can not be advanced", queueItem.Status)
+	}
+
+	// same as the worker does when the tx is mined
+	var fields []bson.E
+	if next == OperationStatus_Completed {
+		receipt, err := aqueue.findSentTxReceipt(ctx, queueItem)
+		if err != nil {
+			return nil, err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return nil, errors.Newf("tx %s has failed, item can not be completed", receipt.TxHash.Hex())
+		}
+		aqueue.setCompletedBlock(queueItem, receipt)
+		fields = append(fields,
+			bson.E{Key: "blockNumber", Value: queueItem.BlockNumber},
+			bson.E{Key: "blockHash", Value: queueItem.BlockHash})
+	}
+
+	prevState := queueItem.Status
+	err = aqueue.setStatus(ctx, queueItem, next, fields...)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("item is advanced", zap.Int64("Item Index", index), zap.Stringer("prev state", prevState), zap.Stringer("new state", next))
+
+	if next == OperationStatus_Completed {
+		aqueue.cache.InvalidateName(queueItem.FullName)
+	}
+
+	if StatusToState(next) == nsp.OperationState_Pending {
+		err = aqueue.enqueue(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return queueItem, nil
+}
+
+// receipt of the last sent register or renew tx or of any tx it has replaced
+func (aqueue *anynsQueue) findSentTxReceipt(ctx context.Context, queueItem *QueueItem) (*types.Receipt, error) {
+	txHash := queueItem.TxRegisterHash
+	if queueItem.Status == OperationStatus_RenewSent {
+		txHash = queueItem.TxRenewHash
+	}
+	if txHash == "" {
+		return nil, errors.New("tx hash is empty")
+	}
+
+	hashes := append([]string{txHash}, queueItem.TxReplacedHashes...)
+	for _, hash := range hashes {
+		receipt, err := aqueue.contracts.TxReceipt(ctx, common.HexToHash(hash))
+		if errors.Is(err, ethereum.NotFound) || (err == nil && receipt == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+	return nil, errors.New("tx is not mined yet, item can not be completed")
+}
+
+// - pending item is added to the in-memory queue again (i.e. if it is stuck)
+// - cancelled item is processed again from the state it was cancelled in
+// - dead item is processed again from the state it has failed in (see RequeueDeadItem)
+func (aqueue *anynsQueue) RequeueItem(ctx context.Context, index int64) (*QueueItem, error) {
+	queueItem, err := aqueue.GetItem(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case StatusToState(queueItem.Status) == nsp.OperationState_Pending:
+		// item is skipped if it is being processed right now
+	case queueItem.Status == OperationStatus_Cancelled:
+		err = aqueue.setStatus(ctx, queueItem, queueItem.CancelledStatus)
+		if err != nil {
+			return nil, err
+		}
+	case StatusToState(queueItem.Status) == nsp.OperationState_Error:
+		err = aqueue.RequeueDeadItem(ctx, index)
+		if errors.Is(err, ErrItemNotFound) {
+			return nil, errors.New("item is not in the dead items, it can not be requeued")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return aqueue.GetItem(ctx, index)
+	default:
+		return nil, errors.Newf("item in the %q state can not be requeued", queueItem.Status)
+	}
+
+	log.Info("item is requeued", zap.Int64("Item Index", index), zap.Stringer("state", queueItem.Status))
+	err = aqueue.enqueue(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return queueItem, nil
+}
+
+// updates status of the item only if it was not changed since it was read
+// and if it is not processed by any node, otherwise the worker would overwrite the new status with its copy of the item
+// (cancelled items are never overwritten, see SaveItemToDb, so they can be cancelled at any time)
+func (aqueue *anynsQueue) setStatus(ctx context.Context, queueItem *QueueItem, newStatus QueueItemStatus, fields ...bson.E) error {
+	now := time.Now().Unix()
+	set := bson.D{
+		{Key: "status", Value: newStatus},
+		{Key: "stateRetry", Value: 0},
+		{Key: "nextAttemptAt", Value: 0},
+		{Key: "dateModified", Value: now},
+	}
+	set = append(set, fields...)
+
+	filter := bson.D{{Key: "index", Value: queueItem.Index}, {Key: "status", Value: queueItem.Status}}
+	if newStatus != OperationStatus_Cancelled {
+		filter = append(filter, notLeased(now))
+	}
+	res, err := aqueue.itemColl.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if item, err := aqueue.GetItem(ctx, queueItem.Index); err == nil && item.Status == queueItem.Status {
+			return ErrItemLeased
+		}
+		return ErrItemStatusChanged
+	}
+
+	if newStatus == OperationStatus_Cancelled {
+		queueItem.CancelledStatus = queueItem.Status
+	}
+	queueItem.Status = newStatus
+	queueItem.StateRetry = 0
+	queueItem.NextAttemptAt = 0
+	queueItem.DateModified = now
+	return nil
+}
@@ -0,0 +1,304 @@
+package queue
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/mock/gomock"
+
+	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
+)
+
+func insertItems(t *testing.T, items ...QueueItem) {
+	// TODO: mock Mongo!
+	uri := "mongodb://localhost:27017"
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	coll := client.Database("any-ns").Collection("queue")
+
+	docs := make([]interface{}, len(items))
+	for i := range items {
+		docs[i] = items[i]
+	}
+	_, err = coll.InsertMany(ctx, docs)
+	require.NoError(t, err)
+}
+
+func testItems() []QueueItem {
+	now := time.Now().Unix()
+	return []QueueItem{
+		{Index: 1, ItemType: ItemType_NameRegister, FullName: "one.any", Status: OperationStatus_CommitSent, DateCreated: now - 7200},
+		{Index: 2, ItemType: ItemType_NameRegister, FullName: "two.any", Status: OperationStatus_CommitSent, DateCreated: now},
+		{Index: 3, ItemType: ItemType_NameRenew, FullName: "three.any", Status: OperationStatus_RenewSent, DateCreated: now - 3600},
+		{Index: 4, ItemType: ItemType_NameRegister, FullName: "four.any", Status: OperationStatus_Completed, DateCreated: now - 10000},
+	}
+}
+
+func TestAnynsQueue_ListItems(t *testing.T) {
+	fx := newFixture(t)
+	defer fx.finish(t)
+
+	insertItems(t, testItems()...)
+
+	t.Run("all items, oldest first", func(t *testing.T) {
+		items, err := fx.ListItems(ctx, ListItemsFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		require.Equal(t, []int64{4, 1, 3, 2}, []int64{items[0].Index, items[1].Index, items[2].Index, items[3].Index})
+	})
+
+	t.Run("by status and age", func(t *testing.T) {
+		items, err := fx.ListItems(ctx, ListItemsFilter{
+			Statuses: []QueueItemStatus{OperationStatus_CommitSent, OperationStatus_RenewSent},
+			MinAge:   time.Minute,
+			Limit:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, int64(1), items[0].Index)
+		require.Equal(t, int64(3), items[1].Index)
+	})
+
+	t.Run("count by status", func(t *testing.T) {
+		counts, err := fx.CountItemsByStatus(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[QueueItemStatus]int64{
+			OperationStatus_CommitSent: 2,
+			OperationStatus_RenewSent:  1,
+			OperationStatus_Completed:  1,
+		}, counts)
+	})
+}
+
+func TestAnynsQueue_CancelItem(t *testing.T) {
+	t.Run("cancel and requeue", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertItems(t, testItems()...)
+
+		item, err := fx.CancelItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Cancelled, item.Status)
+
+		s, err := fx.GetRequestStatus(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, nsp.OperationState_Error, s)
+
+		// worker can not overwrite it
+		item.Status = OperationStatus_CommitDone
+		err = fx.SaveItemToDb(ctx, item)
+		require.ErrorIs(t, err, ErrItemCancelled)
+
+		// and does not process it
+		item, err = fx.GetItem(ctx, 1)
+		require.NoError(t, err)
+		require.NoError(t, fx.ProcessItem(ctx, item))
+
+		// processed from the state where it was cancelled
+		item, err = fx.RequeueItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_CommitSent, item.Status)
+	})
+
+	t.Run("fail if item is not pending", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertItems(t, testItems()...)
+
+		_, err := fx.CancelItem(ctx, 4)
+		require.Error(t, err)
+
+		_, err = fx.CancelItem(ctx, 100)
+		require.ErrorIs(t, err, ErrItemNotFound)
+	})
+}
+
+func TestAnynsQueue_AdvanceItem(t *testing.T) {
+	t.Run("advance if tx was mined", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		items := testItems()
+		items[2].TxRenewHash = common.HexToHash("0x03").Hex()
+		items[2].TxReplacedHashes = []string{common.HexToHash("0x02").Hex()}
+		insertItems(t, items...)
+
+		// replaced tx was mined
+		fx.contracts.EXPECT().TxReceipt(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, hash common.Hash) (*types.Receipt, error) {
+			if hash != common.HexToHash("0x02") {
+				return nil, ethereum.NotFound
+			}
+			return &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100), BlockHash: common.HexToHash("0x100")}, nil
+		}).Times(2)
+
+		item, err := fx.AdvanceItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_CommitDone, item.Status)
+
+		item, err = fx.AdvanceItem(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Completed, item.Status)
+
+		item, err = fx.GetItem(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Completed, item.Status)
+		require.Equal(t, int64(100), item.BlockNumber)
+		require.Equal(t, common.HexToHash("0x100").Hex(), item.BlockHash)
+
+		// register tx should be sent first
+		_, err = fx.AdvanceItem(ctx, 1)
+		require.Error(t, err)
+
+		// completed items can not be requeued
+		_, err = fx.RequeueItem(ctx, 4)
+		require.Error(t, err)
+	})
+
+	t.Run("fail if tx was not mined or has failed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		items := testItems()
+		items[2].TxRenewHash = common.HexToHash("0x03").Hex()
+		insertItems(t, items...)
+
+		gomock.InOrder(
+			fx.contracts.EXPECT().TxReceipt(gomock.Any(), common.HexToHash("0x03")).Return(nil, ethereum.NotFound),
+			fx.contracts.EXPECT().TxReceipt(gomock.Any(), common.HexToHash("0x03")).Return(&types.Receipt{Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(100)}, nil),
+		)
+
+		_, err := fx.AdvanceItem(ctx, 3)
+		require.Error(t, err)
+
+		_, err = fx.AdvanceItem(ctx, 3)
+		require.Error(t, err)
+
+		item, err := fx.GetItem(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_RenewSent, item.Status)
+	})
+}
+
+func TestAnynsQueue_AdminLeasedItem(t *testing.T) {
+	fx := newFixture(t)
+	defer fx.finish(t)
+
+	insertItems(t, testItems()...)
+
+	// item is processed by other node
+	processing, err := fx.otherNode().claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+	require.NoError(t, err)
+
+	_, err = fx.AdvanceItem(ctx, 1)
+	require.ErrorIs(t, err, ErrItemLeased)
+
+	// worker saves its copy, nothing is lost
+	processing.Status = OperationStatus_CommitDone
+	require.NoError(t, fx.otherNode().SaveItemToDb(ctx, processing))
+
+	// but it can be cancelled
+	_, err = fx.CancelItem(ctx, 1)
+	require.NoError(t, err)
+
+	// and is requeued only after the worker has stopped
+	_, err = fx.RequeueItem(ctx, 1)
+	require.ErrorIs(t, err, ErrItemLeased)
+
+	fx.otherNode().releaseItem(ctx, 1)
+	item, err := fx.RequeueItem(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, OperationStatus_CommitDone, item.Status)
+}
+
+func TestAnynsQueue_RequeueDeadItem(t *testing.T) {
+	// register item that died in the failedStatus
+	insertDeadItem := func(t *testing.T, fx *fixture, failedStatus QueueItemStatus, errorCode string) {
+		item := QueueItem{
+			Index:        1,
+			ItemType:     ItemType_NameRegister,
+			FullName:     "one.any",
+			SecretBase64: "c2VjcmV0",
+			Status:       OperationStatus_Error,
+			CommitDoneAt: time.Now().Unix() - 600,
+		}
+		insertItems(t, item)
+
+		_, err := fx.deadColl.InsertOne(ctx, DeadItem{
+			Index:        1,
+			ItemType:     ItemType_NameRegister,
+			FullName:     "one.any",
+			FailedStatus: failedStatus,
+			Status:       OperationStatus_Error,
+			ErrorCode:    errorCode,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("continue with the commitment that is still valid", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertDeadItem(t, fx, OperationStatus_CommitDone, "")
+		fx.contracts.EXPECT().GetMaxCommitmentAge(gomock.Any()).Return(big.NewInt(24*60*60), nil)
+
+		require.NoError(t, fx.RequeueDeadItem(ctx, 1))
+
+		item, err := fx.GetItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_CommitDone, item.Status)
+		require.Equal(t, "c2VjcmV0", item.SecretBase64)
+	})
+
+	t.Run("start again with a new secret if commitment is too old", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertDeadItem(t, fx, OperationStatus_CommitDone, "CommitmentTooOld")
+
+		require.NoError(t, fx.RequeueDeadItem(ctx, 1))
+
+		item, err := fx.GetItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Initial, item.Status)
+		require.NotEqual(t, "c2VjcmV0", item.SecretBase64)
+	})
+
+	t.Run("start again if commitment expires soon", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertDeadItem(t, fx, OperationStatus_CommitDone, "")
+		fx.contracts.EXPECT().GetMaxCommitmentAge(gomock.Any()).Return(big.NewInt(15*60), nil)
+
+		require.NoError(t, fx.RequeueDeadItem(ctx, 1))
+
+		item, err := fx.GetItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Initial, item.Status)
+		require.NotEqual(t, "c2VjcmV0", item.SecretBase64)
+	})
+
+	t.Run("start again if commitment was never mined", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertDeadItem(t, fx, OperationStatus_CommitSent, "")
+
+		require.NoError(t, fx.RequeueDeadItem(ctx, 1))
+
+		item, err := fx.GetItem(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Initial, item.Status)
+		require.NotEqual(t, "c2VjcmV0", item.SecretBase64)
+	})
+}
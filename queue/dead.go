@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	contracts "github.com/anyproto/any-ns-node/contracts"
+)
+
+const deadCollectionName = "queue-dead"
+
+// time to send and mine the register tx
+const commitmentExpiryMargin = 10 * time.Minute
+
+// item is in the queue-dead collection if it has failed with a permanent error
+// or if all attempts of some state were used
+func (aqueue *anynsQueue) saveDeadItem(ctx context.Context, queueItem *QueueItem, failedStatus QueueItemStatus, newStatus QueueItemStatus, reason error) error {
+	if aqueue.deadColl == nil {
+		// no error, required for some tests!
+		return nil
+	}
+
+	dead := DeadItem{
+		Index:        queueItem.Index,
+		ItemType:     queueItem.ItemType,
+		FullName:     queueItem.FullName,
+		FailedStatus: failedStatus,
+		Status:       newStatus,
+		ErrorCode:    contracts.RevertName(reason),
+		ErrorMessage: reason.Error(),
+		Attempts:     queueItem.Attempts,
+		DateCreated:  queueItem.DateCreated,
+		DateDied:     time.Now().Unix(),
+	}
+
+	// item can die again after it was requeued
+	_, err := aqueue.deadColl.ReplaceOne(ctx, findItemByIndexQuery{Index: queueItem.Index}, dead, options.Replace().SetUpsert(true))
+	return err
+}
+
+// newest first
+func (aqueue *anynsQueue) GetDeadItems(ctx context.Context, limit int64) ([]DeadItem, error) {
+	type findAllItemsQuery struct {
+	}
+
+	opts := options.Find().SetSort(map[string]int{"dateDied": -1}).SetLimit(limit)
+	cursor, err := aqueue.deadColl.Find(ctx, findAllItemsQuery{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []DeadItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (aqueue *anynsQueue) RequeueDeadItem(ctx context.Context, index int64) error {
+	// 1 - find both records
+	dead, queueItem, err := aqueue.findDeadItem(ctx, index)
+	if err != nil {
+		return err
+	}
+
+	// 2 - item is processed from the failed state with all attempts available
+	queueItem.Status = dead.FailedStatus
+	queueItem.StateRetry = 0
+	queueItem.ErrorCode = ""
+	queueItem.ErrorMessage = ""
+
+	// register is started again with a new secret if the commitment can not be used anymore
+	// (previous commitment can still exist and be unexpired, so it is never sent again)
+	if queueItem.ItemType == ItemType_NameRegister && dead.FailedStatus != OperationStatus_RegisterSent {
+		isValid, err := aqueue.isCommitmentValid(ctx, queueItem, dead)
+		if err != nil {
+			return err
+		}
+		if !isValid {
+			secret, err := contracts.GenerateRandomSecret()
+			if err != nil {
+				return err
+			}
+			queueItem.SecretBase64 = b64.StdEncoding.EncodeToString(secret[:])
+			queueItem.Status = OperationStatus_Initial
+		}
+	}
+
+	err = aqueue.SaveItemToDb(ctx, queueItem)
+	if err != nil {
+		return err
+	}
+
+	// 3 - remove from the dead items and process it
+	_, err = aqueue.deadColl.DeleteOne(ctx, findItemByIndexQuery{Index: index})
+	if err != nil {
+		return err
+	}
+
+	log.Info("dead item is requeued", zap.Int64("Item Index", index), zap.Stringer("state", queueItem.Status))
+	return aqueue.enqueue(ctx, index)
+}
+
+// commitment was mined and the name can still be registered with it
+func (aqueue *anynsQueue) isCommitmentValid(ctx context.Context, queueItem *QueueItem, dead *DeadItem) (bool, error) {
+	if dead.FailedStatus != OperationStatus_CommitDone || queueItem.CommitDoneAt == 0 {
+		return false, nil
+	}
+	// register tx was reverted with contracts.ErrCommitmentTooOld
+	if dead.ErrorCode == "CommitmentTooOld" {
+		return false, nil
+	}
+
+	maxAge, err := aqueue.contracts.GetMaxCommitmentAge(ctx)
+	if err != nil {
+		return false, err
+	}
+	// register tx should be mined before the commitment expires
+	expires := queueItem.CommitDoneAt + maxAge.Int64() - int64(commitmentExpiryMargin.Seconds())
+	return time.Now().Unix() < expires, nil
+}
+
+// item stays in the error state and is never processed again
+func (aqueue *anynsQueue) AbandonDeadItem(ctx context.Context, index int64) error {
+	res, err := aqueue.deadColl.DeleteOne(ctx, findItemByIndexQuery{Index: index})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrItemNotFound
+	}
+
+	log.Info("dead item is abandoned", zap.Int64("Item Index", index))
+	return nil
+}
+
+func (aqueue *anynsQueue) findDeadItem(ctx context.Context, index int64) (*DeadItem, *QueueItem, error) {
+	var dead DeadItem
+	err := aqueue.deadColl.FindOne(ctx, findItemByIndexQuery{Index: index}).Decode(&dead)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queueItem, err := aqueue.GetItem(ctx, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &dead, queueItem, nil
+}
+
+// item is added to the in-memory queue again after the backoff
+// (i.e. if DB was not available)
+func (aqueue *anynsQueue) addLater(ctx context.Context, index int64) {
+	aqueue.addAfter(ctx, index, aqueue.retry.backoff(1))
+}
+
+// item is added to the in-memory queue when its retry is due (see QueueItem.NextAttemptAt)
+func (aqueue *anynsQueue) addAt(ctx context.Context, index int64, nextAttemptAt int64) {
+	aqueue.addAfter(ctx, index, time.Until(time.Unix(nextAttemptAt, 0)))
+}
+
+func (aqueue *anynsQueue) addAfter(ctx context.Context, index int64, delay time.Duration) {
+	go func() {
+		if !sleep(ctx, delay) {
+			return
+		}
+		err := aqueue.enqueue(ctx, index)
+		if err != nil && ctx.Err() == nil {
+			log.Error("can not add item to the queue", zap.Error(err), zap.Int64("Item Index", index))
+		}
+	}()
+}
@@ -1,6 +1,8 @@
 package queue
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
@@ -12,7 +14,8 @@ type QueueItemStatus int32
 
 // when adding new status, don't forget to update these function:
 // 1. StatusToState
-// 2. NameRegisterMoveStateNext (IsStopProcessing in some rare cases)
+// 2. NameRegisterMoveStateNext or nameRenewMoveStateNext (IsStopProcessing in some rare cases)
+// 3. FindAndProcessAllItemsInDb
 const (
 	OperationStatus_Initial    QueueItemStatus = 0
 	OperationStatus_CommitSent QueueItemStatus = 1
@@ -25,19 +28,67 @@ const (
 	OperationStatus_RegisterError QueueItemStatus = 6
 
 	OperationStatus_Error QueueItemStatus = 7
+
+	// ItemType_NameRenew only
+	OperationStatus_RenewSent  QueueItemStatus = 8
+	OperationStatus_RenewError QueueItemStatus = 9
+
+	// item was cancelled by the admin, it is not processed anymore
+	OperationStatus_Cancelled QueueItemStatus = 10
 )
 
+// names are used in the config (queue.retry.maxAttemptsPerState) and in the logs
+var statusNames = map[QueueItemStatus]string{
+	OperationStatus_Initial:       "initial",
+	OperationStatus_CommitSent:    "commitSent",
+	OperationStatus_CommitDone:    "commitDone",
+	OperationStatus_RegisterSent:  "registerSent",
+	OperationStatus_Completed:     "completed",
+	OperationStatus_CommitError:   "commitError",
+	OperationStatus_RegisterError: "registerError",
+	OperationStatus_Error:         "error",
+	OperationStatus_RenewSent:     "renewSent",
+	OperationStatus_RenewError:    "renewError",
+	OperationStatus_Cancelled:     "cancelled",
+}
+
+func (s QueueItemStatus) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+func ParseStatus(name string) (QueueItemStatus, bool) {
+	for status, n := range statusNames {
+		if n == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
 const (
 	ItemType_NameRegister QueueItemType = 1
 	ItemType_NameRenew    QueueItemType = 2
 )
 
+func (t QueueItemType) String() string {
+	switch t {
+	case ItemType_NameRegister:
+		return "nameRegister"
+	case ItemType_NameRenew:
+		return "nameRenew"
+	}
+	return "unknown(" + strconv.Itoa(int(t)) + ")"
+}
+
 func StatusToState(status QueueItemStatus) nsp.OperationState {
 	switch status {
-	case OperationStatus_Initial, OperationStatus_CommitSent, OperationStatus_CommitDone, OperationStatus_RegisterSent:
+	case OperationStatus_Initial, OperationStatus_CommitSent, OperationStatus_CommitDone, OperationStatus_RegisterSent, OperationStatus_RenewSent:
 		return nsp.OperationState_Pending
 
-	case OperationStatus_CommitError, OperationStatus_RegisterError, OperationStatus_Error:
+	case OperationStatus_CommitError, OperationStatus_RegisterError, OperationStatus_RenewError, OperationStatus_Error, OperationStatus_Cancelled:
 		return nsp.OperationState_Error
 
 	case OperationStatus_Completed:
@@ -48,6 +99,26 @@ func StatusToState(status QueueItemStatus) nsp.OperationState {
 	return nsp.OperationState_Error
 }
 
+// operations of the queue are returned to the client as "queue-<index>"
+// to tell them from the AA operations (hashes of the user operations)
+const operationIDPrefix = "queue-"
+
+func OperationID(index int64) string {
+	return operationIDPrefix + strconv.FormatInt(index, 10)
+}
+
+func ParseOperationID(operationID string) (index int64, ok bool) {
+	s, found := strings.CutPrefix(operationID, operationIDPrefix)
+	if !found {
+		return 0, false
+	}
+	index, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || index < 0 {
+		return 0, false
+	}
+	return index, true
+}
+
 // this structure is saved to mem queue and to DB
 type QueueItem struct {
 	Index           int64         `bson:"index"`
@@ -66,20 +137,80 @@ type QueueItem struct {
 	DateCreated          int64           `bson:"dateCreated"`
 	DateModified         int64           `bson:"dateModified"`
 	RegisterPeriodMonths uint32          `bson:"registerPeriodMonths"`
+	// when commit tx was mined, name can be registered with it only during MaxCommitmentAge
+	CommitDoneAt int64 `bson:"commitDoneAt,omitempty"`
 
-	// for ItemType_NameRenew
-	TxRenewCommitHash string `bson:"txRenewCommitHash"`
+	// for ItemType_NameRenew (renewal period is in RegisterPeriodMonths)
+	TxRenewHash  string `bson:"txRenewHash"`
+	TxRenewNonce uint64 `bson:"txRenewNonce"`
 
 	TxCurrentNonce uint64 `bson:"currentTxNonce"`
 	TxCurrentRetry uint   `bson:"currentTxRetry"`
+
+	// hashes of the txs of the current state that were replaced by the tx with higher fees
+	// (the latest one is in TxCommitHash/TxRegisterHash/TxRenewHash), any of them can be mined
+	TxReplacedHashes []string `bson:"txReplacedHashes,omitempty"`
+
+	// block with the last (register/renew) tx
+	// is set only after tx has ConfirmationBlocks on top of it
+	BlockNumber int64  `bson:"blockNumber"`
+	BlockHash   string `bson:"blockHash"`
+
+	// why item is in the error state
+	// code is the name of the contract's custom error (like "NameNotAvailable") if tx was reverted
+	ErrorCode    string `bson:"errorCode,omitempty"`
+	ErrorMessage string `bson:"errorMessage,omitempty"`
+
+	// failed attempts of the current state (nonce errors are not counted)
+	StateRetry uint `bson:"stateRetry"`
+	// item is not processed before this time (unix seconds), it is waiting for the retry backoff
+	NextAttemptAt int64 `bson:"nextAttemptAt,omitempty"`
+	// all failed attempts of the item
+	Attempts []QueueItemAttempt `bson:"attempts,omitempty"`
+
+	// state that the item was cancelled in (see OperationStatus_Cancelled)
+	CancelledStatus QueueItemStatus `bson:"cancelledStatus,omitempty"`
+
+	// node that processes the item right now and when its lease expires (see lease.go)
+	LeaseOwner   string `bson:"leaseOwner,omitempty"`
+	LeaseExpires int64  `bson:"leaseExpires,omitempty"`
+}
+
+type QueueItemAttempt struct {
+	// state that has failed
+	Status       QueueItemStatus `bson:"status"`
+	ErrorCode    string          `bson:"errorCode,omitempty"`
+	ErrorMessage string          `bson:"errorMessage"`
+	Permanent    bool            `bson:"permanent"`
+	Date         int64           `bson:"date"`
+}
+
+// item that has failed and is not processed anymore
+// it is saved to the queue-dead collection, item itself stays in the queue with the error status
+type DeadItem struct {
+	Index    int64         `bson:"index"`
+	ItemType QueueItemType `bson:"itemType"`
+	FullName string        `bson:"fullName"`
+
+	// item is processed from this state again if it is requeued
+	FailedStatus QueueItemStatus `bson:"failedStatus"`
+	// status of the item in the queue (one of the error statuses)
+	Status QueueItemStatus `bson:"status"`
+
+	ErrorCode    string             `bson:"errorCode,omitempty"`
+	ErrorMessage string             `bson:"errorMessage"`
+	Attempts     []QueueItemAttempt `bson:"attempts"`
+
+	DateCreated int64 `bson:"dateCreated"`
+	DateDied    int64 `bson:"dateDied"`
 }
 
 // convert item to in-memory queue struct from initial dRPC request struct
-func queueItemFromNameRegisterRequest(req *nsp.NameRegisterRequest, count int64) QueueItem {
+func queueItemFromNameRegisterRequest(req *nsp.NameRegisterRequest, index int64) QueueItem {
 	currTime := time.Now().Unix()
 
 	return QueueItem{
-		Index:           count,
+		Index:           index,
 		ItemType:        ItemType_NameRegister,
 		FullName:        req.FullName,
 		OwnerAnyAddress: req.OwnerAnyAddress,
@@ -97,12 +228,11 @@ func queueItemFromNameRegisterRequest(req *nsp.NameRegisterRequest, count int64)
 	}
 }
 
-/*
-func queueItemFromNameRenewRequest(req *nsp.NameRenewRequest, count int64) QueueItem {
+func queueItemFromNameRenewRequest(req *nsp.NameRenewRequest, index int64) QueueItem {
 	currTime := time.Now().Unix()
 
 	return QueueItem{
-		Index:           count,
+		Index:           index,
 		ItemType:        ItemType_NameRenew,
 		FullName:        req.FullName,
 		OwnerAnyAddress: req.OwnerAnyAddress,
@@ -115,7 +245,6 @@ func queueItemFromNameRenewRequest(req *nsp.NameRenewRequest, count int64) Queue
 		DateModified: currTime,
 	}
 }
-*/
 
 // TODO: remove this
 func nameRegisterRequestFromQueueItem(item QueueItem) *nsp.NameRegisterRequest {
@@ -0,0 +1,259 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"time"
+
+	"github.com/cheggaaa/mb/v3"
+	"github.com/cockroachdb/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	seqCollectionName = "queue-seq"
+	seqId             = "index"
+
+	defaultLeaseTimeout = time.Minute
+)
+
+var ErrLeaseLost = errors.New("item is processed by another node")
+
+// all states that are processed by the worker
+var pendingStatuses = []QueueItemStatus{
+	OperationStatus_Initial,
+	OperationStatus_CommitSent,
+	OperationStatus_CommitDone,
+	OperationStatus_RegisterSent,
+	OperationStatus_RenewSent,
+}
+
+// unique for each run of the node
+func newLeaseOwner() (string, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 4)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return host + "-" + hex.EncodeToString(b), nil
+}
+
+// sequence continues the indexes of the items that were added before it existed
+func (aqueue *anynsQueue) initSequence(ctx context.Context) error {
+	var last QueueItem
+	next := int64(0)
+	err := aqueue.itemColl.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "index", Value: -1}})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if err == nil {
+		next = last.Index + 1
+	}
+
+	// $max does not move it back if other node has already added items
+	_, err = aqueue.seqColl.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: seqId}},
+		bson.D{{Key: "$max", Value: bson.D{{Key: "next", Value: next}}}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// index of the new item, unique even if several nodes add items at the same time
+func (aqueue *anynsQueue) nextIndex(ctx context.Context) (int64, error) {
+	var seq struct {
+		Next int64 `bson:"next"`
+	}
+	err := aqueue.seqColl.FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: seqId}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "next", Value: 1}}}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)).Decode(&seq)
+	if err == mongo.ErrNoDocuments {
+		// was just created by the upsert
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return seq.Next, nil
+}
+
+// item is not processed by any node right now
+func notLeased(now int64) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "leaseOwner", Value: nil}},
+		bson.D{{Key: "leaseExpires", Value: bson.D{{Key: "$lte", Value: now}}}},
+	}}
+}
+
+// item is not processed by other node right now
+func notLeasedByOthers(owner string, now int64) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "leaseOwner", Value: nil}},
+		bson.D{{Key: "leaseOwner", Value: owner}},
+		bson.D{{Key: "leaseExpires", Value: bson.D{{Key: "$lte", Value: now}}}},
+	}}
+}
+
+// item is not waiting for the retry backoff (field is not set for the items that were never retried)
+func retryIsDue(now int64) bson.E {
+	return bson.E{Key: "nextAttemptAt", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gt", Value: now}}}}}
+}
+
+// returns ErrItemNotFound if there is no pending item that can be processed by this node
+func (aqueue *anynsQueue) claimItem(ctx context.Context, filter bson.D) (*QueueItem, error) {
+	now := time.Now()
+	query := append(bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: pendingStatuses}}},
+		notLeasedByOthers(aqueue.leaseOwner, now.Unix()),
+		retryIsDue(now.Unix()),
+	}, filter...)
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "leaseOwner", Value: aqueue.leaseOwner},
+		{Key: "leaseExpires", Value: now.Add(aqueue.leaseTimeout).Unix()},
+	}}}
+
+	var queueItem QueueItem
+	err := aqueue.itemColl.FindOneAndUpdate(ctx, query, update,
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "index", Value: 1}}).SetReturnDocument(options.After)).Decode(&queueItem)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &queueItem, nil
+}
+
+// lease is extended while the item is processed
+// processing is stopped if other node has taken the item (i.e. this node was paused for too long)
+func (aqueue *anynsQueue) processClaimedItem(ctx context.Context, queueItem *QueueItem) error {
+	processCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		aqueue.heartbeat(processCtx, cancel, queueItem.Index)
+	}()
+
+	err := aqueue.ProcessItem(processCtx, queueItem)
+	isLeaseLost := ctx.Err() == nil && processCtx.Err() != nil
+	cancel()
+	<-heartbeatDone
+
+	if isLeaseLost {
+		log.Info("item is processed by another node, stop processing it", zap.Int64("Item Index", queueItem.Index))
+		return nil
+	}
+
+	// item should be released even if the node is stopping
+	aqueue.releaseItem(context.WithoutCancel(ctx), queueItem.Index)
+	return err
+}
+
+func (aqueue *anynsQueue) heartbeat(ctx context.Context, stop context.CancelFunc, index int64) {
+	ticker := time.NewTicker(aqueue.leaseTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := aqueue.itemColl.UpdateOne(ctx,
+			bson.D{{Key: "index", Value: index}, {Key: "leaseOwner", Value: aqueue.leaseOwner}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "leaseExpires", Value: time.Now().Add(aqueue.leaseTimeout).Unix()}}}})
+		if err != nil {
+			// lease is still valid for a while, try again on the next tick
+			log.Warn("can not extend lease of the item", zap.Error(err), zap.Int64("Item Index", index))
+			continue
+		}
+		if res.MatchedCount == 0 {
+			log.Warn("lease of the item is lost, stop processing it", zap.Int64("Item Index", index))
+			stop()
+			return
+		}
+	}
+}
+
+func (aqueue *anynsQueue) releaseItem(ctx context.Context, index int64) {
+	_, err := aqueue.itemColl.UpdateOne(ctx,
+		bson.D{{Key: "index", Value: index}, {Key: "leaseOwner", Value: aqueue.leaseOwner}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "leaseOwner", Value: ""}, {Key: "leaseExpires", Value: ""}}}})
+	if err != nil {
+		// other nodes will take it after the lease expires
+		log.Error("can not release item", zap.Error(err), zap.Int64("Item Index", index))
+	}
+}
+
+// items of the stopped nodes are added to the in-memory queue after their leases expire
+// as well as the items that were never claimed (i.e. node was stopped right after it has added the item)
+func (aqueue *anynsQueue) reclaimer(ctx context.Context) {
+	ticker := time.NewTicker(aqueue.leaseTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := aqueue.reclaimExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("can not find items with expired leases", zap.Error(err))
+		}
+	}
+}
+
+func (aqueue *anynsQueue) reclaimExpired(ctx context.Context) error {
+	now := time.Now()
+	query := bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: pendingStatuses}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "leaseExpires", Value: bson.D{{Key: "$lte", Value: now.Unix()}}}},
+			bson.D{
+				{Key: "leaseOwner", Value: nil},
+				{Key: "dateModified", Value: bson.D{{Key: "$lte", Value: now.Add(-aqueue.leaseTimeout).Unix()}}},
+			},
+		}},
+		retryIsDue(now.Unix()),
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "index", Value: 1}}).SetProjection(bson.D{{Key: "index", Value: 1}})
+	cursor, err := aqueue.itemColl.Find(ctx, query, opts)
+	if err != nil {
+		return err
+	}
+
+	var items []QueueItem
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		return err
+	}
+
+	// items that are already in the in-memory queue are skipped
+	// reclaimer never waits for the free space, the rest is added on the next tick
+	for _, item := range items {
+		err = aqueue.tryEnqueue(item.Index)
+		if err == mb.ErrOverflowed {
+			log.Info("queue is full, add the rest of items later", zap.Int64("Item Index", item.Index))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
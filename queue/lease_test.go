@@ -0,0 +1,277 @@
+package queue
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/mock/gomock"
+
+	"github.com/anyproto/any-ns-node/nonce_manager"
+)
+
+// another node that shares the same DB
+func (fx *fixture) otherNode() *anynsQueue {
+	return &anynsQueue{
+		itemColl:     fx.itemColl,
+		deadColl:     fx.deadColl,
+		seqColl:      fx.seqColl,
+		leaseOwner:   "other",
+		leaseTimeout: time.Minute,
+		retry:        fx.retry,
+	}
+}
+
+func TestAnynsQueue_NextIndex(t *testing.T) {
+	t.Run("indexes are unique for concurrent callers", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		other := fx.otherNode()
+
+		var mu sync.Mutex
+		indexes := make(map[int64]bool)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(node *anynsQueue) {
+				defer wg.Done()
+				index, err := node.nextIndex(ctx)
+				assert.NoError(t, err)
+
+				mu.Lock()
+				indexes[index] = true
+				mu.Unlock()
+			}([]*anynsQueue{fx.anynsQueue, other}[i%2])
+		}
+		wg.Wait()
+
+		require.Len(t, indexes, 20)
+		for i := int64(0); i < 20; i++ {
+			require.True(t, indexes[i])
+		}
+	})
+
+	t.Run("continue indexes of existing items", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertItems(t, testItems()...)
+		require.NoError(t, fx.initSequence(ctx))
+
+		index, err := fx.nextIndex(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), index)
+
+		// sequence is never moved back
+		require.NoError(t, fx.initSequence(ctx))
+		index, err = fx.nextIndex(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), index)
+	})
+}
+
+func TestAnynsQueue_ClaimItem(t *testing.T) {
+	t.Run("item is claimed by one node only", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		insertItems(t, testItems()...)
+		other := fx.otherNode()
+
+		item, err := fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+		require.NoError(t, err)
+		require.Equal(t, fx.leaseOwner, item.LeaseOwner)
+		require.Greater(t, item.LeaseExpires, time.Now().Unix())
+
+		_, err = other.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+		require.ErrorIs(t, err, ErrItemNotFound)
+
+		// other node gets the next item with the same status
+		item, err = other.claimItem(ctx, bson.D{{Key: "status", Value: OperationStatus_CommitSent}})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), item.Index)
+
+		// completed items are not claimed
+		_, err = other.claimItem(ctx, bson.D{{Key: "index", Value: int64(4)}})
+		require.ErrorIs(t, err, ErrItemNotFound)
+
+		// released item can be claimed by other node
+		fx.releaseItem(ctx, 1)
+		item, err = other.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+		require.NoError(t, err)
+		require.Equal(t, "other", item.LeaseOwner)
+	})
+
+	t.Run("expired lease can be taken", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		items := testItems()
+		items[0].LeaseOwner = "stopped"
+		items[0].LeaseExpires = time.Now().Unix() - 1
+		items[2].LeaseOwner = "running"
+		items[2].LeaseExpires = time.Now().Unix() + 100
+		insertItems(t, items...)
+
+		item, err := fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+		require.NoError(t, err)
+		require.Equal(t, fx.leaseOwner, item.LeaseOwner)
+
+		_, err = fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(3)}})
+		require.ErrorIs(t, err, ErrItemNotFound)
+	})
+
+	t.Run("item is not claimed before its retry is due", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		items := testItems()
+		items[0].NextAttemptAt = time.Now().Unix() + 100
+		items[1].NextAttemptAt = time.Now().Unix() - 1
+		insertItems(t, items...)
+
+		_, err := fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+		require.ErrorIs(t, err, ErrItemNotFound)
+
+		// items that were never retried or whose retry is due are claimed
+		item, err := fx.claimItem(ctx, bson.D{{Key: "status", Value: OperationStatus_CommitSent}})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), item.Index)
+
+		item, err = fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(3)}})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), item.Index)
+	})
+}
+
+func TestAnynsQueue_ReclaimExpired(t *testing.T) {
+	t.Run("reclaimer does not block if there are more items than the queue holds", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		// not claimed by any node for a long time
+		var items []QueueItem
+		for i := int64(0); i < 15; i++ {
+			items = append(items, QueueItem{Index: i, ItemType: ItemType_NameRenew, FullName: "renew.any", Status: OperationStatus_RenewSent, DateModified: time.Now().Unix() - 3600})
+		}
+		insertItems(t, items...)
+
+		tickCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		// items that are still in the queue are not added again
+		require.NoError(t, fx.reclaimExpired(tickCtx))
+		require.NoError(t, fx.reclaimExpired(tickCtx))
+		require.Equal(t, 10, fx.q.Len())
+
+		// new item is added while the queue is full
+		go func() {
+			_, _ = fx.q.Wait(ctx)
+		}()
+		_, err := fx.addItem(tickCtx, &QueueItem{Index: 15, ItemType: ItemType_NameRenew, FullName: "new.any", Status: OperationStatus_Initial})
+		require.NoError(t, err)
+
+		// the rest is added on the next tick
+		require.NoError(t, fx.reclaimExpired(tickCtx))
+		indexes := fx.q.GetAll()
+		require.Equal(t, []int64{15, 10, 11, 12, 13, 14}, indexes)
+	})
+}
+
+func TestAnynsQueue_SaveItemToDbLeased(t *testing.T) {
+	fx := newFixture(t)
+	defer fx.finish(t)
+
+	insertItems(t, testItems()...)
+	other := fx.otherNode()
+
+	item, err := fx.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+	require.NoError(t, err)
+
+	// lease is taken by other node after it has expired
+	_, err = fx.itemColl.UpdateOne(ctx, bson.D{{Key: "index", Value: int64(1)}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "leaseExpires", Value: time.Now().Unix() - 1}}}})
+	require.NoError(t, err)
+	_, err = other.claimItem(ctx, bson.D{{Key: "index", Value: int64(1)}})
+	require.NoError(t, err)
+
+	// so this node can not overwrite it
+	item.Status = OperationStatus_CommitDone
+	err = fx.SaveItemToDb(ctx, item)
+	require.ErrorIs(t, err, ErrLeaseLost)
+
+	saved, err := fx.GetItem(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, OperationStatus_CommitSent, saved.Status)
+	require.Equal(t, "other", saved.LeaseOwner)
+}
+
+// nonce manager of the node, nonces of all nodes are stored in the same DB
+func (fx *fixture) dbNonceManager(t *testing.T) nonce_manager.NonceService {
+	a := new(app.App)
+	a.Register(fx.config).
+		Register(fx.contracts).
+		Register(nonce_manager.New())
+	require.NoError(t, a.Start(ctx))
+	t.Cleanup(func() { _ = a.Close(ctx) })
+	return a.MustComponent(nonce_manager.CName).(nonce_manager.NonceService)
+}
+
+func TestAnynsQueue_AllocateNonce(t *testing.T) {
+	t.Run("nodes never send txs with the same nonce", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().GenerateAuthOptsForAdmin(gomock.Any()).AnyTimes()
+		fx.contracts.EXPECT().Renew(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (*types.Transaction, error) {
+			return types.NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil), nil
+		}).AnyTimes()
+
+		other := fx.otherNode()
+		other.contracts = fx.contracts
+		other.confContracts = fx.confContracts
+		nodes := []*anynsQueue{fx.anynsQueue, other}
+		for _, node := range nodes {
+			node.nonceManager = fx.dbNonceManager(t)
+		}
+		_, err := fx.anynsQueue.nonceManager.SaveNonce(ctx, common.HexToAddress(fx.confContracts.AddrAdmin), 10)
+		require.NoError(t, err)
+
+		var items []QueueItem
+		for i := int64(0); i < 20; i++ {
+			items = append(items, QueueItem{Index: i, ItemType: ItemType_NameRenew, FullName: "renew.any", Status: OperationStatus_Initial})
+		}
+		insertItems(t, items...)
+
+		var wg sync.WaitGroup
+		for i := range items {
+			wg.Add(1)
+			go func(node *anynsQueue, item *QueueItem) {
+				defer wg.Done()
+				assert.NoError(t, node.nameRenew_InitialState(ctx, item))
+			}(nodes[i%2], &items[i])
+		}
+		wg.Wait()
+
+		nonces := make(map[uint64]bool)
+		for i := range items {
+			saved, err := fx.GetItem(ctx, items[i].Index)
+			require.NoError(t, err)
+			require.Equal(t, OperationStatus_RenewSent, saved.Status)
+			nonces[saved.TxRenewNonce] = true
+		}
+		require.Len(t, nonces, 20)
+		for nonce := uint64(10); nonce < 30; nonce++ {
+			require.True(t, nonces[nonce])
+		}
+	})
+}
@@ -42,6 +42,20 @@ func (m *MockQueueService) EXPECT() *MockQueueServiceMockRecorder {
 	return m.recorder
 }
 
+// AbandonDeadItem mocks base method.
+func (m *MockQueueService) AbandonDeadItem(ctx context.Context, index int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbandonDeadItem", ctx, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbandonDeadItem indicates an expected call of AbandonDeadItem.
+func (mr *MockQueueServiceMockRecorder) AbandonDeadItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbandonDeadItem", reflect.TypeOf((*MockQueueService)(nil).AbandonDeadItem), ctx, index)
+}
+
 // AddNewRequest mocks base method.
 func (m *MockQueueService) AddNewRequest(ctx context.Context, req *nameserviceproto.NameRegisterRequest) (int64, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +71,51 @@ func (mr *MockQueueServiceMockRecorder) AddNewRequest(ctx, req any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNewRequest", reflect.TypeOf((*MockQueueService)(nil).AddNewRequest), ctx, req)
 }
 
+// AddRenewRequest mocks base method.
+func (m *MockQueueService) AddRenewRequest(ctx context.Context, req *nameserviceproto.NameRenewRequest) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRenewRequest", ctx, req)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRenewRequest indicates an expected call of AddRenewRequest.
+func (mr *MockQueueServiceMockRecorder) AddRenewRequest(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRenewRequest", reflect.TypeOf((*MockQueueService)(nil).AddRenewRequest), ctx, req)
+}
+
+// AdvanceItem mocks base method.
+func (m *MockQueueService) AdvanceItem(ctx context.Context, index int64) (*queue.QueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceItem", ctx, index)
+	ret0, _ := ret[0].(*queue.QueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceItem indicates an expected call of AdvanceItem.
+func (mr *MockQueueServiceMockRecorder) AdvanceItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceItem", reflect.TypeOf((*MockQueueService)(nil).AdvanceItem), ctx, index)
+}
+
+// CancelItem mocks base method.
+func (m *MockQueueService) CancelItem(ctx context.Context, index int64) (*queue.QueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelItem", ctx, index)
+	ret0, _ := ret[0].(*queue.QueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelItem indicates an expected call of CancelItem.
+func (mr *MockQueueServiceMockRecorder) CancelItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelItem", reflect.TypeOf((*MockQueueService)(nil).CancelItem), ctx, index)
+}
+
 // Close mocks base method.
 func (m *MockQueueService) Close(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -71,6 +130,21 @@ func (mr *MockQueueServiceMockRecorder) Close(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockQueueService)(nil).Close), ctx)
 }
 
+// CountItemsByStatus mocks base method.
+func (m *MockQueueService) CountItemsByStatus(ctx context.Context) (map[queue.QueueItemStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountItemsByStatus", ctx)
+	ret0, _ := ret[0].(map[queue.QueueItemStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountItemsByStatus indicates an expected call of CountItemsByStatus.
+func (mr *MockQueueServiceMockRecorder) CountItemsByStatus(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountItemsByStatus", reflect.TypeOf((*MockQueueService)(nil).CountItemsByStatus), ctx)
+}
+
 // FindAndProcessAllItemsInDb mocks base method.
 func (m *MockQueueService) FindAndProcessAllItemsInDb(ctx context.Context) {
 	m.ctrl.T.Helper()
@@ -95,6 +169,36 @@ func (mr *MockQueueServiceMockRecorder) FindAndProcessAllItemsInDbWithStatus(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAndProcessAllItemsInDbWithStatus", reflect.TypeOf((*MockQueueService)(nil).FindAndProcessAllItemsInDbWithStatus), ctx, status)
 }
 
+// GetDeadItems mocks base method.
+func (m *MockQueueService) GetDeadItems(ctx context.Context, limit int64) ([]queue.DeadItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadItems", ctx, limit)
+	ret0, _ := ret[0].([]queue.DeadItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadItems indicates an expected call of GetDeadItems.
+func (mr *MockQueueServiceMockRecorder) GetDeadItems(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadItems", reflect.TypeOf((*MockQueueService)(nil).GetDeadItems), ctx, limit)
+}
+
+// GetItem mocks base method.
+func (m *MockQueueService) GetItem(ctx context.Context, index int64) (*queue.QueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, index)
+	ret0, _ := ret[0].(*queue.QueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockQueueServiceMockRecorder) GetItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockQueueService)(nil).GetItem), ctx, index)
+}
+
 // GetRequestStatus mocks base method.
 func (m *MockQueueService) GetRequestStatus(ctx context.Context, operationId int64) (nameserviceproto.OperationState, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +228,21 @@ func (mr *MockQueueServiceMockRecorder) Init(a any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockQueueService)(nil).Init), a)
 }
 
+// ListItems mocks base method.
+func (m *MockQueueService) ListItems(ctx context.Context, filter queue.ListItemsFilter) ([]queue.QueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, filter)
+	ret0, _ := ret[0].([]queue.QueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockQueueServiceMockRecorder) ListItems(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockQueueService)(nil).ListItems), ctx, filter)
+}
+
 // Name mocks base method.
 func (m *MockQueueService) Name() string {
 	m.ctrl.T.Helper()
@@ -167,6 +286,35 @@ func (mr *MockQueueServiceMockRecorder) ProcessItem(ctx, queueItem any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessItem", reflect.TypeOf((*MockQueueService)(nil).ProcessItem), ctx, queueItem)
 }
 
+// RequeueDeadItem mocks base method.
+func (m *MockQueueService) RequeueDeadItem(ctx context.Context, index int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadItem", ctx, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueDeadItem indicates an expected call of RequeueDeadItem.
+func (mr *MockQueueServiceMockRecorder) RequeueDeadItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadItem", reflect.TypeOf((*MockQueueService)(nil).RequeueDeadItem), ctx, index)
+}
+
+// RequeueItem mocks base method.
+func (m *MockQueueService) RequeueItem(ctx context.Context, index int64) (*queue.QueueItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueItem", ctx, index)
+	ret0, _ := ret[0].(*queue.QueueItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueItem indicates an expected call of RequeueItem.
+func (mr *MockQueueServiceMockRecorder) RequeueItem(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueItem", reflect.TypeOf((*MockQueueService)(nil).RequeueItem), ctx, index)
+}
+
 // Run mocks base method.
 func (m *MockQueueService) Run(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -4,20 +4,24 @@ import (
 	"context"
 	b64 "encoding/base64"
 	"math/big"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
 	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/any-ns-node/cache"
 	"github.com/anyproto/any-ns-node/config"
 	contracts "github.com/anyproto/any-ns-node/contracts"
 	"github.com/anyproto/any-ns-node/nonce_manager"
 	nsp "github.com/anyproto/any-sync/nameservice/nameserviceproto"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -28,6 +32,11 @@ const CName = "any-ns.queue"
 
 var log = logger.NewNamed(CName)
 
+var (
+	ErrItemNotFound  = errors.New("item not found")
+	ErrItemCancelled = errors.New("item was cancelled")
+)
+
 type findItemByIndexQuery struct {
 	Index int64 `bson:"index"`
 }
@@ -39,8 +48,25 @@ func New() app.ComponentRunnable {
 type QueueService interface {
 	// 1 - new name registration request
 	AddNewRequest(ctx context.Context, req *nsp.NameRegisterRequest) (operationId int64, err error)
+	// name renewal request (one renew tx, no commitment)
+	AddRenewRequest(ctx context.Context, req *nsp.NameRenewRequest) (operationId int64, err error)
 	GetRequestStatus(ctx context.Context, operationId int64) (status nsp.OperationState, err error)
 
+	// items that failed with a permanent error or used all attempts (newest first)
+	GetDeadItems(ctx context.Context, limit int64) ([]DeadItem, error)
+	// process the dead item again from the state where it failed
+	RequeueDeadItem(ctx context.Context, index int64) error
+	// remove the dead item, it stays in the error state
+	AbandonDeadItem(ctx context.Context, index int64) error
+
+	// admin methods to inspect and control the items
+	ListItems(ctx context.Context, filter ListItemsFilter) ([]QueueItem, error)
+	GetItem(ctx context.Context, index int64) (*QueueItem, error)
+	CountItemsByStatus(ctx context.Context) (map[QueueItemStatus]int64, error)
+	CancelItem(ctx context.Context, index int64) (*QueueItem, error)
+	AdvanceItem(ctx context.Context, index int64) (*QueueItem, error)
+	RequeueItem(ctx context.Context, index int64) (*QueueItem, error)
+
 	// Internal methods (public for tests):
 	// read all "pending" items from DB and try to process em during startup
 	FindAndProcessAllItemsInDb(ctx context.Context)
@@ -58,16 +84,25 @@ type QueueService interface {
 }
 
 type anynsQueue struct {
-	q    *mb.MB[int64]
-	done chan bool
+	q      *mb.MB[int64]
+	cancel context.CancelFunc
+	done   chan bool
+	// indexes that are in the in-memory queue and are not taken by the worker yet
+	queued sync.Map
 
 	confMongo     config.Mongo
 	confContracts config.Contracts
 	confQueue     config.Queue
 
 	itemColl     *mongo.Collection
+	deadColl     *mongo.Collection
+	seqColl      *mongo.Collection
+	leaseOwner   string
+	leaseTimeout time.Duration
+	retry        retryPolicy
 	contracts    contracts.ContractsService
 	nonceManager nonce_manager.NonceService
+	cache        cache.CacheService
 }
 
 func (aqueue *anynsQueue) Name() (name string) {
@@ -81,6 +116,21 @@ func (aqueue *anynsQueue) Init(a *app.App) (err error) {
 
 	aqueue.nonceManager = a.MustComponent(nonce_manager.CName).(nonce_manager.NonceService)
 	aqueue.contracts = a.MustComponent(contracts.CName).(contracts.ContractsService)
+	aqueue.cache = a.MustComponent(cache.CName).(cache.CacheService)
+
+	aqueue.retry, err = newRetryPolicy(aqueue.confQueue.Retry)
+	if err != nil {
+		return err
+	}
+
+	aqueue.leaseOwner, err = newLeaseOwner()
+	if err != nil {
+		return err
+	}
+	aqueue.leaseTimeout = time.Duration(aqueue.confQueue.LeaseTimeoutSec) * time.Second
+	if aqueue.leaseTimeout == 0 {
+		aqueue.leaseTimeout = defaultLeaseTimeout
+	}
 
 	aqueue.done = make(chan bool)
 	aqueue.q = mb.New[int64](10) // TODO: queue size -> config
@@ -103,9 +153,16 @@ func (aqueue *anynsQueue) Run(ctx context.Context) (err error) {
 	if aqueue.itemColl == nil {
 		return errors.New("failed to connect to MongoDB")
 	}
+	aqueue.deadColl = client.Database(dbName).Collection(deadCollectionName)
+	aqueue.seqColl = client.Database(dbName).Collection(seqCollectionName)
 
 	log.Info("mongo connected!")
 
+	err = aqueue.initSequence(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 2 - try to process all items in the DB
 	if !aqueue.confQueue.SkipExistingItemsInDB {
 		aqueue.FindAndProcessAllItemsInDb(ctx)
@@ -113,12 +170,27 @@ func (aqueue *anynsQueue) Run(ctx context.Context) (err error) {
 
 	// 3 - start one worker
 	if !aqueue.confQueue.SkipBackroundProcessing {
-		go aqueue.worker(ctx, aqueue.itemColl, aqueue.q, aqueue.done)
+		// do not use ctx here, it is used only during app start
+		// in-flight chain calls of the worker are aborted on Close
+		var workerCtx context.Context
+		workerCtx, aqueue.cancel = context.WithCancel(context.Background())
+		go aqueue.worker(workerCtx, aqueue.q, aqueue.done)
+		go aqueue.reclaimer(workerCtx)
+		log.Info("queue worker started", zap.String("lease owner", aqueue.leaseOwner), zap.Duration("lease timeout", aqueue.leaseTimeout))
 	}
 	return nil
 }
 
 func (aqueue *anynsQueue) Close(ctx context.Context) (err error) {
+	if aqueue.cancel != nil {
+		aqueue.cancel()
+
+		select {
+		case <-aqueue.done:
+		case <-ctx.Done():
+		}
+	}
+
 	if aqueue.itemColl != nil {
 		err = aqueue.itemColl.Database().Client().Disconnect(ctx)
 		aqueue.itemColl = nil
@@ -127,18 +199,12 @@ func (aqueue *anynsQueue) Close(ctx context.Context) (err error) {
 }
 
 func (aqueue *anynsQueue) AddNewRequest(ctx context.Context, req *nsp.NameRegisterRequest) (operationId int64, err error) {
-	// count all documents in the collection (filter can not be nil)
-	type countAllItemsQuery struct {
-	}
-
-	// find current item count in the queue
-	count, err := aqueue.itemColl.CountDocuments(ctx, countAllItemsQuery{})
+	index, err := aqueue.nextIndex(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	// 1 - insert into Mongo
-	item := queueItemFromNameRegisterRequest(req, count)
+	item := queueItemFromNameRegisterRequest(req, index)
 
 	// calculate new secret
 	secret, err := contracts.GenerateRandomSecret()
@@ -149,36 +215,74 @@ func (aqueue *anynsQueue) AddNewRequest(ctx context.Context, req *nsp.NameRegist
 	// convert [32]byte to base64 string
 	item.SecretBase64 = b64.StdEncoding.EncodeToString(secret[:])
 
+	return aqueue.addItem(ctx, &item)
+}
+
+func (aqueue *anynsQueue) AddRenewRequest(ctx context.Context, req *nsp.NameRenewRequest) (operationId int64, err error) {
+	index, err := aqueue.nextIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	item := queueItemFromNameRenewRequest(req, index)
+	return aqueue.addItem(ctx, &item)
+}
+
+func (aqueue *anynsQueue) addItem(ctx context.Context, item *QueueItem) (operationId int64, err error) {
+	// 1 - insert into Mongo
 	_, err = aqueue.itemColl.InsertOne(ctx, item)
 	if err != nil {
 		return 0, err
 	}
-	log.Info("inserted pending operation into DB", zap.Int64("Item Index", item.Index))
+	log.Info("inserted pending operation into DB", zap.Int64("Item Index", item.Index), zap.Any("Item Type", item.ItemType))
 
 	// 2 - insert into in-memory queue
-	err = aqueue.q.Add(ctx, item.Index)
+	err = aqueue.enqueue(ctx, item.Index)
 	if err != nil {
 		// TODO: the record in DB will be never processed
 		return 0, err
 	}
 
-	operationId = item.Index
-	return operationId, nil
+	return item.Index, nil
+}
+
+// adds the item to the in-memory queue if it is not there yet
+// blocks if the queue is full
+func (aqueue *anynsQueue) enqueue(ctx context.Context, index int64) error {
+	if _, isQueued := aqueue.queued.LoadOrStore(index, true); isQueued {
+		return nil
+	}
+	err := aqueue.q.Add(ctx, index)
+	if err != nil {
+		aqueue.queued.Delete(index)
+	}
+	return err
+}
+
+// same as enqueue, but returns mb.ErrOverflowed instead of waiting if the queue is full
+func (aqueue *anynsQueue) tryEnqueue(index int64) error {
+	if _, isQueued := aqueue.queued.LoadOrStore(index, true); isQueued {
+		return nil
+	}
+	err := aqueue.q.TryAdd(index)
+	if err != nil {
+		aqueue.queued.Delete(index)
+	}
+	return err
 }
 
 func (aqueue *anynsQueue) GetRequestStatus(ctx context.Context, operationId int64) (status nsp.OperationState, err error) {
 	// get status from the queue
-	var item QueueItem
-	result := aqueue.itemColl.FindOne(ctx, findItemByIndexQuery{Index: operationId}).Decode(&item)
-	if result == mongo.ErrNoDocuments {
-		return 0, errors.New("item not found")
+	item, err := aqueue.GetItem(ctx, operationId)
+	if err != nil {
+		return 0, err
 	}
 
 	return StatusToState(item.Status), nil
 }
 
 // runs only if "SkipBackroundProcessing" is not set
-func (aqueue *anynsQueue) worker(ctx context.Context, coll *mongo.Collection, queue *mb.MB[int64], done chan bool) {
+func (aqueue *anynsQueue) worker(ctx context.Context, queue *mb.MB[int64], done chan bool) {
 	log.Info("worker started")
 
 	// process items from in-memory queue
@@ -189,23 +293,34 @@ func (aqueue *anynsQueue) worker(ctx context.Context, coll *mongo.Collection, qu
 		}
 
 		for _, itemIndex := range items {
+			// item can be added to the queue again from now on
+			aqueue.queued.Delete(itemIndex)
 
 			// 1 - get item from DB
 			// each item in in-memory queue is an index of item in DB
-			// so please get them from DB
-			var queueItem QueueItem
-
-			// TODO: add to index
-			err := coll.FindOne(ctx, findItemByIndexQuery{Index: itemIndex}).Decode(&queueItem)
+			// item is claimed by this node, so other nodes do not process it at the same time
+			queueItem, err := aqueue.claimItem(ctx, bson.D{{Key: "index", Value: itemIndex}})
+			if err == ErrItemNotFound {
+				log.Info("item from Queue is not pending or is processed by another node, skip it", zap.Any("Item Index", itemIndex))
+				continue
+			}
 			if err != nil {
-				log.Warn("failed to get item from DB by index from Queue", zap.Error(err), zap.Any("Item Index", itemIndex))
 				// in case of error - do not stop processing queue
+				log.Error("failed to get item from DB by index from Queue, will retry later", zap.Error(err), zap.Any("Item Index", itemIndex))
+				aqueue.addLater(ctx, itemIndex)
+				continue
 			}
 
-			err = aqueue.ProcessItem(ctx, &queueItem)
-			if err != nil {
-				log.Warn("failed to process single item from Queue", zap.Error(err), zap.Any("Item Index", itemIndex))
-				// in case of error - do not stop processing queue
+			// errors of the item itself are handled in ProcessItem
+			// so this is an error of the DB or of the node
+			err = aqueue.processClaimedItem(ctx, queueItem)
+			if errors.Is(err, errRetryLater) {
+				aqueue.addAt(ctx, itemIndex, queueItem.NextAttemptAt)
+				continue
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Error("failed to process single item from Queue, will retry later", zap.Error(err), zap.Any("Item Index", itemIndex))
+				aqueue.addLater(ctx, itemIndex)
 			}
 		}
 	}
@@ -215,45 +330,63 @@ func (aqueue *anynsQueue) worker(ctx context.Context, coll *mongo.Collection, qu
 }
 
 func (aqueue *anynsQueue) FindAndProcessAllItemsInDb(ctx context.Context) {
-	aqueue.FindAndProcessAllItemsInDbWithStatus(ctx, OperationStatus_Initial)
-	aqueue.FindAndProcessAllItemsInDbWithStatus(ctx, OperationStatus_CommitSent)
-	aqueue.FindAndProcessAllItemsInDbWithStatus(ctx, OperationStatus_CommitDone)
-	aqueue.FindAndProcessAllItemsInDbWithStatus(ctx, OperationStatus_RegisterSent)
+	for _, status := range pendingStatuses {
+		aqueue.FindAndProcessAllItemsInDbWithStatus(ctx, status)
+	}
 }
 
+// items that are processed by other nodes are skipped
 func (aqueue *anynsQueue) FindAndProcessAllItemsInDbWithStatus(ctx context.Context, status QueueItemStatus) {
-	type findItemByStatusQuery struct {
-		Status QueueItemStatus `bson:"status"`
-	}
-
 	log.Info("Process all items in DB with state", zap.Any("Status", status))
 
 	for {
-		// 1 - get item from DB that has INITIAL status (not processed yet)
-		var queueItem QueueItem
-		// TODO: add to index
-		err := aqueue.itemColl.FindOne(ctx, findItemByStatusQuery{Status: status}).Decode(&queueItem)
-		if err == mongo.ErrNoDocuments {
+		// 1 - claim item from DB that has such status
+		queueItem, err := aqueue.claimItem(ctx, bson.D{{Key: "status", Value: status}})
+		if err == ErrItemNotFound {
 			log.Info("no more items in the DB with such state", zap.Any("Status", status))
 			return
 		}
 
 		if err != nil {
-			log.Warn("failed to get item from DB", zap.Error(err))
-			// in case of error - do not stop processing queue
+			log.Error("failed to get item from DB", zap.Error(err), zap.Any("Status", status))
+			return
 		}
 
-		err = aqueue.ProcessItem(ctx, &queueItem)
+		// item is still in the same state, so it would be found again and again
+		// the rest of the items with this state are processed after restart
+		err = aqueue.processClaimedItem(ctx, queueItem)
+		if errors.Is(err, errRetryLater) {
+			// item is not claimed again before NextAttemptAt, so the rest of the items are processed
+			aqueue.addAt(ctx, queueItem.Index, queueItem.NextAttemptAt)
+			continue
+		}
 		if err != nil {
-			log.Warn("failed to process item from DB. continue", zap.Error(err))
-			// in case of error - do not stop processing queue
+			log.Error("failed to process item from DB, will retry later", zap.Error(err), zap.Int64("Item Index", queueItem.Index))
+			aqueue.addLater(ctx, queueItem.Index)
+			return
 		}
 	}
 }
 
-func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem) error {
+func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem) (err error) {
 	log.Info("Found item in state", zap.Any("Item", queueItem), zap.Any("Status", queueItem.Status))
 
+	// item was cancelled by the admin, it can be cancelled while it is being processed
+	if StatusToState(queueItem.Status) != nsp.OperationState_Pending {
+		log.Info("item is not pending anymore, skip it", zap.Int64("Item Index", queueItem.Index), zap.Stringer("Status", queueItem.Status))
+		return nil
+	}
+	defer func() {
+		if errors.Is(err, ErrItemCancelled) {
+			log.Info("item was cancelled, stop processing it", zap.Int64("Item Index", queueItem.Index))
+			err = nil
+		}
+		if errors.Is(err, ErrLeaseLost) {
+			log.Info("item is processed by another node, stop processing it", zap.Int64("Item Index", queueItem.Index))
+			err = nil
+		}
+	}()
+
 	if aqueue.confQueue.SkipProcessing {
 		log.Info("skipping processing item in DB. mark item as completed", zap.Any("Item Index", queueItem.Index))
 		queueItem.Status = OperationStatus_Completed
@@ -262,10 +395,10 @@ func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem)
 
 	log.Info("processing item from DB", zap.Int64("Item Index", queueItem.Index))
 
-	// 1 - init item - reset retry count, get new nonce
-	err := aqueue.initNonce(ctx, queueItem)
+	// 1 - init item - reset nonce retry count
+	err = aqueue.initNonce(ctx, queueItem)
 	if err != nil {
-		log.Error("failed to connect to geth", zap.Error(err))
+		log.Error("failed to init item", zap.Error(err))
 		return err
 	}
 
@@ -283,7 +416,8 @@ func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem)
 		// 	OperationStatus_RegisterSent -> OperationStatus_Completed
 		//
 		// ItemType_NameRenew:
-		// 	OperationStatus_Initial ->OperationStatus_Completed
+		// 	OperationStatus_Initial -> OperationStatus_RenewSent
+		// 	OperationStatus_RenewSent -> OperationStatus_Completed
 		switch queueItem.ItemType {
 		case ItemType_NameRegister:
 			newState, err = aqueue.NameRegisterMoveStateNext(ctx, queueItem)
@@ -294,9 +428,14 @@ func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem)
 		// 3 - handle nonce errors
 		newState, err = aqueue.handleNonceErrors(ctx, err, prevState, newState, queueItem)
 
+		// 3.1 - retry transient errors, move failed item to the queue-dead collection
+		if err != nil {
+			newState, err = aqueue.handleRetry(ctx, err, prevState, newState, queueItem)
+		}
+
 		// 4 - update state in DB
 		if newState != prevState {
-			err2 := aqueue.updateItemStatus(ctx, queueItem.Index, newState)
+			err2 := aqueue.updateItemStatus(ctx, queueItem.Index, newState, err)
 			if err2 != nil {
 				log.Error("failed to update item status in DB", zap.Error(err), zap.Any("prev state", prevState), zap.Any("new state", newState))
 				return err2
@@ -307,6 +446,10 @@ func (aqueue *anynsQueue) ProcessItem(ctx context.Context, queueItem *QueueItem)
 		isStopProcessing := aqueue.isStopProcessing(err, prevState, newState)
 		if isStopProcessing {
 			log.Info("state machine: stop processing item", zap.Any("Item", queueItem))
+			if errors.Is(err, errRetryLater) {
+				// caller adds the item to the queue again
+				return err
+			}
 			return nil
 		}
 	}
@@ -321,21 +464,40 @@ func (aqueue *anynsQueue) SaveItemToDb(ctx context.Context, queueItem *QueueItem
 		return nil
 	}
 
-	queueItem.DateModified = time.Now().Unix()
+	now := time.Now()
+	queueItem.DateModified = now.Unix()
+	// saving the item extends its lease
+	if queueItem.LeaseOwner != "" {
+		queueItem.LeaseExpires = now.Add(aqueue.leaseTimeout).Unix()
+	}
 
-	res, err := aqueue.itemColl.ReplaceOne(ctx, findItemByIndexQuery{Index: queueItem.Index}, queueItem)
-	if res.MatchedCount == 0 {
-		log.Error("failed to update item in DB", zap.Error(err))
-		return errors.New("failed to update item in DB")
+	// do not overwrite the item that was cancelled by the admin
+	// or that is processed by another node
+	filter := bson.D{
+		{Key: "index", Value: queueItem.Index},
+		{Key: "status", Value: bson.D{{Key: "$ne", Value: OperationStatus_Cancelled}}},
+		notLeasedByOthers(queueItem.LeaseOwner, now.Unix()),
 	}
+	res, err := aqueue.itemColl.ReplaceOne(ctx, filter, queueItem)
 	if err != nil {
 		log.Error("failed to update item in DB", zap.Error(err))
 		return err
 	}
+	if res.MatchedCount == 0 {
+		if item, err := aqueue.GetItem(ctx, queueItem.Index); err == nil {
+			if item.Status == OperationStatus_Cancelled {
+				return ErrItemCancelled
+			}
+			return ErrLeaseLost
+		}
+		log.Error("failed to update item in DB", zap.Int64("Item Index", queueItem.Index))
+		return errors.New("failed to update item in DB")
+	}
 	return nil
 }
 
-func (aqueue *anynsQueue) updateItemStatus(ctx context.Context, itemIndex int64, newStatus QueueItemStatus) error {
+// reason is saved to the item if it is not nil
+func (aqueue *anynsQueue) updateItemStatus(ctx context.Context, itemIndex int64, newStatus QueueItemStatus, reason error) error {
 	// 1 - find item
 	var queueItem QueueItem
 
@@ -347,34 +509,32 @@ func (aqueue *anynsQueue) updateItemStatus(ctx context.Context, itemIndex int64,
 	}
 
 	// 2 - update status and save
+	// retries are counted for each state separately
 	queueItem.Status = newStatus
+	queueItem.StateRetry = 0
+	if reason != nil {
+		queueItem.ErrorCode = contracts.RevertName(reason)
+		queueItem.ErrorMessage = reason.Error()
+	}
 
 	return aqueue.SaveItemToDb(ctx, &queueItem)
 }
 
 func (aqueue *anynsQueue) recoverLowNonce(ctx context.Context, queueItem *QueueItem) error {
 	retryCount := queueItem.TxCurrentRetry
-	nonce := queueItem.TxCurrentNonce
 
 	if retryCount >= aqueue.confQueue.LowNonceRetryCount {
-		return errors.New("NONCE IS TOO LOW but RETRY COUNT IS TOO BIG, STOP...")
+		return permanent(errors.New("NONCE IS TOO LOW but RETRY COUNT IS TOO BIG, STOP..."))
 	}
 
 	log.Warn("NONCE IS TOO LOW!!! Retrying with new nonce...", zap.Any("retry", retryCount))
 
-	// update nonce in the DB immediately, even if TX is still not sent
-	_, err := aqueue.nonceManager.SaveNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin), nonce+1)
-	if err != nil {
-		log.Error("can not update nonce in DB!", zap.Error(err))
-		return err
-	}
-
-	// update nonce in the item
-	queueItem.TxCurrentNonce = nonce + 1
+	// nonce in the DB is already incremented (see allocateNonce),
+	// so the tx is sent again with the next one
 	queueItem.TxCurrentRetry = retryCount + 1
 
 	// save item to DB
-	err = aqueue.SaveItemToDb(ctx, queueItem)
+	err := aqueue.SaveItemToDb(ctx, queueItem)
 	if err != nil {
 		log.Error("can not save item to DB!", zap.Error(err))
 		return err
@@ -389,20 +549,20 @@ func (aqueue *anynsQueue) recoverHighNonce(ctx context.Context, queueItem *Queue
 
 	// do not give more than N tries
 	if retryCount >= aqueue.confQueue.HighNonceRetryCount {
-		return errors.New("NONCE IS probably TOO HIGH but RETRY COUNT IS TOO BIG, STOP...")
+		return permanent(errors.New("NONCE IS probably TOO HIGH but RETRY COUNT IS TOO BIG, STOP..."))
 	}
 
 	log.Warn("NONCE IS probably TOO HIGH!!! Retrying with new nonce...", zap.Any("retry", retryCount))
 
 	// - get new nonce from network
-	newNonce, err := aqueue.nonceManager.GetCurrentNonceFromNetwork(common.HexToAddress(aqueue.confContracts.AddrAdmin))
+	newNonce, err := aqueue.nonceManager.GetCurrentNonceFromNetwork(ctx, common.HexToAddress(aqueue.confContracts.AddrAdmin))
 	if err != nil {
 		log.Error("can not get new nonce from network!", zap.Error(err))
 		return err
 	}
 
 	// update nonce in the DB immediately, even if TX is still not sent
-	_, err = aqueue.nonceManager.SaveNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin), newNonce)
+	_, err = aqueue.nonceManager.SaveNonce(ctx, common.HexToAddress(aqueue.confContracts.AddrAdmin), newNonce)
 	if err != nil {
 		log.Error("can not update nonce in DB!", zap.Error(err))
 		return err
@@ -423,17 +583,11 @@ func (aqueue *anynsQueue) recoverHighNonce(ctx context.Context, queueItem *Queue
 	return nil
 }
 
+// nonce itself is allocated right before each tx is sent (see allocateNonce)
 func (aqueue *anynsQueue) initNonce(ctx context.Context, queueItem *QueueItem) error {
-	// get nonce (from DB, config file or network)
-	nonce, err := aqueue.nonceManager.GetCurrentNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin))
-	if err != nil {
-		log.Error("can not get nonce", zap.Error(err))
-		return err
-	}
-	queueItem.TxCurrentNonce = nonce
 	queueItem.TxCurrentRetry = 0 // reset retries counter
 
-	err = aqueue.SaveItemToDb(ctx, queueItem)
+	err := aqueue.SaveItemToDb(ctx, queueItem)
 	if err != nil {
 		log.Error("failed to save item to DB", zap.Error(err))
 		return err
@@ -442,6 +596,30 @@ func (aqueue *anynsQueue) initNonce(ctx context.Context, queueItem *QueueItem) e
 	return nil
 }
 
+// each tx of the admin gets its own nonce, even if several nodes send txs at the same time
+func (aqueue *anynsQueue) allocateNonce(ctx context.Context, queueItem *QueueItem) (uint64, error) {
+	nonce, err := aqueue.nonceManager.AllocateNonce(ctx, common.HexToAddress(aqueue.confContracts.AddrAdmin))
+	if err != nil {
+		log.Error("can not allocate nonce", zap.Error(err))
+		return 0, err
+	}
+
+	queueItem.TxCurrentNonce = nonce
+	return nonce, nil
+}
+
+// tx was not sent (contracts return nil tx in this case), so its nonce can be used by the next tx
+// otherwise the gap is fixed by the "nonce too high" recovery
+func (aqueue *anynsQueue) releaseNonce(ctx context.Context, tx *types.Transaction, nonce uint64) {
+	if tx != nil {
+		return
+	}
+	err := aqueue.nonceManager.ReleaseNonce(ctx, common.HexToAddress(aqueue.confContracts.AddrAdmin), nonce)
+	if err != nil {
+		log.Warn("can not release nonce", zap.Error(err), zap.Uint64("nonce", nonce))
+	}
+}
+
 func (aqueue *anynsQueue) handleNonceErrors(ctx context.Context, err error, prevState QueueItemStatus, newState QueueItemStatus, queueItem *QueueItem) (newStatusOut QueueItemStatus, errOut error) {
 	// try to recover from nonoce errors
 	if err != nil {
@@ -449,7 +627,9 @@ func (aqueue *anynsQueue) handleNonceErrors(ctx context.Context, err error, prev
 			// if we got "nonce too low" error the tx is immediately rejected. to fix it:
 			// - get nonce from network
 			// - send this tx again with +1 nonce
-			aqueue.recoverLowNonce(ctx, queueItem)
+			if err2 := aqueue.recoverLowNonce(ctx, queueItem); err2 != nil {
+				return newState, err2
+			}
 
 			newState = prevState // try again with the same state
 			err = nil
@@ -459,7 +639,9 @@ func (aqueue *anynsQueue) handleNonceErrors(ctx context.Context, err error, prev
 			// - wait for N minutes for all TXs to settle
 			// - get new nonce from network
 			// - retry sending this tx with new nonce
-			aqueue.recoverHighNonce(ctx, queueItem)
+			if err2 := aqueue.recoverHighNonce(ctx, queueItem); err2 != nil {
+				return newState, err2
+			}
 
 			newState = prevState // try again with the same state
 			err = nil
@@ -469,10 +651,61 @@ func (aqueue *anynsQueue) handleNonceErrors(ctx context.Context, err error, prev
 	return newState, err
 }
 
+// transient errors are retried in the same state with backoff
+// item is moved to the queue-dead collection if error is permanent or all attempts of the state are used
+func (aqueue *anynsQueue) handleRetry(ctx context.Context, err error, prevState QueueItemStatus, newState QueueItemStatus, queueItem *QueueItem) (newStatusOut QueueItemStatus, errOut error) {
+	// node is stopping, item will be processed after restart
+	// or item was cancelled by the admin or is processed by another node
+	if ctx.Err() != nil || errors.Is(err, ErrItemCancelled) || errors.Is(err, ErrLeaseLost) {
+		return prevState, err
+	}
+
+	// 1 - remember the attempt
+	isPermanent := isPermanentError(err)
+	maxAttempts := aqueue.retry.attempts(prevState)
+	queueItem.StateRetry++
+	queueItem.Attempts = append(queueItem.Attempts, QueueItemAttempt{
+		Status:       prevState,
+		ErrorCode:    contracts.RevertName(err),
+		ErrorMessage: err.Error(),
+		Permanent:    isPermanent,
+		Date:         time.Now().Unix(),
+	})
+	isRetry := !isPermanent && queueItem.StateRetry < maxAttempts
+
+	var delay time.Duration
+	if isRetry {
+		delay = aqueue.retry.backoff(queueItem.StateRetry)
+		queueItem.NextAttemptAt = time.Now().Add(delay).Unix()
+	}
+
+	err2 := aqueue.SaveItemToDb(ctx, queueItem)
+	if err2 != nil {
+		log.Error("can not save attempt to DB", zap.Error(err2), zap.Int64("Item Index", queueItem.Index))
+	}
+
+	// 2 - retry
+	// item is not claimed by any node before NextAttemptAt (see claimItem)
+	if isRetry {
+		log.Warn("state failed, retrying later", zap.Error(err), zap.Int64("Item Index", queueItem.Index), zap.Stringer("state", prevState),
+			zap.Uint("attempt", queueItem.StateRetry), zap.Uint("max attempts", maxAttempts), zap.Duration("delay", delay))
+		return prevState, errRetryLater
+	}
+
+	// 3 - give up
+	log.Error("state failed, moving item to the dead items", zap.Error(err), zap.Int64("Item Index", queueItem.Index),
+		zap.Stringer("state", prevState), zap.Bool("permanent", isPermanent), zap.Uint("attempts", queueItem.StateRetry))
+
+	err2 = aqueue.saveDeadItem(ctx, queueItem, prevState, newState, err)
+	if err2 != nil {
+		log.Error("can not save dead item to DB", zap.Error(err2), zap.Int64("Item Index", queueItem.Index))
+	}
+	return newState, err
+}
+
 func (aqueue *anynsQueue) isStopProcessing(err error, prevState QueueItemStatus, newState QueueItemStatus) bool {
 	if err != nil {
-		// TODO: retry logic?
-		// always stop in case of error
+		// error is returned only after all retries or if the retry is scheduled (see handleRetry)
 		return true
 	}
 
@@ -535,7 +768,7 @@ func (aqueue *anynsQueue) NameRegisterMoveStateNext(ctx context.Context, queueIt
 	case OperationStatus_Completed:
 		// Success
 		return OperationStatus_Completed, nil
-	case OperationStatus_CommitError, OperationStatus_RegisterError, OperationStatus_Error:
+	case OperationStatus_CommitError, OperationStatus_RegisterError, OperationStatus_Error, OperationStatus_Cancelled:
 		// no state transition in case of ERRORS
 		return queueItem.Status, nil
 	}
@@ -545,8 +778,6 @@ func (aqueue *anynsQueue) NameRegisterMoveStateNext(ctx context.Context, queueIt
 }
 
 func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueItem *QueueItem) error {
-	nonce := queueItem.TxCurrentNonce
-
 	controller, err := aqueue.contracts.ConnectToPrivateController()
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
@@ -561,7 +792,7 @@ func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueIt
 
 	if err != nil {
 		log.Error("can not decode base64 secret", zap.Error(err), zap.Any("secret", queueItem.SecretBase64))
-		return err
+		return permanent(err)
 	}
 
 	var secret32 [32]byte
@@ -579,7 +810,7 @@ func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueIt
 	isReverseRecordUpdate := true
 
 	// 1 - make a commitment
-	commitment, err := aqueue.contracts.MakeCommitment(&contracts.MakeCommitmentParams{
+	commitment, err := aqueue.contracts.MakeCommitment(ctx, &contracts.MakeCommitmentParams{
 		NameFirstPart:     nameFirstPart,
 		RegistrantAccount: registrantAccount,
 		Secret:            secret32,
@@ -598,11 +829,17 @@ func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueIt
 		return err
 	}
 
-	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin()
+	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin(ctx)
 	if err != nil {
 		log.Error("can not get auth params for admin", zap.Error(err))
 		return err
 	}
+
+	// nonce is reserved right before the tx is sent
+	nonce, err := aqueue.allocateNonce(ctx, queueItem)
+	if err != nil {
+		return err
+	}
 	if authOpts != nil {
 		authOpts.Nonce = big.NewInt(int64(nonce))
 	}
@@ -619,19 +856,15 @@ func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueIt
 	// can return ErrNonceTooHigh error
 	if err != nil {
 		log.Error("can not Commit tx", zap.Error(err), zap.Any("tx", tx))
+		aqueue.releaseNonce(ctx, tx, nonce)
 		return err
 	}
 
-	// 3 - update nonce and item in DB
-	_, err = aqueue.nonceManager.SaveNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin), nonce+1)
-	if err != nil {
-		log.Error("can not update nonce in DB!", zap.Error(err))
-		return err
-	}
-
+	// 3 - update item in DB
 	queueItem.TxCommitHash = tx.Hash().String()
 	queueItem.TxCommitNonce = nonce
-	queueItem.TxCurrentNonce = nonce + 1
+	queueItem.CommitDoneAt = 0
+	queueItem.TxReplacedHashes = nil
 	queueItem.TxCurrentRetry = 0
 	queueItem.Status = OperationStatus_CommitSent
 
@@ -647,41 +880,24 @@ func (aqueue *anynsQueue) nameRegister_InitialState(ctx context.Context, queueIt
 // wait for commit tx
 func (aqueue *anynsQueue) nameRegister_CommitSent(ctx context.Context, queueItem *QueueItem) error {
 	if len(queueItem.TxCommitHash) == 0 {
-		return errors.New("tx hash is empty")
+		return permanent(errors.New("tx hash is empty"))
 	}
 
 	log.Info("waiting for commit tx", zap.String("tx hash", queueItem.TxCommitHash), zap.Any("Item", queueItem))
-	txHash := common.HexToHash(queueItem.TxCommitHash)
 
-	// 0 - try to wait for TX first and handle "nonce too high" error
-	// wait until TX is "seen" by the network (N times)
-	// can return ErrNonceTooHigh or just error
-	err := aqueue.contracts.WaitForTxToStartMining(ctx, txHash)
-	if err != nil {
-		log.Error("can not wait for Commit tx, can not start", zap.Error(err))
-		return err
-	}
-
-	// 1
-	tx, err := aqueue.contracts.TxByHash(ctx, txHash)
-	if err != nil {
-		// TODO: handle it and retry
-		log.Error("failed to fetch transaction details:", zap.Error(err), zap.String("tx hash", queueItem.TxCommitHash))
-		return err
-	}
-
-	txRes, err := aqueue.contracts.WaitMined(ctx, tx)
+	receipt, err := aqueue.waitSentTx(ctx, queueItem, &queueItem.TxCommitHash)
 	if err != nil {
 		log.Error("can not wait for commit tx", zap.Error(err))
 		return err
 	}
-	if !txRes {
+	if receipt.Status != types.ReceiptStatusSuccessful {
 		// new error
 		log.Warn("tx finished with ERROR result", zap.String("tx hash", queueItem.TxCommitHash))
-		return errors.New("WaitMined - tx not found")
+		return permanent(errors.New("WaitMined - tx not found"))
 	}
 
 	// 2 - update in DB
+	queueItem.CommitDoneAt = time.Now().Unix()
 	queueItem.Status = OperationStatus_CommitDone
 
 	err = aqueue.SaveItemToDb(ctx, queueItem)
@@ -695,25 +911,17 @@ func (aqueue *anynsQueue) nameRegister_CommitSent(ctx context.Context, queueItem
 
 // generate new register tx
 func (aqueue *anynsQueue) nameRegister_CommitDone(ctx context.Context, queueItem *QueueItem) error {
-	nonce := queueItem.TxCurrentNonce
-
 	controller, err := aqueue.contracts.ConnectToPrivateController()
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
 		return err
 	}
 
-	// get new nonce
-	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin()
+	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin(ctx)
 	if err != nil {
 		log.Error("can not get auth params for admin", zap.Error(err))
 		return err
 	}
-	if authOpts != nil {
-		authOpts.Nonce = big.NewInt(int64(nonce))
-	}
-
-	log.Info("Nonce is", zap.Any("Nonce", nonce))
 
 	// register
 	// TODO: normalize string
@@ -724,7 +932,7 @@ func (aqueue *anynsQueue) nameRegister_CommitDone(ctx context.Context, queueItem
 
 	if err != nil {
 		log.Error("can not decode base64 secret", zap.Error(err), zap.Any("secret", queueItem.SecretBase64))
-		return err
+		return permanent(err)
 	}
 
 	var secret32 [32]byte
@@ -734,6 +942,16 @@ func (aqueue *anynsQueue) nameRegister_CommitDone(ctx context.Context, queueItem
 	// NameRegisterRequest has no field for this
 	isReverseRecordUpdate := true
 
+	// nonce is reserved right before the tx is sent
+	nonce, err := aqueue.allocateNonce(ctx, queueItem)
+	if err != nil {
+		return err
+	}
+	if authOpts != nil {
+		authOpts.Nonce = big.NewInt(int64(nonce))
+	}
+	log.Info("Nonce is", zap.Any("Nonce", nonce))
+
 	tx, err := aqueue.contracts.Register(ctx, &contracts.RegisterParams{
 		AuthOpts:          authOpts,
 		NameFirstPart:     nameFirstPart,
@@ -753,20 +971,14 @@ func (aqueue *anynsQueue) nameRegister_CommitDone(ctx context.Context, queueItem
 	// can return ErrNonceTooHigh error
 	if err != nil {
 		log.Error("can not Regsiter tx", zap.Error(err))
-		return err
-	}
-
-	// update nonce in DB
-	_, err = aqueue.nonceManager.SaveNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin), nonce+1)
-	if err != nil {
-		log.Error("can not update nonce in DB!", zap.Error(err))
+		aqueue.releaseNonce(ctx, tx, nonce)
 		return err
 	}
 
 	// update item in DB
 	queueItem.TxRegisterHash = tx.Hash().String()
 	queueItem.TxRegisterNonce = nonce
-	queueItem.TxCurrentNonce = nonce + 1
+	queueItem.TxReplacedHashes = nil
 	queueItem.TxCurrentRetry = 0
 	queueItem.Status = OperationStatus_RegisterSent
 
@@ -782,40 +994,25 @@ func (aqueue *anynsQueue) nameRegister_CommitDone(ctx context.Context, queueItem
 // wait for register tx
 func (aqueue *anynsQueue) nameRegister_RegisterWaiting(ctx context.Context, queueItem *QueueItem) error {
 	if len(queueItem.TxRegisterHash) == 0 {
-		return errors.New("tx hash is empty")
+		return permanent(errors.New("tx hash is empty"))
 	}
 
 	log.Info("waiting for register tx", zap.String("tx hash", queueItem.TxRegisterHash), zap.Any("Item", queueItem))
-	txHash := common.HexToHash(queueItem.TxRegisterHash)
-
-	// 0 - try to wait for TX first and handle "nonce too high" error
-	// wait until TX is "seen" by the network (N times)
-	// can return ErrNonceTooHigh or just error
-	err := aqueue.contracts.WaitForTxToStartMining(ctx, txHash)
-	if err != nil {
-		log.Error("can not wait for Register tx, can not start", zap.Error(err))
-		return err
-	}
-
-	tx, err := aqueue.contracts.TxByHash(ctx, txHash)
-	if err != nil {
-		// TODO: handle it and retry
-		log.Error("failed to fetch transaction details:", zap.Error(err), zap.String("tx hash", queueItem.TxRegisterHash))
-		return err
-	}
 
 	// wait for tx to be mined
-	txRes, err := aqueue.contracts.WaitMined(ctx, tx)
+	receipt, err := aqueue.waitSentTx(ctx, queueItem, &queueItem.TxRegisterHash)
 	if err != nil {
 		log.Error("can not wait for register tx", zap.Error(err))
 		return err
 	}
-	if !txRes {
+	if receipt.Status != types.ReceiptStatusSuccessful {
 		log.Warn("tx finished with ERROR result", zap.String("tx hash", queueItem.TxRegisterHash))
-		return err
+		return permanent(errors.New("register tx failed"))
 	}
 
 	// update item in DB
+	aqueue.setCompletedBlock(queueItem, receipt)
+	aqueue.cache.InvalidateName(queueItem.FullName)
 	queueItem.Status = OperationStatus_Completed
 	err = aqueue.SaveItemToDb(ctx, queueItem)
 	if err != nil {
@@ -827,80 +1024,186 @@ func (aqueue *anynsQueue) nameRegister_RegisterWaiting(ctx context.Context, queu
 	return nil
 }
 
-func (aqueue *anynsQueue) nameRenewMoveStateNext(ctx context.Context, queueItem *QueueItem) (newState QueueItemStatus, err error) {
+// remember which block the completed tx was included into
+// WaitMined already waited for all confirmations, so this block is final
+func (aqueue *anynsQueue) setCompletedBlock(queueItem *QueueItem, receipt *types.Receipt) {
+	queueItem.BlockNumber = receipt.BlockNumber.Int64()
+	queueItem.BlockHash = receipt.BlockHash.Hex()
+}
+
+// wait until the last sent tx of the current state (txHash) or any of the txs it replaced is mined
+func (aqueue *anynsQueue) waitSentTx(ctx context.Context, queueItem *QueueItem, txHash *string) (*types.Receipt, error) {
+	var sentBefore []common.Hash
+	for _, hash := range queueItem.TxReplacedHashes {
+		sentBefore = append(sentBefore, common.HexToHash(hash))
+	}
+
+	// replaced txs are dropped from the mempool, and if one of them was mined
+	// the last tx will never be seen by the network
+	// so "nonce too high" check is done only if nothing was replaced
+	lastHash := common.HexToHash(*txHash)
+	if len(sentBefore) == 0 {
+		// 0 - try to wait for TX first and handle "nonce too high" error
+		// wait until TX is "seen" by the network (N times)
+		// can return ErrNonceTooHigh or just error
+		err := aqueue.contracts.WaitForTxToStartMining(ctx, lastHash)
+		if err != nil {
+			log.Error("can not wait for tx, can not start", zap.Error(err), zap.String("tx hash", *txHash))
+			return nil, err
+		}
+	}
+
+	// 1 - latest tx that is known to the network is replaced again if it is stuck
+	hashes := append(sentBefore, lastHash)
+	for i := len(hashes) - 1; i >= 0; i-- {
+		tx, err := aqueue.contracts.TxByHash(ctx, hashes[i])
+		if errors.Is(err, ethereum.NotFound) && i > 0 {
+			continue
+		}
+		if err != nil {
+			// TODO: handle it and retry
+			log.Error("failed to fetch transaction details:", zap.Error(err), zap.String("tx hash", hashes[i].Hex()))
+			return nil, err
+		}
+
+		others := append(append([]common.Hash{}, hashes[:i]...), hashes[i+1:]...)
+		return aqueue.contracts.WaitMined(ctx, tx, others, aqueue.saveReplacement(ctx, queueItem, txHash))
+	}
+	return nil, ethereum.NotFound
+}
+
+// stuck tx can be replaced by the same tx with higher fees (see WaitMined)
+// hashes of the replacement and of all replaced txs are saved,
+// so after restart we will wait for any of them
+func (aqueue *anynsQueue) saveReplacement(ctx context.Context, queueItem *QueueItem, txHash *string) func(replacement *types.Transaction) {
+	return func(replacement *types.Transaction) {
+		queueItem.TxReplacedHashes = append(queueItem.TxReplacedHashes, *txHash)
+		*txHash = replacement.Hash().String()
+
+		err := aqueue.SaveItemToDb(ctx, queueItem)
+		if err != nil {
+			log.Error("can not save replacement tx", zap.Error(err), zap.String("tx hash", *txHash))
+		}
+	}
+}
+
+func (aqueue *anynsQueue) nameRenewMoveStateNext(ctx context.Context, queueItem *QueueItem) (QueueItemStatus, error) {
+	switch queueItem.Status {
+	case OperationStatus_Initial:
+		err := aqueue.nameRenew_InitialState(ctx, queueItem)
+
+		// in case of failed tx -> save error to DB and stop processing it next time
+		if err != nil {
+			return OperationStatus_RenewError, err
+		}
+		return OperationStatus_RenewSent, nil
+	case OperationStatus_RenewSent:
+		err := aqueue.nameRenew_RenewWaiting(ctx, queueItem)
+
+		// in case of failed tx -> save error to DB and stop processing it next time
+		if err != nil {
+			return OperationStatus_RenewError, err
+		}
+		return OperationStatus_Completed, nil
+	case OperationStatus_Completed:
+		// Success
+		return OperationStatus_Completed, nil
+	case OperationStatus_RenewError, OperationStatus_Error, OperationStatus_Cancelled:
+		// no state transition in case of ERRORS
+		return queueItem.Status, nil
+	}
+
+	log.Fatal("unknown state", zap.Any("state", queueItem.Status))
+	return queueItem.Status, nil
+}
+
+// send renew tx
+func (aqueue *anynsQueue) nameRenew_InitialState(ctx context.Context, queueItem *QueueItem) error {
 	controller, err := aqueue.contracts.ConnectToPrivateController()
 	if err != nil {
 		log.Error("failed to connect to contract", zap.Error(err))
-		return OperationStatus_Error, err
+		return err
 	}
 
-	// 1 - get proper nonce (from DB, config file or network)
-	nonce, err := aqueue.nonceManager.GetCurrentNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin))
+	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin(ctx)
 	if err != nil {
-		log.Error("can not get nonce", zap.Error(err))
-		return OperationStatus_Error, err
+		log.Error("can not get auth params for admin", zap.Error(err))
+		return err
 	}
 
-	authOpts, err := aqueue.contracts.GenerateAuthOptsForAdmin()
+	// nonce is reserved right before the tx is sent
+	nonce, err := aqueue.allocateNonce(ctx, queueItem)
 	if err != nil {
-		log.Error("can not get auth params for admin", zap.Error(err))
-		return OperationStatus_Error, err
+		return err
 	}
 	if authOpts != nil {
 		authOpts.Nonce = big.NewInt(int64(nonce))
 	}
 	log.Info("Nonce is", zap.Any("Nonce", nonce))
 
-	//
-	parts := strings.Split(queueItem.FullName, ".")
-	if len(parts) != 2 {
-		return OperationStatus_Error, errors.New("invalid name")
-	}
-	firstPart := parts[0]
-
-	// get duration in seconds
-	durSeconds := uint64(queueItem.RegisterPeriodMonths) * (31 * 24 * 60 * 60)
+	// same period as in the registration
+	duration := contracts.PeriodMonthsToTimestamp(queueItem.RegisterPeriodMonths)
 
-	// 2 - send tx to the network
+	// 1 - send tx to the network
 	tx, err := aqueue.contracts.Renew(ctx, &contracts.RenewParams{
 		TxOpts:      authOpts,
-		FullName:    firstPart,
-		DurationSec: durSeconds,
+		FullName:    contracts.RemoveTLD(queueItem.FullName),
+		DurationSec: duration.Uint64(),
 		Controller:  controller})
 
 	// can return ErrNonceTooLow error
 	// can return ErrNonceTooHigh error
 	if err != nil {
 		log.Error("can not Renew tx", zap.Error(err))
-		return OperationStatus_Error, err
+		aqueue.releaseNonce(ctx, tx, nonce)
+		return err
 	}
 
-	// update nonce in DB
-	_, err = aqueue.nonceManager.SaveNonce(common.HexToAddress(aqueue.confContracts.AddrAdmin), nonce+1)
+	// 2 - update item in DB
+	queueItem.TxRenewHash = tx.Hash().String()
+	queueItem.TxRenewNonce = nonce
+	queueItem.TxReplacedHashes = nil
+	queueItem.TxCurrentRetry = 0
+	queueItem.Status = OperationStatus_RenewSent
+
+	err = aqueue.SaveItemToDb(ctx, queueItem)
 	if err != nil {
-		log.Error("can not update nonce in DB!", zap.Error(err))
-		return OperationStatus_Error, err
+		log.Error("can not save Renew tx", zap.Error(err), zap.String("tx hash", queueItem.TxRenewHash))
+		return err
 	}
 
-	// wait for tx to be mined
-	txRes, err := aqueue.contracts.WaitMined(ctx, tx)
+	return nil
+}
+
+// wait for renew tx
+func (aqueue *anynsQueue) nameRenew_RenewWaiting(ctx context.Context, queueItem *QueueItem) error {
+	if len(queueItem.TxRenewHash) == 0 {
+		return permanent(errors.New("tx hash is empty"))
+	}
+
+	log.Info("waiting for renew tx", zap.String("tx hash", queueItem.TxRenewHash), zap.Any("Item", queueItem))
+
+	// 1 - wait for tx to be mined
+	receipt, err := aqueue.waitSentTx(ctx, queueItem, &queueItem.TxRenewHash)
 	if err != nil {
-		log.Error("can not wait for register tx", zap.Error(err))
-		return OperationStatus_Error, err
+		log.Error("can not wait for renew tx", zap.Error(err))
+		return err
 	}
-	if !txRes {
-		log.Warn("tx finished with ERROR result", zap.String("tx hash", tx.Hash().String()))
-		return OperationStatus_Error, err
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		log.Warn("tx finished with ERROR result", zap.String("tx hash", queueItem.TxRenewHash))
+		return permanent(errors.New("renew tx failed"))
 	}
 
-	// update item in DB
+	// 2 - update item in DB
+	aqueue.setCompletedBlock(queueItem, receipt)
+	aqueue.cache.InvalidateName(queueItem.FullName)
 	queueItem.Status = OperationStatus_Completed
 	err = aqueue.SaveItemToDb(ctx, queueItem)
 	if err != nil {
-		log.Error("can not save last update", zap.Error(err), zap.String("tx hash", queueItem.TxCommitHash))
-		return OperationStatus_Error, err
+		log.Error("can not save last update", zap.Error(err), zap.String("tx hash", queueItem.TxRenewHash))
+		return err
 	}
 
 	log.Info("renew operation succeeded!")
-	return OperationStatus_Completed, nil
+	return nil
 }
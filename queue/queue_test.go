@@ -15,6 +15,8 @@ import (
 	"github.com/zeebo/assert"
 	"go.uber.org/mock/gomock"
 
+	"github.com/anyproto/any-ns-node/cache"
+	mock_cache "github.com/anyproto/any-ns-node/cache/mock"
 	"github.com/anyproto/any-ns-node/config"
 	contracts "github.com/anyproto/any-ns-node/contracts"
 	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
@@ -64,8 +66,8 @@ func TestAnynsQueue_NameRegisterMoveStateNext(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
-			return false, nil
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
+			return minedReceipt(false), nil
 		}).AnyTimes()
 
 		pctx := context.Background()
@@ -114,8 +116,8 @@ func TestAnynsQueue_NameRegisterMoveStateNext(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
-			return true, nil
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
+			return minedReceipt(true), nil
 		}).AnyTimes()
 
 		fx.itemColl = nil
@@ -141,8 +143,8 @@ func TestAnynsQueue_NameRegisterMoveStateNext(t *testing.T) {
 		fx := newFixture(t)
 		defer fx.finish(t)
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
-			return true, nil
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
+			return minedReceipt(true), nil
 		}).AnyTimes()
 
 		fx.itemColl = nil
@@ -167,6 +169,164 @@ func TestAnynsQueue_NameRegisterMoveStateNext(t *testing.T) {
 	})
 }
 
+func TestAnynsQueue_NameRenewMoveStateNext(t *testing.T) {
+	t.Run("send renew", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().Renew(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, params *contracts.RenewParams) (*types.Transaction, error) {
+			// without TLD
+			assert.Equal(t, params.FullName, "hello")
+			assert.True(t, params.DurationSec > 365*24*60*60)
+
+			var tx = types.NewTransaction(
+				5,
+				common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87"),
+				big.NewInt(0), 0, big.NewInt(0),
+				nil,
+			)
+			return tx, nil
+		})
+
+		fx.itemColl = nil
+
+		item := &QueueItem{
+			FullName:             "hello.any",
+			ItemType:             ItemType_NameRenew,
+			OwnerEthAddress:      "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+			RegisterPeriodMonths: 13,
+			Status:               OperationStatus_Initial,
+		}
+		fx.nextNonce = 5
+		newState, err := fx.nameRenewMoveStateNext(ctx, item)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_RenewSent, newState)
+
+		// tx is saved, so it will be waited for after restart
+		assert.NotEqual(t, item.TxRenewHash, "")
+		assert.Equal(t, item.TxRenewNonce, uint64(5))
+		assert.Equal(t, item.TxCurrentNonce, uint64(5))
+		assert.Equal(t, fx.nextNonce, uint64(6))
+	})
+
+	t.Run("renew tx failed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().Renew(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+
+		newState, err := fx.nameRenewMoveStateNext(ctx,
+			&QueueItem{
+				FullName: "hello.any",
+				ItemType: ItemType_NameRenew,
+				Status:   OperationStatus_Initial,
+			},
+		)
+		require.Error(t, err)
+		require.Equal(t, OperationStatus_RenewError, newState)
+
+		// tx was not sent, so its nonce is used by the next tx
+		assert.Equal(t, fx.nextNonce, uint64(0))
+	})
+
+	t.Run("retry with the next nonce if nonce is too low", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().Renew(gomock.Any(), gomock.Any()).Return(nil, contracts.ErrNonceTooLow)
+
+		item := &QueueItem{
+			FullName: "hello.any",
+			ItemType: ItemType_NameRenew,
+			Status:   OperationStatus_Initial,
+		}
+		newState, err := fx.nameRenewMoveStateNext(ctx, item)
+
+		// ProcessItem will retry with the next nonce
+		fx.itemColl = nil
+		fx.confQueue.LowNonceRetryCount = 1
+		newState, err = fx.handleNonceErrors(ctx, err, OperationStatus_Initial, newState, item)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Initial, newState)
+		require.Equal(t, uint(1), item.TxCurrentRetry)
+
+		// no more nonce retries
+		newState, err = fx.handleNonceErrors(ctx, contracts.ErrNonceTooLow, OperationStatus_Initial, OperationStatus_RenewError, item)
+		require.Error(t, err)
+		require.True(t, isPermanentError(err))
+		require.Equal(t, OperationStatus_RenewError, newState)
+	})
+
+	t.Run("renew tx reverted", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(minedReceipt(false), nil)
+
+		newState, err := fx.nameRenewMoveStateNext(ctx,
+			&QueueItem{
+				FullName:    "hello.any",
+				ItemType:    ItemType_NameRenew,
+				TxRenewHash: "0x4a8e76e2739c2214eca73b0cfa05d0eb64dcfad0a27c027bf2ecf0ce00110963",
+				Status:      OperationStatus_RenewSent,
+			},
+		)
+		require.Error(t, err)
+		require.Equal(t, OperationStatus_RenewError, newState)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(minedReceipt(true), nil)
+
+		fx.itemColl = nil
+
+		item := &QueueItem{
+			FullName:    "hello.any",
+			ItemType:    ItemType_NameRenew,
+			TxRenewHash: "0x4a8e76e2739c2214eca73b0cfa05d0eb64dcfad0a27c027bf2ecf0ce00110963",
+			// wait for renew tx
+			Status: OperationStatus_RenewSent,
+		}
+		newState, err := fx.nameRenewMoveStateNext(ctx, item)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Completed, newState)
+		assert.Equal(t, item.BlockNumber, int64(1))
+	})
+
+	t.Run("wait for replaced txs too", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		replaced := "0x4a8e76e2739c2214eca73b0cfa05d0eb64dcfad0a27c027bf2ecf0ce00110963"
+		last := "0x5b9f87f3840d3325fdb84c1dfb16e1fc75edfbe1b38d138cf3fdf1df11221074"
+		replacement := types.NewTransaction(1, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), []common.Hash{common.HexToHash(replaced)}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *types.Transaction, _ []common.Hash, onReplace func(*types.Transaction)) (*types.Receipt, error) {
+				onReplace(replacement)
+				return minedReceipt(true), nil
+			})
+
+		fx.itemColl = nil
+
+		item := &QueueItem{
+			FullName:         "hello.any",
+			ItemType:         ItemType_NameRenew,
+			TxRenewHash:      last,
+			TxReplacedHashes: []string{replaced},
+			Status:           OperationStatus_RenewSent,
+		}
+		newState, err := fx.nameRenewMoveStateNext(ctx, item)
+		require.NoError(t, err)
+		require.Equal(t, OperationStatus_Completed, newState)
+		assert.Equal(t, item.TxRenewHash, replacement.Hash().String())
+		assert.DeepEqual(t, item.TxReplacedHashes, []string{replaced, last})
+	})
+}
+
 func TestAnynsQueue_SaveItemToDb(t *testing.T) {
 	t.Run("fail if item not found", func(t *testing.T) {
 		fx := newFixture(t)
@@ -231,8 +391,8 @@ func TestAnynsQueue_NameRegister(t *testing.T) {
 			return tx, nil
 		}).AnyTimes()
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
-			return false, nil
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
+			return minedReceipt(false), nil
 		}).AnyTimes()
 
 		pctx := context.Background()
@@ -290,9 +450,9 @@ func TestAnynsQueue_NameRegister(t *testing.T) {
 			return nil, errors.New("error")
 		}).AnyTimes()
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
 			// good
-			return true, nil
+			return minedReceipt(true), nil
 		}).AnyTimes()
 
 		pctx := context.Background()
@@ -356,9 +516,9 @@ func TestAnynsQueue_NameRegister(t *testing.T) {
 			return tx, nil
 		}).AnyTimes()
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
 			// good
-			return true, nil
+			return minedReceipt(true), nil
 		}).AnyTimes()
 
 		pctx := context.Background()
@@ -419,9 +579,9 @@ func TestAnynsQueue_FindAndProcessAllItemsInDb(t *testing.T) {
 			return nil, errors.New("some error")
 		}).AnyTimes()
 
-		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (bool, error) {
+		fx.contracts.EXPECT().WaitMined(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}, interface{}, interface{}) (*types.Receipt, error) {
 			// fail
-			return false, nil
+			return minedReceipt(false), nil
 		}).AnyTimes()
 
 		// TODO: mock Mongo!
@@ -553,6 +713,52 @@ func TestAnynsQueue_AddNewRequest(t *testing.T) {
 	})
 }
 
+func TestOperationID(t *testing.T) {
+	index, ok := ParseOperationID(OperationID(123))
+	assert.True(t, ok)
+	assert.Equal(t, index, int64(123))
+
+	// AA operations
+	for _, opID := range []string{"0x10d5b0e279e5e4c1d1df5f57dfb7e84813920a51", "123", "queue-", "queue-abc", "queue--1"} {
+		_, ok = ParseOperationID(opID)
+		assert.False(t, ok)
+	}
+}
+
+func TestAnynsQueue_AddRenewRequest(t *testing.T) {
+	t.Run("should add new renew item", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		// TODO: mock Mongo!
+		uri := "mongodb://localhost:27017"
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		coll := client.Database("any-ns").Collection("queue")
+
+		operationId, err := fx.AddRenewRequest(ctx, &nsp.NameRenewRequest{
+			FullName:          "hello.any",
+			OwnerEthAddress:   "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
+			RenewPeriodMonths: 24,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), operationId)
+
+		var itemOut QueueItem
+		err = coll.FindOne(ctx, findItemByIndexQuery{Index: 0}).Decode(&itemOut)
+		require.NoError(t, err)
+		require.Equal(t, ItemType_NameRenew, itemOut.ItemType)
+		require.Equal(t, OperationStatus_Initial, itemOut.Status)
+		require.Equal(t, uint32(24), itemOut.RegisterPeriodMonths)
+		// no commitment
+		require.Empty(t, itemOut.SecretBase64)
+
+		status, err := fx.GetRequestStatus(ctx, operationId)
+		require.NoError(t, err)
+		require.Equal(t, nsp.OperationState_Pending, status)
+	})
+}
+
 type fixture struct {
 	a            *app.App
 	ctrl         *gomock.Controller
@@ -560,6 +766,9 @@ type fixture struct {
 	config       *config.Config
 	contracts    *mock_contracts.MockContractsService
 	nonceManager *mock_nonce_manager.MockNonceService
+	cache        *mock_cache.MockCacheService
+	// next nonce returned by the nonce manager mock
+	nextNonce uint64
 
 	*anynsQueue
 }
@@ -577,36 +786,57 @@ func newFixture(t *testing.T) *fixture {
 	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
 	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
 	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().CreateEthConnection().AnyTimes()
-	fx.contracts.EXPECT().GenerateAuthOptsForAdmin().MaxTimes(2)
-	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().CreateEthConnection(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GenerateAuthOptsForAdmin(gomock.Any()).MaxTimes(2)
+	fx.contracts.EXPECT().CalculateTxParams(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().ConnectToPrivateController().AnyTimes()
 	fx.contracts.EXPECT().TxByHash(gomock.Any(), gomock.Any()).AnyTimes()
-	fx.contracts.EXPECT().MakeCommitment(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().MakeCommitment(gomock.Any(), gomock.Any()).AnyTimes()
 	fx.contracts.EXPECT().WaitForTxToStartMining(gomock.Any(), gomock.Any()).AnyTimes()
 
 	fx.nonceManager = mock_nonce_manager.NewMockNonceService(fx.ctrl)
 	fx.nonceManager.EXPECT().Init(gomock.Any()).AnyTimes()
 	fx.nonceManager.EXPECT().Name().Return(nonce_manager.CName).AnyTimes()
 
-	fx.nonceManager.EXPECT().GetCurrentNonce(gomock.Any()).DoAndReturn(func(interface{}) (uint64, error) {
+	fx.nonceManager.EXPECT().GetCurrentNonce(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (uint64, error) {
 		return 0, nil
 	}).AnyTimes()
 
-	fx.nonceManager.EXPECT().GetCurrentNonceFromNetwork(gomock.Any()).DoAndReturn(func(interface{}) (uint64, error) {
+	fx.nonceManager.EXPECT().GetCurrentNonceFromNetwork(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (uint64, error) {
 		return 0, nil
 	}).AnyTimes()
-	fx.nonceManager.EXPECT().SaveNonce(gomock.Any(), gomock.Any()).AnyTimes()
+	fx.nonceManager.EXPECT().SaveNonce(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	// same as the nonce in DB
+	fx.nonceManager.EXPECT().AllocateNonce(gomock.Any(), gomock.Any()).DoAndReturn(func(interface{}, interface{}) (uint64, error) {
+		nonce := fx.nextNonce
+		fx.nextNonce++
+		return nonce, nil
+	}).AnyTimes()
+	fx.nonceManager.EXPECT().ReleaseNonce(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, _ interface{}, nonce uint64) error {
+		if fx.nextNonce == nonce+1 {
+			fx.nextNonce = nonce
+		}
+		return nil
+	}).AnyTimes()
+
+	fx.cache = mock_cache.NewMockCacheService(fx.ctrl)
+	fx.cache.EXPECT().Name().Return(cache.CName).AnyTimes()
+	fx.cache.EXPECT().Init(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Run(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().Close(gomock.Any()).AnyTimes()
+	fx.cache.EXPECT().InvalidateName(gomock.Any()).AnyTimes()
 
 	fx.config.Contracts = config.Contracts{
 		AddrAdmin: "0x10d5B0e279E5E4c1d1Df5F57DFB7E84813920a51",
-		GethUrl:   "xxx",
+		GethUrl:   config.Urls{"xxx"},
 	}
 
 	fx.config.Queue = config.Queue{
 		SkipProcessing:          false,
 		SkipExistingItemsInDB:   true,
 		SkipBackroundProcessing: true,
+		// items fail on the first error, retries are tested in retry_test.go
+		Retry: config.QueueRetry{MaxAttempts: 1},
 	}
 
 	fx.config.Mongo = config.Mongo{
@@ -618,6 +848,7 @@ func newFixture(t *testing.T) *fixture {
 		Register(fx.contracts).
 		Register(fx.config).
 		Register(fx.nonceManager).
+		Register(fx.cache).
 		Register(fx.anynsQueue)
 
 	require.NoError(t, fx.a.Start(ctx))
@@ -641,3 +872,11 @@ func (fx *fixture) finish(t *testing.T) {
 	assert.NoError(t, fx.a.Close(ctx))
 	fx.ctrl.Finish()
 }
+
+func minedReceipt(success bool) *types.Receipt {
+	receipt := &types.Receipt{Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(1)}
+	if success {
+		receipt.Status = types.ReceiptStatusSuccessful
+	}
+	return receipt
+}
@@ -0,0 +1,134 @@
+package queue
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/anyproto/any-ns-node/config"
+	contracts "github.com/anyproto/any-ns-node/contracts"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultJitter         = 0.2
+)
+
+// transient error is retried after the backoff (see QueueItem.NextAttemptAt)
+// item is released and added to the in-memory queue again, so the worker is not blocked by it
+var errRetryLater = errors.New("item is retried later")
+
+// retrying will not help (tx was reverted, item is invalid, etc)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// all errors are transient (network, RPC, timeouts) except:
+// - errors that are marked as permanent
+// - reverts of the contracts, i.e. name is not available anymore
+func isPermanentError(err error) bool {
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return true
+	}
+
+	// commitment will be old enough a bit later
+	if errors.Is(err, contracts.ErrCommitmentTooNew) {
+		return false
+	}
+	return errors.Is(err, contracts.ErrReverted)
+}
+
+type retryPolicy struct {
+	maxAttempts         uint
+	maxAttemptsPerState map[QueueItemStatus]uint
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	jitter         float64
+}
+
+func newRetryPolicy(conf config.QueueRetry) (retryPolicy, error) {
+	p := retryPolicy{
+		maxAttempts:         conf.MaxAttempts,
+		maxAttemptsPerState: make(map[QueueItemStatus]uint),
+		initialBackoff:      time.Duration(conf.InitialBackoffMs) * time.Millisecond,
+		maxBackoff:          time.Duration(conf.MaxBackoffMs) * time.Millisecond,
+		jitter:              conf.Jitter,
+	}
+
+	if p.maxAttempts == 0 {
+		p.maxAttempts = defaultMaxAttempts
+	}
+	if p.initialBackoff == 0 {
+		p.initialBackoff = defaultInitialBackoff
+	}
+	if p.maxBackoff == 0 {
+		p.maxBackoff = defaultMaxBackoff
+	}
+	if p.jitter == 0 {
+		p.jitter = defaultJitter
+	}
+	if p.jitter < 0 || p.jitter > 1 {
+		return p, errors.Newf("queue.retry.jitter should be in [0, 1], got %v", conf.Jitter)
+	}
+
+	for name, attempts := range conf.MaxAttemptsPerState {
+		status, ok := ParseStatus(name)
+		if !ok {
+			return p, errors.Newf("unknown state in queue.retry.maxAttemptsPerState: %q", name)
+		}
+		p.maxAttemptsPerState[status] = attempts
+	}
+	return p, nil
+}
+
+func (p retryPolicy) attempts(status QueueItemStatus) uint {
+	if attempts, ok := p.maxAttemptsPerState[status]; ok && attempts > 0 {
+		return attempts
+	}
+	return p.maxAttempts
+}
+
+// delay after N failed attempts: initial * 2^(N-1) ± jitter, but not more than max
+func (p retryPolicy) backoff(failedAttempts uint) time.Duration {
+	delay := p.initialBackoff
+	for i := uint(1); i < failedAttempts && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+
+	delta := (rand.Float64()*2 - 1) * p.jitter * float64(delay)
+	return delay + time.Duration(delta)
+}
+
+// returns false if ctx is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/any-ns-node/config"
+	contracts "github.com/anyproto/any-ns-node/contracts"
+)
+
+func TestIsPermanentError(t *testing.T) {
+	// reverts are joined with ErrReverted (see contracts.RevertError)
+	reverted := func(err error) error {
+		return fmt.Errorf("simulation: %w", errors.Join(err, contracts.ErrReverted))
+	}
+
+	require.False(t, isPermanentError(errors.New("connection refused")))
+	require.False(t, isPermanentError(context.DeadlineExceeded))
+	require.False(t, isPermanentError(reverted(contracts.ErrCommitmentTooNew)))
+
+	require.True(t, isPermanentError(permanent(errors.New("tx hash is empty"))))
+	require.True(t, isPermanentError(fmt.Errorf("wrapped: %w", permanent(errors.New("renew tx failed")))))
+	require.True(t, isPermanentError(reverted(contracts.ErrNameNotAvailable)))
+	require.True(t, isPermanentError(contracts.ErrReverted))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		p, err := newRetryPolicy(config.QueueRetry{})
+		require.NoError(t, err)
+		require.Equal(t, uint(defaultMaxAttempts), p.attempts(OperationStatus_CommitSent))
+		require.Equal(t, defaultInitialBackoff, p.initialBackoff)
+		require.Equal(t, defaultMaxBackoff, p.maxBackoff)
+		require.Equal(t, defaultJitter, p.jitter)
+	})
+
+	t.Run("attempts per state", func(t *testing.T) {
+		p, err := newRetryPolicy(config.QueueRetry{
+			MaxAttempts:         2,
+			MaxAttemptsPerState: map[string]uint{"commitSent": 10, "renewSent": 0},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint(10), p.attempts(OperationStatus_CommitSent))
+		require.Equal(t, uint(2), p.attempts(OperationStatus_RenewSent))
+		require.Equal(t, uint(2), p.attempts(OperationStatus_Initial))
+	})
+
+	t.Run("fail if state is unknown", func(t *testing.T) {
+		_, err := newRetryPolicy(config.QueueRetry{MaxAttemptsPerState: map[string]uint{"commited": 10}})
+		require.Error(t, err)
+	})
+
+	t.Run("fail if jitter is invalid", func(t *testing.T) {
+		_, err := newRetryPolicy(config.QueueRetry{Jitter: 1.5})
+		require.Error(t, err)
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		p, err := newRetryPolicy(config.QueueRetry{InitialBackoffMs: 100, MaxBackoffMs: 1000, Jitter: 0.1})
+		require.NoError(t, err)
+
+		expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+		for i, base := range expected {
+			base *= time.Millisecond
+			for j := 0; j < 10; j++ {
+				d := p.backoff(uint(i + 1))
+				require.GreaterOrEqual(t, d, base-base/10)
+				require.LessOrEqual(t, d, base+base/10)
+			}
+		}
+	})
+}
+
+func TestAnynsQueue_HandleRetry(t *testing.T) {
+	// DB is not used (see SaveItemToDb and saveDeadItem)
+	newQueue := func(t *testing.T) *anynsQueue {
+		p, err := newRetryPolicy(config.QueueRetry{MaxAttempts: 3, InitialBackoffMs: 1, MaxBackoffMs: 1})
+		require.NoError(t, err)
+		return &anynsQueue{retry: p}
+	}
+
+	t.Run("retry transient error in the same state", func(t *testing.T) {
+		aqueue := newQueue(t)
+		item := &QueueItem{Index: 1, Status: OperationStatus_CommitSent}
+
+		for i := 1; i < 3; i++ {
+			before := time.Now().Unix()
+			newState, err := aqueue.handleRetry(ctx, errors.New("connection refused"), OperationStatus_CommitSent, OperationStatus_CommitError, item)
+			require.ErrorIs(t, err, errRetryLater)
+			require.Equal(t, OperationStatus_CommitSent, newState)
+			require.Equal(t, uint(i), item.StateRetry)
+			// worker is not blocked, item is added to the queue again after the backoff
+			require.GreaterOrEqual(t, item.NextAttemptAt, before)
+			require.LessOrEqual(t, item.NextAttemptAt, time.Now().Add(time.Second).Unix())
+		}
+
+		// all attempts are used
+		newState, err := aqueue.handleRetry(ctx, errors.New("connection refused"), OperationStatus_CommitSent, OperationStatus_CommitError, item)
+		require.Error(t, err)
+		require.Equal(t, OperationStatus_CommitError, newState)
+
+		require.Len(t, item.Attempts, 3)
+		for _, a := range item.Attempts {
+			require.Equal(t, OperationStatus_CommitSent, a.Status)
+			require.Equal(t, "connection refused", a.ErrorMessage)
+			require.False(t, a.Permanent)
+		}
+	})
+
+	t.Run("do not retry permanent error", func(t *testing.T) {
+		aqueue := newQueue(t)
+		item := &QueueItem{Index: 1, Status: OperationStatus_RenewSent}
+
+		newState, err := aqueue.handleRetry(ctx, permanent(errors.New("renew tx failed")), OperationStatus_RenewSent, OperationStatus_RenewError, item)
+		require.Error(t, err)
+		require.Equal(t, OperationStatus_RenewError, newState)
+		require.Len(t, item.Attempts, 1)
+		require.True(t, item.Attempts[0].Permanent)
+	})
+
+	t.Run("keep the state if node is stopping", func(t *testing.T) {
+		aqueue := newQueue(t)
+		item := &QueueItem{Index: 1, Status: OperationStatus_CommitDone}
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		newState, err := aqueue.handleRetry(cctx, context.Canceled, OperationStatus_CommitDone, OperationStatus_RegisterError, item)
+		require.Error(t, err)
+		require.Equal(t, OperationStatus_CommitDone, newState)
+		require.Empty(t, item.Attempts)
+	})
+}
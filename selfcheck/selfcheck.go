@@ -0,0 +1,345 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/contracts"
+)
+
+const CName = "any-ns.selfcheck"
+
+var log = logger.NewNamed(CName)
+
+const defaultTimeoutSec = 60
+
+func New() app.ComponentRunnable {
+	return &anynsSelfCheck{}
+}
+
+type CheckResult struct {
+	Name string
+	// nil if check has passed
+	Err error
+	// node can work even if this check has failed
+	Warning bool
+}
+
+func (r CheckResult) Failed() bool {
+	return r.Err != nil && !r.Warning
+}
+
+// Checks that contracts are deployed at all configured addresses and that the admin
+// has all permissions that are required to register names
+// Node does not start if any check has failed (unless selfCheck.skip is set)
+type SelfCheckService interface {
+	// runs all checks, does not stop on the first failure
+	Check(ctx context.Context) []CheckResult
+
+	app.ComponentRunnable
+}
+
+type anynsSelfCheck struct {
+	confSelfCheck config.SelfCheck
+	confContracts config.Contracts
+	confAA        config.AA
+
+	contracts contracts.ContractsService
+}
+
+func (sc *anynsSelfCheck) Name() (name string) {
+	return CName
+}
+
+func (sc *anynsSelfCheck) Init(a *app.App) (err error) {
+	sc.confSelfCheck = a.MustComponent(config.CName).(*config.Config).GetSelfCheck()
+	sc.confContracts = a.MustComponent(config.CName).(*config.Config).GetContracts()
+	sc.confAA = a.MustComponent(config.CName).(*config.Config).GetAA()
+	sc.contracts = a.MustComponent(contracts.CName).(contracts.ContractsService)
+
+	if sc.confSelfCheck.TimeoutSec == 0 {
+		sc.confSelfCheck.TimeoutSec = defaultTimeoutSec
+	}
+	return nil
+}
+
+func (sc *anynsSelfCheck) Run(ctx context.Context) (err error) {
+	if sc.confSelfCheck.Skip {
+		log.Warn("self-check is skipped")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(sc.confSelfCheck.TimeoutSec)*time.Second)
+	defer cancel()
+
+	var failed []string
+	for _, r := range sc.Check(ctx) {
+		switch {
+		case r.Err == nil:
+			log.Debug("self-check passed", zap.String("check", r.Name))
+		case r.Warning:
+			log.Warn("self-check warning", zap.String("check", r.Name), zap.Error(r.Err))
+		default:
+			log.Error("self-check failed", zap.String("check", r.Name), zap.Error(r.Err))
+			failed = append(failed, fmt.Sprintf("%s: %s", r.Name, r.Err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("self-check failed (set selfCheck.skip to start anyway): %s", strings.Join(failed, "; "))
+	}
+	log.Info("self-check passed")
+	return nil
+}
+
+func (sc *anynsSelfCheck) Close(ctx context.Context) (err error) {
+	return nil
+}
+
+type checker struct {
+	results []CheckResult
+}
+
+func (c *checker) add(name string, err error) {
+	c.results = append(c.results, CheckResult{Name: name, Err: err})
+}
+
+func (c *checker) warn(name string, err error) {
+	c.results = append(c.results, CheckResult{Name: name, Err: err, Warning: true})
+}
+
+func (sc *anynsSelfCheck) Check(ctx context.Context) []CheckResult {
+	c := &checker{}
+
+	// 1 - chain
+	sc.checkChainID(ctx, c)
+
+	// 2 - code at every configured address
+	// checks of the contract are skipped if it is not deployed
+	addrs := [][2]string{
+		{"contracts.ensRegistry", sc.confContracts.AddrRegistry},
+		{"contracts.resolver", sc.confContracts.AddrResolver},
+		{"contracts.registrarImplementation", sc.confContracts.AddrRegistrarImplementation},
+		{"contracts.registrarController", sc.confContracts.AddrRegistrarConroller},
+		{"contracts.registrarControllerPrivate", sc.confContracts.AddrRegistrarPrivateController},
+		{"contracts.nameToken", sc.confContracts.AddrToken},
+		{"contracts.nameWrapper", sc.confContracts.AddrNameWrapper},
+		{"accountAbstraction.entryPoint", sc.confAA.EntryPoint},
+		{"accountAbstraction.accountFactory", sc.confAA.AccountFactory},
+	}
+	deployed := make(map[string]common.Address)
+	for _, a := range addrs {
+		addr, err := sc.checkDeployed(ctx, a[1])
+		c.add("code at "+a[0], err)
+		if err == nil {
+			deployed[a[0]] = addr
+		}
+	}
+
+	// multicall is optional
+	if sc.confContracts.AddrMulticall != "" {
+		_, err := sc.checkDeployed(ctx, sc.confContracts.AddrMulticall)
+		if err != nil {
+			c.warn("code at contracts.multicall", fmt.Errorf("%w, each value will be read with a separate call", err))
+		}
+	}
+
+	// 3 - token
+	if token, ok := deployed["contracts.nameToken"]; ok {
+		c.add("contracts.tokenDecimals", sc.checkTokenDecimals(ctx, token))
+	}
+
+	// 4 - AA contracts respond and admin has SCW
+	if !common.IsHexAddress(sc.confContracts.AddrAdmin) {
+		c.add("contracts.admin", errors.New("admin address is not set"))
+		return c.results
+	}
+	admin := common.HexToAddress(sc.confContracts.AddrAdmin)
+
+	if entryPoint, ok := deployed["accountAbstraction.entryPoint"]; ok {
+		_, err := sc.call(ctx, entryPoint, entryPointABI, "getNonce", admin, big.NewInt(0))
+		c.add("entry point responds", err)
+	}
+
+	var adminScw common.Address
+	if factory, ok := deployed["accountAbstraction.accountFactory"]; ok {
+		var err error
+		adminScw, err = sc.getScwAddress(ctx, factory, admin)
+		c.add("account factory responds", err)
+		if err == nil {
+			sc.checkAdminScw(ctx, c, admin, adminScw)
+		}
+	}
+
+	// 5 - permissions of the admin
+	admins := []common.Address{admin}
+	if adminScw != (common.Address{}) {
+		admins = append(admins, adminScw)
+	}
+
+	// commit/register/renew of the queue and the admin's user operations
+	if controller, ok := deployed["contracts.registrarControllerPrivate"]; ok {
+		c.add("admin owns contracts.registrarControllerPrivate", sc.checkOwner(ctx, controller, admins))
+	}
+	// tokens are minted by the admin's SCW
+	if token, ok := deployed["contracts.nameToken"]; ok {
+		c.add("admin owns contracts.nameToken", sc.checkOwner(ctx, token, admins))
+	}
+
+	// 6 - controllers
+	if wrapper, ok := deployed["contracts.nameWrapper"]; ok {
+		for _, name := range []string{"contracts.registrarController", "contracts.registrarControllerPrivate"} {
+			if controller, ok := deployed[name]; ok {
+				c.add(name+" is a controller of contracts.nameWrapper", sc.checkController(ctx, wrapper, controller))
+			}
+		}
+		if registrar, ok := deployed["contracts.registrarImplementation"]; ok {
+			c.add("contracts.nameWrapper is a controller of contracts.registrarImplementation", sc.checkController(ctx, registrar, wrapper))
+		}
+	}
+
+	return c.results
+}
+
+func (sc *anynsSelfCheck) checkChainID(ctx context.Context, c *checker) {
+	chainID, err := sc.contracts.GetChainID(ctx)
+	if err != nil {
+		c.add("chain ID", fmt.Errorf("can not get chain ID: %w", err))
+		return
+	}
+
+	if chainID.Cmp(big.NewInt(int64(sc.confAA.ChainID))) != 0 {
+		c.add("chain ID", fmt.Errorf("node is connected to the chain %s, but accountAbstraction.chainID is %d", chainID, sc.confAA.ChainID))
+		return
+	}
+	c.add("chain ID", nil)
+}
+
+func (sc *anynsSelfCheck) checkDeployed(ctx context.Context, addrStr string) (common.Address, error) {
+	if !common.IsHexAddress(addrStr) {
+		return common.Address{}, fmt.Errorf("invalid address %q", addrStr)
+	}
+	addr := common.HexToAddress(addrStr)
+
+	isDeployed, err := sc.contracts.IsContractDeployed(ctx, addr)
+	if err != nil {
+		return addr, fmt.Errorf("can not get code: %w", err)
+	}
+	if !isDeployed {
+		return addr, fmt.Errorf("no contract at %s", addr.Hex())
+	}
+	return addr, nil
+}
+
+func (sc *anynsSelfCheck) checkTokenDecimals(ctx context.Context, token common.Address) error {
+	out, err := sc.call(ctx, token, erc20DecimalsABI, "decimals")
+	if err != nil {
+		return err
+	}
+
+	decimals := out[0].(uint8)
+	if decimals != sc.confContracts.TokenDecimals {
+		return fmt.Errorf("token has %d decimals, but contracts.tokenDecimals is %d", decimals, sc.confContracts.TokenDecimals)
+	}
+	return nil
+}
+
+func (sc *anynsSelfCheck) getScwAddress(ctx context.Context, factory common.Address, owner common.Address) (common.Address, error) {
+	out, err := sc.call(ctx, factory, factoryABI, "getAddress", owner, big.NewInt(0))
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	scw := out[0].(common.Address)
+	if scw == (common.Address{}) {
+		return common.Address{}, errors.New("factory returned zero SCW address")
+	}
+	return scw, nil
+}
+
+// SCW is deployed with the first user operation of the admin
+func (sc *anynsSelfCheck) checkAdminScw(ctx context.Context, c *checker, admin common.Address, scw common.Address) {
+	isDeployed, err := sc.contracts.IsContractDeployed(ctx, scw)
+	if err != nil {
+		c.add("admin SCW", fmt.Errorf("can not get code: %w", err))
+		return
+	}
+	if !isDeployed {
+		c.warn("admin SCW", fmt.Errorf("SCW %s is not deployed yet, it will be deployed with the first user operation", scw.Hex()))
+		return
+	}
+
+	owner, err := sc.contracts.GetScwOwner(ctx, scw, contracts.BlockRef{})
+	if err != nil {
+		c.add("admin SCW", fmt.Errorf("can not get owner of the SCW: %w", err))
+		return
+	}
+	if owner != admin {
+		c.add("admin SCW", fmt.Errorf("owner of the SCW %s is %s, not the admin", scw.Hex(), owner.Hex()))
+		return
+	}
+	c.add("admin SCW", nil)
+}
+
+// owner should be the admin or the admin's SCW
+func (sc *anynsSelfCheck) checkOwner(ctx context.Context, contract common.Address, admins []common.Address) error {
+	out, err := sc.call(ctx, contract, ownableABI, "owner")
+	if err != nil {
+		return err
+	}
+
+	owner := out[0].(common.Address)
+	for _, a := range admins {
+		if owner == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("owner is %s, not the admin or the admin's SCW", owner.Hex())
+}
+
+func (sc *anynsSelfCheck) checkController(ctx context.Context, contract common.Address, controller common.Address) error {
+	out, err := sc.call(ctx, contract, controllersABI, "controllers", controller)
+	if err != nil {
+		return err
+	}
+
+	if !out[0].(bool) {
+		return fmt.Errorf("%s is not a controller", controller.Hex())
+	}
+	return nil
+}
+
+func (sc *anynsSelfCheck) call(ctx context.Context, to common.Address, abiJSON string, method string, args ...interface{}) ([]interface{}, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := sc.contracts.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input})
+	if err != nil {
+		return nil, fmt.Errorf("%s() failed: %w", method, err)
+	}
+
+	out, err := parsedABI.Unpack(method, res)
+	if err != nil {
+		return nil, fmt.Errorf("%s() returned invalid data: %w", method, err)
+	}
+	return out, nil
+}
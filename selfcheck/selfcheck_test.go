@@ -0,0 +1,274 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/anyproto/any-ns-node/config"
+	"github.com/anyproto/any-ns-node/contracts"
+	mock_contracts "github.com/anyproto/any-ns-node/contracts/mock"
+)
+
+var ctx = context.Background()
+
+var (
+	addrRegistry          = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	addrResolver          = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	addrRegistrar         = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	addrController        = common.HexToAddress("0x0000000000000000000000000000000000000004")
+	addrPrivateController = common.HexToAddress("0x0000000000000000000000000000000000000005")
+	addrToken             = common.HexToAddress("0x0000000000000000000000000000000000000006")
+	addrNameWrapper       = common.HexToAddress("0x0000000000000000000000000000000000000007")
+	addrEntryPoint        = common.HexToAddress("0x0000000000000000000000000000000000000008")
+	addrFactory           = common.HexToAddress("0x0000000000000000000000000000000000000009")
+	addrAdmin             = common.HexToAddress("0x00000000000000000000000000000000000000a0")
+	addrAdminScw          = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+)
+
+// chain where everything is configured correctly
+type testChain struct {
+	chainID     int64
+	deployed    map[common.Address]bool
+	owners      map[common.Address]common.Address
+	decimals    uint8
+	controllers map[common.Address][]common.Address
+}
+
+func newTestChain() *testChain {
+	return &testChain{
+		chainID: 11155111,
+		deployed: map[common.Address]bool{
+			addrRegistry: true, addrResolver: true, addrRegistrar: true, addrController: true, addrPrivateController: true,
+			addrToken: true, addrNameWrapper: true, addrEntryPoint: true, addrFactory: true, addrAdminScw: true,
+		},
+		owners: map[common.Address]common.Address{
+			addrPrivateController: addrAdmin,
+			addrToken:             addrAdminScw,
+		},
+		decimals: 6,
+		controllers: map[common.Address][]common.Address{
+			addrNameWrapper: {addrController, addrPrivateController},
+			addrRegistrar:   {addrNameWrapper},
+		},
+	}
+}
+
+func (tc *testChain) call(t *testing.T, msg ethereum.CallMsg) ([]byte, error) {
+	to := *msg.To
+	if !tc.deployed[to] {
+		return nil, nil
+	}
+
+	for _, abiJSON := range []string{ownableABI, erc20DecimalsABI, controllersABI, factoryABI, entryPointABI} {
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		require.NoError(t, err)
+
+		method, err := parsed.MethodById(msg.Data[:4])
+		if err != nil {
+			continue
+		}
+		args, err := method.Inputs.Unpack(msg.Data[4:])
+		require.NoError(t, err)
+
+		switch method.Name {
+		case "owner":
+			owner, ok := tc.owners[to]
+			if !ok {
+				return nil, errors.New("execution reverted")
+			}
+			return method.Outputs.Pack(owner)
+		case "decimals":
+			return method.Outputs.Pack(tc.decimals)
+		case "controllers":
+			for _, c := range tc.controllers[to] {
+				if c == args[0].(common.Address) {
+					return method.Outputs.Pack(true)
+				}
+			}
+			return method.Outputs.Pack(false)
+		case "getAddress":
+			assert.Equal(t, args[0].(common.Address), addrAdmin)
+			return method.Outputs.Pack(addrAdminScw)
+		case "getNonce":
+			return method.Outputs.Pack(big.NewInt(3))
+		}
+	}
+	return nil, errors.New("unknown method")
+}
+
+type fixture struct {
+	a         *app.App
+	ctrl      *gomock.Controller
+	config    *config.Config
+	contracts *mock_contracts.MockContractsService
+	chain     *testChain
+
+	*anynsSelfCheck
+}
+
+func newFixture(t *testing.T) *fixture {
+	fx := &fixture{
+		a:              new(app.App),
+		ctrl:           gomock.NewController(t),
+		config:         new(config.Config),
+		chain:          newTestChain(),
+		anynsSelfCheck: New().(*anynsSelfCheck),
+	}
+
+	fx.config.Contracts = config.Contracts{
+		AddrRegistry:                   addrRegistry.Hex(),
+		AddrResolver:                   addrResolver.Hex(),
+		AddrRegistrarImplementation:    addrRegistrar.Hex(),
+		AddrRegistrarConroller:         addrController.Hex(),
+		AddrRegistrarPrivateController: addrPrivateController.Hex(),
+		AddrToken:                      addrToken.Hex(),
+		TokenDecimals:                  6,
+		AddrNameWrapper:                addrNameWrapper.Hex(),
+		AddrAdmin:                      addrAdmin.Hex(),
+	}
+	fx.config.Aa = config.AA{
+		EntryPoint:     addrEntryPoint.Hex(),
+		AccountFactory: addrFactory.Hex(),
+		ChainID:        11155111,
+	}
+
+	fx.contracts = mock_contracts.NewMockContractsService(fx.ctrl)
+	fx.contracts.EXPECT().Name().Return(contracts.CName).AnyTimes()
+	fx.contracts.EXPECT().Init(gomock.Any()).AnyTimes()
+	fx.contracts.EXPECT().GetChainID(gomock.Any()).DoAndReturn(func(ctx context.Context) (*big.Int, error) {
+		return big.NewInt(fx.chain.chainID), nil
+	}).AnyTimes()
+	fx.contracts.EXPECT().IsContractDeployed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, addr common.Address) (bool, error) {
+		return fx.chain.deployed[addr], nil
+	}).AnyTimes()
+	fx.contracts.EXPECT().CallContract(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+		return fx.chain.call(t, msg)
+	}).AnyTimes()
+	fx.contracts.EXPECT().GetScwOwner(gomock.Any(), addrAdminScw, gomock.Any()).Return(addrAdmin, nil).AnyTimes()
+
+	fx.a.Register(fx.config).
+		Register(fx.contracts).
+		Register(fx.anynsSelfCheck)
+
+	// chain is configured correctly, so the check on start passes
+	require.NoError(t, fx.a.Start(ctx))
+	return fx
+}
+
+func (fx *fixture) finish(t *testing.T) {
+	assert.NoError(t, fx.a.Close(ctx))
+	fx.ctrl.Finish()
+}
+
+// names of the failed checks
+func failed(results []CheckResult) (out []string) {
+	for _, r := range results {
+		if r.Failed() {
+			out = append(out, r.Name)
+		}
+	}
+	return out
+}
+
+func TestSelfCheck_Check(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		results := fx.Check(ctx)
+		assert.Equal(t, len(failed(results)), 0)
+		assert.Equal(t, len(results), 19)
+		assert.NoError(t, fx.Run(ctx))
+	})
+
+	t.Run("fail if chain ID is different", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.chainID = 1
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{"chain ID"})
+		assert.Error(t, fx.Run(ctx))
+	})
+
+	t.Run("fail if contract is not deployed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		// dependent checks are skipped
+		fx.chain.deployed[addrNameWrapper] = false
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{"code at contracts.nameWrapper"})
+	})
+
+	t.Run("fail if address is invalid", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.confAA.EntryPoint = "0x123"
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{"code at accountAbstraction.entryPoint"})
+	})
+
+	t.Run("fail if token decimals are different", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.decimals = 18
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{"contracts.tokenDecimals"})
+	})
+
+	t.Run("fail if admin is not an owner", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.owners[addrPrivateController] = addrRegistry
+		delete(fx.chain.owners, addrToken)
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{
+			"admin owns contracts.registrarControllerPrivate",
+			"admin owns contracts.nameToken",
+		})
+	})
+
+	t.Run("fail if controller is not set", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.controllers[addrNameWrapper] = []common.Address{addrController}
+		assert.DeepEqual(t, failed(fx.Check(ctx)), []string{"contracts.registrarControllerPrivate is a controller of contracts.nameWrapper"})
+	})
+
+	t.Run("warn if admin SCW is not deployed", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.deployed[addrAdminScw] = false
+		results := fx.Check(ctx)
+		assert.Equal(t, len(failed(results)), 0)
+
+		var warnings []string
+		for _, r := range results {
+			if r.Warning {
+				warnings = append(warnings, r.Name)
+			}
+		}
+		assert.DeepEqual(t, warnings, []string{"admin SCW"})
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		fx := newFixture(t)
+		defer fx.finish(t)
+
+		fx.chain.chainID = 1
+		fx.confSelfCheck.Skip = true
+		assert.NoError(t, fx.Run(ctx))
+	})
+}
@@ -0,0 +1,137 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+const defaultRemoteTimeoutSec = 10
+
+// signs with the Web3Signer-compatible API (eth_sign, eth_signTransaction)
+// key is never sent to the node
+type remoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	// applied to each request (in addition to the caller's deadline)
+	timeout time.Duration
+}
+
+// arguments of eth_signTransaction
+type txArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Data                 hexutil.Bytes   `json:"data"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	ChainID              *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+func newRemoteSigner(conf config.Signer, admin string) (*remoteSigner, error) {
+	if conf.RemoteUrl == "" {
+		return nil, errors.New("signer.remoteUrl is not set")
+	}
+	if !common.IsHexAddress(admin) {
+		return nil, errors.New("contracts.admin is not set, it is required for the remote signer")
+	}
+
+	// does not connect, HTTP requests are sent on each call
+	client, err := rpc.DialOptions(context.Background(), conf.RemoteUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutSec := conf.RemoteTimeoutSec
+	if timeoutSec == 0 {
+		timeoutSec = defaultRemoteTimeoutSec
+	}
+
+	return &remoteSigner{
+		client:  client,
+		address: common.HexToAddress(admin),
+		timeout: time.Duration(timeoutSec) * time.Second,
+	}, nil
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// 1 - send all fields of the tx
+	args := txArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Data:    tx.Data(),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	}
+
+	var raw hexutil.Bytes
+	err := s.client.CallContext(ctx, &raw, "eth_signTransaction", args)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed to sign tx: %w", err)
+	}
+
+	// 2 - check that the signer has signed exactly what we asked
+	signed := new(types.Transaction)
+	err = signed.UnmarshalBinary(raw)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid tx: %w", err)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid signature: %w", err)
+	}
+	if from != s.address || signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() ||
+		signed.To() == nil || tx.To() == nil || *signed.To() != *tx.To() ||
+		signed.Value().Cmp(tx.Value()) != 0 || !bytes.Equal(signed.Data(), tx.Data()) {
+		return nil, errors.New("remote signer returned another tx")
+	}
+	return signed, nil
+}
+
+func (s *remoteSigner) SignMessage(ctx context.Context, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var sig hexutil.Bytes
+	err := s.client.CallContext(ctx, &sig, "eth_sign", s.address, hexutil.Bytes(data))
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed to sign message: %w", err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("remote signer returned signature of %d bytes", len(sig))
+	}
+	// some signers return V as 0 or 1
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
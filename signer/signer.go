@@ -0,0 +1,162 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+const CName = "any-ns.signer"
+
+const (
+	TypeKeystore   = "keystore"
+	TypeWeb3Signer = "web3signer"
+)
+
+var log = logger.NewNamed(CName)
+
+// signs txs and user operations of the admin
+// private key is never stored in the config: it is either decrypted from the keystore file
+// or is kept by the remote signer
+type Signer interface {
+	// address of the admin
+	Address() common.Address
+	// returns the signed copy of the tx (replay-protected with chainID)
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// EIP-191 signature ("\x19Ethereum Signed Message:\n" + len(data) + data), V is 27 or 28
+	SignMessage(ctx context.Context, data []byte) ([]byte, error)
+
+	app.Component
+}
+
+type signerImpl interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	SignMessage(ctx context.Context, data []byte) ([]byte, error)
+}
+
+func New() app.Component {
+	return &anynsSigner{}
+}
+
+// key is kept in memory (for tests and tools)
+func NewWithKey(key *ecdsa.PrivateKey) Signer {
+	return &anynsSigner{signerImpl: &keySigner{key: key}}
+}
+
+type anynsSigner struct {
+	signerImpl
+}
+
+func (s *anynsSigner) Name() (name string) {
+	return CName
+}
+
+func (s *anynsSigner) Init(a *app.App) (err error) {
+	if s.signerImpl != nil {
+		// created with the key
+		return nil
+	}
+
+	conf := a.MustComponent(config.CName).(*config.Config)
+	signerConf := conf.GetSigner()
+	admin := conf.GetContracts().AddrAdmin
+
+	switch signerConf.Type {
+	case "", TypeKeystore:
+		s.signerImpl, err = newKeystoreSigner(signerConf)
+	case TypeWeb3Signer:
+		s.signerImpl, err = newRemoteSigner(signerConf, admin)
+	default:
+		err = fmt.Errorf("unknown signer type: %s", signerConf.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if admin != "" && s.Address() != common.HexToAddress(admin) {
+		return fmt.Errorf("signer address %s does not match contracts.admin %s", s.Address().Hex(), admin)
+	}
+
+	log.Info("admin signer is ready", zap.String("type", signerConf.Type), zap.String("address", s.Address().Hex()))
+	return nil
+}
+
+// signs with the key in memory
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newKeystoreSigner(conf config.Signer) (*keySigner, error) {
+	if conf.KeystorePath == "" {
+		return nil, errors.New("signer.keystorePath is not set")
+	}
+
+	data, err := os.ReadFile(conf.KeystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("can not read keystore file: %w", err)
+	}
+
+	passphrase, err := readPassphrase(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("can not decrypt keystore file: %w", err)
+	}
+	return &keySigner{key: key.PrivateKey}, nil
+}
+
+// env variable is checked first, then the file
+func readPassphrase(conf config.Signer) (string, error) {
+	if conf.PassphraseEnv != "" {
+		if passphrase, ok := os.LookupEnv(conf.PassphraseEnv); ok {
+			return passphrase, nil
+		}
+	}
+
+	if conf.PassphraseFile != "" {
+		data, err := os.ReadFile(conf.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("can not read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	return "", errors.New("passphrase of the keystore is not set (signer.passphraseEnv or signer.passphraseFile)")
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+func (s *keySigner) SignMessage(ctx context.Context, data []byte) ([]byte, error) {
+	sig, err := crypto.Sign(accounts.TextHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
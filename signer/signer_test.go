@@ -0,0 +1,260 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/assert"
+
+	"github.com/anyproto/any-ns-node/config"
+)
+
+var ctx = context.Background()
+
+var testTo = common.HexToAddress("0x0000000000000000000000000000000000000042")
+
+func newKeystoreFile(t *testing.T, passphrase string) (string, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	id, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	data, err := keystore.EncryptKey(&keystore.Key{Id: id, Address: address, PrivateKey: key}, passphrase, keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "admin.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path, address
+}
+
+func startSigner(t *testing.T, conf *config.Config) (Signer, error) {
+	a := new(app.App)
+	s := New().(Signer)
+	a.Register(conf).Register(s)
+	err := a.Start(ctx)
+	if err == nil {
+		t.Cleanup(func() { _ = a.Close(ctx) })
+	}
+	return s, err
+}
+
+func checkSigner(t *testing.T, s Signer) {
+	// tx
+	chainID := big.NewInt(11155111)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     5,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &testTo,
+		Value:     big.NewInt(0),
+		Data:      []byte{1, 2, 3},
+	})
+	signed, err := s.SignTx(ctx, tx, chainID)
+	require.NoError(t, err)
+
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	assert.Equal(t, from, s.Address())
+	assert.Equal(t, signed.Nonce(), uint64(5))
+
+	// message
+	data := crypto.Keccak256([]byte("user operation"))
+	sig, err := s.SignMessage(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, len(sig), 65)
+	assert.True(t, sig[64] == 27 || sig[64] == 28)
+
+	recoverable := common.CopyBytes(sig)
+	recoverable[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(data), recoverable)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(*pub), s.Address())
+}
+
+func TestSigner_Keystore(t *testing.T) {
+	const passphrase = "secret passphrase"
+
+	t.Run("passphrase from env", func(t *testing.T) {
+		path, address := newKeystoreFile(t, passphrase)
+		t.Setenv("ANYNS_TEST_PASSPHRASE", passphrase)
+
+		conf := new(config.Config)
+		conf.Contracts.AddrAdmin = address.Hex()
+		conf.Signer = config.Signer{KeystorePath: path, PassphraseEnv: "ANYNS_TEST_PASSPHRASE"}
+
+		s, err := startSigner(t, conf)
+		require.NoError(t, err)
+		assert.Equal(t, s.Address(), address)
+		checkSigner(t, s)
+	})
+
+	t.Run("passphrase from file", func(t *testing.T) {
+		path, address := newKeystoreFile(t, passphrase)
+		passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+		require.NoError(t, os.WriteFile(passphraseFile, []byte(passphrase+"\n"), 0600))
+
+		conf := new(config.Config)
+		conf.Signer = config.Signer{Type: TypeKeystore, KeystorePath: path, PassphraseEnv: "ANYNS_TEST_NOT_SET", PassphraseFile: passphraseFile}
+
+		s, err := startSigner(t, conf)
+		require.NoError(t, err)
+		assert.Equal(t, s.Address(), address)
+		checkSigner(t, s)
+	})
+
+	t.Run("fail if passphrase is wrong", func(t *testing.T) {
+		path, _ := newKeystoreFile(t, passphrase)
+		t.Setenv("ANYNS_TEST_PASSPHRASE", "wrong")
+
+		conf := new(config.Config)
+		conf.Signer = config.Signer{KeystorePath: path, PassphraseEnv: "ANYNS_TEST_PASSPHRASE"}
+
+		_, err := startSigner(t, conf)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail if passphrase is not set", func(t *testing.T) {
+		path, _ := newKeystoreFile(t, passphrase)
+
+		conf := new(config.Config)
+		conf.Signer = config.Signer{KeystorePath: path}
+
+		_, err := startSigner(t, conf)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail if admin does not match", func(t *testing.T) {
+		path, _ := newKeystoreFile(t, passphrase)
+		t.Setenv("ANYNS_TEST_PASSPHRASE", passphrase)
+
+		conf := new(config.Config)
+		conf.Contracts.AddrAdmin = testTo.Hex()
+		conf.Signer = config.Signer{KeystorePath: path, PassphraseEnv: "ANYNS_TEST_PASSPHRASE"}
+
+		_, err := startSigner(t, conf)
+		assert.Error(t, err)
+	})
+}
+
+// Web3Signer stub that signs with the key
+// if tamper is set - it changes the tx before signing
+func newWeb3SignerStub(t *testing.T, tamper bool) (*httptest.Server, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keySigner := &keySigner{key: key}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		switch req.Method {
+		case "eth_sign":
+			var from common.Address
+			var data hexutil.Bytes
+			require.NoError(t, json.Unmarshal(req.Params[0], &from))
+			require.NoError(t, json.Unmarshal(req.Params[1], &data))
+			assert.Equal(t, from, keySigner.Address())
+
+			sig, err := crypto.Sign(accounts.TextHash(data), key)
+			require.NoError(t, err)
+			// V is 0 or 1, signer should fix it
+			result = hexutil.Bytes(sig)
+		case "eth_signTransaction":
+			var args txArgs
+			require.NoError(t, json.Unmarshal(req.Params[0], &args))
+			if tamper {
+				args.Value = (*hexutil.Big)(big.NewInt(1))
+			}
+
+			tx := types.NewTx(&types.DynamicFeeTx{
+				ChainID:   (*big.Int)(args.ChainID),
+				Nonce:     uint64(args.Nonce),
+				GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+				GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+				Gas:       uint64(args.Gas),
+				To:        args.To,
+				Value:     (*big.Int)(args.Value),
+				Data:      args.Data,
+			})
+			signed, err := keySigner.SignTx(ctx, tx, (*big.Int)(args.ChainID))
+			require.NoError(t, err)
+			raw, err := signed.MarshalBinary()
+			require.NoError(t, err)
+			result = hexutil.Bytes(raw)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, keySigner.Address()
+}
+
+func TestSigner_Web3Signer(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		srv, address := newWeb3SignerStub(t, false)
+
+		conf := new(config.Config)
+		conf.Contracts.AddrAdmin = address.Hex()
+		conf.Signer = config.Signer{Type: TypeWeb3Signer, RemoteUrl: srv.URL}
+
+		s, err := startSigner(t, conf)
+		require.NoError(t, err)
+		assert.Equal(t, s.Address(), address)
+		checkSigner(t, s)
+	})
+
+	t.Run("fail if tx was changed by the signer", func(t *testing.T) {
+		srv, address := newWeb3SignerStub(t, true)
+
+		conf := new(config.Config)
+		conf.Contracts.AddrAdmin = address.Hex()
+		conf.Signer = config.Signer{Type: TypeWeb3Signer, RemoteUrl: srv.URL}
+
+		s, err := startSigner(t, conf)
+		require.NoError(t, err)
+
+		chainID := big.NewInt(1)
+		tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), Gas: 21000, To: &testTo, Value: big.NewInt(0)})
+		_, err = s.SignTx(ctx, tx, chainID)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail if admin is not set", func(t *testing.T) {
+		conf := new(config.Config)
+		conf.Signer = config.Signer{Type: TypeWeb3Signer, RemoteUrl: "http://127.0.0.1:1"}
+
+		_, err := startSigner(t, conf)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail if type is unknown", func(t *testing.T) {
+		conf := new(config.Config)
+		conf.Signer = config.Signer{Type: "hsm"}
+
+		_, err := startSigner(t, conf)
+		assert.Error(t, err)
+	})
+}